@@ -0,0 +1,114 @@
+package linkpreview
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stored for URLs whose fetch failed
+const failedMarker = "null"
+
+type redisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a preview cache shared by all nodes
+func NewRedisCache(client *redis.Client) Cache {
+	return &redisCache{client: client}
+}
+
+func cacheKey(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return "linkpreview:" + hex.EncodeToString(sum[:])
+}
+
+func (c *redisCache) Get(ctx context.Context, rawURL string) (*Preview, bool, error) {
+	data, err := c.client.Get(ctx, cacheKey(rawURL)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get link preview: %w", err)
+	}
+
+	if data == failedMarker {
+		return nil, true, nil
+	}
+
+	var preview Preview
+	if err := json.Unmarshal([]byte(data), &preview); err != nil {
+		return nil, false, fmt.Errorf("failed to decode link preview: %w", err)
+	}
+	return &preview, true, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, rawURL string, preview *Preview, ttl time.Duration) error {
+	data := []byte(failedMarker)
+	if preview != nil {
+		var err error
+		if data, err = json.Marshal(preview); err != nil {
+			return fmt.Errorf("failed to encode link preview: %w", err)
+		}
+	}
+
+	if err := c.client.Set(ctx, cacheKey(rawURL), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache link preview: %w", err)
+	}
+	return nil
+}
+
+type memoryEntry struct {
+	preview   *Preview
+	expiresAt time.Time
+}
+
+type memoryCache struct {
+	entries map[string]memoryEntry
+	mu      sync.Mutex
+}
+
+// NewMemoryCache creates an in-process preview cache.
+// It is used when Redis is unavailable; each node then fetches previews on its own.
+func NewMemoryCache() Cache {
+	return &memoryCache{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+func (c *memoryCache) Get(_ context.Context, rawURL string) (*Preview, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[rawURL]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, rawURL)
+		return nil, false, nil
+	}
+	return entry.preview, true, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, rawURL string, preview *Preview, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.entries[rawURL] = memoryEntry{preview: preview, expiresAt: now.Add(ttl)}
+	return nil
+}
@@ -0,0 +1,169 @@
+package linkpreview
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	maxRedirects = 3
+	userAgent    = "VNaloBot/1.0 (+link preview)"
+)
+
+// Carrier-grade NAT space, which net.IP.IsPrivate does not cover
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Fetcher downloads pages for previews without letting a URL reach internal services.
+// Every address is checked at connect time, after DNS resolution, so redirects and
+// DNS rebinding cannot point it at a private network.
+type Fetcher struct {
+	client *http.Client
+	cfg    Config
+}
+
+// NewFetcher creates a Fetcher
+func NewFetcher(cfg Config) *Fetcher {
+	f := &Fetcher{cfg: cfg}
+
+	dialer := &net.Dialer{
+		Timeout: cfg.Timeout,
+		Control: f.checkAddress,
+	}
+
+	f.client = &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // A proxy would connect on our behalf and bypass checkAddress
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   cfg.Timeout,
+			ResponseHeaderTimeout: cfg.Timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+
+	return f
+}
+
+// Fetch downloads a page and extracts its preview
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrBlockedURL
+	}
+	if err := f.checkURL(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", target.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", ErrBadResponse, resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	// Resolve relative image URLs against the page we ended up on
+	return parsePreview(io.LimitReader(resp.Body, f.cfg.MaxBodyBytes), resp.Request.URL), nil
+}
+
+// checkURL rejects URLs by scheme, port and host name
+func (f *Fetcher) checkURL(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return ErrBlockedURL
+	}
+	if target.User != nil {
+		return ErrBlockedURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if host == "" {
+		return ErrBlockedURL
+	}
+
+	if port := target.Port(); port != "" && !f.cfg.AllowPrivateNetworks && port != "80" && port != "443" {
+		return ErrBlockedURL
+	}
+
+	if matchesHost(host, f.cfg.BlockedHosts) {
+		return ErrBlockedURL
+	}
+	if len(f.cfg.AllowedHosts) > 0 && !matchesHost(host, f.cfg.AllowedHosts) {
+		return ErrBlockedURL
+	}
+
+	return nil
+}
+
+// checkAddress runs before every connection with the resolved address
+func (f *Fetcher) checkAddress(network, address string, _ syscall.RawConn) error {
+	if network != "tcp4" && network != "tcp6" {
+		return ErrBlockedURL
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlockedURL
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrBlockedURL
+	}
+	if !f.cfg.AllowPrivateNetworks && !isPublicIP(ip) {
+		return ErrBlockedURL
+	}
+
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// matchesHost reports whether host is one of hosts or a subdomain of one
+func matchesHost(host string, hosts []string) bool {
+	for _, candidate := range hosts {
+		candidate = strings.ToLower(strings.TrimSuffix(candidate, "."))
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var (
+	ErrBlockedURL  = errors.New("url is not allowed")
+	ErrNotHTML     = errors.New("response is not an html page")
+	ErrBadResponse = errors.New("unexpected response status")
+)
+
+// Preview is the OpenGraph summary of a web page
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+// IsEmpty reports whether the page had nothing worth showing
+func (p *Preview) IsEmpty() bool {
+	return p.Title == "" && p.Description == "" && p.ImageURL == ""
+}
+
+// Service generates link previews
+type Service interface {
+	// Get returns the preview of a URL, or nil when the page has none or cannot be fetched
+	Get(ctx context.Context, rawURL string) (*Preview, error)
+}
+
+// Config configures link preview generation
+type Config struct {
+	// Time allowed for a whole fetch, redirects included
+	Timeout time.Duration
+
+	// Pages are read up to MaxBodyBytes; OpenGraph tags live in the head
+	MaxBodyBytes int64
+
+	// When AllowedHosts is not empty only these hosts and their subdomains are fetched.
+	// BlockedHosts and their subdomains are never fetched.
+	AllowedHosts []string
+	BlockedHosts []string
+
+	// AllowPrivateNetworks lifts the ban on loopback and private addresses and
+	// non-standard ports. Only for local development and tests.
+	AllowPrivateNetworks bool
+
+	// How long previews, and failed fetches, are cached
+	CacheTTL   time.Duration
+	FailureTTL time.Duration
+}
+
+// Cache keeps previews by URL. A cached nil preview records a failed fetch.
+type Cache interface {
+	Get(ctx context.Context, rawURL string) (preview *Preview, found bool, err error)
+	Set(ctx context.Context, rawURL string, preview *Preview, ttl time.Duration) error
+}
+
+type cachedService struct {
+	fetcher *Fetcher
+	cache   Cache
+	cfg     Config
+}
+
+// NewService creates a link preview service that caches what it fetches
+func NewService(fetcher *Fetcher, cache Cache, cfg Config) Service {
+	return &cachedService{
+		fetcher: fetcher,
+		cache:   cache,
+		cfg:     cfg,
+	}
+}
+
+func (s *cachedService) Get(ctx context.Context, rawURL string) (*Preview, error) {
+	preview, found, err := s.cache.Get(ctx, rawURL)
+	if err != nil {
+		log.Printf("⚠️  Failed to read cached link preview: %v", err)
+	} else if found {
+		return preview, nil
+	}
+
+	preview, err = s.fetcher.Fetch(ctx, rawURL)
+	ttl := s.cfg.CacheTTL
+	if err != nil || preview.IsEmpty() {
+		// Remember failures for a while so a popular broken link is not refetched per message
+		preview, ttl = nil, s.cfg.FailureTTL
+	}
+
+	if cacheErr := s.cache.Set(ctx, rawURL, preview, ttl); cacheErr != nil {
+		log.Printf("⚠️  Failed to cache link preview: %v", cacheErr)
+	}
+
+	return preview, err
+}
@@ -0,0 +1,130 @@
+package linkpreview
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+)
+
+// parsePreview reads OpenGraph tags from the head of a page, falling back to
+// the title element and the description meta tag
+func parsePreview(r io.Reader, pageURL *url.URL) *Preview {
+	preview := &Preview{URL: pageURL.String()}
+
+	var title, description string
+	tokenizer := html.NewTokenizer(r)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			// End of input, or of the bytes we were willing to read
+			return finishPreview(preview, title, description, pageURL)
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "body":
+				return finishPreview(preview, title, description, pageURL)
+
+			case "title":
+				if title == "" && tokenizer.Next() == html.TextToken {
+					title = string(tokenizer.Text())
+				}
+
+			case "meta":
+				key, content := metaAttributes(token)
+				switch key {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "og:image", "og:image:url", "og:image:secure_url":
+					if preview.ImageURL == "" {
+						preview.ImageURL = content
+					}
+				case "og:site_name":
+					preview.SiteName = content
+				case "description":
+					description = content
+				}
+			}
+
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return finishPreview(preview, title, description, pageURL)
+			}
+		}
+	}
+}
+
+func finishPreview(preview *Preview, title, description string, pageURL *url.URL) *Preview {
+	if preview.Title == "" {
+		preview.Title = title
+	}
+	if preview.Description == "" {
+		preview.Description = description
+	}
+
+	preview.Title = clean(preview.Title, maxTitleLength)
+	preview.Description = clean(preview.Description, maxDescriptionLength)
+	preview.SiteName = clean(preview.SiteName, maxTitleLength)
+	preview.ImageURL = resolveImage(preview.ImageURL, pageURL)
+
+	return preview
+}
+
+// metaAttributes returns the property (or name) and content of a meta tag
+func metaAttributes(token html.Token) (key string, content string) {
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = attr.Val
+		}
+	}
+	return key, content
+}
+
+// resolveImage makes the image URL absolute and drops anything that is not http(s)
+func resolveImage(raw string, pageURL *url.URL) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	image := pageURL.ResolveReference(ref)
+	if image.Scheme != "http" && image.Scheme != "https" {
+		return ""
+	}
+	return image.String()
+}
+
+// clean collapses whitespace and truncates to maxRunes
+func clean(text string, maxRunes int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, "")
+	}
+
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "…"
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Drivers a mailer can be configured with
+const (
+	DriverLog    = "log"    // print emails to the log, for local development
+	DriverMemory = "memory" // keep emails in memory, for tests
+	DriverSMTP   = "smtp"
+)
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// validHeader rejects values that would let user input add headers to an email
+func validHeader(value string) bool {
+	return !strings.ContainsAny(value, "\r\n")
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// LogMailer prints emails to the log instead of sending them
+type LogMailer struct{}
+
+// NewLogMailer creates a LogMailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(_ context.Context, message Message) error {
+	log.Printf("Mail (log) to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// MemoryMailer keeps emails in memory so tests can read them back
+type MemoryMailer struct {
+	sent []Message
+	mu   sync.Mutex
+}
+
+// NewMemoryMailer creates a MemoryMailer
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(_ context.Context, message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+// Sent returns the emails sent so far
+func (m *MemoryMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sent := make([]Message, len(m.sent))
+	copy(sent, m.sent)
+	return sent
+}
@@ -1,8 +1,10 @@
 package infrastructure
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gocql/gocql"
 	"github.com/redis/go-redis/v9"
@@ -13,7 +15,13 @@ import (
 
 	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/cache"
 	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/database/cassandra"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/linkpreview"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/mailer"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/push"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/sms"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
 	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
 )
 
 // Config interface for infrastructure providers
@@ -21,6 +29,26 @@ type Config interface {
 	GetPostgresConfig() PostgresConfig
 	GetCassandraConfig() CassandraConfig
 	GetRedisConfig() RedisConfig
+	GetWebSocketConfig() WebSocketConfig
+	GetJWTConfig() JWTConfig
+	GetPresenceConfig() PresenceConfig
+	GetTypingConfig() TypingConfig
+	GetReactionConfig() ReactionConfig
+	GetPinConfig() PinConfig
+	GetMentionConfig() MentionConfig
+	GetScheduledConfig() ScheduledConfig
+	GetStorageConfig() StorageConfig
+	GetLinkPreviewConfig() LinkPreviewConfig
+	GetPushConfig() PushConfig
+	GetMailConfig() MailConfig
+	GetAuthConfig() AuthConfig
+	GetTwoFactorConfig() TwoFactorConfig
+	GetOTPConfig() OTPConfig
+	GetSMSConfig() SMSConfig
+	GetProfileConfig() ProfileConfig
+	GetSearchConfig() SearchConfig
+	GetContactSyncConfig() ContactSyncConfig
+	GetAccountDeletionConfig() AccountDeletionConfig
 	GetServerMode() string
 }
 
@@ -47,6 +75,150 @@ type RedisConfig struct {
 	DB       int
 }
 
+type WebSocketConfig struct {
+	ReadBufferSize  int
+	WriteBufferSize int
+	ReplayRetention int
+	ReplayMaxEvents int
+}
+
+type JWTConfig struct {
+	Secret     string
+	Expiration int
+}
+
+type PresenceConfig struct {
+	TTL       int
+	AwayAfter int
+}
+
+type TypingConfig struct {
+	TTL                int
+	Throttle           int
+	AggregateThreshold int
+}
+
+type ReactionConfig struct {
+	SinglePerUser bool
+	Allowed       []string
+}
+
+type PinConfig struct {
+	MaxPerConversation int
+}
+
+type MentionConfig struct {
+	AllAdminOnlyAbove int
+}
+
+type StorageConfig struct {
+	Dir                string
+	BaseURL            string
+	SweepInterval      int
+	MaxAttachmentBytes int64
+}
+
+type LinkPreviewConfig struct {
+	Enabled              bool
+	Timeout              int
+	MaxBodyBytes         int64
+	AllowedHosts         []string
+	BlockedHosts         []string
+	AllowPrivateNetworks bool
+	CacheTTL             int
+	FailureTTL           int
+}
+
+type PushConfig struct {
+	Workers        int
+	QueueSize      int
+	CollapseWindow int
+	Timeout        int
+	Fake           bool
+
+	FCMEnabled         bool
+	FCMProjectID       string
+	FCMCredentialsFile string
+
+	APNsEnabled    bool
+	APNsKeyFile    string
+	APNsKeyID      string
+	APNsTeamID     string
+	APNsTopic      string
+	APNsProduction bool
+}
+
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	Timeout      int
+}
+
+type AuthConfig struct {
+	AppURL                   string
+	RequireEmailVerification bool
+	VerificationTTL          int
+	ResetTTL                 int
+	RateLimitPerEmail        int
+	RateLimitPerIP           int
+	RateLimitWindow          int
+}
+
+type TwoFactorConfig struct {
+	Issuer          string
+	ChallengeTTL    int
+	EnforceForVIP   bool
+	EnforceForStaff bool
+	RecoveryCodes   int
+	MaxAttempts     int
+	EncryptionKey   string
+}
+
+type OTPConfig struct {
+	Length      int
+	TTL         int
+	Cooldown    int
+	MaxAttempts int
+	DailyLimit  int
+}
+
+type SMSConfig struct {
+	Driver string
+}
+
+type ProfileConfig struct {
+	MaxAvatarBytes int64
+}
+
+type SearchConfig struct {
+	PerMinute          int
+	PhoneLookupsPerDay int
+}
+
+type ContactSyncConfig struct {
+	BatchSize    int
+	PerHour      int
+	HashesPerDay int
+}
+
+type AccountDeletionConfig struct {
+	GracePeriod  int
+	ExportTTL    int
+	PollInterval int
+	BatchSize    int
+	ClaimTimeout int
+}
+
+type ScheduledConfig struct {
+	PollInterval int
+	BatchSize    int
+	ClaimTimeout int
+}
+
 // Module provides all infrastructure dependencies
 var Module = fx.Options(
 	// Databases
@@ -54,15 +226,32 @@ var Module = fx.Options(
 	fx.Provide(ProvideCassandra),
 	fx.Provide(ProvideRedis),
 
+	// Auth
+	fx.Provide(ProvideTokenManager),
+
 	// WebSocket
 	fx.Provide(ProvideWebSocketHub),
 	fx.Invoke(runWebSocketHub),
+
+	// Blob storage
+	fx.Provide(ProvideStorage),
+	fx.Invoke(runStorage),
+
+	// Link previews
+	fx.Provide(ProvideLinkPreviews),
+
+	// Push notifications
+	fx.Provide(ProvidePushSenders),
+
+	// Email and SMS
+	fx.Provide(ProvideMailer),
+	fx.Provide(ProvideSMSSender),
 )
 
 // ProvidePostgresGORM provides PostgreSQL GORM connection
 func ProvidePostgresGORM(cfg Config) (*gorm.DB, error) {
 	log.Println("🗄️  Initializing PostgreSQL (GORM)...")
-	
+
 	pgCfg := cfg.GetPostgresConfig()
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		pgCfg.Host,
@@ -102,7 +291,7 @@ func ProvidePostgresGORM(cfg Config) (*gorm.DB, error) {
 // ProvideCassandra provides Cassandra session
 func ProvideCassandra(cfg Config) *gocql.Session {
 	log.Println("🗄️  Initializing Cassandra...")
-	
+
 	cassCfg := cfg.GetCassandraConfig()
 	cassSession, err := cassandra.NewSession(cassandra.Config{
 		Hosts:    cassCfg.Hosts,
@@ -122,7 +311,7 @@ func ProvideCassandra(cfg Config) *gocql.Session {
 // ProvideRedis provides Redis client
 func ProvideRedis(cfg Config) *redis.Client {
 	log.Println("🗄️  Initializing Redis...")
-	
+
 	redisCfg := cfg.GetRedisConfig()
 	redisClient, err := cache.NewRedisClient(cache.Config{
 		Host:     redisCfg.Host,
@@ -139,10 +328,31 @@ func ProvideRedis(cfg Config) *redis.Client {
 	return redisClient
 }
 
+// ProvideTokenManager provides the JWT access token manager
+func ProvideTokenManager(cfg Config) *token.Manager {
+	jwtCfg := cfg.GetJWTConfig()
+	return token.NewManager(jwtCfg.Secret, time.Duration(jwtCfg.Expiration)*time.Second)
+}
+
 // ProvideWebSocketHub provides WebSocket Hub
-func ProvideWebSocketHub() *websocket.Hub {
+func ProvideWebSocketHub(cfg Config, redisClient *redis.Client) *websocket.Hub {
 	log.Println("🔌 Creating WebSocket Hub...")
-	return websocket.NewHub()
+
+	wsCfg := cfg.GetWebSocketConfig()
+	deliveryCfg := websocket.DeliveryConfig{
+		Retention: time.Duration(wsCfg.ReplayRetention) * time.Second,
+		MaxEvents: int64(wsCfg.ReplayMaxEvents),
+	}
+
+	var queue websocket.DeliveryQueue
+	if redisClient != nil {
+		queue = websocket.NewRedisDeliveryQueue(redisClient, deliveryCfg)
+	} else {
+		log.Println("⚠️  Redis not available, pending WebSocket events are kept in memory only")
+		queue = websocket.NewMemoryDeliveryQueue(deliveryCfg)
+	}
+
+	return websocket.NewHub(queue)
 }
 
 func runWebSocketHub(hub *websocket.Hub) {
@@ -150,3 +360,163 @@ func runWebSocketHub(hub *websocket.Hub) {
 	go hub.Run()
 }
 
+// ProvideStorage provides blob storage for attachments and avatars
+func ProvideStorage(cfg Config, redisClient *redis.Client) (storage.Storage, error) {
+	log.Println("🗂️  Initializing blob storage...")
+
+	storageCfg := cfg.GetStorageConfig()
+
+	var expiry storage.ExpiryIndex
+	if redisClient != nil {
+		expiry = storage.NewRedisExpiryIndex(redisClient)
+	} else {
+		log.Println("⚠️  Redis not available, blob expiries are kept in memory only")
+		expiry = storage.NewMemoryExpiryIndex()
+	}
+
+	return storage.NewLocalStorage(storage.Config{
+		Dir:           storageCfg.Dir,
+		BaseURL:       storageCfg.BaseURL,
+		SweepInterval: time.Duration(storageCfg.SweepInterval) * time.Second,
+	}, expiry)
+}
+
+// ProvideLinkPreviews provides the link preview service, or nil when previews are disabled
+func ProvideLinkPreviews(cfg Config, redisClient *redis.Client) linkpreview.Service {
+	previewCfg := cfg.GetLinkPreviewConfig()
+	if !previewCfg.Enabled {
+		log.Println("⚠️  Link previews are disabled")
+		return nil
+	}
+
+	var cache linkpreview.Cache
+	if redisClient != nil {
+		cache = linkpreview.NewRedisCache(redisClient)
+	} else {
+		log.Println("⚠️  Redis not available, link previews are cached in memory only")
+		cache = linkpreview.NewMemoryCache()
+	}
+
+	fetcherCfg := linkpreview.Config{
+		Timeout:              time.Duration(previewCfg.Timeout) * time.Second,
+		MaxBodyBytes:         previewCfg.MaxBodyBytes,
+		AllowedHosts:         previewCfg.AllowedHosts,
+		BlockedHosts:         previewCfg.BlockedHosts,
+		AllowPrivateNetworks: previewCfg.AllowPrivateNetworks,
+		CacheTTL:             time.Duration(previewCfg.CacheTTL) * time.Second,
+		FailureTTL:           time.Duration(previewCfg.FailureTTL) * time.Second,
+	}
+	if fetcherCfg.AllowPrivateNetworks {
+		log.Println("⚠️  Link previews may fetch private network addresses")
+	}
+
+	return linkpreview.NewService(linkpreview.NewFetcher(fetcherCfg), cache, fetcherCfg)
+}
+
+// ProvidePushSenders provides a sender per configured push platform. A
+// provider that fails to set up is left out so the app still starts.
+func ProvidePushSenders(cfg Config) push.Senders {
+	pushCfg := cfg.GetPushConfig()
+	timeout := time.Duration(pushCfg.Timeout) * time.Second
+	senders := push.Senders{}
+
+	if pushCfg.Fake {
+		log.Println("⚠️  Push notifications are logged by a fake sender, nothing reaches devices")
+		senders[push.PlatformFCM] = push.NewFakeSender()
+		senders[push.PlatformAPNs] = push.NewFakeSender()
+		return senders
+	}
+
+	if pushCfg.FCMEnabled {
+		sender, err := push.NewFCMSender(push.FCMConfig{
+			ProjectID:       pushCfg.FCMProjectID,
+			CredentialsFile: pushCfg.FCMCredentialsFile,
+			Timeout:         timeout,
+		})
+		if err != nil {
+			log.Printf("⚠️  FCM not available: %v", err)
+		} else {
+			log.Println("✅ FCM push sender ready")
+			senders[push.PlatformFCM] = sender
+		}
+	}
+
+	if pushCfg.APNsEnabled {
+		sender, err := push.NewAPNsSender(push.APNsConfig{
+			KeyFile:    pushCfg.APNsKeyFile,
+			KeyID:      pushCfg.APNsKeyID,
+			TeamID:     pushCfg.APNsTeamID,
+			Topic:      pushCfg.APNsTopic,
+			Production: pushCfg.APNsProduction,
+			Timeout:    timeout,
+		})
+		if err != nil {
+			log.Printf("⚠️  APNs not available: %v", err)
+		} else {
+			log.Println("✅ APNs push sender ready")
+			senders[push.PlatformAPNs] = sender
+		}
+	}
+
+	if len(senders) == 0 {
+		log.Println("⚠️  No push provider configured, push notifications are disabled")
+	}
+
+	return senders
+}
+
+// ProvideMailer provides the configured mailer, falling back to logging emails
+// when SMTP cannot be set up
+func ProvideMailer(cfg Config) mailer.Mailer {
+	mailCfg := cfg.GetMailConfig()
+
+	switch mailCfg.Driver {
+	case mailer.DriverSMTP:
+		smtpMailer, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     mailCfg.SMTPHost,
+			Port:     mailCfg.SMTPPort,
+			Username: mailCfg.SMTPUsername,
+			Password: mailCfg.SMTPPassword,
+			From:     mailCfg.From,
+			Timeout:  time.Duration(mailCfg.Timeout) * time.Second,
+		})
+		if err != nil {
+			log.Printf("⚠️  SMTP not available, emails are logged instead: %v", err)
+			return mailer.NewLogMailer()
+		}
+		log.Println("✅ SMTP mailer ready")
+		return smtpMailer
+	case mailer.DriverMemory:
+		log.Println("⚠️  Emails are kept in memory, nothing is delivered")
+		return mailer.NewMemoryMailer()
+	default:
+		log.Println("⚠️  Emails are logged, nothing is delivered")
+		return mailer.NewLogMailer()
+	}
+}
+
+// ProvideSMSSender provides the text message sender. Only the fake driver
+// exists so far; it logs codes, which must never happen in production.
+func ProvideSMSSender(cfg Config) sms.Sender {
+	if driver := cfg.GetSMSConfig().Driver; driver != sms.DriverFake {
+		log.Printf("⚠️  Unknown SMS driver %q, text messages are logged instead", driver)
+	} else {
+		log.Println("⚠️  Text messages are logged by a fake sender, nothing reaches phones")
+	}
+	return sms.NewFakeSender()
+}
+
+// runStorage deletes expired blobs for the app lifetime
+func runStorage(lc fx.Lifecycle, blobs storage.Storage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go blobs.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,176 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and throttles frequent refreshes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+type apnsSender struct {
+	client *http.Client
+	host   string
+	cfg    APNsConfig
+	key    *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates a sender for the APNs HTTP/2 API
+func NewAPNsSender(cfg APNsConfig) (Sender, error) {
+	data, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read APNs key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("APNs key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APNs key: %w", err)
+	}
+
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("APNs key is not an ECDSA key")
+	}
+
+	host := apnsSandboxHost
+	if cfg.Production {
+		host = apnsProductionHost
+	}
+
+	// APNs only speaks HTTP/2
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+
+	return &apnsSender{
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		host:   host,
+		cfg:    cfg,
+		key:    key,
+	}, nil
+}
+
+func (s *apnsSender) Send(ctx context.Context, message Message) error {
+	token, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"sound":     "default",
+			"thread-id": message.CollapseKey,
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode APNs payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+message.Token, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build APNs request: %w", err)
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", s.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if message.CollapseKey != "" {
+		req.Header.Set("apns-collapse-id", message.CollapseKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&apnsErr)
+
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		apnsErr.Reason == "BadDeviceToken",
+		apnsErr.Reason == "DeviceTokenNotForTopic",
+		apnsErr.Reason == "Unregistered":
+		return ErrInvalidToken
+	}
+
+	return fmt.Errorf("APNs rejected notification: %d %s", resp.StatusCode, apnsErr.Reason)
+}
+
+// providerToken returns the signed JWT APNs authenticates us with, reusing it while it is fresh
+func (s *apnsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenLifetime {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token, err := signJWT(map[string]interface{}{"alg": "ES256", "kid": s.cfg.KeyID}, map[string]interface{}{
+		"iss": s.cfg.TeamID,
+		"iat": now.Unix(),
+	}, func(signingInput []byte) ([]byte, error) {
+		digest := sha256.Sum256(signingInput)
+		r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+		if err != nil {
+			return nil, err
+		}
+
+		// JWS wants the raw 64 byte r||s form, not ASN.1
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sig.FillBytes(signature[32:])
+		return signature, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.token, s.issuedAt = token, now
+	return s.token, nil
+}
@@ -0,0 +1,53 @@
+package push
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// FakeSender records notifications instead of sending them. It serves local
+// development and tests; tokens marked invalid are rejected like a real provider would.
+type FakeSender struct {
+	sent    []Message
+	invalid map[string]bool
+	mu      sync.Mutex
+}
+
+// NewFakeSender creates a FakeSender
+func NewFakeSender() *FakeSender {
+	return &FakeSender{
+		invalid: make(map[string]bool),
+	}
+}
+
+func (s *FakeSender) Send(_ context.Context, message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.invalid[message.Token] {
+		return ErrInvalidToken
+	}
+
+	s.sent = append(s.sent, message)
+	log.Printf("Push (fake): %q %q to token %.12s…", message.Title, message.Body, message.Token)
+	return nil
+}
+
+// MarkInvalid makes later sends to the token fail with ErrInvalidToken
+func (s *FakeSender) MarkInvalid(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.invalid[token] = true
+}
+
+// Sent returns the notifications sent so far
+func (s *FakeSender) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := make([]Message, len(s.sent))
+	copy(sent, s.sent)
+	return sent
+}
@@ -0,0 +1,68 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Platforms a device token can belong to
+const (
+	PlatformFCM  = "fcm"  // Android and web, through Firebase Cloud Messaging
+	PlatformAPNs = "apns" // iOS, through the Apple Push Notification service
+)
+
+var (
+	// ErrInvalidToken means the provider will never deliver to the token again;
+	// the token should be forgotten
+	ErrInvalidToken = errors.New("device token is no longer valid")
+
+	ErrProviderUnavailable = errors.New("push provider is unavailable")
+)
+
+// Message is a push notification for one device
+type Message struct {
+	Token string
+	Title string
+	Body  string
+
+	// Delivered to the app alongside the notification
+	Data map[string]string
+
+	// Notifications with the same key replace each other on the device
+	CollapseKey string
+}
+
+// Sender delivers push notifications through one provider
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Senders maps a platform to the sender that serves it
+type Senders map[string]Sender
+
+// FCMConfig configures Firebase Cloud Messaging
+type FCMConfig struct {
+	ProjectID string
+
+	// Service account key file downloaded from the Firebase console
+	CredentialsFile string
+
+	Timeout time.Duration
+}
+
+// APNsConfig configures the Apple Push Notification service with token-based auth
+type APNsConfig struct {
+	// The .p8 signing key, its ID and the team that owns it
+	KeyFile string
+	KeyID   string
+	TeamID  string
+
+	// Bundle ID of the app
+	Topic string
+
+	// Production selects the production gateway instead of the sandbox
+	Production bool
+
+	Timeout time.Duration
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limiter allows a fixed number of attempts per key and window
+type Limiter interface {
+	// Allow counts an attempt for key and reports whether it is within the limit
+	Allow(ctx context.Context, key string) (bool, error)
+
+	// AllowN counts n units of a quota for key at once, such as the items of
+	// a batch, and reports whether the total is within the limit
+	AllowN(ctx context.Context, key string, n int) (bool, error)
+}
+
+// incrScript counts attempts and starts the window on the first ones
+var incrScript = redis.NewScript(`
+local count = redis.call("INCRBY", KEYS[1], ARGV[2])
+if count == tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+type redisLimiter struct {
+	client *redis.Client
+	name   string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisLimiter creates a limiter shared by every node. name keeps the
+// counters of different limiters apart.
+func NewRedisLimiter(client *redis.Client, name string, limit int, window time.Duration) Limiter {
+	return &redisLimiter{
+		client: client,
+		name:   name,
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *redisLimiter) AllowN(ctx context.Context, key string, n int) (bool, error) {
+	count, err := incrScript.Run(ctx, l.client, []string{"ratelimit:" + l.name + ":" + key}, l.window.Milliseconds(), n).Int()
+	if err != nil {
+		return false, err
+	}
+	return count <= l.limit, nil
+}
+
+// Counters are swept once there are this many
+const memorySweepThreshold = 1024
+
+type memoryWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+type memoryLimiter struct {
+	limit   int
+	window  time.Duration
+	windows map[string]*memoryWindow
+	mu      sync.Mutex
+}
+
+// NewMemoryLimiter creates a limiter that only counts attempts on this node
+func NewMemoryLimiter(limit int, window time.Duration) Limiter {
+	return &memoryLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*memoryWindow),
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *memoryLimiter) AllowN(_ context.Context, key string, n int) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if len(l.windows) >= memorySweepThreshold {
+		for k, w := range l.windows {
+			if !now.Before(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &memoryWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+	w.count += n
+
+	return w.count <= l.limit, nil
+}
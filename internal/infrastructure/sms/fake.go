@@ -0,0 +1,57 @@
+package sms
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Message is a text message recorded by FakeSender
+type Message struct {
+	To   string
+	Text string
+}
+
+// FakeSender records text messages instead of sending them. It serves local
+// development and tests, which read the codes back with Sent or Last.
+type FakeSender struct {
+	sent []Message
+	mu   sync.Mutex
+}
+
+// NewFakeSender creates a FakeSender
+func NewFakeSender() *FakeSender {
+	return &FakeSender{}
+}
+
+func (s *FakeSender) Send(_ context.Context, to string, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, Message{To: to, Text: text})
+	log.Printf("SMS (fake) to %s: %s", to, text)
+	return nil
+}
+
+// Sent returns the messages sent so far
+func (s *FakeSender) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := make([]Message, len(s.sent))
+	copy(sent, s.sent)
+	return sent
+}
+
+// Last returns the latest message sent to a number
+func (s *FakeSender) Last(to string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.sent) - 1; i >= 0; i-- {
+		if s.sent[i].To == to {
+			return s.sent[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,13 @@
+package sms
+
+import "context"
+
+// Drivers an SMS sender can be configured with
+const (
+	DriverFake = "fake" // log messages instead of sending them
+)
+
+// Sender sends text messages to E.164 phone numbers
+type Sender interface {
+	Send(ctx context.Context, to string, text string) error
+}
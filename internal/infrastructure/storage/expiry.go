@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Blob expiries are kept in one sorted set scored by unix time
+const expiryKey = "storage:expiry"
+
+type redisExpiryIndex struct {
+	client *redis.Client
+}
+
+// NewRedisExpiryIndex creates an ExpiryIndex shared by all nodes
+func NewRedisExpiryIndex(client *redis.Client) ExpiryIndex {
+	return &redisExpiryIndex{client: client}
+}
+
+func (i *redisExpiryIndex) Set(ctx context.Context, key string, at time.Time) error {
+	// GT keeps the latest expiry when several messages share a blob
+	err := i.client.ZAddArgs(ctx, expiryKey, redis.ZAddArgs{
+		GT:      true,
+		Members: []redis.Z{{Score: float64(at.Unix()), Member: key}},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule blob expiry: %w", err)
+	}
+	return nil
+}
+
+func (i *redisExpiryIndex) Clear(ctx context.Context, key string) error {
+	if err := i.client.ZRem(ctx, expiryKey, key).Err(); err != nil {
+		return fmt.Errorf("failed to clear blob expiry: %w", err)
+	}
+	return nil
+}
+
+func (i *redisExpiryIndex) Extend(ctx context.Context, key string, at time.Time) error {
+	// XX never schedules a blob that is kept forever
+	err := i.client.ZAddArgs(ctx, expiryKey, redis.ZAddArgs{
+		XX:      true,
+		GT:      true,
+		Members: []redis.Z{{Score: float64(at.Unix()), Member: key}},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to extend blob expiry: %w", err)
+	}
+	return nil
+}
+
+func (i *redisExpiryIndex) Due(ctx context.Context, now time.Time) ([]string, error) {
+	keys, err := i.client.ZRangeByScore(ctx, expiryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load expired blobs: %w", err)
+	}
+
+	// Only the node that removes a key deletes the blob
+	due := make([]string, 0, len(keys))
+	for _, key := range keys {
+		removed, err := i.client.ZRem(ctx, expiryKey, key).Result()
+		if err != nil {
+			return due, fmt.Errorf("failed to claim expired blob: %w", err)
+		}
+		if removed > 0 {
+			due = append(due, key)
+		}
+	}
+
+	return due, nil
+}
+
+type memoryExpiryIndex struct {
+	expiries map[string]time.Time
+	mu       sync.Mutex
+}
+
+// NewMemoryExpiryIndex creates an in-process ExpiryIndex.
+// It is used when Redis is unavailable; scheduled expiries do not survive a restart.
+func NewMemoryExpiryIndex() ExpiryIndex {
+	return &memoryExpiryIndex{
+		expiries: make(map[string]time.Time),
+	}
+}
+
+func (i *memoryExpiryIndex) Set(ctx context.Context, key string, at time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if current, ok := i.expiries[key]; !ok || at.After(current) {
+		i.expiries[key] = at
+	}
+	return nil
+}
+
+func (i *memoryExpiryIndex) Clear(ctx context.Context, key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.expiries, key)
+	return nil
+}
+
+func (i *memoryExpiryIndex) Extend(ctx context.Context, key string, at time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if current, ok := i.expiries[key]; ok && at.After(current) {
+		i.expiries[key] = at
+	}
+	return nil
+}
+
+func (i *memoryExpiryIndex) Due(ctx context.Context, now time.Time) ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var due []string
+	for key, at := range i.expiries {
+		if !at.After(now) {
+			due = append(due, key)
+			delete(i.expiries, key)
+		}
+	}
+	return due, nil
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type localStorage struct {
+	cfg    Config
+	expiry ExpiryIndex
+}
+
+// NewLocalStorage creates a Storage that keeps blobs on the local filesystem
+func NewLocalStorage(cfg Config, expiry ExpiryIndex) (Storage, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &localStorage{
+		cfg:    cfg,
+		expiry: expiry,
+	}, nil
+}
+
+// path maps a key to a file inside the storage directory, rejecting keys that escape it
+func (s *localStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.cfg.Dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	return f, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return s.expiry.Clear(ctx, key)
+}
+
+func (s *localStorage) URL(key string) string {
+	return strings.TrimSuffix(s.cfg.BaseURL, "/") + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (s *localStorage) ExpireAt(ctx context.Context, key string, at time.Time) error {
+	if _, err := s.path(key); err != nil {
+		return err
+	}
+	return s.expiry.Set(ctx, key, at)
+}
+
+func (s *localStorage) Retain(ctx context.Context, key string) error {
+	if _, err := s.path(key); err != nil {
+		return err
+	}
+	return s.expiry.Clear(ctx, key)
+}
+
+func (s *localStorage) ExtendExpiry(ctx context.Context, key string, at time.Time) error {
+	if _, err := s.path(key); err != nil {
+		return err
+	}
+	return s.expiry.Extend(ctx, key, at)
+}
+
+func (s *localStorage) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweep(ctx, now)
+		}
+	}
+}
+
+func (s *localStorage) sweep(ctx context.Context, now time.Time) {
+	keys, err := s.expiry.Due(ctx, now)
+	if err != nil {
+		log.Printf("Storage: failed to load expired blobs: %v", err)
+		return
+	}
+
+	for _, key := range keys {
+		if err := s.Delete(ctx, key); err != nil {
+			log.Printf("Storage: failed to delete expired blob %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Storage keeps uploaded blobs such as attachments and avatars.
+// Blobs are addressed by key; messages and profiles store the key, not the bytes.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the public URL of a blob
+	URL(key string) string
+
+	// ExpireAt schedules a blob for deletion; a later expiry wins over an earlier one
+	ExpireAt(ctx context.Context, key string, at time.Time) error
+
+	// Retain cancels any scheduled deletion, e.g. when a permanent message shares the blob
+	Retain(ctx context.Context, key string) error
+
+	// ExtendExpiry moves a scheduled deletion to at if that is later. A blob
+	// with no deletion scheduled is kept forever and stays so.
+	ExtendExpiry(ctx context.Context, key string, at time.Time) error
+
+	// Run deletes expired blobs until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+// Config configures blob storage
+type Config struct {
+	// Directory blobs are written to
+	Dir string
+
+	// Public URL prefix blobs are served under
+	BaseURL string
+
+	// How often expired blobs are deleted
+	SweepInterval time.Duration
+}
+
+// ExpiryIndex tracks when blobs are due for deletion
+type ExpiryIndex interface {
+	Set(ctx context.Context, key string, at time.Time) error
+	Clear(ctx context.Context, key string) error
+
+	// Extend moves an existing expiry later; keys without one are left alone
+	Extend(ctx context.Context, key string, at time.Time) error
+
+	// Due removes and returns keys that expired at or before now
+	Due(ctx context.Context, now time.Time) ([]string, error)
+}
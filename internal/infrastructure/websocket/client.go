@@ -3,6 +3,8 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -20,14 +22,86 @@ const (
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512 * 1024 // 512KB
+
+	// Number of outbound frames buffered per client before it is considered lagging
+	sendBufferSize = 256
 )
 
 // Client represents a WebSocket client
 type Client struct {
-	hub    *Hub
-	conn   *websocket.Conn
-	send   chan []byte
+	hub  *Hub
+	conn *websocket.Conn
+
+	// Outbound frames. Never closed: senders may race with the hub dropping
+	// the client, so shutdown is signalled through done instead.
+	send chan []byte
+
+	// Closed once the hub drops the client; the write pump then hangs up
+	done      chan struct{}
+	closeOnce sync.Once
+
 	UserID int64
+
+	// Unique per connection; a user may be connected from several nodes
+	ID string
+
+	// Login session of the access token the connection was opened with
+	SessionID string
+
+	// Event ID the client asked to resume from when it connected
+	resumeFrom string
+
+	// Set when the send buffer overflowed; live pushes pause until the client resumes
+	lagging atomic.Bool
+
+	// Set once the resync hint for the current lag has been written
+	resyncSent atomic.Bool
+}
+
+// NewClient creates a client for an upgraded connection
+func NewClient(hub *Hub, conn *websocket.Conn, userID int64, sessionID string) *Client {
+	return &Client{
+		hub:       hub,
+		conn:      conn,
+		send:      make(chan []byte, sendBufferSize),
+		done:      make(chan struct{}),
+		UserID:    userID,
+		ID:        newConnectionID(),
+		SessionID: sessionID,
+	}
+}
+
+// close tells the write pump to hang up. It is safe to call more than once.
+func (c *Client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// trySend queues a frame without blocking. It reports false when the buffer
+// is full or the client has been closed.
+func (c *Client) trySend(frame []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) isLagging() bool {
+	return c.lagging.Load()
+}
+
+func (c *Client) setLagging(lagging bool) {
+	c.lagging.Store(lagging)
+	if !lagging {
+		c.resyncSent.Store(false)
+	}
 }
 
 // WSMessage represents a WebSocket message structure
@@ -38,15 +112,25 @@ type WSMessage struct {
 
 // ReadPump pumps messages from the websocket connection to the hub
 func (c *Client) ReadPump() {
+	for _, observer := range c.hub.observers {
+		observer.ClientConnected(c.UserID, c.ID)
+	}
+
 	defer func() {
 		c.hub.unregister <- c
 		c.conn.Close()
+		for _, observer := range c.hub.observers {
+			observer.ClientDisconnected(c.UserID, c.ID)
+		}
 	}()
 
 	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		for _, observer := range c.hub.observers {
+			observer.ClientHeartbeat(c.UserID, c.ID)
+		}
 		return nil
 	})
 
@@ -81,14 +165,14 @@ func (c *Client) WritePump() {
 
 	for {
 		select {
-		case message, ok := <-c.send:
+		case <-c.done:
+			// The hub dropped the client; closing the connection also ends the read pump
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
 
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			w, err := c.conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
@@ -106,6 +190,14 @@ func (c *Client) WritePump() {
 				return
 			}
 
+			// Buffer drained after an overflow: ask the client to resume from its last processed event
+			if c.isLagging() && len(c.send) == 0 && c.resyncSent.CompareAndSwap(false, true) {
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := c.conn.WriteMessage(websocket.TextMessage, resyncFrame("lagging")); err != nil {
+					return
+				}
+			}
+
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -123,13 +215,54 @@ func (c *Client) handleMessage(msg *WSMessage) {
 	// For example: typing indicators, read receipts, etc.
 	switch msg.Type {
 	case "ping":
-		// Respond with pong
-		c.send <- []byte(`{"type":"pong"}`)
-	case "typing":
-		// Handle typing indicator
-		// This would be processed by the message service
+		// Respond with pong; a full buffer means the client is lagging and misses it
+		c.trySend([]byte(`{"type":"pong"}`))
+	case "ack":
+		// Client processed all events up to data.id
+		var ack struct {
+			ID string `json:"id"`
+		}
+		if err := decodeData(msg.Data, &ack); err != nil || ack.ID == "" {
+			log.Printf("Invalid ack from user %d", c.UserID)
+			return
+		}
+		c.hub.ack(c.UserID, c.SessionID, ack.ID)
+	case "resume":
+		// Client asks for events after data.last_event_id, e.g. after a resync hint
+		var resume struct {
+			LastEventID string `json:"last_event_id"`
+		}
+		if err := decodeData(msg.Data, &resume); err != nil {
+			log.Printf("Invalid resume from user %d", c.UserID)
+			return
+		}
+		go c.hub.replay(c, resume.LastEventID)
+	case "presence":
+		// Client reports foreground/background state: data.status is "online" or "away"
+		var presence struct {
+			Status string `json:"status"`
+		}
+		if err := decodeData(msg.Data, &presence); err != nil {
+			log.Printf("Invalid presence from user %d", c.UserID)
+			return
+		}
+		for _, observer := range c.hub.observers {
+			observer.ClientStatusChanged(c.UserID, c.ID, presence.Status)
+		}
 	default:
+		if handler, ok := c.hub.handlers[msg.Type]; ok {
+			handler(c.UserID, msg.Data)
+			return
+		}
 		log.Printf("Unknown message type: %s", msg.Type)
 	}
 }
 
+// decodeData re-decodes the generic Data field of a WSMessage into target
+func decodeData(data interface{}, target interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, target)
+}
@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a server event persisted in a user's pending queue.
+// IDs use the Redis stream format "<unix-ms>-<seq>" and grow monotonically per user.
+type Event struct {
+	ID      string
+	Payload []byte
+}
+
+// DeliveryQueue stores server events per user until the client acknowledges them,
+// so they can be replayed when the user reconnects
+type DeliveryQueue interface {
+	// Append stores an event for the user and returns its ID
+	Append(ctx context.Context, userID int64, payload []byte) (string, error)
+
+	// Ack records the last event ID processed by one of the user's devices,
+	// identified by its login session. Each device keeps its own position, so
+	// an ack from one does not skip events another has not seen.
+	Ack(ctx context.Context, userID int64, sessionID string, eventID string) error
+
+	// LastAcked returns the last event ID acknowledged by the device, or "" if none
+	LastAcked(ctx context.Context, userID int64, sessionID string) (string, error)
+
+	// Since returns up to limit events after afterID that are still retained.
+	// expired is true when events after afterID have already been dropped,
+	// whether by the retention window or by the MaxEvents bound.
+	Since(ctx context.Context, userID int64, afterID string, limit int) (events []Event, expired bool, err error)
+}
+
+// DeliveryConfig bounds how long and how many events are retained per user
+type DeliveryConfig struct {
+	Retention time.Duration
+	MaxEvents int64
+}
+
+// retentionCutoff returns the lowest event ID still inside the retention window
+func retentionCutoff(retention time.Duration) string {
+	return fmt.Sprintf("%d-0", time.Now().Add(-retention).UnixMilli())
+}
+
+// compareEventIDs compares two "<ms>-<seq>" IDs; an empty ID sorts first
+func compareEventIDs(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return -1
+	}
+	if b == "" {
+		return 1
+	}
+
+	aMs, aSeq := parseEventID(a)
+	bMs, bSeq := parseEventID(b)
+
+	switch {
+	case aMs != bMs:
+		if aMs < bMs {
+			return -1
+		}
+		return 1
+	case aSeq < bSeq:
+		return -1
+	case aSeq > bSeq:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func parseEventID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
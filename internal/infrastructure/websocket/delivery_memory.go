@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type memoryStream struct {
+	events []Event
+	acked  map[string]string // By login session
+
+	// Highest event ID trimmed from the stream
+	dropped string
+}
+
+type memoryDeliveryQueue struct {
+	cfg     DeliveryConfig
+	streams map[int64]*memoryStream
+	lastMs  int64
+	seq     uint64
+	mu      sync.Mutex
+}
+
+// NewMemoryDeliveryQueue creates an in-process delivery queue.
+// It is used when Redis is unavailable; pending events do not survive a restart or span nodes.
+func NewMemoryDeliveryQueue(cfg DeliveryConfig) DeliveryQueue {
+	return &memoryDeliveryQueue{
+		cfg:     cfg,
+		streams: make(map[int64]*memoryStream),
+	}
+}
+
+func (q *memoryDeliveryQueue) Append(ctx context.Context, userID int64, payload []byte) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if now > q.lastMs {
+		q.lastMs = now
+		q.seq = 0
+	} else {
+		q.seq++
+	}
+	id := fmt.Sprintf("%d-%d", q.lastMs, q.seq)
+
+	stream, ok := q.streams[userID]
+	if !ok {
+		stream = &memoryStream{}
+		q.streams[userID] = stream
+	}
+	stream.events = append(stream.events, Event{ID: id, Payload: payload})
+	q.trim(stream)
+
+	return id, nil
+}
+
+func (q *memoryDeliveryQueue) Ack(ctx context.Context, userID int64, sessionID string, eventID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stream, ok := q.streams[userID]
+	if !ok {
+		stream = &memoryStream{}
+		q.streams[userID] = stream
+	}
+	if stream.acked == nil {
+		stream.acked = make(map[string]string)
+	}
+	if compareEventIDs(eventID, stream.acked[sessionID]) > 0 {
+		stream.acked[sessionID] = eventID
+	}
+
+	return nil
+}
+
+func (q *memoryDeliveryQueue) LastAcked(ctx context.Context, userID int64, sessionID string) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if stream, ok := q.streams[userID]; ok {
+		return stream.acked[sessionID], nil
+	}
+	return "", nil
+}
+
+func (q *memoryDeliveryQueue) Since(ctx context.Context, userID int64, afterID string, limit int) ([]Event, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cutoff := retentionCutoff(q.cfg.Retention)
+	expired := afterID != "" && compareEventIDs(afterID, cutoff) < 0
+
+	stream, ok := q.streams[userID]
+	if !ok {
+		return nil, expired, nil
+	}
+	q.trim(stream)
+	if afterID != "" && compareEventIDs(stream.dropped, afterID) > 0 {
+		expired = true
+	}
+
+	var events []Event
+	for _, event := range stream.events {
+		if compareEventIDs(event.ID, afterID) <= 0 {
+			continue
+		}
+		events = append(events, event)
+		if len(events) >= limit {
+			break
+		}
+	}
+
+	return events, expired, nil
+}
+
+// trim drops events outside the retention window or over the size bound
+func (q *memoryDeliveryQueue) trim(stream *memoryStream) {
+	cutoff := retentionCutoff(q.cfg.Retention)
+
+	drop := 0
+	for drop < len(stream.events) && compareEventIDs(stream.events[drop].ID, cutoff) < 0 {
+		drop++
+	}
+	if overflow := len(stream.events) - drop - int(q.cfg.MaxEvents); q.cfg.MaxEvents > 0 && overflow > 0 {
+		drop += overflow
+	}
+
+	if drop > 0 {
+		stream.dropped = stream.events[drop-1].ID
+	}
+	stream.events = stream.events[drop:]
+}
@@ -0,0 +1,133 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisDeliveryQueue struct {
+	client *redis.Client
+	cfg    DeliveryConfig
+}
+
+// NewRedisDeliveryQueue creates a delivery queue backed by one Redis stream per user
+func NewRedisDeliveryQueue(client *redis.Client, cfg DeliveryConfig) DeliveryQueue {
+	return &redisDeliveryQueue{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+func streamKey(userID int64) string {
+	return fmt.Sprintf("ws:events:%d", userID)
+}
+
+// ackKey holds a hash of the last acked event ID per login session
+func ackKey(userID int64) string {
+	return fmt.Sprintf("ws:acks:%d", userID)
+}
+
+func (q *redisDeliveryQueue) Append(ctx context.Context, userID int64, payload []byte) (string, error) {
+	key := streamKey(userID)
+
+	id, err := q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: q.cfg.MaxEvents,
+		Approx: true,
+		Values: map[string]interface{}{"payload": payload},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to append event: %w", err)
+	}
+
+	// Drop entries older than the retention window and let idle streams expire
+	pipe := q.client.Pipeline()
+	pipe.XTrimMinIDApprox(ctx, key, retentionCutoff(q.cfg.Retention), 0)
+	pipe.Expire(ctx, key, q.cfg.Retention)
+	pipe.Expire(ctx, ackKey(userID), q.cfg.Retention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to trim event stream: %w", err)
+	}
+
+	return id, nil
+}
+
+func (q *redisDeliveryQueue) Ack(ctx context.Context, userID int64, sessionID string, eventID string) error {
+	current, err := q.LastAcked(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Acks may arrive out of order from batched frames; only move forward
+	if compareEventIDs(eventID, current) <= 0 {
+		return nil
+	}
+
+	key := ackKey(userID)
+	pipe := q.client.TxPipeline()
+	pipe.HSet(ctx, key, sessionID, eventID)
+	pipe.Expire(ctx, key, q.cfg.Retention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to ack event: %w", err)
+	}
+
+	return nil
+}
+
+func (q *redisDeliveryQueue) LastAcked(ctx context.Context, userID int64, sessionID string) (string, error) {
+	id, err := q.client.HGet(ctx, ackKey(userID), sessionID).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get last acked event: %w", err)
+	}
+	return id, nil
+}
+
+func (q *redisDeliveryQueue) Since(ctx context.Context, userID int64, afterID string, limit int) ([]Event, bool, error) {
+	cutoff := retentionCutoff(q.cfg.Retention)
+	expired := afterID != "" && compareEventIDs(afterID, cutoff) < 0
+
+	start := cutoff
+	if compareEventIDs(afterID, cutoff) >= 0 {
+		start = "(" + afterID // exclusive range
+	}
+
+	messages, err := q.client.XRangeN(ctx, streamKey(userID), start, "+", int64(limit)).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read event stream: %w", err)
+	}
+
+	events := make([]Event, 0, len(messages))
+	for _, msg := range messages {
+		payload, _ := msg.Values["payload"].(string)
+		events = append(events, Event{ID: msg.ID, Payload: []byte(payload)})
+	}
+
+	if afterID != "" && !expired {
+		if expired, err = q.droppedAfter(ctx, userID, afterID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return events, expired, nil
+}
+
+// droppedAfter reports whether the MaxEvents bound trimmed an event newer
+// than afterID. Redis tracks the highest ID it deleted from the stream.
+func (q *redisDeliveryQueue) droppedAfter(ctx context.Context, userID int64, afterID string) (bool, error) {
+	info, err := q.client.XInfoStream(ctx, streamKey(userID)).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			// The stream idled past the retention window, which Since already checks
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect event stream: %w", err)
+	}
+	return compareEventIDs(info.MaxDeletedEntryID, afterID) > 0, nil
+}
@@ -1,15 +1,27 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log"
+	"slices"
 	"sync"
+	"time"
+)
+
+const (
+	// Maximum number of events replayed per queue read
+	replayBatchSize = 100
+
+	// Time allowed for a replay or a queue operation
+	queueTimeout = 5 * time.Second
 )
 
 // Hub maintains active WebSocket connections
 type Hub struct {
-	// Registered clients (userID -> connection)
-	clients map[int64]*Client
+	// Registered clients (userID -> connection ID -> connection). A user may
+	// be connected from several devices at once.
+	clients map[int64]map[string]*Client
 
 	// Register requests from clients
 	register chan *Client
@@ -20,6 +32,18 @@ type Hub struct {
 	// Broadcast messages to clients
 	broadcast chan *BroadcastMessage
 
+	// Close the connections of signed out sessions
+	disconnect chan *disconnectRequest
+
+	// Pending events per user, replayed on reconnect until acked
+	queue DeliveryQueue
+
+	// Notified about connects, disconnects and heartbeats
+	observers []ConnectionObserver
+
+	// Handlers for inbound client message types owned by business modules
+	handlers map[string]MessageHandlerFunc
+
 	mu sync.RWMutex
 }
 
@@ -30,13 +54,28 @@ type BroadcastMessage struct {
 	Type         string
 }
 
+type disconnectRequest struct {
+	userID     int64
+	sessionIDs []string
+}
+
+// eventFrame wraps a durable event so the client can ack it by ID
+type eventFrame struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
 // NewHub creates a new Hub
-func NewHub() *Hub {
+func NewHub(queue DeliveryQueue) *Hub {
 	return &Hub{
-		clients:    make(map[int64]*Client),
+		clients:    make(map[int64]map[string]*Client),
 		register:   make(chan *Client, 256),
 		unregister: make(chan *Client, 256),
 		broadcast:  make(chan *BroadcastMessage, 1024),
+		disconnect: make(chan *disconnectRequest, 256),
+		queue:      queue,
+		handlers:   make(map[string]MessageHandlerFunc),
 	}
 }
 
@@ -46,32 +85,40 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client.UserID] = client
+			if h.clients[client.UserID] == nil {
+				h.clients[client.UserID] = make(map[string]*Client)
+			}
+			h.clients[client.UserID][client.ID] = client
 			h.mu.Unlock()
-			log.Printf("WebSocket: User %d connected. Total connections: %d", client.UserID, len(h.clients))
+			log.Printf("WebSocket: User %d connected. Total connections: %d", client.UserID, h.GetConnectionCount())
+
+			go h.replay(client, client.resumeFrom)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
-				close(client.send)
-				log.Printf("WebSocket: User %d disconnected. Total connections: %d", client.UserID, len(h.clients))
+			if h.removeClient(client) {
+				log.Printf("WebSocket: User %d disconnected. Total connections: %d", client.UserID, h.countLocked())
+			}
+			h.mu.Unlock()
+			client.close()
+
+		case request := <-h.disconnect:
+			h.mu.Lock()
+			for _, client := range h.clients[request.userID] {
+				if slices.Contains(request.sessionIDs, client.SessionID) {
+					// Closing done makes the write pump send a close frame and hang up
+					h.removeClient(client)
+					client.close()
+					log.Printf("WebSocket: User %d disconnected, session %s signed out", client.UserID, client.SessionID)
+				}
 			}
 			h.mu.Unlock()
 
 		case message := <-h.broadcast:
 			h.mu.RLock()
 			for _, userID := range message.RecipientIDs {
-				if client, ok := h.clients[userID]; ok {
-					select {
-					case client.send <- message.Data:
-						// Message sent successfully
-					default:
-						// Send buffer full, close and remove client
-						close(client.send)
-						delete(h.clients, userID)
-						log.Printf("WebSocket: Client buffer full, disconnecting user %d", userID)
-					}
+				for _, client := range h.clients[userID] {
+					h.push(client, message.Data)
 				}
 			}
 			h.mu.RUnlock()
@@ -79,31 +126,73 @@ func (h *Hub) Run() {
 	}
 }
 
-// SendToUser sends a message to a specific user
-func (h *Hub) SendToUser(userID int64, data interface{}) error {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// removeClient drops a client from the registry and reports whether it was
+// registered. Callers must hold h.mu for writing.
+func (h *Hub) removeClient(client *Client) bool {
+	connections := h.clients[client.UserID]
+	if connections[client.ID] != client {
+		return false
+	}
 
-	client, ok := h.clients[userID]
-	if !ok {
-		return nil // User not connected, skip
+	delete(connections, client.ID)
+	if len(connections) == 0 {
+		delete(h.clients, client.UserID)
 	}
+	return true
+}
 
-	jsonData, err := json.Marshal(data)
+// isRegistered reports whether the client is still connected to the hub.
+// Callers must hold h.mu.
+func (h *Hub) isRegistered(client *Client) bool {
+	return h.clients[client.UserID][client.ID] == client
+}
+
+// Register adds a client to the hub. Events after lastEventID (or after the
+// last event acked from the same session, whichever is newer) are replayed
+// once it is registered.
+func (h *Hub) Register(client *Client, lastEventID string) {
+	client.resumeFrom = lastEventID
+	h.register <- client
+}
+
+// DisconnectSessions closes the user's connections opened by any of the sessions
+func (h *Hub) DisconnectSessions(userID int64, sessionIDs []string) {
+	h.disconnect <- &disconnectRequest{
+		userID:     userID,
+		sessionIDs: sessionIDs,
+	}
+}
+
+// SendToUser persists an event for the user and pushes it if the user is connected.
+// Offline users receive it on reconnect.
+func (h *Hub) SendToUser(userID int64, data interface{}) error {
+	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	select {
-	case client.send <- jsonData:
-		return nil
-	default:
-		return nil // Channel full, skip
-	}
+	return h.deliver(userID, payload)
 }
 
-// BroadcastToUsers broadcasts a message to multiple users
+// BroadcastToUsers persists and delivers an event to multiple users
 func (h *Hub) BroadcastToUsers(userIDs []int64, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := h.deliver(userID, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BroadcastEphemeral pushes an event to the users that are connected right now.
+// Nothing is persisted, so it suits transient state such as presence or typing.
+func (h *Hub) BroadcastEphemeral(userIDs []int64, data interface{}) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
@@ -117,6 +206,126 @@ func (h *Hub) BroadcastToUsers(userIDs []int64, data interface{}) error {
 	return nil
 }
 
+func (h *Hub) deliver(userID int64, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	id, err := h.queue.Append(ctx, userID, payload)
+	if err != nil {
+		return err
+	}
+
+	frame, err := json.Marshal(eventFrame{Type: "event", ID: id, Data: payload})
+	if err != nil {
+		return err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.clients[userID] {
+		h.push(client, frame)
+	}
+
+	return nil
+}
+
+// push sends a frame without blocking. A client whose buffer is full is marked
+// as lagging instead of being disconnected: live pushes stop, it is told to
+// resync once its buffer drains, and the missed events are replayed from the queue.
+// Callers must hold h.mu.
+func (h *Hub) push(client *Client, frame []byte) {
+	if client.isLagging() {
+		return
+	}
+
+	if !client.trySend(frame) {
+		select {
+		case <-client.done:
+			// Dropped by the hub; nothing more is delivered to it
+		default:
+			client.setLagging(true)
+			log.Printf("WebSocket: Client buffer full, user %d must resync", client.UserID)
+		}
+	}
+}
+
+// ack records the last event processed by one of the user's devices
+func (h *Hub) ack(userID int64, sessionID string, eventID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	if err := h.queue.Ack(ctx, userID, sessionID, eventID); err != nil {
+		log.Printf("WebSocket: Failed to ack event %s for user %d: %v", eventID, userID, err)
+	}
+}
+
+// replay resends pending events to a client. Live events may interleave with
+// replayed ones, so clients must de-duplicate and order by event ID.
+func (h *Hub) replay(client *Client, afterID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	// Only this device's own ack may move the start forward; another device
+	// having seen an event says nothing about this one
+	acked, err := h.queue.LastAcked(ctx, client.UserID, client.SessionID)
+	if err != nil {
+		log.Printf("WebSocket: Replay failed for user %d: %v", client.UserID, err)
+		return
+	}
+	if compareEventIDs(acked, afterID) > 0 {
+		afterID = acked
+	}
+
+	client.setLagging(false)
+
+	for {
+		events, expired, err := h.queue.Since(ctx, client.UserID, afterID, replayBatchSize)
+		if err != nil {
+			log.Printf("WebSocket: Replay failed for user %d: %v", client.UserID, err)
+			return
+		}
+
+		if expired {
+			// Some events were dropped by retention or the size bound; the client must reload state
+			h.mu.RLock()
+			h.push(client, resyncFrame("expired"))
+			h.mu.RUnlock()
+			expired = false
+		}
+
+		for _, event := range events {
+			frame, err := json.Marshal(eventFrame{Type: "event", ID: event.ID, Data: event.Payload})
+			if err != nil {
+				continue
+			}
+
+			h.mu.RLock()
+			if !h.isRegistered(client) {
+				h.mu.RUnlock()
+				return
+			}
+			h.push(client, frame)
+			h.mu.RUnlock()
+
+			if client.isLagging() {
+				// The client will resume from its last processed event once drained
+				return
+			}
+			afterID = event.ID
+		}
+
+		if len(events) < replayBatchSize {
+			return
+		}
+	}
+}
+
+func resyncFrame(reason string) []byte {
+	frame, _ := json.Marshal(WSMessage{Type: "resync", Data: map[string]string{"reason": reason}})
+	return frame
+}
+
 // IsUserOnline checks if a user is online
 func (h *Hub) IsUserOnline(userID int64) bool {
 	h.mu.RLock()
@@ -144,6 +353,14 @@ func (h *Hub) GetConnectionCount() int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	return len(h.clients)
+	return h.countLocked()
 }
 
+// countLocked counts connections across users. Callers must hold h.mu.
+func (h *Hub) countLocked() int {
+	count := 0
+	for _, connections := range h.clients {
+		count += len(connections)
+	}
+	return count
+}
@@ -0,0 +1,193 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func newTestHub(t *testing.T) *Hub {
+	return newTestHubWithConfig(t, DeliveryConfig{Retention: time.Hour, MaxEvents: 100})
+}
+
+func newTestHubWithConfig(t *testing.T, cfg DeliveryConfig) *Hub {
+	t.Helper()
+	hub := NewHub(NewMemoryDeliveryQueue(cfg))
+	go hub.Run()
+	return hub
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, client *Client) []byte {
+	t.Helper()
+	select {
+	case frame := <-client.send:
+		return frame
+	case <-time.After(time.Second):
+		t.Fatalf("connection %s received nothing", client.ID)
+		return nil
+	}
+}
+
+// receiveFrame reads the next frame and returns its type and event ID
+func receiveFrame(t *testing.T, client *Client) (string, string) {
+	t.Helper()
+	var frame eventFrame
+	if err := json.Unmarshal(receive(t, client), &frame); err != nil {
+		t.Fatal(err)
+	}
+	return frame.Type, frame.ID
+}
+
+// appendEvents queues n events for the user while none of their devices is connected
+func appendEvents(t *testing.T, hub *Hub, userID int64, n int) []string {
+	t.Helper()
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		id, err := hub.queue.Append(context.Background(), userID, []byte(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func isClosed(client *Client) bool {
+	select {
+	case <-client.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHubKeepsEveryDeviceOfAUser(t *testing.T) {
+	hub := newTestHub(t)
+	phone := NewClient(hub, nil, 1, "phone")
+	laptop := NewClient(hub, nil, 1, "laptop")
+	hub.Register(phone, "")
+	hub.Register(laptop, "")
+	waitFor(t, "both connections", func() bool { return hub.GetConnectionCount() == 2 })
+
+	if err := hub.BroadcastEphemeral([]int64{1}, map[string]string{"type": "presence.changed"}); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, phone)
+	receive(t, laptop)
+
+	if isClosed(phone) || isClosed(laptop) {
+		t.Fatal("a second device closed the first")
+	}
+}
+
+func TestHubDisconnectsOnlySignedOutSessions(t *testing.T) {
+	hub := newTestHub(t)
+	phone := NewClient(hub, nil, 1, "phone")
+	laptop := NewClient(hub, nil, 1, "laptop")
+	hub.Register(phone, "")
+	hub.Register(laptop, "")
+	waitFor(t, "both connections", func() bool { return hub.GetConnectionCount() == 2 })
+
+	hub.DisconnectSessions(1, []string{"phone"})
+	waitFor(t, "the phone to be dropped", func() bool { return hub.GetConnectionCount() == 1 })
+
+	if !isClosed(phone) {
+		t.Fatal("signed out connection was not closed")
+	}
+	if isClosed(laptop) {
+		t.Fatal("connection of another session was closed")
+	}
+	if !hub.IsUserOnline(1) {
+		t.Fatal("user went offline while the laptop is still connected")
+	}
+}
+
+func TestDroppedClientCanStillHandleFrames(t *testing.T) {
+	hub := newTestHub(t)
+	client := NewClient(hub, nil, 1, "phone")
+	hub.Register(client, "")
+	waitFor(t, "the connection", func() bool { return hub.IsUserOnline(1) })
+
+	hub.DisconnectSessions(1, []string{"phone"})
+	waitFor(t, "the connection to be dropped", func() bool { return isClosed(client) })
+
+	// The read pump keeps running until the connection closes; frames it
+	// handles meanwhile must not panic
+	client.handleMessage(&WSMessage{Type: "ping"})
+	hub.mu.RLock()
+	hub.push(client, []byte(`{}`))
+	hub.mu.RUnlock()
+
+	// Unregistering after the hub already dropped the client is harmless
+	hub.unregister <- client
+	waitFor(t, "the user to go offline", func() bool { return !hub.IsUserOnline(1) })
+}
+
+func TestAckFromOneDeviceDoesNotSkipAnother(t *testing.T) {
+	hub := newTestHub(t)
+	ids := appendEvents(t, hub, 1, 3)
+
+	laptop := NewClient(hub, nil, 1, "laptop")
+	hub.Register(laptop, "")
+	for range ids {
+		receiveFrame(t, laptop)
+	}
+	hub.ack(1, "laptop", ids[2])
+
+	// The phone was offline and last saw the first event
+	phone := NewClient(hub, nil, 1, "phone")
+	hub.Register(phone, ids[0])
+	for _, want := range ids[1:] {
+		if kind, id := receiveFrame(t, phone); kind != "event" || id != want {
+			t.Fatalf("phone got %s %s, want event %s", kind, id, want)
+		}
+	}
+
+	// A reconnecting laptop resumes from its own ack
+	again := NewClient(hub, nil, 1, "laptop")
+	hub.Register(again, "")
+	waitFor(t, "the laptop to reconnect", func() bool { return hub.GetConnectionCount() == 3 })
+	if err := hub.deliver(1, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, id := receiveFrame(t, again); compareEventIDs(id, ids[2]) <= 0 {
+		t.Fatalf("laptop was replayed %s, which it already acked", id)
+	}
+}
+
+func TestReplayAfterMaxEventsOverflowAsksForResync(t *testing.T) {
+	hub := newTestHubWithConfig(t, DeliveryConfig{Retention: time.Hour, MaxEvents: 2})
+	ids := appendEvents(t, hub, 1, 4)
+
+	// The event after ids[0] was dropped by the size bound
+	phone := NewClient(hub, nil, 1, "phone")
+	hub.Register(phone, ids[0])
+	if kind, _ := receiveFrame(t, phone); kind != "resync" {
+		t.Fatalf("got %s frame, want resync", kind)
+	}
+	for _, want := range ids[2:] {
+		if _, id := receiveFrame(t, phone); id != want {
+			t.Fatalf("got event %s, want %s", id, want)
+		}
+	}
+
+	// Resuming from the last retained gap-free position needs no resync
+	laptop := NewClient(hub, nil, 1, "laptop")
+	hub.Register(laptop, ids[2])
+	if kind, id := receiveFrame(t, laptop); kind != "event" || id != ids[3] {
+		t.Fatalf("got %s %s, want event %s", kind, id, ids[3])
+	}
+}
@@ -0,0 +1,36 @@
+package websocket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// ConnectionObserver is notified about the lifecycle of client connections.
+// Calls for one connection are made sequentially from its read goroutine.
+type ConnectionObserver interface {
+	ClientConnected(userID int64, connID string)
+	ClientDisconnected(userID int64, connID string)
+	ClientHeartbeat(userID int64, connID string)
+	ClientStatusChanged(userID int64, connID string, status string)
+}
+
+// MessageHandlerFunc handles an inbound client message of one type
+type MessageHandlerFunc func(userID int64, data interface{})
+
+// Handle registers the handler for an inbound client message type.
+// Handlers must be registered before the server starts accepting connections.
+func (h *Hub) Handle(msgType string, handler MessageHandlerFunc) {
+	h.handlers[msgType] = handler
+}
+
+// AddObserver registers an observer for connection lifecycle events.
+// Observers must be added before the server starts accepting connections.
+func (h *Hub) AddObserver(observer ConnectionObserver) {
+	h.observers = append(h.observers, observer)
+}
+
+func newConnectionID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
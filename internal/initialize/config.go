@@ -15,6 +15,25 @@ type Config struct {
 	Redis     RedisConfig     `mapstructure:"redis"`
 	WebSocket WebSocketConfig `mapstructure:"websocket"`
 	JWT       JWTConfig       `mapstructure:"jwt"`
+	Presence  PresenceConfig  `mapstructure:"presence"`
+	Typing    TypingConfig    `mapstructure:"typing"`
+	Reactions ReactionConfig  `mapstructure:"reactions"`
+	Pins      PinConfig       `mapstructure:"pins"`
+	Mentions  MentionConfig   `mapstructure:"mentions"`
+	Scheduled ScheduledConfig `mapstructure:"scheduled"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	Previews  PreviewConfig   `mapstructure:"link_preview"`
+	Push      PushConfig      `mapstructure:"push"`
+	Mail      MailConfig      `mapstructure:"mail"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	TwoFactor TwoFactorConfig `mapstructure:"two_factor"`
+	OTP       OTPConfig       `mapstructure:"otp"`
+	SMS       SMSConfig       `mapstructure:"sms"`
+	Profile   ProfileConfig   `mapstructure:"profile"`
+	Search    SearchConfig    `mapstructure:"search"`
+
+	ContactSync     ContactSyncConfig     `mapstructure:"contact_sync"`
+	AccountDeletion AccountDeletionConfig `mapstructure:"account_deletion"`
 }
 
 type ServerConfig struct {
@@ -49,6 +68,8 @@ type WebSocketConfig struct {
 	ReadBufferSize  int `mapstructure:"read_buffer_size"`
 	WriteBufferSize int `mapstructure:"write_buffer_size"`
 	MaxMessageSize  int `mapstructure:"max_message_size"`
+	ReplayRetention int `mapstructure:"replay_retention"`  // seconds
+	ReplayMaxEvents int `mapstructure:"replay_max_events"` // per user
 }
 
 type JWTConfig struct {
@@ -56,6 +77,152 @@ type JWTConfig struct {
 	Expiration int    `mapstructure:"expiration"`
 }
 
+type PresenceConfig struct {
+	TTL       int `mapstructure:"ttl"`        // seconds without heartbeat before a connection is gone
+	AwayAfter int `mapstructure:"away_after"` // seconds without activity before a user is away
+}
+
+type TypingConfig struct {
+	TTL                int `mapstructure:"ttl"`                 // seconds before an unrefreshed indicator stops
+	Throttle           int `mapstructure:"throttle"`            // milliseconds between accepted refreshes per user
+	AggregateThreshold int `mapstructure:"aggregate_threshold"` // members above which typing events are aggregated
+}
+
+type ReactionConfig struct {
+	SinglePerUser bool     `mapstructure:"single_per_user"` // a new reaction replaces the user's previous one
+	Allowed       []string `mapstructure:"allowed"`         // empty means any single emoji
+}
+
+type PinConfig struct {
+	MaxPerConversation int `mapstructure:"max_per_conversation"` // 0 means no limit
+}
+
+type MentionConfig struct {
+	AllAdminOnlyAbove int `mapstructure:"all_admin_only_above"` // groups larger than this reserve @all for admins
+}
+
+type StorageConfig struct {
+	Dir                string `mapstructure:"dir"`                  // directory blobs are written to
+	BaseURL            string `mapstructure:"base_url"`             // public URL prefix of blobs
+	SweepInterval      int    `mapstructure:"sweep_interval"`       // seconds between deletions of expired blobs
+	MaxAttachmentBytes int64  `mapstructure:"max_attachment_bytes"` // largest message attachment upload accepted
+}
+
+type PreviewConfig struct {
+	Enabled              bool     `mapstructure:"enabled"`
+	Timeout              int      `mapstructure:"timeout"`                // seconds allowed per fetch
+	MaxBodyBytes         int64    `mapstructure:"max_body_bytes"`         // bytes of a page read at most
+	AllowedHosts         []string `mapstructure:"allowed_hosts"`          // empty means any public host
+	BlockedHosts         []string `mapstructure:"blocked_hosts"`          // never fetched, subdomains included
+	AllowPrivateNetworks bool     `mapstructure:"allow_private_networks"` // local development only
+	CacheTTL             int      `mapstructure:"cache_ttl"`              // seconds a preview is cached
+	FailureTTL           int      `mapstructure:"failure_ttl"`            // seconds a failed fetch is remembered
+}
+
+type PushConfig struct {
+	Workers        int            `mapstructure:"workers"`         // goroutines sending pushes
+	QueueSize      int            `mapstructure:"queue_size"`      // messages waiting for a worker
+	CollapseWindow int            `mapstructure:"collapse_window"` // seconds a burst per conversation is collapsed
+	Timeout        int            `mapstructure:"timeout"`         // seconds allowed per provider request
+	Fake           bool           `mapstructure:"fake"`            // log pushes instead of sending them
+	FCM            FCMConfig      `mapstructure:"fcm"`
+	APNs           APNsPushConfig `mapstructure:"apns"`
+}
+
+type FCMConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	ProjectID       string `mapstructure:"project_id"`
+	CredentialsFile string `mapstructure:"credentials_file"` // service account key
+}
+
+type APNsPushConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	KeyFile    string `mapstructure:"key_file"` // .p8 signing key
+	KeyID      string `mapstructure:"key_id"`
+	TeamID     string `mapstructure:"team_id"`
+	Topic      string `mapstructure:"topic"` // app bundle ID
+	Production bool   `mapstructure:"production"`
+}
+
+type MailConfig struct {
+	Driver  string         `mapstructure:"driver"` // log, memory or smtp
+	From    string         `mapstructure:"from"`
+	Timeout int            `mapstructure:"timeout"` // seconds allowed per email
+	SMTP    SMTPMailConfig `mapstructure:"smtp"`
+}
+
+type SMTPMailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type AuthConfig struct {
+	AppURL                   string          `mapstructure:"app_url"` // links in emails point here
+	RequireEmailVerification bool            `mapstructure:"require_email_verification"`
+	VerificationTTL          int             `mapstructure:"verification_ttl"` // seconds an email verification link is valid
+	ResetTTL                 int             `mapstructure:"reset_ttl"`        // seconds a password reset link is valid
+	RateLimit                AuthLimitConfig `mapstructure:"rate_limit"`
+}
+
+type AuthLimitConfig struct {
+	PerEmail int `mapstructure:"per_email"` // emails sent to one address per window
+	PerIP    int `mapstructure:"per_ip"`    // requests from one IP per window
+	Window   int `mapstructure:"window"`    // seconds
+}
+
+type TwoFactorConfig struct {
+	Issuer        string `mapstructure:"issuer"`         // name shown in authenticator apps
+	ChallengeTTL  int    `mapstructure:"challenge_ttl"`  // seconds between the password and the second factor
+	EnforceForVIP bool   `mapstructure:"enforce_vip"`    // VIP accounts must enroll before they can log in
+	EnforceStaff  bool   `mapstructure:"enforce_staff"`  // moderators and admins must enroll before they can log in
+	RecoveryCodes int    `mapstructure:"recovery_codes"` // codes handed out when 2FA is enabled
+	MaxAttempts   int    `mapstructure:"max_attempts"`   // codes a user may try per challenge_ttl
+	EncryptionKey string `mapstructure:"encryption_key"` // encrypts TOTP secrets at rest; falls back to jwt.secret
+}
+
+type OTPConfig struct {
+	Length      int `mapstructure:"length"`       // digits per code
+	TTL         int `mapstructure:"ttl"`          // seconds a code is valid
+	Cooldown    int `mapstructure:"cooldown"`     // seconds before another code can be sent to a number
+	MaxAttempts int `mapstructure:"max_attempts"` // wrong guesses before a code is thrown away
+	DailyLimit  int `mapstructure:"daily_limit"`  // codes sent to one number per day
+}
+
+type SMSConfig struct {
+	Driver string `mapstructure:"driver"` // only fake so far
+}
+
+type ProfileConfig struct {
+	MaxAvatarBytes int64 `mapstructure:"max_avatar_bytes"` // largest avatar upload accepted
+}
+
+type SearchConfig struct {
+	PerMinute          int `mapstructure:"per_minute"`            // user searches allowed per minute
+	PhoneLookupsPerDay int `mapstructure:"phone_lookups_per_day"` // searches by phone number allowed per user per day
+}
+
+type ContactSyncConfig struct {
+	BatchSize    int `mapstructure:"batch_size"`     // most phone hashes accepted in one sync
+	PerHour      int `mapstructure:"per_hour"`       // syncs allowed per user per hour
+	HashesPerDay int `mapstructure:"hashes_per_day"` // phone hashes a user may check per day
+}
+
+type AccountDeletionConfig struct {
+	GracePeriod  int `mapstructure:"grace_period"`  // seconds a deletion can be canceled before the account is purged
+	ExportTTL    int `mapstructure:"export_ttl"`    // seconds a data export can be downloaded
+	PollInterval int `mapstructure:"poll_interval"` // seconds between checks for requested exports and due deletions
+	BatchSize    int `mapstructure:"batch_size"`    // exports and accounts claimed per check
+	ClaimTimeout int `mapstructure:"claim_timeout"` // seconds before an interrupted export or purge is tried again
+}
+
+type ScheduledConfig struct {
+	PollInterval int `mapstructure:"poll_interval"` // seconds between checks for due messages
+	BatchSize    int `mapstructure:"batch_size"`    // messages claimed per check
+	ClaimTimeout int `mapstructure:"claim_timeout"` // seconds before an interrupted send is checked and settled
+}
+
 // Implement infrastructure.Config interface
 func (c *Config) GetPostgresConfig() infrastructure.PostgresConfig {
 	return infrastructure.PostgresConfig{
@@ -86,6 +253,188 @@ func (c *Config) GetRedisConfig() infrastructure.RedisConfig {
 	}
 }
 
+func (c *Config) GetWebSocketConfig() infrastructure.WebSocketConfig {
+	return infrastructure.WebSocketConfig{
+		ReadBufferSize:  c.WebSocket.ReadBufferSize,
+		WriteBufferSize: c.WebSocket.WriteBufferSize,
+		ReplayRetention: c.WebSocket.ReplayRetention,
+		ReplayMaxEvents: c.WebSocket.ReplayMaxEvents,
+	}
+}
+
+func (c *Config) GetJWTConfig() infrastructure.JWTConfig {
+	return infrastructure.JWTConfig{
+		Secret:     c.JWT.Secret,
+		Expiration: c.JWT.Expiration,
+	}
+}
+
+func (c *Config) GetPresenceConfig() infrastructure.PresenceConfig {
+	return infrastructure.PresenceConfig{
+		TTL:       c.Presence.TTL,
+		AwayAfter: c.Presence.AwayAfter,
+	}
+}
+
+func (c *Config) GetTypingConfig() infrastructure.TypingConfig {
+	return infrastructure.TypingConfig{
+		TTL:                c.Typing.TTL,
+		Throttle:           c.Typing.Throttle,
+		AggregateThreshold: c.Typing.AggregateThreshold,
+	}
+}
+
+func (c *Config) GetReactionConfig() infrastructure.ReactionConfig {
+	return infrastructure.ReactionConfig{
+		SinglePerUser: c.Reactions.SinglePerUser,
+		Allowed:       c.Reactions.Allowed,
+	}
+}
+
+func (c *Config) GetPinConfig() infrastructure.PinConfig {
+	return infrastructure.PinConfig{
+		MaxPerConversation: c.Pins.MaxPerConversation,
+	}
+}
+
+func (c *Config) GetMentionConfig() infrastructure.MentionConfig {
+	return infrastructure.MentionConfig{
+		AllAdminOnlyAbove: c.Mentions.AllAdminOnlyAbove,
+	}
+}
+
+func (c *Config) GetScheduledConfig() infrastructure.ScheduledConfig {
+	return infrastructure.ScheduledConfig{
+		PollInterval: c.Scheduled.PollInterval,
+		BatchSize:    c.Scheduled.BatchSize,
+		ClaimTimeout: c.Scheduled.ClaimTimeout,
+	}
+}
+
+func (c *Config) GetStorageConfig() infrastructure.StorageConfig {
+	return infrastructure.StorageConfig{
+		Dir:                c.Storage.Dir,
+		BaseURL:            c.Storage.BaseURL,
+		SweepInterval:      c.Storage.SweepInterval,
+		MaxAttachmentBytes: c.Storage.MaxAttachmentBytes,
+	}
+}
+
+func (c *Config) GetLinkPreviewConfig() infrastructure.LinkPreviewConfig {
+	return infrastructure.LinkPreviewConfig{
+		Enabled:              c.Previews.Enabled,
+		Timeout:              c.Previews.Timeout,
+		MaxBodyBytes:         c.Previews.MaxBodyBytes,
+		AllowedHosts:         c.Previews.AllowedHosts,
+		BlockedHosts:         c.Previews.BlockedHosts,
+		AllowPrivateNetworks: c.Previews.AllowPrivateNetworks,
+		CacheTTL:             c.Previews.CacheTTL,
+		FailureTTL:           c.Previews.FailureTTL,
+	}
+}
+
+func (c *Config) GetPushConfig() infrastructure.PushConfig {
+	return infrastructure.PushConfig{
+		Workers:            c.Push.Workers,
+		QueueSize:          c.Push.QueueSize,
+		CollapseWindow:     c.Push.CollapseWindow,
+		Timeout:            c.Push.Timeout,
+		Fake:               c.Push.Fake,
+		FCMEnabled:         c.Push.FCM.Enabled,
+		FCMProjectID:       c.Push.FCM.ProjectID,
+		FCMCredentialsFile: c.Push.FCM.CredentialsFile,
+		APNsEnabled:        c.Push.APNs.Enabled,
+		APNsKeyFile:        c.Push.APNs.KeyFile,
+		APNsKeyID:          c.Push.APNs.KeyID,
+		APNsTeamID:         c.Push.APNs.TeamID,
+		APNsTopic:          c.Push.APNs.Topic,
+		APNsProduction:     c.Push.APNs.Production,
+	}
+}
+
+func (c *Config) GetMailConfig() infrastructure.MailConfig {
+	return infrastructure.MailConfig{
+		Driver:       c.Mail.Driver,
+		From:         c.Mail.From,
+		SMTPHost:     c.Mail.SMTP.Host,
+		SMTPPort:     c.Mail.SMTP.Port,
+		SMTPUsername: c.Mail.SMTP.Username,
+		SMTPPassword: c.Mail.SMTP.Password,
+		Timeout:      c.Mail.Timeout,
+	}
+}
+
+func (c *Config) GetAuthConfig() infrastructure.AuthConfig {
+	return infrastructure.AuthConfig{
+		AppURL:                   c.Auth.AppURL,
+		RequireEmailVerification: c.Auth.RequireEmailVerification,
+		VerificationTTL:          c.Auth.VerificationTTL,
+		ResetTTL:                 c.Auth.ResetTTL,
+		RateLimitPerEmail:        c.Auth.RateLimit.PerEmail,
+		RateLimitPerIP:           c.Auth.RateLimit.PerIP,
+		RateLimitWindow:          c.Auth.RateLimit.Window,
+	}
+}
+
+func (c *Config) GetTwoFactorConfig() infrastructure.TwoFactorConfig {
+	return infrastructure.TwoFactorConfig{
+		Issuer:          c.TwoFactor.Issuer,
+		ChallengeTTL:    c.TwoFactor.ChallengeTTL,
+		EnforceForVIP:   c.TwoFactor.EnforceForVIP,
+		EnforceForStaff: c.TwoFactor.EnforceStaff,
+		RecoveryCodes:   c.TwoFactor.RecoveryCodes,
+		MaxAttempts:     c.TwoFactor.MaxAttempts,
+		EncryptionKey:   c.TwoFactor.EncryptionKey,
+	}
+}
+
+func (c *Config) GetOTPConfig() infrastructure.OTPConfig {
+	return infrastructure.OTPConfig{
+		Length:      c.OTP.Length,
+		TTL:         c.OTP.TTL,
+		Cooldown:    c.OTP.Cooldown,
+		MaxAttempts: c.OTP.MaxAttempts,
+		DailyLimit:  c.OTP.DailyLimit,
+	}
+}
+
+func (c *Config) GetSMSConfig() infrastructure.SMSConfig {
+	return infrastructure.SMSConfig{
+		Driver: c.SMS.Driver,
+	}
+}
+
+func (c *Config) GetProfileConfig() infrastructure.ProfileConfig {
+	return infrastructure.ProfileConfig{
+		MaxAvatarBytes: c.Profile.MaxAvatarBytes,
+	}
+}
+
+func (c *Config) GetSearchConfig() infrastructure.SearchConfig {
+	return infrastructure.SearchConfig{
+		PerMinute:          c.Search.PerMinute,
+		PhoneLookupsPerDay: c.Search.PhoneLookupsPerDay,
+	}
+}
+
+func (c *Config) GetContactSyncConfig() infrastructure.ContactSyncConfig {
+	return infrastructure.ContactSyncConfig{
+		BatchSize:    c.ContactSync.BatchSize,
+		PerHour:      c.ContactSync.PerHour,
+		HashesPerDay: c.ContactSync.HashesPerDay,
+	}
+}
+
+func (c *Config) GetAccountDeletionConfig() infrastructure.AccountDeletionConfig {
+	return infrastructure.AccountDeletionConfig{
+		GracePeriod:  c.AccountDeletion.GracePeriod,
+		ExportTTL:    c.AccountDeletion.ExportTTL,
+		PollInterval: c.AccountDeletion.PollInterval,
+		BatchSize:    c.AccountDeletion.BatchSize,
+		ClaimTimeout: c.AccountDeletion.ClaimTimeout,
+	}
+}
+
 func (c *Config) GetServerMode() string {
 	return c.Server.Mode
 }
@@ -125,11 +474,95 @@ func LoadConfig() *Config {
 	viper.SetDefault("websocket.read_buffer_size", 1024)
 	viper.SetDefault("websocket.write_buffer_size", 1024)
 	viper.SetDefault("websocket.max_message_size", 524288) // 512KB
+	viper.SetDefault("websocket.replay_retention", 86400)  // 24 hours
+	viper.SetDefault("websocket.replay_max_events", 1000)
 
 	// Set defaults for JWT
 	viper.SetDefault("jwt.secret", "change-this-secret-key-in-production")
 	viper.SetDefault("jwt.expiration", 86400) // 24 hours
 
+	// Set defaults for Presence
+	viper.SetDefault("presence.ttl", 90) // must exceed the WebSocket ping period
+	viper.SetDefault("presence.away_after", 300)
+
+	// Set defaults for Typing indicators
+	viper.SetDefault("typing.ttl", 6)
+	viper.SetDefault("typing.throttle", 2000)
+	viper.SetDefault("typing.aggregate_threshold", 20)
+
+	// Set defaults for Reactions
+	viper.SetDefault("reactions.single_per_user", false)
+	viper.SetDefault("reactions.allowed", []string{})
+
+	// Set defaults for Pinned messages
+	viper.SetDefault("pins.max_per_conversation", 5)
+
+	// Set defaults for Mentions
+	viper.SetDefault("mentions.all_admin_only_above", 20)
+
+	// Set defaults for Scheduled messages
+	viper.SetDefault("scheduled.poll_interval", 5)
+	viper.SetDefault("scheduled.batch_size", 50)
+	viper.SetDefault("scheduled.claim_timeout", 300)
+
+	// Set defaults for Blob storage
+	viper.SetDefault("storage.dir", "./uploads")
+	viper.SetDefault("storage.base_url", "/uploads")
+	viper.SetDefault("storage.sweep_interval", 60)
+	viper.SetDefault("storage.max_attachment_bytes", 25<<20)
+
+	// Set defaults for Link previews
+	viper.SetDefault("link_preview.enabled", true)
+	viper.SetDefault("link_preview.timeout", 5)
+	viper.SetDefault("link_preview.max_body_bytes", 512*1024)
+	viper.SetDefault("link_preview.allowed_hosts", []string{})
+	viper.SetDefault("link_preview.blocked_hosts", []string{})
+	viper.SetDefault("link_preview.allow_private_networks", false)
+	viper.SetDefault("link_preview.cache_ttl", 86400)
+	viper.SetDefault("link_preview.failure_ttl", 3600)
+	viper.SetDefault("push.workers", 4)
+	viper.SetDefault("push.queue_size", 1024)
+	viper.SetDefault("push.collapse_window", 30)
+	viper.SetDefault("push.timeout", 10)
+	viper.SetDefault("push.fake", false)
+	viper.SetDefault("push.fcm.enabled", false)
+	viper.SetDefault("push.apns.enabled", false)
+	viper.SetDefault("push.apns.production", false)
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "VNalo <no-reply@vnalo.local>")
+	viper.SetDefault("mail.timeout", 10)
+	viper.SetDefault("mail.smtp.port", 587)
+	viper.SetDefault("auth.app_url", "http://localhost:3000")
+	viper.SetDefault("auth.require_email_verification", true)
+	viper.SetDefault("auth.verification_ttl", 86400)
+	viper.SetDefault("auth.reset_ttl", 3600)
+	viper.SetDefault("auth.rate_limit.per_email", 3)
+	viper.SetDefault("auth.rate_limit.per_ip", 20)
+	viper.SetDefault("auth.rate_limit.window", 3600)
+	viper.SetDefault("two_factor.issuer", "VNalo")
+	viper.SetDefault("two_factor.challenge_ttl", 300)
+	viper.SetDefault("two_factor.enforce_vip", true)
+	viper.SetDefault("two_factor.enforce_staff", true)
+	viper.SetDefault("two_factor.recovery_codes", 10)
+	viper.SetDefault("two_factor.max_attempts", 5)
+	viper.SetDefault("otp.length", 6)
+	viper.SetDefault("otp.ttl", 300)
+	viper.SetDefault("otp.cooldown", 60)
+	viper.SetDefault("otp.max_attempts", 5)
+	viper.SetDefault("otp.daily_limit", 10)
+	viper.SetDefault("sms.driver", "fake")
+	viper.SetDefault("profile.max_avatar_bytes", 5<<20)
+	viper.SetDefault("search.per_minute", 30)
+	viper.SetDefault("search.phone_lookups_per_day", 20)
+	viper.SetDefault("contact_sync.batch_size", 500)
+	viper.SetDefault("contact_sync.per_hour", 10)
+	viper.SetDefault("contact_sync.hashes_per_day", 5000)
+	viper.SetDefault("account_deletion.grace_period", 30*86400)
+	viper.SetDefault("account_deletion.export_ttl", 7*86400)
+	viper.SetDefault("account_deletion.poll_interval", 60)
+	viper.SetDefault("account_deletion.batch_size", 10)
+	viper.SetDefault("account_deletion.claim_timeout", 3600)
+
 	// Enable reading from environment variables
 	viper.AutomaticEnv()
 
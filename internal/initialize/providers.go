@@ -4,7 +4,10 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user"
 )
 
@@ -22,8 +25,11 @@ var AppModule = fx.Options(
 
 	// Business modules (from internal/modules)
 	// TODO: Add more modules as they are implemented
+	audit.Module,
 	user.Module,
 	message.Module,
+	presence.Module,
+	notification.Module,
 
 	// Router (must be last)
 	RouterModule,
@@ -1,18 +1,27 @@
 package initialize
 
 import (
+	"errors"
 	"log"
+	"net/http"
+	"path"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/fx"
 
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
 	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
 )
 
-// RouteRegisterFunc is a function that registers routes to a router group
-type RouteRegisterFunc func(*gin.RouterGroup)
+// Avatar keys get a new name on every upload, so browsers may keep them for good
+const avatarCacheControl = "public, max-age=31536000, immutable"
+
+// RouteRegisterFunc is a function that registers routes to a router group.
+// It is an alias so that plain func(*gin.RouterGroup) values provided by modules match the Fx group.
+type RouteRegisterFunc = func(*gin.RouterGroup)
 
 // RouterModule provides router
 var RouterModule = fx.Options(
@@ -24,6 +33,7 @@ type RouterParams struct {
 	fx.In
 
 	Config         *Config
+	Storage        storage.Storage
 	RouteRegisters []RouteRegisterFunc `group:"routes"` // ← Auto-collect tất cả!
 }
 
@@ -40,6 +50,7 @@ func NewRouter(params RouterParams) *gin.Engine {
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORS())
 	router.Use(middleware.Logger())
+	router.Use(middleware.RequestActor())
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -52,6 +63,13 @@ func NewRouter(params RouterParams) *gin.Engine {
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Avatars are public. Attachments and exports share the storage directory
+	// but are only handed out by the API, so nothing else under it is served.
+	if baseURL := strings.TrimSuffix(params.Config.Storage.BaseURL, "/"); strings.HasPrefix(baseURL, "/") {
+		router.GET(baseURL+"/avatars/*key", serveAvatar(params.Storage))
+		router.HEAD(baseURL+"/avatars/*key", serveAvatar(params.Storage))
+	}
+
 	// API versioning
 	v1 := router.Group("/api/v1")
 
@@ -63,3 +81,31 @@ func NewRouter(params RouterParams) *gin.Engine {
 	log.Printf("✅ Router configured with %d module(s)", len(params.RouteRegisters))
 	return router
 }
+
+// serveAvatar streams one size of an uploaded avatar from blob storage
+func serveAvatar(blobs storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := path.Clean("/avatars" + c.Param("key"))
+		if !strings.HasPrefix(key, "/avatars/") || path.Ext(key) != ".jpg" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		blob, err := blobs.Open(c.Request.Context(), strings.TrimPrefix(key, "/"))
+		if err != nil {
+			if !errors.Is(err, storage.ErrNotFound) && !errors.Is(err, storage.ErrInvalidKey) {
+				log.Printf("Failed to open avatar %s: %v", key, err)
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			c.Status(http.StatusNotFound)
+			return
+		}
+		defer blob.Close()
+
+		c.DataFromReader(http.StatusOK, -1, "image/jpeg", blob, map[string]string{
+			"Cache-Control":          avatarCacheControl,
+			"X-Content-Type-Options": "nosniff",
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package initialize
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+)
+
+func TestServeAvatar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	blobs, err := storage.NewLocalStorage(storage.Config{Dir: t.TempDir(), BaseURL: "/uploads"}, storage.NewMemoryExpiryIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"avatars/1/abc/64.jpg", "exports/1/export.zip", "attachments/7/1/abc"} {
+		if err := blobs.Put(ctx, key, strings.NewReader("blob"), "application/octet-stream"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := gin.New()
+	router.GET("/uploads/avatars/*key", serveAvatar(blobs))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/uploads/avatars/1/abc/64.jpg", want: http.StatusOK},
+		{path: "/uploads/avatars/1/abc/128.jpg", want: http.StatusNotFound},
+		{path: "/uploads/avatars/../exports/1/export.zip", want: http.StatusNotFound},
+		{path: "/uploads/avatars/%2e%2e/exports/1/export.zip", want: http.StatusNotFound},
+		{path: "/uploads/exports/1/export.zip", want: http.StatusNotFound},
+		{path: "/uploads/attachments/7/1/abc", want: http.StatusNotFound},
+		{path: "/uploads/avatars/1/abc", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.Path = tt.path
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK {
+				if rec.Body.String() != "blob" || rec.Header().Get("Content-Type") != "image/jpeg" {
+					t.Fatalf("got %q as %s", rec.Body.String(), rec.Header().Get("Content-Type"))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type actorKey struct{}
+
+// Actor identifies who made a request, for audit records
+type Actor struct {
+	UserID    int64 // Zero until the Auth middleware verified the caller
+	IP        string
+	UserAgent string
+}
+
+// RequestActor middleware stores the client's IP and user agent in the request
+// context, so services can attribute their actions without gin
+func RequestActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setActor(c, Actor{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Next()
+	}
+}
+
+// ActorFromContext returns the actor of the request ctx belongs to
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+func setActor(c *gin.Context, actor Actor) {
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), actorKey{}, actor))
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+)
+
+const (
+	userIDKey    = "user_id"
+	sessionIDKey = "session_id"
+)
+
+var ErrMissingToken = errors.New("missing access token")
+
+// SessionChecker reports whether the login session of an access token is
+// still signed in. It runs on every authenticated request, so it must be cheap.
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID int64, sessionID string) error
+}
+
+// Auth middleware verifies the bearer token and its session and stores the caller's user ID in the context.
+// Browsers cannot set headers on WebSocket upgrades, so the access_token query parameter is accepted too.
+func Auth(tokens *token.Manager, sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if raw == "" {
+			raw = c.Query("access_token")
+		}
+		if raw == "" {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrMissingToken)
+			c.Abort()
+			return
+		}
+
+		claims, err := tokens.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized", err)
+			c.Abort()
+			return
+		}
+
+		if err := sessions.CheckSession(c.Request.Context(), claims.UserID, claims.SessionID); err != nil {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized", err)
+			c.Abort()
+			return
+		}
+
+		actor := ActorFromContext(c.Request.Context())
+		actor.UserID = claims.UserID
+		setActor(c, actor)
+
+		c.Set(userIDKey, claims.UserID)
+		c.Set(sessionIDKey, claims.SessionID)
+		c.Next()
+	}
+}
+
+// GetUserID returns the authenticated user ID set by the Auth middleware
+func GetUserID(c *gin.Context) (int64, bool) {
+	value, ok := c.Get(userIDKey)
+	if !ok {
+		return 0, false
+	}
+	userID, ok := value.(int64)
+	return userID, ok
+}
+
+// GetSessionID returns the login session of the access token set by the Auth middleware
+func GetSessionID(c *gin.Context) (string, bool) {
+	value, ok := c.Get(sessionIDKey)
+	if !ok {
+		return "", false
+	}
+	sessionID, ok := value.(string)
+	return sessionID, ok
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+var ErrForbidden = errors.New("permission denied")
+
+// Authorizer decides whether a user holds a permission
+type Authorizer interface {
+	Authorize(ctx context.Context, userID int64, permission string) error
+}
+
+// RequirePermission middleware lets the request through only when the caller
+// holds permission. It must run after Auth.
+func RequirePermission(authz Authorizer, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrMissingToken)
+			c.Abort()
+			return
+		}
+
+		if err := authz.Authorize(c.Request.Context(), userID, permission); err != nil {
+			if errors.Is(err, ErrForbidden) {
+				response.Error(c, http.StatusForbidden, "Forbidden", err)
+			} else {
+				response.Error(c, http.StatusInternalServerError, "Failed to check permission", err)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/entity"
+)
+
+// Record describes an action to write to the audit log. Who did it, from
+// where, is taken from the request context.
+type Record struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    map[string]Change
+}
+
+// Change is the value of a field before and after an action
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ListRequest selects a page of the audit log
+type ListRequest struct {
+	ActorID    *int64
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+	Cursor     string // NextCursor of the previous page
+	Limit      int
+}
+
+// EntryResponse is one audit entry. Export writes one per line.
+type EntryResponse struct {
+	ID         int64             `json:"id"`
+	ActorID    *int64            `json:"actor_id"`
+	Action     string            `json:"action"`
+	TargetType string            `json:"target_type"`
+	TargetID   string            `json:"target_id"`
+	IP         string            `json:"ip"`
+	UserAgent  string            `json:"user_agent"`
+	Changes    map[string]Change `json:"changes,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// NewEntryResponse converts domain entity to DTO
+func NewEntryResponse(entry *entity.Entry) *EntryResponse {
+	resp := &EntryResponse{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if len(entry.Changes) > 0 {
+		resp.Changes = make(map[string]Change, len(entry.Changes))
+		for field, change := range entry.Changes {
+			resp.Changes[field] = Change{Before: change.Before, After: change.After}
+		}
+	}
+	return resp
+}
+
+// EntryListResponse is a page of the audit log, newest first
+type EntryListResponse struct {
+	Entries    []*EntryResponse `json:"entries"`
+	NextCursor string           `json:"next_cursor,omitempty"` // Empty on the last page
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+)
+
+// AuditService defines the audit log other modules write to
+type AuditService interface {
+	// Record appends an entry attributed to the actor of ctx. A failure is
+	// logged rather than returned: the action being audited already happened.
+	Record(ctx context.Context, record dto.Record)
+
+	// List returns a page of entries, newest first
+	List(ctx context.Context, req dto.ListRequest) (*dto.EntryListResponse, error)
+
+	// Export calls write for every entry matching req, newest first, ignoring
+	// its Limit. It stops at the first error write returns.
+	Export(ctx context.Context, req dto.ListRequest, write func(*dto.EntryResponse) error) error
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/repository"
+)
+
+const (
+	// Time allowed to write an entry, even when the request was cancelled meanwhile
+	recordTimeout = 5 * time.Second
+
+	defaultPageSize = 50
+	maxPageSize     = 200
+
+	// Entries read per query while exporting
+	exportBatchSize = 500
+)
+
+type auditServiceImpl struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService creates the audit log service
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditServiceImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *auditServiceImpl) Record(ctx context.Context, record dto.Record) {
+	actor := middleware.ActorFromContext(ctx)
+
+	entry := &entity.Entry{
+		Action:     record.Action,
+		TargetType: record.TargetType,
+		TargetID:   record.TargetID,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+		CreatedAt:  time.Now(),
+	}
+	if actor.UserID != 0 {
+		actorID := actor.UserID
+		entry.ActorID = &actorID
+	}
+	if len(record.Changes) > 0 {
+		entry.Changes = make(map[string]entity.Change, len(record.Changes))
+		for field, change := range record.Changes {
+			entry.Changes[field] = entity.Change{Before: change.Before, After: change.After}
+		}
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if err := s.auditRepo.Append(writeCtx, entry); err != nil {
+		log.Printf("Failed to write audit entry %s on %s %s: %v", record.Action, record.TargetType, record.TargetID, err)
+	}
+}
+
+func (s *auditServiceImpl) List(ctx context.Context, req dto.ListRequest) (*dto.EntryListResponse, error) {
+	filter, err := newFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	// One extra entry tells whether another page follows
+	entries, err := s.auditRepo.List(ctx, filter, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.EntryListResponse{Entries: make([]*dto.EntryResponse, 0, limit)}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		resp.NextCursor = encodeCursor(entries[limit-1].ID)
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, dto.NewEntryResponse(entry))
+	}
+
+	return resp, nil
+}
+
+func (s *auditServiceImpl) Export(ctx context.Context, req dto.ListRequest, write func(*dto.EntryResponse) error) error {
+	filter, err := newFilter(req)
+	if err != nil {
+		return err
+	}
+
+	// Reading the whole log is itself worth a record
+	s.Record(ctx, dto.Record{Action: "audit.export", TargetType: "audit_log"})
+
+	for {
+		entries, err := s.auditRepo.List(ctx, filter, exportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := write(dto.NewEntryResponse(entry)); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < exportBatchSize {
+			return nil
+		}
+		filter.BeforeID = entries[len(entries)-1].ID
+	}
+}
+
+// newFilter validates the filters of req and decodes its cursor
+func newFilter(req dto.ListRequest) (entity.Filter, error) {
+	if req.Since != nil && req.Until != nil && !req.Since.Before(*req.Until) {
+		return entity.Filter{}, entity.ErrInvalidFilter
+	}
+
+	filter := entity.Filter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Since:      req.Since,
+		Until:      req.Until,
+	}
+
+	if req.Cursor != "" {
+		beforeID, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return entity.Filter{}, err
+		}
+		filter.BeforeID = beforeID
+	}
+
+	return filter, nil
+}
+
+// Cursors are opaque to clients so the paging key can change later
+
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, entity.ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, entity.ErrInvalidCursor
+	}
+	return id, nil
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Entry is one record of the append-only audit log
+type Entry struct {
+	ID         int64
+	ActorID    *int64 // Nil for actions of the system or of anonymous callers
+	Action     string // e.g. "user.promote_vip"
+	TargetType string // e.g. "user"
+	TargetID   string
+	IP         string
+	UserAgent  string
+	Changes    map[string]Change // Field -> before and after; nil when nothing was edited
+	CreatedAt  time.Time
+}
+
+// Change is the value of a field before and after an action
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
@@ -0,0 +1,8 @@
+package entity
+
+import "errors"
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid filter")
+)
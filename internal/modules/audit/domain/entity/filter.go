@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// Filter selects audit entries. Zero fields match everything.
+type Filter struct {
+	ActorID    *int64
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time // Inclusive
+	Until      *time.Time // Exclusive
+	BeforeID   int64      // Entries older than this one; zero starts at the newest
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/entity"
+)
+
+// AuditRepository stores audit entries. Entries are never updated or deleted.
+type AuditRepository interface {
+	Append(ctx context.Context, entry *entity.Entry) error
+
+	// List returns up to limit entries matching filter, newest first
+	List(ctx context.Context, filter entity.Filter, limit int) ([]*entity.Entry, error)
+}
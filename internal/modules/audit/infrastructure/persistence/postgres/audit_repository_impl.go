@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/repository"
+)
+
+// auditModel maps the audit_logs table
+type auditModel struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement"`
+	ActorID    *int64 `gorm:"column:actor_id"`
+	Action     string `gorm:"type:varchar(64);not null"`
+	TargetType string `gorm:"type:varchar(32);not null"`
+	TargetID   string `gorm:"type:varchar(64);not null"`
+	IP         string `gorm:"column:ip;type:varchar(64);not null"`
+	UserAgent  string `gorm:"type:varchar(512);not null"`
+	Changes    []byte `gorm:"type:jsonb"`
+	CreatedAt  time.Time
+}
+
+func (auditModel) TableName() string {
+	return "audit_logs"
+}
+
+type auditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates an audit log repository
+func NewAuditRepository(db *gorm.DB) repository.AuditRepository {
+	return &auditRepositoryImpl{db: db}
+}
+
+func (r *auditRepositoryImpl) Append(ctx context.Context, entry *entity.Entry) error {
+	model := &auditModel{
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         entry.IP,
+		UserAgent:  truncate(entry.UserAgent, 512),
+		CreatedAt:  entry.CreatedAt,
+	}
+
+	if entry.Changes != nil {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit changes: %w", err)
+		}
+		model.Changes = changes
+	}
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	entry.ID = model.ID
+	return nil
+}
+
+func (r *auditRepositoryImpl) List(ctx context.Context, filter entity.Filter, limit int) ([]*entity.Entry, error) {
+	query := r.db.WithContext(ctx).Model(&auditModel{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var models []auditModel
+	if err := query.Order("id DESC").Limit(limit).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	entries := make([]*entity.Entry, 0, len(models))
+	for i := range models {
+		entry, err := models[i].toEntity()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (m *auditModel) toEntity() (*entity.Entry, error) {
+	entry := &entity.Entry{
+		ID:         m.ID,
+		ActorID:    m.ActorID,
+		Action:     m.Action,
+		TargetType: m.TargetType,
+		TargetID:   m.TargetID,
+		IP:         m.IP,
+		UserAgent:  m.UserAgent,
+		CreatedAt:  m.CreatedAt,
+	}
+
+	if len(m.Changes) > 0 {
+		if err := json.Unmarshal(m.Changes, &entry.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode audit changes of entry %d: %w", m.ID, err)
+		}
+	}
+	return entry, nil
+}
+
+// truncate cuts s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
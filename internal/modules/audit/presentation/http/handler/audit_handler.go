@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListEntries godoc
+// @Summary List audit log entries
+// @Description Page through the audit log, newest first
+// @Tags admin
+// @Produce json
+// @Param actor_id query int false "User who acted"
+// @Param action query string false "Action, e.g. user.promote_vip"
+// @Param target_type query string false "Target type, e.g. user"
+// @Param target_id query string false "Target ID"
+// @Param since query string false "RFC 3339 time, inclusive"
+// @Param until query string false "RFC 3339 time, exclusive"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" default(50)
+// @Success 200 {object} response.Response{data=dto.EntryListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /admin/audit-logs [get]
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	req, err := parseListRequest(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+
+	entries, err := h.auditService.List(c.Request.Context(), req)
+	if err != nil {
+		respondAuditError(c, "Failed to list audit log", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Audit log retrieved successfully", entries)
+}
+
+// ExportEntries godoc
+// @Summary Export audit log entries
+// @Description Download every matching entry as newline-delimited JSON, newest first
+// @Tags admin
+// @Produce application/x-ndjson
+// @Param actor_id query int false "User who acted"
+// @Param action query string false "Action, e.g. user.promote_vip"
+// @Param target_type query string false "Target type, e.g. user"
+// @Param target_id query string false "Target ID"
+// @Param since query string false "RFC 3339 time, inclusive"
+// @Param until query string false "RFC 3339 time, exclusive"
+// @Success 200 {string} string "One dto.EntryResponse per line"
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /admin/audit-logs/export [get]
+func (h *AuditHandler) ExportEntries(c *gin.Context) {
+	req, err := parseListRequest(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+	req.Cursor = ""
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit-log.ndjson"`)
+
+	out := bufio.NewWriter(c.Writer)
+	encoder := json.NewEncoder(out)
+	started := false
+
+	err = h.auditService.Export(c.Request.Context(), req, func(entry *dto.EntryResponse) error {
+		started = true
+		return encoder.Encode(entry)
+	})
+	if err != nil && !started {
+		respondAuditError(c, "Failed to export audit log", err)
+		return
+	}
+	if err != nil {
+		// Part of the file may have been sent; a truncated file is all the client can get
+		log.Printf("Audit log export stopped early: %v", err)
+	}
+
+	c.Status(http.StatusOK)
+	if err := out.Flush(); err != nil {
+		log.Printf("Failed to write audit log export: %v", err)
+	}
+}
+
+// parseListRequest reads the filters shared by listing and exporting
+func parseListRequest(c *gin.Context) (dto.ListRequest, error) {
+	req := dto.ListRequest{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Cursor:     c.Query("cursor"),
+	}
+	req.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if raw := c.Query("actor_id"); raw != "" {
+		actorID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return req, err
+		}
+		req.ActorID = &actorID
+	}
+
+	for _, bound := range []struct {
+		name string
+		dest **time.Time
+	}{{"since", &req.Since}, {"until", &req.Until}} {
+		raw := c.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return req, err
+		}
+		*bound.dest = &t
+	}
+
+	return req, nil
+}
+
+// respondAuditError maps audit log errors to HTTP status codes
+func respondAuditError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, entity.ErrInvalidFilter):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/presentation/http/handler"
+	userEntity "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// RegisterAuditRoutes registers the audit log routes for staff
+func RegisterAuditRoutes(router *gin.RouterGroup, auditHandler *handler.AuditHandler, auth gin.HandlerFunc, authz middleware.Authorizer) {
+	logs := router.Group("/admin/audit-logs", auth, middleware.RequirePermission(authz, userEntity.PermissionAuditView))
+	{
+		logs.GET("", auditHandler.ListEntries)
+		logs.GET("/export", auditHandler.ExportEntries)
+	}
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/domain/repository"
+	auditPostgres "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/infrastructure/persistence/postgres"
+	auditHandler "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/presentation/http/handler"
+	auditRouter "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/presentation/http/router"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+)
+
+// Module provides audit module dependencies
+var Module = fx.Options(
+	fx.Provide(provideRepository),
+	fx.Provide(provideService),
+	fx.Provide(provideHandler),
+	fx.Provide(
+		fx.Annotate(
+			provideRouteRegistration,
+			fx.ResultTags(`group:"routes"`),
+		),
+	),
+)
+
+func provideRepository(db *gorm.DB) repository.AuditRepository {
+	log.Println("📦 Creating audit repository...")
+	return auditPostgres.NewAuditRepository(db)
+}
+
+func provideService(repo repository.AuditRepository) service.AuditService {
+	log.Println("⚙️  Creating audit service...")
+	return service.NewAuditService(repo)
+}
+
+func provideHandler(svc service.AuditService) *auditHandler.AuditHandler {
+	log.Println("🎯 Creating audit handler...")
+	return auditHandler.NewAuditHandler(svc)
+}
+
+func provideRouteRegistration(
+	h *auditHandler.AuditHandler,
+	tokens *token.Manager,
+	sessions middleware.SessionChecker,
+	authz middleware.Authorizer,
+) func(*gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		log.Println("✅ Registering audit routes...")
+		auditRouter.RegisterAuditRoutes(router, h, middleware.Auth(tokens, sessions), authz)
+	}
+}
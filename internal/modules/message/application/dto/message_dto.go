@@ -13,22 +13,129 @@ type SendMessageRequest struct {
 	Content         string            `json:"content" validate:"required"`
 	ParentMessageID *string           `json:"parent_message_id,omitempty"` // UUID string for reply
 	Metadata        map[string]string `json:"metadata,omitempty"`
+	AttachmentKey   string            `json:"attachment_key,omitempty" validate:"max=255"` // From POST /conversations/{id}/attachments
+	SendAt          *time.Time        `json:"send_at,omitempty"`                           // Schedule the message instead of sending it now
+	Mentions        []MentionEntity   `json:"mentions,omitempty" validate:"max=50,dive"`
+	Entities        []TextEntity      `json:"entities,omitempty" validate:"max=100,dive"` // Formatting of TEXT messages
+}
+
+// TextEntity formats a span of a text message. Offset and length count characters of the content.
+// A link without url links the text itself.
+type TextEntity struct {
+	Type   string `json:"type" validate:"required,oneof=bold italic code link"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Length int    `json:"length" validate:"min=1"`
+	URL    string `json:"url,omitempty" validate:"omitempty,url,max=2048"`
+}
+
+// ToTextEntities converts text entity DTOs to domain entities
+func ToTextEntities(entities []TextEntity) []entity.TextEntity {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	result := make([]entity.TextEntity, 0, len(entities))
+	for _, span := range entities {
+		result = append(result, entity.TextEntity{
+			Type:   entity.TextEntityType(span.Type),
+			Offset: span.Offset,
+			Length: span.Length,
+			URL:    span.URL,
+		})
+	}
+	return result
+}
+
+// NewTextEntities converts domain text entities to DTOs
+func NewTextEntities(entities []entity.TextEntity) []TextEntity {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	result := make([]TextEntity, 0, len(entities))
+	for _, span := range entities {
+		result = append(result, TextEntity{
+			Type:   string(span.Type),
+			Offset: span.Offset,
+			Length: span.Length,
+			URL:    span.URL,
+		})
+	}
+	return result
+}
+
+// LinkPreviewResponse summarizes the page behind the first link of a message
+type LinkPreviewResponse struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+// MentionEntity marks a span of the content that mentions a member (type user) or everyone (type all).
+// Offset and length count characters of the content.
+type MentionEntity struct {
+	Type   string `json:"type" validate:"required,oneof=user all"`
+	UserID int64  `json:"user_id,omitempty"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Length int    `json:"length" validate:"min=1"`
+}
+
+// ToMentions converts mention DTOs to domain mentions
+func ToMentions(mentions []MentionEntity) []entity.Mention {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	result := make([]entity.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		result = append(result, entity.Mention{
+			Type:   entity.MentionType(mention.Type),
+			UserID: mention.UserID,
+			Offset: mention.Offset,
+			Length: mention.Length,
+		})
+	}
+	return result
+}
+
+// NewMentionEntities converts domain mentions to DTOs
+func NewMentionEntities(mentions []entity.Mention) []MentionEntity {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	result := make([]MentionEntity, 0, len(mentions))
+	for _, mention := range mentions {
+		result = append(result, MentionEntity{
+			Type:   string(mention.Type),
+			UserID: mention.UserID,
+			Offset: mention.Offset,
+			Length: mention.Length,
+		})
+	}
+	return result
 }
 
 // MessageResponse represents a message response
 type MessageResponse struct {
-	MessageID       string            `json:"message_id"`
-	ConversationID  int64             `json:"conversation_id"`
-	SenderID        int64             `json:"sender_id"`
-	ParentMessageID *string           `json:"parent_message_id,omitempty"`
-	Type            string            `json:"type"`
-	Content         string            `json:"content"`
-	Metadata        map[string]string `json:"metadata,omitempty"`
-	Status          string            `json:"status"`
-	IsEdited        bool              `json:"is_edited"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt       time.Time         `json:"updated_at"`
-	ReactionCounts  map[string]int64  `json:"reaction_counts,omitempty"`
+	MessageID       string               `json:"message_id"`
+	ConversationID  int64                `json:"conversation_id"`
+	SenderID        int64                `json:"sender_id"`
+	ParentMessageID *string              `json:"parent_message_id,omitempty"`
+	Type            string               `json:"type"`
+	Content         string               `json:"content"`
+	Metadata        map[string]string    `json:"metadata,omitempty"`
+	Mentions        []MentionEntity      `json:"mentions,omitempty"`
+	Entities        []TextEntity         `json:"entities,omitempty"`
+	LinkPreview     *LinkPreviewResponse `json:"link_preview,omitempty"` // Arrives later in message.updated
+	Status          string               `json:"status"`
+	IsEdited        bool                 `json:"is_edited"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	ReactionCounts  map[string]int64     `json:"reaction_counts,omitempty"`
+	ExpiresAt       *time.Time           `json:"expires_at,omitempty"`
 }
 
 // NewMessageResponse converts domain entity to DTO
@@ -40,16 +147,28 @@ func NewMessageResponse(message *entity.Message) *MessageResponse {
 		Type:           string(message.Type),
 		Content:        message.Content,
 		Metadata:       message.Metadata,
+		Mentions:       NewMentionEntities(message.Mentions),
+		Entities:       NewTextEntities(message.Entities),
 		Status:         string(message.Status),
 		IsEdited:       message.IsEdited,
 		CreatedAt:      message.CreatedAt,
 		UpdatedAt:      message.UpdatedAt,
+		ExpiresAt:      message.ExpiresAt,
 	}
 
 	if message.ParentMessageID != nil {
 		parentID := message.ParentMessageID.String()
 		resp.ParentMessageID = &parentID
 	}
+	if message.LinkPreview != nil {
+		resp.LinkPreview = &LinkPreviewResponse{
+			URL:         message.LinkPreview.URL,
+			Title:       message.LinkPreview.Title,
+			Description: message.LinkPreview.Description,
+			ImageURL:    message.LinkPreview.ImageURL,
+			SiteName:    message.LinkPreview.SiteName,
+		}
+	}
 
 	return resp
 }
@@ -62,9 +181,135 @@ type MessageListResponse struct {
 	ConversationID int64              `json:"conversation_id"`
 }
 
+// MentionResponse is an entry of the mentions inbox
+type MentionResponse struct {
+	ConversationID int64     `json:"conversation_id"`
+	MessageID      string    `json:"message_id"`
+	SenderID       int64     `json:"sender_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MentionListResponse represents a page of the mentions inbox, newest first
+type MentionListResponse struct {
+	Mentions      []*MentionResponse `json:"mentions"`
+	NextPageState string             `json:"next_page_state,omitempty"`
+	HasMore       bool               `json:"has_more"`
+}
+
+// MentionEvent is pushed as mention.new to each mentioned member, muted or not
+type MentionEvent struct {
+	ConversationID int64  `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+	SenderID       int64  `json:"sender_id"`
+	Preview        string `json:"preview"`
+	All            bool   `json:"all"` // Mentioned through @all
+}
+
 // EditMessageRequest represents the request to edit a message
 type EditMessageRequest struct {
-	Content string `json:"content" validate:"required"`
+	Content  string       `json:"content" validate:"required"`
+	Entities []TextEntity `json:"entities,omitempty" validate:"max=100,dive"` // Replace the formatting of the old content
+}
+
+// ForwardMessageRequest represents the request to forward a message to other conversations
+type ForwardMessageRequest struct {
+	ConversationID        int64   `json:"conversation_id" validate:"required"` // Conversation the message is in
+	TargetConversationIDs []int64 `json:"target_conversation_ids" validate:"required,min=1,max=20,dive,required"`
+}
+
+// ForwardMessageResponse lists the copies created in the target conversations
+type ForwardMessageResponse struct {
+	Messages []*MessageResponse `json:"messages"`
+}
+
+// ScheduledMessageResponse represents a message waiting to be sent
+type ScheduledMessageResponse struct {
+	ID              int64             `json:"id"`
+	ConversationID  int64             `json:"conversation_id"`
+	ParentMessageID *string           `json:"parent_message_id,omitempty"`
+	Type            string            `json:"type"`
+	Content         string            `json:"content"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	Mentions        []MentionEntity   `json:"mentions,omitempty"`
+	Entities        []TextEntity      `json:"entities,omitempty"`
+	SendAt          time.Time         `json:"send_at"`
+	Status          string            `json:"status"`
+	FailureReason   string            `json:"failure_reason,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// NewScheduledMessageResponse converts domain entity to DTO
+func NewScheduledMessageResponse(message *entity.ScheduledMessage) *ScheduledMessageResponse {
+	resp := &ScheduledMessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		Type:           string(message.Type),
+		Content:        message.Content,
+		Metadata:       message.Metadata,
+		Mentions:       NewMentionEntities(message.Mentions),
+		Entities:       NewTextEntities(message.Entities),
+		SendAt:         message.SendAt,
+		Status:         string(message.Status),
+		FailureReason:  message.FailureReason,
+		CreatedAt:      message.CreatedAt,
+		UpdatedAt:      message.UpdatedAt,
+	}
+
+	if message.ParentMessageID != nil {
+		parentID := message.ParentMessageID.String()
+		resp.ParentMessageID = &parentID
+	}
+
+	return resp
+}
+
+// UpdateScheduledMessageRequest changes the content or send time of a scheduled message.
+// New content replaces the mentions and entities with Mentions and Entities.
+type UpdateScheduledMessageRequest struct {
+	Content  *string         `json:"content,omitempty" validate:"omitempty,min=1"`
+	Mentions []MentionEntity `json:"mentions,omitempty" validate:"max=50,dive"`
+	Entities []TextEntity    `json:"entities,omitempty" validate:"max=100,dive"`
+	SendAt   *time.Time      `json:"send_at,omitempty"`
+}
+
+// DisappearingMessagesRequest sets how long new messages in a conversation live
+type DisappearingMessagesRequest struct {
+	TTLSeconds int64 `json:"ttl_seconds" validate:"oneof=0 86400 604800 7776000"` // Off, 1 day, 7 days or 90 days
+}
+
+// DisappearingMessagesResponse represents the disappearing messages setting
+type DisappearingMessagesResponse struct {
+	ConversationID int64 `json:"conversation_id"`
+	TTLSeconds     int64 `json:"ttl_seconds"`
+}
+
+// OpenDirectConversationRequest represents the request to start or find a
+// direct conversation with a user
+type OpenDirectConversationRequest struct {
+	UserID int64 `json:"user_id" validate:"required"`
+}
+
+// DirectConversationResponse identifies the direct conversation with a user
+type DirectConversationResponse struct {
+	ConversationID int64 `json:"conversation_id"`
+	Created        bool  `json:"created"` // False when the conversation already existed
+}
+
+// AddMembersRequest represents the request to add users to a group
+type AddMembersRequest struct {
+	UserIDs []int64 `json:"user_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// ChangeRoleRequest represents the request to promote or demote a member
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=ADMIN MEMBER"`
+}
+
+// RenameConversationRequest represents the request to rename a group
+type RenameConversationRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
 }
 
 // ReactToMessageRequest represents the request to react to a message
@@ -77,3 +322,102 @@ type TypingIndicatorRequest struct {
 	IsTyping bool `json:"is_typing"`
 }
 
+// TypingUsersResponse represents users currently typing in a conversation
+type TypingUsersResponse struct {
+	ConversationID int64   `json:"conversation_id"`
+	UserIDs        []int64 `json:"user_ids"`
+}
+
+// TypingEvent is pushed as typing.started / typing.stopped
+type TypingEvent struct {
+	ConversationID int64 `json:"conversation_id"`
+	UserID         int64 `json:"user_id"`
+}
+
+// TypingSummaryEvent is pushed as typing.updated in large groups instead of per-user events
+type TypingSummaryEvent struct {
+	ConversationID int64   `json:"conversation_id"`
+	UserIDs        []int64 `json:"user_ids"` // A few of the typing users, for display
+	Count          int     `json:"count"`
+}
+
+// MessageRefEvent identifies a message in events such as message.deleted
+type MessageRefEvent struct {
+	ConversationID int64  `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+}
+
+// ReactionEvent is pushed when a reaction is added or removed
+type ReactionEvent struct {
+	ConversationID int64  `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+	UserID         int64  `json:"user_id"`
+	Emoji          string `json:"emoji"`
+}
+
+// PinnedMessageResponse represents a pinned message with a preview of its content
+type PinnedMessageResponse struct {
+	ConversationID int64     `json:"conversation_id"`
+	MessageID      string    `json:"message_id"`
+	SenderID       int64     `json:"sender_id"`
+	Type           string    `json:"type"`
+	Preview        string    `json:"preview"`
+	PinnedBy       int64     `json:"pinned_by"`
+	PinnedAt       time.Time `json:"pinned_at"`
+}
+
+// NewPinnedMessageResponse converts a pin and its message to DTO
+func NewPinnedMessageResponse(pin *entity.Pin, message *entity.Message, previewLength int) *PinnedMessageResponse {
+	return &PinnedMessageResponse{
+		ConversationID: pin.ConversationID,
+		MessageID:      pin.MessageID.String(),
+		SenderID:       message.SenderID,
+		Type:           string(message.Type),
+		Preview:        message.Preview(previewLength),
+		PinnedBy:       pin.PinnedBy,
+		PinnedAt:       pin.PinnedAt,
+	}
+}
+
+// PinEvent is pushed as message.unpinned; message.pinned carries a PinnedMessageResponse
+type PinEvent struct {
+	ConversationID int64  `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+	UserID         int64  `json:"user_id"`
+}
+
+// ReadReceiptEvent is pushed to the sender when a member reads their message
+type ReadReceiptEvent struct {
+	ConversationID int64  `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+	UserID         int64  `json:"user_id"`
+}
+
+// MembershipResponse is a conversation in an account data export
+type MembershipResponse struct {
+	ConversationID int64      `json:"conversation_id"`
+	Type           string     `json:"type"`
+	Name           string     `json:"name,omitempty"`
+	Role           string     `json:"role"`
+	JoinedAt       *time.Time `json:"joined_at,omitempty"`
+	LeftAt         *time.Time `json:"left_at,omitempty"`
+}
+
+// NewMembershipResponse converts domain entity to DTO
+func NewMembershipResponse(membership *entity.Membership) *MembershipResponse {
+	return &MembershipResponse{
+		ConversationID: membership.ConversationID,
+		Type:           membership.ConversationType,
+		Name:           membership.ConversationName,
+		Role:           string(membership.Role),
+		JoinedAt:       membership.JoinedAt,
+		LeftAt:         membership.LeftAt,
+	}
+}
+
+// AttachmentResponse identifies an uploaded attachment. Send it as attachment_key of a media message.
+type AttachmentResponse struct {
+	AttachmentKey string `json:"attachment_key"`
+	ContentType   string `json:"content_type"`
+	SizeBytes     int64  `json:"size_bytes"`
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+)
+
+// AccountDataService exports and erases a user's messaging data when they
+// delete their account
+type AccountDataService interface {
+	// ListMemberships returns the conversations the user joined, left ones included
+	ListMemberships(ctx context.Context, userID int64) ([]*dto.MembershipResponse, error)
+
+	// ExportMessages calls fn for every message the user sent and did not
+	// recall, newest first, stopping at the first error fn returns
+	ExportMessages(ctx context.Context, userID int64, fn func(*dto.MessageResponse) error) error
+
+	// ListAttachmentKeys returns the storage keys of the attachments the user
+	// uploaded. Message metadata names blobs too, but only these are the user's.
+	ListAttachmentKeys(ctx context.Context, userID int64) ([]string, error)
+
+	// PurgeUser replaces the user's messages with tombstones and deletes their
+	// uploads, reactions, mentions inbox and scheduled messages. It can be
+	// run again after a failure.
+	PurgeUser(ctx context.Context, userID int64) error
+}
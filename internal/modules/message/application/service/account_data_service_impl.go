@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// Messages read per page while exporting or purging an account
+const accountDataPageSize = 100
+
+type accountDataServiceImpl struct {
+	messageRepo   repository.MessageRepository
+	memberRepo    repository.MemberRepository
+	scheduledRepo repository.ScheduledMessageRepository
+	attachments   repository.AttachmentRepository
+	blobs         storage.Storage
+}
+
+// NewAccountDataService creates the account data service. messageRepo is nil
+// while Cassandra is not connected; exports and purges then fail and are retried.
+func NewAccountDataService(
+	messageRepo repository.MessageRepository,
+	memberRepo repository.MemberRepository,
+	scheduledRepo repository.ScheduledMessageRepository,
+	attachments repository.AttachmentRepository,
+	blobs storage.Storage,
+) AccountDataService {
+	return &accountDataServiceImpl{
+		messageRepo:   messageRepo,
+		memberRepo:    memberRepo,
+		scheduledRepo: scheduledRepo,
+		attachments:   attachments,
+		blobs:         blobs,
+	}
+}
+
+func (s *accountDataServiceImpl) ListMemberships(ctx context.Context, userID int64) ([]*dto.MembershipResponse, error) {
+	memberships, err := s.memberRepo.ListMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.MembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		responses = append(responses, dto.NewMembershipResponse(membership))
+	}
+
+	return responses, nil
+}
+
+func (s *accountDataServiceImpl) ExportMessages(ctx context.Context, userID int64, fn func(*dto.MessageResponse) error) error {
+	return s.eachMessage(ctx, userID, func(message *entity.Message) error {
+		if message.IsDeleted || message.IsTombstone() {
+			return nil
+		}
+		return fn(dto.NewMessageResponse(message))
+	})
+}
+
+func (s *accountDataServiceImpl) ListAttachmentKeys(ctx context.Context, userID int64) ([]string, error) {
+	attachments, err := s.attachments.ListByUploader(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey)
+	}
+	return keys, nil
+}
+
+func (s *accountDataServiceImpl) PurgeUser(ctx context.Context, userID int64) error {
+	// The indexes are dropped last, so a purge that stops halfway finds the
+	// remaining messages and reactions when it runs again
+	if err := s.eachMessage(ctx, userID, func(message *entity.Message) error {
+		return s.tombstone(ctx, message)
+	}); err != nil {
+		return err
+	}
+
+	reactions, err := s.messageRepo.ListReactionsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, reaction := range reactions {
+		if _, err := s.messageRepo.RemoveReaction(ctx, reaction.MessageID, userID, reaction.Emoji); err != nil {
+			return err
+		}
+	}
+
+	if err := s.scheduledRepo.DeleteBySender(ctx, userID); err != nil {
+		return err
+	}
+
+	// Blobs are found through the upload records rather than message
+	// metadata, which can name keys that belong to someone else
+	attachments, err := s.attachments.ListByUploader(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if err := s.blobs.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+		if err := s.attachments.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+	}
+
+	return s.messageRepo.DeleteUserIndexes(ctx, userID)
+}
+
+// tombstone erases one message and the copies of it in other users' mentions
+// inboxes. Its attachment is deleted with the user's other uploads.
+func (s *accountDataServiceImpl) tombstone(ctx context.Context, message *entity.Message) error {
+	if message.IsTombstone() {
+		return nil
+	}
+
+	if len(message.Mentions) > 0 {
+		memberIDs, err := s.memberRepo.GetMemberIDs(ctx, message.ConversationID)
+		if err != nil {
+			return err
+		}
+		if err := s.messageRepo.RemoveMentions(ctx, message.MessageID, message.MentionedUserIDs(memberIDs)); err != nil {
+			return err
+		}
+	}
+
+	message.Tombstone()
+	return s.messageRepo.Tombstone(ctx, message)
+}
+
+// eachMessage calls fn with every message the user sent that has not expired
+func (s *accountDataServiceImpl) eachMessage(ctx context.Context, userID int64, fn func(*entity.Message) error) error {
+	if s.messageRepo == nil {
+		return entity.ErrMessageStoreUnavailable
+	}
+
+	var pageState []byte
+	for {
+		refs, next, err := s.messageRepo.ListMessagesByUser(ctx, userID, accountDataPageSize, pageState)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range refs {
+			message, err := s.messageRepo.GetByID(ctx, ref.ConversationID, ref.MessageID)
+			if errors.Is(err, entity.ErrMessageNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := fn(message); err != nil {
+				return err
+			}
+		}
+
+		if len(next) == 0 {
+			return nil
+		}
+		pageState = next
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// fakeMessageRepo serves one page of messages and records tombstones
+type fakeMessageRepo struct {
+	repository.MessageRepository
+	messages   []*entity.Message
+	tombstoned map[gocql.UUID]bool
+}
+
+func (f *fakeMessageRepo) ListMessagesByUser(ctx context.Context, userID int64, limit int, pagingState []byte) ([]*entity.Message, []byte, error) {
+	var sent []*entity.Message
+	for _, message := range f.messages {
+		if message.SenderID == userID {
+			sent = append(sent, message)
+		}
+	}
+	return sent, nil, nil
+}
+
+func (f *fakeMessageRepo) GetByID(ctx context.Context, conversationID int64, messageID gocql.UUID) (*entity.Message, error) {
+	for _, message := range f.messages {
+		if message.MessageID == messageID {
+			return message, nil
+		}
+	}
+	return nil, entity.ErrMessageNotFound
+}
+
+func (f *fakeMessageRepo) Tombstone(ctx context.Context, message *entity.Message) error {
+	f.tombstoned[message.MessageID] = true
+	return nil
+}
+
+func (f *fakeMessageRepo) ListReactionsByUser(ctx context.Context, userID int64) ([]entity.UserReaction, error) {
+	return nil, nil
+}
+
+func (f *fakeMessageRepo) DeleteUserIndexes(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (f *fakeScheduledRepo) DeleteBySender(ctx context.Context, senderID int64) error {
+	return nil
+}
+
+// fakeAttachments keeps upload records in memory by storage key
+type fakeAttachments struct {
+	repository.AttachmentRepository
+	records map[string]*entity.Attachment
+}
+
+func (f *fakeAttachments) ListByUploader(ctx context.Context, uploaderID int64) ([]*entity.Attachment, error) {
+	var attachments []*entity.Attachment
+	for _, attachment := range f.records {
+		if attachment.UploaderID == uploaderID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (f *fakeAttachments) Delete(ctx context.Context, storageKey string) error {
+	delete(f.records, storageKey)
+	return nil
+}
+
+func TestPurgeUserDeletesOnlyOwnUploads(t *testing.T) {
+	ctx := context.Background()
+
+	blobs, err := storage.NewLocalStorage(storage.Config{Dir: t.TempDir(), BaseURL: "/uploads"}, storage.NewMemoryExpiryIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const mine, theirs, unsent = "attachments/7/1/mine", "attachments/7/2/theirs", "attachments/7/1/unsent"
+	for _, key := range []string{mine, theirs, unsent} {
+		if err := blobs.Put(ctx, key, strings.NewReader("blob"), "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attachments := &fakeAttachments{records: map[string]*entity.Attachment{
+		mine:   entity.NewAttachment(mine, 1, 7, "image/jpeg", 4),
+		theirs: entity.NewAttachment(theirs, 2, 7, "image/jpeg", 4),
+		unsent: entity.NewAttachment(unsent, 1, 7, "image/jpeg", 4),
+	}}
+
+	// The second message names another member's blob in its metadata
+	own := entity.NewMessage(7, 1, entity.MessageTypeImage, "")
+	own.Metadata[entity.MetadataAttachmentKey] = mine
+	forged := entity.NewMessage(7, 1, entity.MessageTypeImage, "")
+	forged.Metadata[entity.MetadataAttachmentKey] = theirs
+	original := entity.NewMessage(7, 2, entity.MessageTypeImage, "")
+	original.Metadata[entity.MetadataAttachmentKey] = theirs
+	messages := &fakeMessageRepo{messages: []*entity.Message{own, forged, original}, tombstoned: make(map[gocql.UUID]bool)}
+
+	svc := NewAccountDataService(messages, nil, &fakeScheduledRepo{}, attachments, blobs)
+
+	keys, err := svc.ListAttachmentKeys(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("listed %q, want the two uploads of user 1", keys)
+	}
+
+	if err := svc.PurgeUser(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if !messages.tombstoned[own.MessageID] || !messages.tombstoned[forged.MessageID] || messages.tombstoned[original.MessageID] {
+		t.Fatalf("tombstoned %v", messages.tombstoned)
+	}
+	for _, key := range []string{mine, unsent} {
+		if _, err := blobs.Open(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("open %s after purge: %v, want ErrNotFound", key, err)
+		}
+		if _, ok := attachments.records[key]; ok {
+			t.Fatalf("record of %s survived the purge", key)
+		}
+	}
+	blob, err := blobs.Open(ctx, theirs)
+	if err != nil {
+		t.Fatalf("purge deleted another member's blob: %v", err)
+	}
+	blob.Close()
+	if _, ok := attachments.records[theirs]; !ok {
+		t.Fatal("purge deleted another member's upload record")
+	}
+}
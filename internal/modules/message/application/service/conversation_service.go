@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+)
+
+// ConversationService runs group lifecycle operations and announces them with system messages
+type ConversationService interface {
+	// OpenDirect returns the direct conversation between the actor and another
+	// user, starting it if there is none and the other user accepts it
+	OpenDirect(ctx context.Context, actorID int64, req dto.OpenDirectConversationRequest) (*dto.DirectConversationResponse, error)
+
+	AddMembers(ctx context.Context, conversationID int64, actorID int64, req dto.AddMembersRequest) error
+
+	// RemoveMember removes a member; removing yourself leaves the conversation
+	RemoveMember(ctx context.Context, conversationID int64, actorID int64, targetID int64) error
+	ChangeRole(ctx context.Context, conversationID int64, actorID int64, targetID int64, req dto.ChangeRoleRequest) error
+	Rename(ctx context.Context, conversationID int64, actorID int64, req dto.RenameConversationRequest) error
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+type conversationServiceImpl struct {
+	memberRepo repository.MemberRepository
+	convRepo   repository.ConversationRepository
+	users      repository.UserDirectory
+	messages   MessageService
+}
+
+// NewConversationService creates the conversation lifecycle service
+func NewConversationService(
+	memberRepo repository.MemberRepository,
+	convRepo repository.ConversationRepository,
+	users repository.UserDirectory,
+	messages MessageService,
+) ConversationService {
+	return &conversationServiceImpl{
+		memberRepo: memberRepo,
+		convRepo:   convRepo,
+		users:      users,
+		messages:   messages,
+	}
+}
+
+func (s *conversationServiceImpl) OpenDirect(ctx context.Context, actorID int64, req dto.OpenDirectConversationRequest) (*dto.DirectConversationResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if req.UserID == actorID {
+		return nil, entity.ErrCannotMessageSelf
+	}
+
+	// The privacy setting only governs new conversations
+	conversationID, err := s.convRepo.FindDirect(ctx, actorID, req.UserID)
+	if err == nil {
+		return &dto.DirectConversationResponse{ConversationID: conversationID}, nil
+	}
+	if !errors.Is(err, entity.ErrConversationNotFound) {
+		return nil, err
+	}
+
+	accepts, err := s.users.AcceptsDirectMessages(ctx, req.UserID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !accepts {
+		return nil, entity.ErrOnlyFriendsCanMessage
+	}
+
+	conversationID, created, err := s.convRepo.CreateDirect(ctx, actorID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DirectConversationResponse{ConversationID: conversationID, Created: created}, nil
+}
+
+func (s *conversationServiceImpl) AddMembers(ctx context.Context, conversationID int64, actorID int64, req dto.AddMembersRequest) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if _, err := s.groupRole(ctx, conversationID, actorID); err != nil {
+		return err
+	}
+
+	// Only existing users can be added
+	names, err := s.users.GetUsernames(ctx, req.UserIDs)
+	if err != nil {
+		return err
+	}
+	for _, userID := range req.UserIDs {
+		if _, ok := names[userID]; !ok {
+			return entity.ErrUserNotFound
+		}
+	}
+
+	added, err := s.memberRepo.AddMembers(ctx, conversationID, req.UserIDs)
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	s.announce(ctx, conversationID, entity.SystemEvent{
+		Kind:      entity.SystemEventMembersAdded,
+		ActorID:   actorID,
+		TargetIDs: added,
+	})
+	return nil
+}
+
+func (s *conversationServiceImpl) RemoveMember(ctx context.Context, conversationID int64, actorID int64, targetID int64) error {
+	actorRole, err := s.groupRole(ctx, conversationID, actorID)
+	if err != nil {
+		return err
+	}
+
+	event := entity.SystemEvent{
+		Kind:      entity.SystemEventMemberRemoved,
+		ActorID:   actorID,
+		TargetIDs: []int64{targetID},
+	}
+
+	if targetID == actorID {
+		if actorRole == entity.MemberRoleOwner {
+			return entity.ErrCannotRemoveOwner
+		}
+		event = entity.SystemEvent{Kind: entity.SystemEventMemberLeft, ActorID: actorID}
+	} else {
+		if !actorRole.CanModerate() {
+			return entity.ErrNotConversationAdmin
+		}
+
+		targetRole, err := s.memberRepo.GetRole(ctx, conversationID, targetID)
+		if err != nil {
+			return err
+		}
+		// Admins can only remove plain members
+		if targetRole == entity.MemberRoleOwner {
+			return entity.ErrCannotRemoveOwner
+		}
+		if targetRole == entity.MemberRoleAdmin && actorRole != entity.MemberRoleOwner {
+			return entity.ErrNotConversationOwner
+		}
+	}
+
+	removed, err := s.memberRepo.RemoveMember(ctx, conversationID, targetID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return nil
+	}
+
+	// The removed user is no longer a member but should still see why
+	s.announce(ctx, conversationID, event, targetID)
+	return nil
+}
+
+func (s *conversationServiceImpl) ChangeRole(ctx context.Context, conversationID int64, actorID int64, targetID int64, req dto.ChangeRoleRequest) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	actorRole, err := s.groupRole(ctx, conversationID, actorID)
+	if err != nil {
+		return err
+	}
+	if actorRole != entity.MemberRoleOwner {
+		return entity.ErrNotConversationOwner
+	}
+
+	targetRole, err := s.memberRepo.GetRole(ctx, conversationID, targetID)
+	if err != nil {
+		return err
+	}
+	if targetRole == entity.MemberRoleOwner {
+		return entity.ErrCannotRemoveOwner
+	}
+
+	role := entity.MemberRole(req.Role)
+	if role == targetRole {
+		return nil
+	}
+
+	if err := s.memberRepo.SetRole(ctx, conversationID, targetID, role); err != nil {
+		return err
+	}
+
+	s.announce(ctx, conversationID, entity.SystemEvent{
+		Kind:      entity.SystemEventMemberRoleChanged,
+		ActorID:   actorID,
+		TargetIDs: []int64{targetID},
+		Params:    map[string]string{entity.MetadataRole: string(role)},
+	})
+	return nil
+}
+
+func (s *conversationServiceImpl) Rename(ctx context.Context, conversationID int64, actorID int64, req dto.RenameConversationRequest) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	actorRole, err := s.groupRole(ctx, conversationID, actorID)
+	if err != nil {
+		return err
+	}
+	if !actorRole.CanModerate() {
+		return entity.ErrNotConversationAdmin
+	}
+
+	if err := s.convRepo.Rename(ctx, conversationID, req.Name); err != nil {
+		return err
+	}
+
+	s.announce(ctx, conversationID, entity.SystemEvent{
+		Kind:    entity.SystemEventConversationRenamed,
+		ActorID: actorID,
+		Params:  map[string]string{entity.MetadataName: req.Name},
+	})
+	return nil
+}
+
+// groupRole checks that the conversation is a group and returns the actor's role in it
+func (s *conversationServiceImpl) groupRole(ctx context.Context, conversationID int64, actorID int64) (entity.MemberRole, error) {
+	settings, err := s.convRepo.GetSettings(ctx, conversationID)
+	if err != nil {
+		return "", err
+	}
+	if settings.IsDirect {
+		return "", entity.ErrNotGroupConversation
+	}
+
+	return s.memberRepo.GetRole(ctx, conversationID, actorID)
+}
+
+// announce posts the system message for a completed operation; the operation
+// itself has succeeded, so a failure here is only logged
+func (s *conversationServiceImpl) announce(ctx context.Context, conversationID int64, event entity.SystemEvent, alsoNotify ...int64) {
+	if _, err := s.messages.PostSystemMessage(ctx, conversationID, event, alsoNotify...); err != nil {
+		log.Printf("Failed to announce %s in conversation %d: %v", event.Kind, conversationID, err)
+	}
+}
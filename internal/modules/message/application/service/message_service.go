@@ -2,27 +2,52 @@ package service
 
 import (
 	"context"
+	"io"
 
 	"github.com/gocql/gocql"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
 )
 
 // MessageService defines application use cases for messages
 type MessageService interface {
 	// Send and manage messages
 	SendMessage(ctx context.Context, senderID int64, req dto.SendMessageRequest) (*dto.MessageResponse, error)
+
+	// SendScheduledMessage sends a scheduled message under an ID chosen by the scheduler.
+	// It returns the stored message if that ID was already sent, so retries never duplicate.
+	SendScheduledMessage(ctx context.Context, senderID int64, messageID gocql.UUID, req dto.SendMessageRequest) (*dto.MessageResponse, error)
 	GetMessage(ctx context.Context, conversationID int64, messageID gocql.UUID) (*dto.MessageResponse, error)
-	GetConversationMessages(ctx context.Context, conversationID int64, userID int64, limit int, pagingState string) (*dto.MessageListResponse, error)
+	GetConversationMessages(ctx context.Context, conversationID int64, userID int64, limit int, pagingState string, localize bool) (*dto.MessageListResponse, error)
 	EditMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, req dto.EditMessageRequest) (*dto.MessageResponse, error)
 	DeleteMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) error
+	ForwardMessage(ctx context.Context, messageID gocql.UUID, userID int64, req dto.ForwardMessageRequest) (*dto.ForwardMessageResponse, error)
+
+	// UploadAttachment stores a blob a member can then send as the attachment_key of a
+	// media message in the same conversation
+	UploadAttachment(ctx context.Context, conversationID int64, userID int64, file io.Reader, contentType string, size int64) (*dto.AttachmentResponse, error)
+
+	// GetMentions lists the messages that mention the user, newest first
+	GetMentions(ctx context.Context, userID int64, limit int, pagingState string) (*dto.MentionListResponse, error)
 
 	// Reactions
 	AddReaction(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, emoji string) error
 	RemoveReaction(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, emoji string) error
 
+	// PostSystemMessage announces a conversation event to its members and to alsoNotify
+	PostSystemMessage(ctx context.Context, conversationID int64, event entity.SystemEvent, alsoNotify ...int64) (*dto.MessageResponse, error)
+
+	// Disappearing messages
+	SetDisappearingMessages(ctx context.Context, conversationID int64, userID int64, req dto.DisappearingMessagesRequest) (*dto.DisappearingMessagesResponse, error)
+
+	// Pinned messages
+	PinMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) (*dto.PinnedMessageResponse, error)
+	UnpinMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) error
+	GetPinnedMessages(ctx context.Context, conversationID int64, userID int64) ([]*dto.PinnedMessageResponse, error)
+
 	// Typing indicators
 	SetTyping(ctx context.Context, conversationID int64, userID int64, isTyping bool) error
-	GetTypingUsers(ctx context.Context, conversationID int64) ([]int64, error)
+	GetTypingUsers(ctx context.Context, conversationID int64, userID int64) ([]int64, error)
 
 	// Mark as read
 	MarkAsRead(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) error
@@ -0,0 +1,1062 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/linkpreview"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+	notificationDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/dto"
+	notificationService "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+const (
+	defaultPageSize  = 50
+	maxPageSize      = 100
+	pinPreviewLength = 100
+
+	// Link previews are fetched in the background, a few at a time
+	linkPreviewTimeout    = 15 * time.Second
+	maxConcurrentPreviews = 8
+)
+
+// MessageConfig holds the tunable behaviour of the message service
+type MessageConfig struct {
+	Reactions ReactionConfig
+
+	// A conversation can have at most MaxPins pinned messages; zero means no limit
+	MaxPins int
+
+	// In groups with more members only owners and admins may mention @all
+	MentionAllAdminOnlyAbove int
+}
+
+// ReactionConfig controls which reactions are accepted
+type ReactionConfig struct {
+	// A new reaction replaces the user's previous one when SinglePerUser is set
+	SinglePerUser bool
+
+	// Only these emojis are accepted when Allowed is not empty
+	Allowed []string
+}
+
+type messageServiceImpl struct {
+	messageRepo repository.MessageRepository
+	memberRepo  repository.MemberRepository
+	pinRepo     repository.PinRepository
+	convRepo    repository.ConversationRepository
+	attachments repository.AttachmentRepository
+	typing      TypingService
+	hub         *websocket.Hub
+	users       repository.UserDirectory
+	blobs       storage.Storage
+	previews    linkpreview.Service // nil when link previews are disabled
+	pushes      notificationService.NotificationService
+	activity    ActivityTracker
+	cfg         MessageConfig
+
+	previewSlots chan struct{}
+}
+
+// NewMessageService creates a new message application service
+func NewMessageService(
+	messageRepo repository.MessageRepository,
+	memberRepo repository.MemberRepository,
+	pinRepo repository.PinRepository,
+	convRepo repository.ConversationRepository,
+	attachments repository.AttachmentRepository,
+	typing TypingService,
+	hub *websocket.Hub,
+	users repository.UserDirectory,
+	blobs storage.Storage,
+	previews linkpreview.Service,
+	pushes notificationService.NotificationService,
+	activity ActivityTracker,
+	cfg MessageConfig,
+) MessageService {
+	return &messageServiceImpl{
+		messageRepo: messageRepo,
+		memberRepo:  memberRepo,
+		pinRepo:     pinRepo,
+		convRepo:    convRepo,
+		attachments: attachments,
+		typing:      typing,
+		hub:         hub,
+		users:       users,
+		blobs:       blobs,
+		previews:    previews,
+		pushes:      pushes,
+		activity:    activity,
+		cfg:         cfg,
+
+		previewSlots: make(chan struct{}, maxConcurrentPreviews),
+	}
+}
+
+func (s *messageServiceImpl) SendMessage(ctx context.Context, senderID int64, req dto.SendMessageRequest) (*dto.MessageResponse, error) {
+	message, err := s.newMessage(ctx, senderID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.deliver(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activity.MarkActive(senderID)
+	return resp, nil
+}
+
+func (s *messageServiceImpl) SendScheduledMessage(ctx context.Context, senderID int64, messageID gocql.UUID, req dto.SendMessageRequest) (*dto.MessageResponse, error) {
+	// A retry after the message was stored must not deliver it twice
+	existing, err := s.messageRepo.GetByID(ctx, req.ConversationID, messageID)
+	if err == nil {
+		return dto.NewMessageResponse(existing), nil
+	}
+	if !errors.Is(err, entity.ErrMessageNotFound) {
+		return nil, err
+	}
+
+	message, err := s.newMessage(ctx, senderID, req)
+	if err != nil {
+		return nil, err
+	}
+	message.MessageID = messageID
+
+	// The sender is not around when the scheduler sends, so this is not activity
+	return s.deliver(ctx, message)
+}
+
+// newMessage validates a send request and builds the message it describes
+func (s *messageServiceImpl) newMessage(ctx context.Context, senderID int64, req dto.SendMessageRequest) (*entity.Message, error) {
+	// 1. Validate request
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := entity.CheckClientMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+
+	// 2. Check membership
+	if err := s.ensureMember(ctx, req.ConversationID, senderID); err != nil {
+		return nil, err
+	}
+
+	// 3. Create domain entity
+	var message *entity.Message
+	if req.ParentMessageID != nil {
+		parentID, err := gocql.ParseUUID(*req.ParentMessageID)
+		if err != nil {
+			return nil, entity.ErrMessageNotFound
+		}
+		if _, err := s.messageRepo.GetByID(ctx, req.ConversationID, parentID); err != nil {
+			return nil, err
+		}
+		message = entity.NewReplyMessage(req.ConversationID, senderID, parentID, req.Content)
+		message.Type = entity.MessageType(req.Type)
+	} else {
+		message = entity.NewMessage(req.ConversationID, senderID, entity.MessageType(req.Type), req.Content)
+	}
+
+	for key, value := range req.Metadata {
+		message.AddMetadata(key, value)
+	}
+
+	if req.AttachmentKey != "" {
+		if err := checkAttachment(ctx, s.attachments, req.AttachmentKey, senderID, message.ConversationID, message.Type); err != nil {
+			return nil, err
+		}
+		message.AddMetadata(entity.MetadataAttachmentKey, req.AttachmentKey)
+	}
+
+	// 4. Check mentions and formatting
+	message.Mentions = dto.ToMentions(req.Mentions)
+	if err := s.checkMentions(ctx, message); err != nil {
+		return nil, err
+	}
+
+	message.Entities = dto.ToTextEntities(req.Entities)
+	if err := checkTextEntities(message.Type, message.Content, message.Entities); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *messageServiceImpl) ForwardMessage(ctx context.Context, messageID gocql.UUID, userID int64, req dto.ForwardMessageRequest) (*dto.ForwardMessageResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// 1. The caller must be able to read the original and the source must allow forwarding
+	if err := s.ensureMember(ctx, req.ConversationID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.convRepo.GetSettings(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.ForwardingDisabled {
+		return nil, entity.ErrForwardingDisabled
+	}
+
+	original, err := s.messageRepo.GetByID(ctx, req.ConversationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if original.IsDeleted {
+		return nil, entity.ErrMessageNotFound
+	}
+	if original.IsSystemMessage() {
+		return nil, entity.ErrInvalidMessageType
+	}
+
+	// 2. Check every target before sending anything so a bad target forwards nothing
+	targets := make([]int64, 0, len(req.TargetConversationIDs))
+	seen := make(map[int64]bool, len(req.TargetConversationIDs))
+	for _, targetID := range req.TargetConversationIDs {
+		if seen[targetID] {
+			continue
+		}
+		seen[targetID] = true
+
+		if err := s.ensureMember(ctx, targetID, userID); err != nil {
+			return nil, err
+		}
+		targets = append(targets, targetID)
+	}
+
+	// 3. Send a copy to each target
+	resp := &dto.ForwardMessageResponse{
+		Messages: make([]*dto.MessageResponse, 0, len(targets)),
+	}
+	for _, targetID := range targets {
+		forwarded, err := s.deliver(ctx, entity.NewForwardedMessage(original, targetID, userID))
+		if err != nil {
+			return resp, err
+		}
+		resp.Messages = append(resp.Messages, forwarded)
+	}
+
+	s.activity.MarkActive(userID)
+	return resp, nil
+}
+
+func (s *messageServiceImpl) UploadAttachment(ctx context.Context, conversationID int64, userID int64, file io.Reader, contentType string, size int64) (*dto.AttachmentResponse, error) {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	key := fmt.Sprintf("attachments/%d/%d/%s", conversationID, userID, hex.EncodeToString(suffix))
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if err := s.blobs.Put(ctx, key, file, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment := entity.NewAttachment(key, userID, conversationID, contentType, size)
+	if err := s.attachments.Create(ctx, attachment); err != nil {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete unrecorded attachment %s: %v", key, err)
+		}
+		return nil, err
+	}
+
+	return &dto.AttachmentResponse{
+		AttachmentKey: attachment.StorageKey,
+		ContentType:   attachment.ContentType,
+		SizeBytes:     attachment.SizeBytes,
+	}, nil
+}
+
+// checkAttachment makes sure a message only carries a blob its sender uploaded
+// to the same conversation, so it can never point at someone else's data
+func checkAttachment(ctx context.Context, attachments repository.AttachmentRepository, key string, senderID, conversationID int64, msgType entity.MessageType) error {
+	if !msgType.IsMedia() {
+		return entity.ErrAttachmentNotAllowed
+	}
+
+	attachment, err := attachments.GetByKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !attachment.UsableBy(senderID, conversationID) {
+		return entity.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (s *messageServiceImpl) GetMessage(ctx context.Context, conversationID int64, messageID gocql.UUID) (*dto.MessageResponse, error) {
+	message, err := s.messageRepo.GetByID(ctx, conversationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.IsDeleted {
+		return nil, entity.ErrMessageNotFound
+	}
+
+	resp := dto.NewMessageResponse(message)
+	s.attachReactionCounts(ctx, resp, message.MessageID)
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) GetConversationMessages(ctx context.Context, conversationID int64, userID int64, limit int, pagingState string, localize bool) (*dto.MessageListResponse, error) {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	var state []byte
+	if pagingState != "" {
+		decoded, err := base64.URLEncoding.DecodeString(pagingState)
+		if err != nil {
+			return nil, entity.ErrInvalidPageState
+		}
+		state = decoded
+	}
+
+	messages, nextState, err := s.messageRepo.GetByConversation(ctx, conversationID, limit, state)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		resp := dto.NewMessageResponse(message)
+		if !message.IsDeleted {
+			s.attachReactionCounts(ctx, resp, message.MessageID)
+		}
+		responses = append(responses, resp)
+	}
+
+	if localize {
+		s.localizeSystemMessages(ctx, userID, messages, responses)
+	}
+
+	resp := &dto.MessageListResponse{
+		Messages:       responses,
+		HasMore:        len(nextState) > 0,
+		ConversationID: conversationID,
+	}
+	if len(nextState) > 0 {
+		resp.NextPageState = base64.URLEncoding.EncodeToString(nextState)
+	}
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) GetMentions(ctx context.Context, userID int64, limit int, pagingState string) (*dto.MentionListResponse, error) {
+	if limit < 1 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	var state []byte
+	if pagingState != "" {
+		decoded, err := base64.URLEncoding.DecodeString(pagingState)
+		if err != nil {
+			return nil, entity.ErrInvalidPageState
+		}
+		state = decoded
+	}
+
+	messages, nextState, err := s.messageRepo.GetMentions(ctx, userID, limit, state)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MentionListResponse{
+		Mentions: make([]*dto.MentionResponse, 0, len(messages)),
+		HasMore:  len(nextState) > 0,
+	}
+	for _, message := range messages {
+		resp.Mentions = append(resp.Mentions, &dto.MentionResponse{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID.String(),
+			SenderID:       message.SenderID,
+			Content:        message.Content,
+			CreatedAt:      message.CreatedAt,
+		})
+	}
+	if len(nextState) > 0 {
+		resp.NextPageState = base64.URLEncoding.EncodeToString(nextState)
+	}
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) EditMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, req dto.EditMessageRequest) (*dto.MessageResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	message, err := s.messageRepo.GetByID(ctx, conversationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderID != userID || message.IsDeleted || !message.IsTextMessage() {
+		return nil, entity.ErrCannotEditMessage
+	}
+
+	entities := dto.ToTextEntities(req.Entities)
+	if err := checkTextEntities(message.Type, req.Content, entities); err != nil {
+		return nil, err
+	}
+
+	message.Edit(req.Content, entities)
+
+	if err := s.messageRepo.Update(ctx, message); err != nil {
+		return nil, err
+	}
+
+	resp := dto.NewMessageResponse(message)
+	s.notifyMembers(ctx, conversationID, "message.updated", resp)
+
+	s.requestLinkPreview(message)
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) DeleteMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) error {
+	message, err := s.messageRepo.GetByID(ctx, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if message.SenderID != userID || message.IsDeleted || message.IsSystemMessage() {
+		return entity.ErrCannotDeleteMessage
+	}
+
+	if err := s.messageRepo.Delete(ctx, conversationID, messageID); err != nil {
+		return err
+	}
+
+	s.notifyMembers(ctx, conversationID, "message.deleted", dto.MessageRefEvent{
+		ConversationID: conversationID,
+		MessageID:      messageID.String(),
+	})
+
+	// A recalled message leaves the mentions inbox too
+	if len(message.Mentions) > 0 {
+		memberIDs, err := s.memberRepo.GetMemberIDs(ctx, conversationID)
+		if err == nil {
+			err = s.messageRepo.RemoveMentions(ctx, messageID, message.MentionedUserIDs(memberIDs))
+		}
+		if err != nil {
+			log.Printf("Failed to remove mentions of recalled message %s: %v", messageID, err)
+		}
+	}
+
+	// A recalled message cannot stay pinned
+	removed, err := s.pinRepo.Remove(ctx, conversationID, messageID)
+	if err != nil {
+		log.Printf("Failed to unpin recalled message %s: %v", messageID, err)
+	} else if removed {
+		s.notifyMembers(ctx, conversationID, "message.unpinned", dto.PinEvent{
+			ConversationID: conversationID,
+			MessageID:      messageID.String(),
+			UserID:         userID,
+		})
+	}
+
+	return nil
+}
+
+func (s *messageServiceImpl) SetDisappearingMessages(ctx context.Context, conversationID int64, userID int64, req dto.DisappearingMessagesRequest) (*dto.DisappearingMessagesResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	settings, err := s.convRepo.GetSettings(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Either side of a direct chat may change it; in groups only owners and admins
+	if settings.IsDirect {
+		err = s.ensureMember(ctx, conversationID, userID)
+	} else {
+		err = s.ensureModerator(ctx, conversationID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	resp := &dto.DisappearingMessagesResponse{
+		ConversationID: conversationID,
+		TTLSeconds:     req.TTLSeconds,
+	}
+	if ttl == settings.MessageTTL {
+		return resp, nil
+	}
+
+	if err := s.convRepo.SetMessageTTL(ctx, conversationID, ttl); err != nil {
+		return nil, err
+	}
+
+	// The announcement is sent under the new setting, so it disappears too
+	event := entity.SystemEvent{
+		Kind:    entity.SystemEventDisappearingChanged,
+		ActorID: userID,
+		Params: map[string]string{
+			entity.MetadataTTLSeconds: strconv.FormatInt(req.TTLSeconds, 10),
+		},
+	}
+	if _, err := s.PostSystemMessage(ctx, conversationID, event); err != nil {
+		log.Printf("Failed to announce disappearing messages in conversation %d: %v", conversationID, err)
+	}
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) PostSystemMessage(ctx context.Context, conversationID int64, event entity.SystemEvent, alsoNotify ...int64) (*dto.MessageResponse, error) {
+	names, err := s.users.GetUsernames(ctx, event.UserIDs())
+	if err != nil {
+		log.Printf("Failed to get usernames for system message: %v", err)
+	}
+
+	message := entity.NewSystemMessage(conversationID, event, renderSystemEvent(event, fallbackLanguage, names))
+	resp, err := s.deliver(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	// e.g. a removed member, who no longer receives conversation events
+	for _, userID := range alsoNotify {
+		if err := s.hub.SendToUser(userID, websocket.WSMessage{Type: "message.new", Data: resp}); err != nil {
+			log.Printf("Failed to send system message to user %d: %v", userID, err)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) PinMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) (*dto.PinnedMessageResponse, error) {
+	if err := s.ensureModerator(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageRepo.GetByID(ctx, conversationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.IsDeleted {
+		return nil, entity.ErrMessageNotFound
+	}
+
+	pin := entity.NewPin(message, userID)
+	added, err := s.pinRepo.Add(ctx, pin, s.cfg.MaxPins)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.NewPinnedMessageResponse(pin, message, pinPreviewLength)
+	if added {
+		s.notifyMembers(ctx, conversationID, "message.pinned", resp)
+	}
+
+	return resp, nil
+}
+
+func (s *messageServiceImpl) UnpinMessage(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) error {
+	if err := s.ensureModerator(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	removed, err := s.pinRepo.Remove(ctx, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if removed {
+		s.notifyMembers(ctx, conversationID, "message.unpinned", dto.PinEvent{
+			ConversationID: conversationID,
+			MessageID:      messageID.String(),
+			UserID:         userID,
+		})
+	}
+	return nil
+}
+
+func (s *messageServiceImpl) GetPinnedMessages(ctx context.Context, conversationID int64, userID int64) ([]*dto.PinnedMessageResponse, error) {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	pins, err := s.pinRepo.List(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PinnedMessageResponse, 0, len(pins))
+	for _, pin := range pins {
+		message, err := s.messageRepo.GetByID(ctx, conversationID, pin.MessageID)
+		if errors.Is(err, entity.ErrMessageNotFound) {
+			// Pinned before pins expired with their message; free the slot
+			if _, err := s.pinRepo.Remove(ctx, conversationID, pin.MessageID); err != nil {
+				log.Printf("⚠️  Failed to remove pin of missing message %s: %v", pin.MessageID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to load pinned message %s: %v", pin.MessageID, err)
+			continue
+		}
+		if message.IsDeleted {
+			continue
+		}
+		responses = append(responses, dto.NewPinnedMessageResponse(pin, message, pinPreviewLength))
+	}
+
+	return responses, nil
+}
+
+func (s *messageServiceImpl) AddReaction(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, emoji string) error {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	if err := entity.ValidateEmoji(emoji, s.cfg.Reactions.Allowed); err != nil {
+		return err
+	}
+
+	message, err := s.messageRepo.GetByID(ctx, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+	if message.IsDeleted {
+		return entity.ErrMessageNotFound
+	}
+
+	// In single reaction mode the new emoji replaces any previous one
+	if s.cfg.Reactions.SinglePerUser {
+		replaced, added, err := s.messageRepo.ReplaceReaction(ctx, messageID, userID, emoji, message.RemainingTTL(time.Now()))
+		if err != nil {
+			return err
+		}
+		for _, old := range replaced {
+			s.notifyReaction(ctx, conversationID, messageID, userID, old, "message.reaction_removed")
+		}
+		if added {
+			s.notifyReaction(ctx, conversationID, messageID, userID, emoji, "message.reaction_added")
+		}
+		return nil
+	}
+
+	added, err := s.messageRepo.AddReaction(ctx, messageID, userID, emoji, message.RemainingTTL(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if added {
+		s.notifyReaction(ctx, conversationID, messageID, userID, emoji, "message.reaction_added")
+	}
+	return nil
+}
+
+func (s *messageServiceImpl) RemoveReaction(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, emoji string) error {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	removed, err := s.messageRepo.RemoveReaction(ctx, messageID, userID, emoji)
+	if err != nil {
+		return err
+	}
+
+	if removed {
+		s.notifyReaction(ctx, conversationID, messageID, userID, emoji, "message.reaction_removed")
+	}
+	return nil
+}
+
+func (s *messageServiceImpl) SetTyping(ctx context.Context, conversationID int64, userID int64, isTyping bool) error {
+	return s.typing.SetTyping(ctx, conversationID, userID, isTyping)
+}
+
+func (s *messageServiceImpl) GetTypingUsers(ctx context.Context, conversationID int64, userID int64) ([]int64, error) {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.typing.GetTypingUsers(ctx, conversationID)
+}
+
+func (s *messageServiceImpl) MarkAsRead(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64) error {
+	if err := s.ensureMember(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	message, err := s.messageRepo.GetByID(ctx, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.UpdateDeliveryStatus(ctx, messageID, userID, entity.MessageStatusRead); err != nil {
+		return fmt.Errorf("failed to update delivery status: %w", err)
+	}
+
+	if err := s.memberRepo.MarkRead(ctx, conversationID, userID, messageID.String()); err != nil {
+		return err
+	}
+
+	if message.SenderID != userID {
+		event := websocket.WSMessage{
+			Type: "message.read",
+			Data: dto.ReadReceiptEvent{
+				ConversationID: conversationID,
+				MessageID:      messageID.String(),
+				UserID:         userID,
+			},
+		}
+		if err := s.hub.SendToUser(message.SenderID, event); err != nil {
+			log.Printf("Failed to send read receipt to user %d: %v", message.SenderID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *messageServiceImpl) ensureMember(ctx context.Context, conversationID int64, userID int64) error {
+	isMember, err := s.memberRepo.IsMember(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return entity.ErrUserNotInConversation
+	}
+	return nil
+}
+
+// deliver persists a new message and fans it out: last message, unread counts,
+// typing and real-time delivery
+func (s *messageServiceImpl) deliver(ctx context.Context, message *entity.Message) (*dto.MessageResponse, error) {
+	settings, err := s.convRepo.GetSettings(ctx, message.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	message.ExpireAfter(settings.MessageTTL)
+
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.trackAttachment(ctx, message)
+
+	// System messages show in the conversation but never count as unread
+	if err := s.memberRepo.RecordMessage(ctx, message.ConversationID, message.SenderID, message.MessageID.String(), message.CreatedAt, !message.IsSystemMessage()); err != nil {
+		log.Printf("Failed to record message %s in conversation %d: %v", message.MessageID, message.ConversationID, err)
+	}
+
+	if err := s.typing.Stop(ctx, message.ConversationID, message.SenderID); err != nil {
+		log.Printf("Failed to stop typing for user %d: %v", message.SenderID, err)
+	}
+
+	resp := dto.NewMessageResponse(message)
+	s.notifyMembers(ctx, message.ConversationID, "message.new", resp)
+
+	var mentioned []int64
+	if len(message.Mentions) > 0 {
+		mentioned = s.deliverMentions(ctx, message)
+	}
+
+	if !message.IsSystemMessage() {
+		s.pushMessage(ctx, message, mentioned)
+	}
+
+	s.requestLinkPreview(message)
+
+	return resp, nil
+}
+
+// checkTextEntities validates the formatting of a message; only text messages are formatted
+func checkTextEntities(messageType entity.MessageType, content string, entities []entity.TextEntity) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	if messageType != entity.MessageTypeText {
+		return entity.ErrInvalidTextEntity
+	}
+	return entity.ValidateTextEntities(content, entities)
+}
+
+// requestLinkPreview generates a preview for the first link of a text message in
+// the background. Members get the message again in message.updated once it is attached.
+func (s *messageServiceImpl) requestLinkPreview(message *entity.Message) {
+	if s.previews == nil || message.LinkPreview != nil {
+		return
+	}
+
+	link, ok := message.PreviewURL()
+	if !ok {
+		return
+	}
+
+	snapshot := *message
+	go s.attachLinkPreview(&snapshot, link)
+}
+
+func (s *messageServiceImpl) attachLinkPreview(message *entity.Message, link string) {
+	s.previewSlots <- struct{}{}
+	defer func() { <-s.previewSlots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+	defer cancel()
+
+	preview, err := s.previews.Get(ctx, link)
+	if err != nil {
+		log.Printf("Failed to fetch link preview for message %s: %v", message.MessageID, err)
+		return
+	}
+	if preview == nil {
+		return
+	}
+
+	message.LinkPreview = &entity.LinkPreview{
+		URL:         preview.URL,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageURL,
+		SiteName:    preview.SiteName,
+	}
+
+	attached, err := s.messageRepo.SetLinkPreview(ctx, message, message.LinkPreview)
+	if err != nil {
+		log.Printf("Failed to attach link preview to message %s: %v", message.MessageID, err)
+		return
+	}
+	if !attached {
+		return
+	}
+
+	resp := dto.NewMessageResponse(message)
+	s.attachReactionCounts(ctx, resp, message.MessageID)
+	s.notifyMembers(ctx, message.ConversationID, "message.updated", resp)
+}
+
+// checkMentions verifies that mentioned users are members and that the sender may mention @all
+func (s *messageServiceImpl) checkMentions(ctx context.Context, message *entity.Message) error {
+	if len(message.Mentions) == 0 {
+		return nil
+	}
+
+	if err := entity.ValidateMentions(message.Content, message.Mentions); err != nil {
+		return err
+	}
+
+	memberIDs, err := s.memberRepo.GetMemberIDs(ctx, message.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	members := make(map[int64]bool, len(memberIDs))
+	for _, userID := range memberIDs {
+		members[userID] = true
+	}
+	for _, mention := range message.Mentions {
+		if mention.Type == entity.MentionTypeUser && !members[mention.UserID] {
+			return entity.ErrInvalidMention
+		}
+	}
+
+	if message.MentionsAll() && len(memberIDs) > s.cfg.MentionAllAdminOnlyAbove {
+		if err := s.ensureModerator(ctx, message.ConversationID, message.SenderID); err != nil {
+			return entity.ErrMentionAllNotAllowed
+		}
+	}
+
+	return nil
+}
+
+// deliverMentions files a new message in the mentions inbox of each mentioned
+// member, bumps their mention counts and alerts them. The alert is sent even
+// when the member muted the conversation.
+func (s *messageServiceImpl) deliverMentions(ctx context.Context, message *entity.Message) []int64 {
+	var memberIDs []int64
+	if message.MentionsAll() {
+		var err error
+		if memberIDs, err = s.memberRepo.GetMemberIDs(ctx, message.ConversationID); err != nil {
+			log.Printf("Failed to get members of conversation %d: %v", message.ConversationID, err)
+			return nil
+		}
+	}
+
+	userIDs := message.MentionedUserIDs(memberIDs)
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	if err := s.messageRepo.AddMentions(ctx, message, userIDs); err != nil {
+		log.Printf("Failed to add mentions of message %s: %v", message.MessageID, err)
+	}
+	if err := s.memberRepo.RecordMentions(ctx, message.ConversationID, userIDs); err != nil {
+		log.Printf("Failed to record mentions in conversation %d: %v", message.ConversationID, err)
+	}
+
+	event := websocket.WSMessage{
+		Type: "mention.new",
+		Data: dto.MentionEvent{
+			ConversationID: message.ConversationID,
+			MessageID:      message.MessageID.String(),
+			SenderID:       message.SenderID,
+			Preview:        message.Preview(pinPreviewLength),
+			All:            message.MentionsAll(),
+		},
+	}
+	if err := s.hub.BroadcastToUsers(userIDs, event); err != nil {
+		log.Printf("Failed to deliver mentions of message %s: %v", message.MessageID, err)
+	}
+
+	return userIDs
+}
+
+// pushMessage hands a new message to the push dispatcher, which notifies the
+// members that are not connected
+func (s *messageServiceImpl) pushMessage(ctx context.Context, message *entity.Message, mentioned []int64) {
+	names, err := s.users.GetUsernames(ctx, []int64{message.SenderID})
+	if err != nil {
+		log.Printf("Failed to get sender of message %s: %v", message.MessageID, err)
+	}
+
+	s.pushes.NotifyMessage(ctx, notificationDto.MessageNotification{
+		ConversationID:   message.ConversationID,
+		MessageID:        message.MessageID.String(),
+		SenderID:         message.SenderID,
+		SenderName:       names[message.SenderID],
+		Preview:          message.Preview(pinPreviewLength),
+		MentionedUserIDs: mentioned,
+	})
+}
+
+// trackAttachment keeps the blob behind a message alive as long as the
+// longest-lived message that shows it. Forwarded copies share the blob: a
+// disappearing copy pushes a scheduled deletion back to its own expiry but
+// never shortens it, and a permanent copy keeps the blob forever.
+func (s *messageServiceImpl) trackAttachment(ctx context.Context, message *entity.Message) {
+	key, ok := message.AttachmentKey()
+	if !ok {
+		return
+	}
+
+	var err error
+	switch {
+	case message.IsForwarded() && message.ExpiresAt == nil:
+		err = s.blobs.Retain(ctx, key)
+	case message.IsForwarded():
+		err = s.blobs.ExtendExpiry(ctx, key, *message.ExpiresAt)
+	case message.ExpiresAt != nil:
+		err = s.blobs.ExpireAt(ctx, key, *message.ExpiresAt)
+	}
+
+	if err != nil {
+		log.Printf("Failed to update expiry of attachment %s: %v", key, err)
+	}
+}
+
+// localizeSystemMessages re-renders system messages in the reader's language
+func (s *messageServiceImpl) localizeSystemMessages(ctx context.Context, userID int64, messages []*entity.Message, responses []*dto.MessageResponse) {
+	events := make(map[int]entity.SystemEvent)
+	var userIDs []int64
+	for i, message := range messages {
+		if event, ok := message.SystemEvent(); ok {
+			events[i] = event
+			userIDs = append(userIDs, event.UserIDs()...)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	language, err := s.users.GetLanguage(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get language of user %d: %v", userID, err)
+		return
+	}
+
+	names, err := s.users.GetUsernames(ctx, userIDs)
+	if err != nil {
+		log.Printf("Failed to get usernames for system messages: %v", err)
+	}
+
+	for i, event := range events {
+		if text := renderSystemEvent(event, language, names); text != "" {
+			responses[i].Content = text
+		}
+	}
+}
+
+// ensureModerator checks that the user is an owner or admin of the conversation
+func (s *messageServiceImpl) ensureModerator(ctx context.Context, conversationID int64, userID int64) error {
+	role, err := s.memberRepo.GetRole(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if !role.CanModerate() {
+		return entity.ErrNotConversationAdmin
+	}
+	return nil
+}
+
+// attachReactionCounts fills in reaction counts; a failed lookup leaves them empty
+func (s *messageServiceImpl) attachReactionCounts(ctx context.Context, resp *dto.MessageResponse, messageID gocql.UUID) {
+	counts, err := s.messageRepo.GetReactionCounts(ctx, messageID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load reaction counts for message %s: %v", messageID, err)
+		return
+	}
+	if len(counts) > 0 {
+		resp.ReactionCounts = counts
+	}
+}
+
+func (s *messageServiceImpl) notifyReaction(ctx context.Context, conversationID int64, messageID gocql.UUID, userID int64, emoji string, eventType string) {
+	s.notifyMembers(ctx, conversationID, eventType, dto.ReactionEvent{
+		ConversationID: conversationID,
+		MessageID:      messageID.String(),
+		UserID:         userID,
+		Emoji:          emoji,
+	})
+}
+
+// notifyMembers delivers an event to every member of the conversation through the Hub
+func (s *messageServiceImpl) notifyMembers(ctx context.Context, conversationID int64, eventType string, data interface{}) {
+	memberIDs, err := s.memberRepo.GetMemberIDs(ctx, conversationID)
+	if err != nil {
+		log.Printf("Failed to get members of conversation %d: %v", conversationID, err)
+		return
+	}
+
+	event := websocket.WSMessage{Type: eventType, Data: data}
+	if err := s.hub.BroadcastToUsers(memberIDs, event); err != nil {
+		log.Printf("Failed to deliver %s to conversation %d: %v", eventType, conversationID, err)
+	}
+}
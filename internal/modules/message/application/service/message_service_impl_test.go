@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+func TestTrackAttachmentKeepsBlobForLongestCopy(t *testing.T) {
+	now := time.Now()
+	soon, later := now.Add(time.Hour), now.Add(2*time.Hour)
+
+	tests := []struct {
+		name     string
+		original *time.Time // Expiry of the uploading message, nil for permanent
+		copy     *time.Time // Expiry of the forwarded copy, nil for permanent
+		want     *time.Time // When the blob is deleted, nil for never
+	}{
+		{name: "copy expires later", original: &soon, copy: &later, want: &later},
+		{name: "copy expires sooner", original: &later, copy: &soon, want: &later},
+		{name: "permanent copy", original: &soon, copy: nil, want: nil},
+		{name: "permanent original", original: nil, copy: &soon, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			expiry := storage.NewMemoryExpiryIndex()
+			blobs, err := storage.NewLocalStorage(storage.Config{Dir: t.TempDir(), BaseURL: "/uploads"}, expiry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			const key = "attachments/7/1/abc"
+			if err := blobs.Put(ctx, key, strings.NewReader("blob"), "image/jpeg"); err != nil {
+				t.Fatal(err)
+			}
+			svc := &messageServiceImpl{blobs: blobs}
+
+			original := entity.NewMessage(7, 1, entity.MessageTypeImage, "")
+			original.Metadata[entity.MetadataAttachmentKey] = key
+			original.ExpiresAt = tt.original
+			svc.trackAttachment(ctx, original)
+
+			copied := entity.NewForwardedMessage(original, 8, 2)
+			copied.ExpiresAt = tt.copy
+			svc.trackAttachment(ctx, copied)
+
+			// Nothing is due just before the expected deletion
+			deadline := now.Add(24 * time.Hour)
+			if tt.want != nil {
+				deadline = *tt.want
+			}
+			if due, _ := expiry.Due(ctx, deadline.Add(-time.Second)); len(due) != 0 {
+				t.Fatalf("blob due before %v", deadline)
+			}
+
+			due, _ := expiry.Due(ctx, deadline)
+			if deleted := len(due) == 1; deleted != (tt.want != nil) {
+				t.Fatalf("due at %v: %q, want deleted %v", deadline, due, tt.want != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+)
+
+// ScheduledMessageService manages messages to be sent later and the worker that sends them
+type ScheduledMessageService interface {
+	Schedule(ctx context.Context, senderID int64, req dto.SendMessageRequest) (*dto.ScheduledMessageResponse, error)
+	List(ctx context.Context, senderID int64) ([]*dto.ScheduledMessageResponse, error)
+	Update(ctx context.Context, id int64, senderID int64, req dto.UpdateScheduledMessageRequest) (*dto.ScheduledMessageResponse, error)
+	Cancel(ctx context.Context, id int64, senderID int64) error
+
+	// Run sends due messages through the normal send path until ctx is cancelled
+	Run(ctx context.Context)
+}
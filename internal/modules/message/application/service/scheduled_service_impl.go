@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+// Time allowed for sending one scheduled message
+const scheduledSendTimeout = 10 * time.Second
+
+// ScheduledConfig tunes the scheduled message worker
+type ScheduledConfig struct {
+	// How often the worker looks for due messages
+	PollInterval time.Duration
+
+	// Maximum number of messages claimed per poll
+	BatchSize int
+
+	// Messages claimed longer ago than ClaimTimeout are settled instead of retried:
+	// marked sent if their message exists and failed otherwise
+	ClaimTimeout time.Duration
+}
+
+type scheduledServiceImpl struct {
+	repo        repository.ScheduledMessageRepository
+	memberRepo  repository.MemberRepository
+	attachments repository.AttachmentRepository
+	messages    MessageService
+	cfg         ScheduledConfig
+}
+
+// NewScheduledMessageService creates the scheduled message service
+func NewScheduledMessageService(
+	repo repository.ScheduledMessageRepository,
+	memberRepo repository.MemberRepository,
+	attachments repository.AttachmentRepository,
+	messages MessageService,
+	cfg ScheduledConfig,
+) ScheduledMessageService {
+	return &scheduledServiceImpl{
+		repo:        repo,
+		memberRepo:  memberRepo,
+		attachments: attachments,
+		messages:    messages,
+		cfg:         cfg,
+	}
+}
+
+func (s *scheduledServiceImpl) Schedule(ctx context.Context, senderID int64, req dto.SendMessageRequest) (*dto.ScheduledMessageResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if req.SendAt == nil || !req.SendAt.After(time.Now()) {
+		return nil, entity.ErrInvalidSendAt
+	}
+	if err := entity.CheckClientMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureMember(ctx, req.ConversationID, senderID); err != nil {
+		return nil, err
+	}
+
+	message := entity.NewScheduledMessage(req.ConversationID, senderID, entity.MessageType(req.Type), req.Content, *req.SendAt)
+	if req.ParentMessageID != nil {
+		parentID, err := gocql.ParseUUID(*req.ParentMessageID)
+		if err != nil {
+			return nil, entity.ErrMessageNotFound
+		}
+		message.ParentMessageID = &parentID
+	}
+	for key, value := range req.Metadata {
+		message.Metadata[key] = value
+	}
+	if req.AttachmentKey != "" {
+		if err := checkAttachment(ctx, s.attachments, req.AttachmentKey, senderID, message.ConversationID, message.Type); err != nil {
+			return nil, err
+		}
+		message.Metadata[entity.MetadataAttachmentKey] = req.AttachmentKey
+	}
+
+	// Membership of mentioned users is checked when the message is sent
+	message.Mentions = dto.ToMentions(req.Mentions)
+	if err := entity.ValidateMentions(message.Content, message.Mentions); err != nil {
+		return nil, err
+	}
+
+	message.Entities = dto.ToTextEntities(req.Entities)
+	if err := checkTextEntities(message.Type, message.Content, message.Entities); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return dto.NewScheduledMessageResponse(message), nil
+}
+
+func (s *scheduledServiceImpl) List(ctx context.Context, senderID int64) ([]*dto.ScheduledMessageResponse, error) {
+	messages, err := s.repo.ListBySender(ctx, senderID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ScheduledMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		responses = append(responses, dto.NewScheduledMessageResponse(message))
+	}
+
+	return responses, nil
+}
+
+func (s *scheduledServiceImpl) Update(ctx context.Context, id int64, senderID int64, req dto.UpdateScheduledMessageRequest) (*dto.ScheduledMessageResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	message, err := s.getOwned(ctx, id, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if !message.IsEditable() {
+		return nil, entity.ErrScheduledNotEditable
+	}
+
+	content, sendAt := message.Content, message.SendAt
+	if req.Content != nil {
+		content = *req.Content
+	}
+	if req.SendAt != nil {
+		sendAt = *req.SendAt
+	}
+	if !sendAt.After(time.Now()) {
+		return nil, entity.ErrInvalidSendAt
+	}
+
+	message.Reschedule(content, sendAt)
+
+	// Offsets refer to the content, so new content brings new mentions and entities
+	if req.Content != nil {
+		message.Mentions = dto.ToMentions(req.Mentions)
+		if err := entity.ValidateMentions(message.Content, message.Mentions); err != nil {
+			return nil, err
+		}
+
+		message.Entities = dto.ToTextEntities(req.Entities)
+		if err := checkTextEntities(message.Type, message.Content, message.Entities); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return dto.NewScheduledMessageResponse(message), nil
+}
+
+func (s *scheduledServiceImpl) Cancel(ctx context.Context, id int64, senderID int64) error {
+	message, err := s.getOwned(ctx, id, senderID)
+	if err != nil {
+		return err
+	}
+	if !message.IsEditable() {
+		return entity.ErrScheduledNotEditable
+	}
+
+	message.Cancel()
+	return s.repo.Update(ctx, message)
+}
+
+func (s *scheduledServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.dispatchDue(ctx, now)
+		}
+	}
+}
+
+// dispatchDue claims due messages and sends them. Claiming is atomic in the
+// repository, so several replicas can run the worker at once.
+func (s *scheduledServiceImpl) dispatchDue(ctx context.Context, now time.Time) {
+	s.settleStale(ctx, now)
+
+	due, err := s.repo.ClaimDue(ctx, now, s.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Scheduler: failed to claim due messages: %v", err)
+		return
+	}
+
+	for _, message := range due {
+		s.send(ctx, message)
+	}
+}
+
+// settleStale finishes messages whose replica stopped mid-send. The message ID
+// is assigned before sending, so whether the send went through can be checked
+// instead of guessed.
+func (s *scheduledServiceImpl) settleStale(ctx context.Context, now time.Time) {
+	stale, err := s.repo.ListStale(ctx, now.Add(-s.cfg.ClaimTimeout), s.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Scheduler: failed to list stale messages: %v", err)
+		return
+	}
+
+	for _, message := range stale {
+		s.settle(ctx, message, "delivery was interrupted")
+	}
+}
+
+func (s *scheduledServiceImpl) send(ctx context.Context, message *entity.ScheduledMessage) {
+	ctx, cancel := context.WithTimeout(ctx, scheduledSendTimeout)
+	defer cancel()
+
+	messageID := gocql.TimeUUID()
+	if err := s.repo.AssignMessageID(ctx, message.ID, messageID); err != nil {
+		log.Printf("Scheduler: failed to assign an ID to scheduled message %d: %v", message.ID, err)
+		return
+	}
+	message.MessageID = &messageID
+
+	req := dto.SendMessageRequest{
+		ConversationID: message.ConversationID,
+		Type:           string(message.Type),
+		Content:        message.Content,
+		Metadata:       make(map[string]string, len(message.Metadata)),
+		Mentions:       dto.NewMentionEntities(message.Mentions),
+		Entities:       dto.NewTextEntities(message.Entities),
+	}
+	for key, value := range message.Metadata {
+		if key == entity.MetadataAttachmentKey {
+			req.AttachmentKey = value
+			continue
+		}
+		req.Metadata[key] = value
+	}
+	if message.ParentMessageID != nil {
+		parentID := message.ParentMessageID.String()
+		req.ParentMessageID = &parentID
+	}
+
+	if _, err := s.messages.SendScheduledMessage(ctx, message.SenderID, messageID, req); err != nil {
+		log.Printf("Scheduler: failed to send scheduled message %d: %v", message.ID, err)
+		s.settle(ctx, message, err.Error())
+		return
+	}
+
+	if err := s.repo.MarkSent(ctx, message.ID, messageID); err != nil {
+		// The row stays SENDING; settleStale finds the message and marks it sent
+		log.Printf("Scheduler: %v", err)
+	}
+}
+
+// settle marks a claimed message sent if its message exists and failed otherwise
+func (s *scheduledServiceImpl) settle(ctx context.Context, message *entity.ScheduledMessage, reason string) {
+	if message.MessageID != nil {
+		_, err := s.messages.GetMessage(ctx, message.ConversationID, *message.MessageID)
+		if err == nil {
+			if err := s.repo.MarkSent(ctx, message.ID, *message.MessageID); err != nil {
+				log.Printf("Scheduler: %v", err)
+			}
+			return
+		}
+		if !errors.Is(err, entity.ErrMessageNotFound) {
+			// Unknown outcome; leave it claimed and check again on the next poll
+			log.Printf("Scheduler: failed to check scheduled message %d: %v", message.ID, err)
+			return
+		}
+	}
+
+	if err := s.repo.MarkFailed(ctx, message.ID, reason); err != nil {
+		log.Printf("Scheduler: %v", err)
+	}
+}
+
+// getOwned loads a scheduled message, hiding other users' messages
+func (s *scheduledServiceImpl) getOwned(ctx context.Context, id int64, senderID int64) (*entity.ScheduledMessage, error) {
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != senderID {
+		return nil, entity.ErrScheduledNotFound
+	}
+	return message, nil
+}
+
+func (s *scheduledServiceImpl) ensureMember(ctx context.Context, conversationID int64, userID int64) error {
+	isMember, err := s.memberRepo.IsMember(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return entity.ErrUserNotInConversation
+	}
+	return nil
+}
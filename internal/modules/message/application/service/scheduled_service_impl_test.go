@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// fakeScheduledRepo keeps scheduled messages in memory and can fail MarkSent
+type fakeScheduledRepo struct {
+	repository.ScheduledMessageRepository
+	messages     map[int64]*entity.ScheduledMessage
+	failMarkSent bool
+}
+
+func (f *fakeScheduledRepo) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*entity.ScheduledMessage, error) {
+	var due []*entity.ScheduledMessage
+	for _, message := range f.messages {
+		if message.Status == entity.ScheduledStatusPending && !message.SendAt.After(now) {
+			message.Status = entity.ScheduledStatusSending
+			copied := *message
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeScheduledRepo) AssignMessageID(ctx context.Context, id int64, messageID gocql.UUID) error {
+	f.messages[id].MessageID = &messageID
+	return nil
+}
+
+func (f *fakeScheduledRepo) MarkSent(ctx context.Context, id int64, messageID gocql.UUID) error {
+	if f.failMarkSent {
+		return errors.New("connection reset")
+	}
+	if message := f.messages[id]; message.Status == entity.ScheduledStatusSending {
+		message.Status = entity.ScheduledStatusSent
+		message.MessageID = &messageID
+	}
+	return nil
+}
+
+func (f *fakeScheduledRepo) MarkFailed(ctx context.Context, id int64, reason string) error {
+	if message := f.messages[id]; message.Status == entity.ScheduledStatusSending {
+		message.Status = entity.ScheduledStatusFailed
+		message.MessageID = nil
+		message.FailureReason = reason
+	}
+	return nil
+}
+
+// ListStale treats every claimed message as stale
+func (f *fakeScheduledRepo) ListStale(ctx context.Context, claimedBefore time.Time, limit int) ([]*entity.ScheduledMessage, error) {
+	var stale []*entity.ScheduledMessage
+	for _, message := range f.messages {
+		if message.Status == entity.ScheduledStatusSending {
+			copied := *message
+			stale = append(stale, &copied)
+		}
+	}
+	return stale, nil
+}
+
+// fakeMessages records sent messages by ID
+type fakeMessages struct {
+	MessageService
+	sent    map[gocql.UUID]int
+	sendErr error
+}
+
+func (f *fakeMessages) SendScheduledMessage(ctx context.Context, senderID int64, messageID gocql.UUID, req dto.SendMessageRequest) (*dto.MessageResponse, error) {
+	if f.sendErr != nil {
+		return nil, f.sendErr
+	}
+	f.sent[messageID]++
+	return &dto.MessageResponse{MessageID: messageID.String()}, nil
+}
+
+func (f *fakeMessages) GetMessage(ctx context.Context, conversationID int64, messageID gocql.UUID) (*dto.MessageResponse, error) {
+	if f.sent[messageID] == 0 {
+		return nil, entity.ErrMessageNotFound
+	}
+	return &dto.MessageResponse{MessageID: messageID.String()}, nil
+}
+
+func TestScheduledSendSettlesWithoutDuplicates(t *testing.T) {
+	tests := []struct {
+		name         string
+		failMarkSent bool
+		sendErr      error
+		wantStatus   entity.ScheduledStatus
+		wantSent     int
+	}{
+		{name: "sent", wantStatus: entity.ScheduledStatusSent, wantSent: 1},
+		{name: "sent but not marked", failMarkSent: true, wantStatus: entity.ScheduledStatusSent, wantSent: 1},
+		{name: "send failed", sendErr: entity.ErrUserNotInConversation, wantStatus: entity.ScheduledStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+			repo := &fakeScheduledRepo{
+				messages: map[int64]*entity.ScheduledMessage{
+					1: {ID: 1, ConversationID: 7, SenderID: 1, Type: entity.MessageTypeText, Content: "hi", SendAt: now, Status: entity.ScheduledStatusPending},
+				},
+				failMarkSent: tt.failMarkSent,
+			}
+			messages := &fakeMessages{sent: make(map[gocql.UUID]int), sendErr: tt.sendErr}
+			scheduler := NewScheduledMessageService(repo, &fakeMembers{}, nil, messages, ScheduledConfig{BatchSize: 10}).(*scheduledServiceImpl)
+
+			scheduler.dispatchDue(ctx, now)
+			repo.failMarkSent = false
+			scheduler.dispatchDue(ctx, now.Add(time.Hour))
+
+			message := repo.messages[1]
+			if message.Status != tt.wantStatus {
+				t.Fatalf("got status %s, want %s", message.Status, tt.wantStatus)
+			}
+			sent := 0
+			for _, count := range messages.sent {
+				sent += count
+			}
+			if sent != tt.wantSent {
+				t.Fatalf("sent %d messages, want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestSettleStaleWithoutSentMessageFails(t *testing.T) {
+	ctx := context.Background()
+	assigned := gocql.TimeUUID()
+	repo := &fakeScheduledRepo{
+		messages: map[int64]*entity.ScheduledMessage{
+			1: {ID: 1, ConversationID: 7, Status: entity.ScheduledStatusSending},
+			2: {ID: 2, ConversationID: 7, Status: entity.ScheduledStatusSending, MessageID: &assigned},
+		},
+	}
+	messages := &fakeMessages{sent: make(map[gocql.UUID]int)}
+	scheduler := NewScheduledMessageService(repo, &fakeMembers{}, nil, messages, ScheduledConfig{BatchSize: 10}).(*scheduledServiceImpl)
+
+	scheduler.settleStale(ctx, time.Now())
+
+	for id, message := range repo.messages {
+		if message.Status != entity.ScheduledStatusFailed {
+			t.Fatalf("message %d: got status %s, want FAILED", id, message.Status)
+		}
+		if message.MessageID != nil {
+			t.Fatalf("message %d kept its assigned ID", id)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+// Language system messages are stored in and fall back to
+const fallbackLanguage = "en"
+
+// systemPhrases holds the wording of system messages per language
+type systemPhrases struct {
+	templates    map[string]string // By event kind; placeholders are {actor}, {targets}, {name}, {role}, {ttl}
+	roles        map[entity.MemberRole]string
+	day, days    string
+	someone, and string
+}
+
+var systemLanguages = map[string]systemPhrases{
+	"en": {
+		templates: map[string]string{
+			string(entity.SystemEventMembersAdded):        "{actor} added {targets}",
+			string(entity.SystemEventMemberLeft):          "{actor} left the conversation",
+			string(entity.SystemEventMemberRemoved):       "{actor} removed {targets}",
+			string(entity.SystemEventMemberRoleChanged):   "{actor} made {targets} {role}",
+			string(entity.SystemEventConversationRenamed): "{actor} renamed the conversation to \"{name}\"",
+			string(entity.SystemEventDisappearingChanged): "{actor} set disappearing messages to {ttl}",
+			disappearingOffKey:                            "{actor} turned off disappearing messages",
+		},
+		roles: map[entity.MemberRole]string{
+			entity.MemberRoleOwner:  "the owner",
+			entity.MemberRoleAdmin:  "an admin",
+			entity.MemberRoleMember: "a member",
+		},
+		day:     "1 day",
+		days:    "%d days",
+		someone: "Someone",
+		and:     " and ",
+	},
+	"vi": {
+		templates: map[string]string{
+			string(entity.SystemEventMembersAdded):        "{actor} đã thêm {targets}",
+			string(entity.SystemEventMemberLeft):          "{actor} đã rời khỏi cuộc trò chuyện",
+			string(entity.SystemEventMemberRemoved):       "{actor} đã xóa {targets}",
+			string(entity.SystemEventMemberRoleChanged):   "{actor} đã đặt {targets} làm {role}",
+			string(entity.SystemEventConversationRenamed): "{actor} đã đổi tên cuộc trò chuyện thành \"{name}\"",
+			string(entity.SystemEventDisappearingChanged): "{actor} đã đặt tin nhắn tự xóa sau {ttl}",
+			disappearingOffKey:                            "{actor} đã tắt tin nhắn tự xóa",
+		},
+		roles: map[entity.MemberRole]string{
+			entity.MemberRoleOwner:  "trưởng nhóm",
+			entity.MemberRoleAdmin:  "phó nhóm",
+			entity.MemberRoleMember: "thành viên",
+		},
+		day:     "1 ngày",
+		days:    "%d ngày",
+		someone: "Ai đó",
+		and:     " và ",
+	},
+}
+
+// Turning disappearing messages off has its own wording
+const disappearingOffKey = "disappearing_messages_off"
+
+// renderSystemEvent renders an event as text in the given language, using
+// usernames for the actor and targets
+func renderSystemEvent(event entity.SystemEvent, language string, names map[int64]string) string {
+	phrases, ok := systemLanguages[language]
+	if !ok {
+		phrases = systemLanguages[fallbackLanguage]
+	}
+
+	key := string(event.Kind)
+	if event.Kind == entity.SystemEventDisappearingChanged && event.Params[entity.MetadataTTLSeconds] == "0" {
+		key = disappearingOffKey
+	}
+
+	template, ok := phrases.templates[key]
+	if !ok {
+		return ""
+	}
+
+	name := func(id int64) string {
+		if username, ok := names[id]; ok {
+			return username
+		}
+		return phrases.someone
+	}
+
+	targets := make([]string, 0, len(event.TargetIDs))
+	for _, id := range event.TargetIDs {
+		targets = append(targets, name(id))
+	}
+
+	replacer := strings.NewReplacer(
+		"{actor}", name(event.ActorID),
+		"{targets}", joinNames(targets, phrases.and),
+		"{name}", event.Params[entity.MetadataName],
+		"{role}", phrases.roles[entity.MemberRole(event.Params[entity.MetadataRole])],
+		"{ttl}", phrases.describeTTL(event.Params[entity.MetadataTTLSeconds]),
+	)
+
+	return replacer.Replace(template)
+}
+
+// joinNames lists names as "a, b and c"
+func joinNames(names []string, and string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + and + names[len(names)-1]
+}
+
+func (p systemPhrases) describeTTL(seconds string) string {
+	value, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil || value <= 0 {
+		return ""
+	}
+
+	ttl := time.Duration(value) * time.Second
+	days := int(ttl / (24 * time.Hour))
+	switch {
+	case ttl%(24*time.Hour) != 0:
+		return ttl.String()
+	case days == 1:
+		return p.day
+	default:
+		return fmt.Sprintf(p.days, days)
+	}
+}
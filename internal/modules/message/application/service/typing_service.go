@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+)
+
+// TypingService manages ephemeral typing state and its real-time events.
+// It observes WebSocket connections to stop typing when a client disconnects.
+type TypingService interface {
+	websocket.ConnectionObserver
+
+	SetTyping(ctx context.Context, conversationID int64, userID int64, isTyping bool) error
+	GetTypingUsers(ctx context.Context, conversationID int64) ([]int64, error)
+
+	// Stop clears typing state without a membership check, e.g. after the user sent a message
+	Stop(ctx context.Context, conversationID int64, userID int64) error
+
+	// HandleClientMessage handles an inbound "typing" WebSocket message
+	HandleClientMessage(userID int64, data interface{})
+
+	// Run expires stale typing state until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+// ActivityTracker is told when a user does something in a conversation, so their
+// presence shows them online
+type ActivityTracker interface {
+	MarkActive(userID int64)
+}
+
+// ConnectionChecker tells whether a user is still connected after one of their connections closed
+type ConnectionChecker interface {
+	ConnectedElsewhere(ctx context.Context, userID int64, connID string) (bool, error)
+}
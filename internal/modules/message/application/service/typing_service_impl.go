@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+const (
+	// How often expired typing state is swept
+	typingSweepInterval = time.Second
+
+	// Number of typing users listed in an aggregated event
+	typingSummarySize = 3
+
+	// Time allowed for typing bookkeeping triggered outside a request
+	typingTimeout = 5 * time.Second
+)
+
+// TypingConfig tunes typing indicators
+type TypingConfig struct {
+	// Typing state expires if not refreshed within TTL
+	TTL time.Duration
+
+	// Refreshes from the same user within Throttle are ignored
+	Throttle time.Duration
+
+	// Conversations with more members get aggregated typing.updated events
+	AggregateThreshold int
+}
+
+type typingServiceImpl struct {
+	store       repository.TypingStore
+	members     repository.MemberRepository
+	hub         *websocket.Hub
+	activity    ActivityTracker
+	connections ConnectionChecker
+	cfg         TypingConfig
+
+	// Last accepted refresh per conversation/user, for throttling
+	lastRefresh map[typingKey]time.Time
+	mu          sync.Mutex
+}
+
+type typingKey struct {
+	conversationID int64
+	userID         int64
+}
+
+// NewTypingService creates a new typing application service
+func NewTypingService(store repository.TypingStore, members repository.MemberRepository, hub *websocket.Hub, activity ActivityTracker, connections ConnectionChecker, cfg TypingConfig) TypingService {
+	return &typingServiceImpl{
+		store:       store,
+		members:     members,
+		hub:         hub,
+		activity:    activity,
+		connections: connections,
+		cfg:         cfg,
+		lastRefresh: make(map[typingKey]time.Time),
+	}
+}
+
+func (s *typingServiceImpl) SetTyping(ctx context.Context, conversationID int64, userID int64, isTyping bool) error {
+	if !isTyping {
+		s.forget(conversationID, userID)
+		return s.Stop(ctx, conversationID, userID)
+	}
+
+	if s.throttled(conversationID, userID) {
+		return nil
+	}
+
+	isMember, err := s.members.IsMember(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return entity.ErrUserNotInConversation
+	}
+
+	started, err := s.store.Start(ctx, conversationID, userID, time.Now().Add(s.cfg.TTL))
+	if err != nil {
+		return err
+	}
+	s.activity.MarkActive(userID)
+
+	if started {
+		s.publish(ctx, conversationID, userID, "typing.started")
+	}
+
+	return nil
+}
+
+func (s *typingServiceImpl) GetTypingUsers(ctx context.Context, conversationID int64) ([]int64, error) {
+	return s.store.List(ctx, conversationID)
+}
+
+func (s *typingServiceImpl) Stop(ctx context.Context, conversationID int64, userID int64) error {
+	s.forget(conversationID, userID)
+
+	stopped, err := s.store.Stop(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	if stopped {
+		s.publish(ctx, conversationID, userID, "typing.stopped")
+	}
+
+	return nil
+}
+
+func (s *typingServiceImpl) HandleClientMessage(userID int64, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	var req struct {
+		ConversationID int64 `json:"conversation_id"`
+		IsTyping       bool  `json:"is_typing"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil || req.ConversationID == 0 {
+		log.Printf("Invalid typing message from user %d", userID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), typingTimeout)
+	defer cancel()
+
+	if err := s.SetTyping(ctx, req.ConversationID, userID, req.IsTyping); err != nil {
+		log.Printf("Typing: failed to update user %d in conversation %d: %v", userID, req.ConversationID, err)
+	}
+}
+
+func (s *typingServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(typingSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweep(ctx, now)
+		}
+	}
+}
+
+func (s *typingServiceImpl) sweep(ctx context.Context, now time.Time) {
+	expired, err := s.store.Expire(ctx, now)
+	if err != nil {
+		log.Printf("Typing: failed to expire typing state: %v", err)
+		return
+	}
+
+	for conversationID, userIDs := range expired {
+		for _, userID := range userIDs {
+			s.forget(conversationID, userID)
+			s.publish(ctx, conversationID, userID, "typing.stopped")
+		}
+	}
+
+	// Drop throttle entries that can no longer matter
+	s.mu.Lock()
+	for key, at := range s.lastRefresh {
+		if now.Sub(at) > s.cfg.TTL {
+			delete(s.lastRefresh, key)
+		}
+	}
+	s.mu.Unlock()
+}
+
+func (s *typingServiceImpl) ClientDisconnected(userID int64, connID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), typingTimeout)
+	defer cancel()
+
+	// Another device may still be typing; its state expires on its own if not
+	connected, err := s.connections.ConnectedElsewhere(ctx, userID, connID)
+	if err != nil {
+		log.Printf("Typing: failed to check connections of user %d: %v", userID, err)
+	}
+	if connected {
+		return
+	}
+
+	stopped, err := s.store.StopAll(ctx, userID)
+	if err != nil {
+		log.Printf("Typing: failed to stop typing for user %d: %v", userID, err)
+		return
+	}
+
+	for _, conversationID := range stopped {
+		s.forget(conversationID, userID)
+		s.publish(ctx, conversationID, userID, "typing.stopped")
+	}
+}
+
+func (s *typingServiceImpl) ClientConnected(userID int64, connID string)                    {}
+func (s *typingServiceImpl) ClientHeartbeat(userID int64, connID string)                    {}
+func (s *typingServiceImpl) ClientStatusChanged(userID int64, connID string, status string) {}
+
+// throttled reports whether a refresh arrived too soon after the previous accepted one
+func (s *typingServiceImpl) throttled(conversationID int64, userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := typingKey{conversationID: conversationID, userID: userID}
+	now := time.Now()
+	if last, ok := s.lastRefresh[key]; ok && now.Sub(last) < s.cfg.Throttle {
+		return true
+	}
+	s.lastRefresh[key] = now
+
+	return false
+}
+
+func (s *typingServiceImpl) forget(conversationID int64, userID int64) {
+	s.mu.Lock()
+	delete(s.lastRefresh, typingKey{conversationID: conversationID, userID: userID})
+	s.mu.Unlock()
+}
+
+// publish notifies the other members. Large groups get a single aggregated
+// typing.updated event instead of one event per typing user.
+func (s *typingServiceImpl) publish(ctx context.Context, conversationID int64, userID int64, eventType string) {
+	memberIDs, err := s.members.GetMemberIDs(ctx, conversationID)
+	if err != nil {
+		log.Printf("Typing: failed to get members of conversation %d: %v", conversationID, err)
+		return
+	}
+
+	recipients := make([]int64, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if memberID != userID {
+			recipients = append(recipients, memberID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	event := websocket.WSMessage{
+		Type: eventType,
+		Data: dto.TypingEvent{ConversationID: conversationID, UserID: userID},
+	}
+
+	if len(memberIDs) > s.cfg.AggregateThreshold {
+		typingUsers, err := s.store.List(ctx, conversationID)
+		if err != nil {
+			log.Printf("Typing: failed to list typing users of conversation %d: %v", conversationID, err)
+			return
+		}
+
+		summary := dto.TypingSummaryEvent{
+			ConversationID: conversationID,
+			UserIDs:        typingUsers,
+			Count:          len(typingUsers),
+		}
+		if len(summary.UserIDs) > typingSummarySize {
+			summary.UserIDs = summary.UserIDs[:typingSummarySize]
+		}
+
+		event = websocket.WSMessage{Type: "typing.updated", Data: summary}
+	}
+
+	if err := s.hub.BroadcastEphemeral(recipients, event); err != nil {
+		log.Printf("Typing: failed to publish %s for conversation %d: %v", eventType, conversationID, err)
+	}
+}
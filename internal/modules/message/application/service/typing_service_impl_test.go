@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+	typingStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/infrastructure/persistence/redis"
+)
+
+type fakeMembers struct {
+	repository.MemberRepository
+	ids []int64
+}
+
+func (f *fakeMembers) IsMember(ctx context.Context, conversationID int64, userID int64) (bool, error) {
+	for _, id := range f.ids {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeMembers) GetMemberIDs(ctx context.Context, conversationID int64) ([]int64, error) {
+	return f.ids, nil
+}
+
+type fakePresence struct {
+	elsewhere bool
+}
+
+func (f *fakePresence) MarkActive(userID int64) {}
+
+func (f *fakePresence) ConnectedElsewhere(ctx context.Context, userID int64, connID string) (bool, error) {
+	return f.elsewhere, nil
+}
+
+func TestTypingSurvivesClosingAnotherDevice(t *testing.T) {
+	tests := []struct {
+		name      string
+		elsewhere bool
+		want      int
+	}{
+		{name: "last connection closed", elsewhere: false, want: 0},
+		{name: "another device still connected", elsewhere: true, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			presence := &fakePresence{elsewhere: tt.elsewhere}
+			hub := websocket.NewHub(websocket.NewMemoryDeliveryQueue(websocket.DeliveryConfig{Retention: time.Hour, MaxEvents: 10}))
+			typing := NewTypingService(typingStore.NewMemoryTypingStore(), &fakeMembers{ids: []int64{1, 2}}, hub, presence, presence, TypingConfig{
+				TTL:                time.Minute,
+				AggregateThreshold: 10,
+			})
+
+			if err := typing.SetTyping(ctx, 7, 1, true); err != nil {
+				t.Fatal(err)
+			}
+			typing.ClientDisconnected(1, "phone")
+
+			users, err := typing.GetTypingUsers(ctx, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(users) != tt.want {
+				t.Fatalf("got %d typing users, want %d", len(users), tt.want)
+			}
+		})
+	}
+}
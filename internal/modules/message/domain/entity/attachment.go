@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// Attachment is a blob a member uploaded to a conversation for a media message
+type Attachment struct {
+	StorageKey     string
+	UploaderID     int64
+	ConversationID int64
+	ContentType    string
+	SizeBytes      int64
+	CreatedAt      time.Time
+}
+
+// NewAttachment records an upload stored under storageKey
+func NewAttachment(storageKey string, uploaderID, conversationID int64, contentType string, sizeBytes int64) *Attachment {
+	return &Attachment{
+		StorageKey:     storageKey,
+		UploaderID:     uploaderID,
+		ConversationID: conversationID,
+		ContentType:    contentType,
+		SizeBytes:      sizeBytes,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// UsableBy reports whether a message from senderID in conversationID may carry the attachment
+func (a *Attachment) UsableBy(senderID, conversationID int64) bool {
+	return a.UploaderID == senderID && a.ConversationID == conversationID
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// ConversationSettings holds the conversation options that affect messaging
+type ConversationSettings struct {
+	ConversationID     int64
+	IsDirect           bool
+	ForwardingDisabled bool
+	MessageTTL         time.Duration // Zero unless disappearing messages are on
+}
@@ -11,5 +11,26 @@ var (
 	ErrCannotEditMessage      = errors.New("cannot edit this message")
 	ErrCannotDeleteMessage    = errors.New("cannot delete this message")
 	ErrInvalidReaction        = errors.New("invalid reaction emoji")
+	ErrInvalidPageState       = errors.New("invalid page state")
+	ErrNotConversationAdmin   = errors.New("only conversation owners and admins can do this")
+	ErrPinLimitReached        = errors.New("pinned message limit reached")
+	ErrForwardingDisabled     = errors.New("forwarding is disabled in this conversation")
+	ErrScheduledNotFound      = errors.New("scheduled message not found")
+	ErrScheduledNotEditable   = errors.New("scheduled message has already been sent or canceled")
+	ErrInvalidSendAt          = errors.New("send_at must be in the future")
+	ErrNotGroupConversation   = errors.New("this action is only available in group conversations")
+	ErrNotConversationOwner   = errors.New("only the conversation owner can do this")
+	ErrCannotRemoveOwner      = errors.New("the conversation owner cannot leave or be removed")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrInvalidMention         = errors.New("invalid mention")
+	ErrMentionAllNotAllowed   = errors.New("only owners and admins can mention everyone in this group")
+	ErrInvalidTextEntity      = errors.New("invalid text entity")
+	ErrCannotMessageSelf      = errors.New("you cannot start a conversation with yourself")
+	ErrOnlyFriendsCanMessage  = errors.New("this user only accepts direct messages from friends")
+	ErrReservedMetadata       = errors.New("metadata contains a key only the server may set")
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrAttachmentNotAllowed   = errors.New("only media messages can carry an attachment")
+
+	ErrMessageStoreUnavailable = errors.New("message store is not available")
 )
 
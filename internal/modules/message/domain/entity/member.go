@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type MemberRole string
+
+const (
+	MemberRoleOwner  MemberRole = "OWNER"
+	MemberRoleAdmin  MemberRole = "ADMIN"
+	MemberRoleMember MemberRole = "MEMBER"
+)
+
+// CanModerate reports whether the role may manage the conversation, e.g. pin messages
+func (r MemberRole) CanModerate() bool {
+	return r == MemberRoleOwner || r == MemberRoleAdmin
+}
+
+// Membership is one conversation a user belongs to or has left
+type Membership struct {
+	ConversationID   int64
+	ConversationType string
+	ConversationName string
+	Role             MemberRole
+	JoinedAt         *time.Time
+	LeftAt           *time.Time // Nil while the user is still a member
+}
@@ -0,0 +1,82 @@
+package entity
+
+import "unicode/utf8"
+
+type MentionType string
+
+const (
+	MentionTypeUser MentionType = "user"
+	MentionTypeAll  MentionType = "all"
+)
+
+// Mention marks a span of the message content that mentions a member or everyone.
+// Offset and Length count characters (Unicode code points) of the content.
+type Mention struct {
+	Type   MentionType
+	UserID int64 // Zero for MentionTypeAll
+	Offset int
+	Length int
+}
+
+// ValidateMentions checks that mentions are well formed and lie within the content
+func ValidateMentions(content string, mentions []Mention) error {
+	length := utf8.RuneCountInString(content)
+
+	for _, mention := range mentions {
+		switch mention.Type {
+		case MentionTypeUser:
+			if mention.UserID <= 0 {
+				return ErrInvalidMention
+			}
+		case MentionTypeAll:
+			if mention.UserID != 0 {
+				return ErrInvalidMention
+			}
+		default:
+			return ErrInvalidMention
+		}
+
+		if mention.Offset < 0 || mention.Length <= 0 || mention.Offset+mention.Length > length {
+			return ErrInvalidMention
+		}
+	}
+
+	return nil
+}
+
+// MentionsAll reports whether the message mentions everyone
+func (m *Message) MentionsAll() bool {
+	for _, mention := range m.Mentions {
+		if mention.Type == MentionTypeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// MentionedUserIDs returns the users mentioned by the message, expanding @all
+// to the given members. The sender is never mentioned.
+func (m *Message) MentionedUserIDs(memberIDs []int64) []int64 {
+	seen := map[int64]bool{m.SenderID: true}
+	var userIDs []int64
+
+	add := func(userID int64) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	for _, mention := range m.Mentions {
+		if mention.Type == MentionTypeUser {
+			add(mention.UserID)
+		}
+	}
+	if m.MentionsAll() {
+		for _, userID := range memberIDs {
+			add(userID)
+		}
+	}
+
+	return userIDs
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -15,6 +16,7 @@ const (
 	MessageTypeAudio    MessageType = "AUDIO"
 	MessageTypeFile     MessageType = "FILE"
 	MessageTypeLocation MessageType = "LOCATION"
+	MessageTypeSystem   MessageType = "SYSTEM" // Generated by the server, never sent by clients
 )
 
 type MessageStatus string
@@ -25,6 +27,43 @@ const (
 	MessageStatusRead      MessageStatus = "READ"
 )
 
+// Metadata keys recording where a forwarded message originally came from
+const (
+	MetadataForwardedFromSender       = "forwarded_from_sender_id"
+	MetadataForwardedFromConversation = "forwarded_from_conversation_id"
+	MetadataForwardedFromMessage      = "forwarded_from_message_id"
+
+	// Storage key of the uploaded blob behind a media message
+	MetadataAttachmentKey = "attachment_key"
+
+	// Set on messages whose sender's account was deleted; see Tombstone
+	MetadataTombstone = "tombstone"
+)
+
+// reservedMetadata lists metadata keys only the server may write
+var reservedMetadata = map[string]bool{
+	MetadataForwardedFromSender:       true,
+	MetadataForwardedFromConversation: true,
+	MetadataForwardedFromMessage:      true,
+	MetadataAttachmentKey:             true,
+	MetadataTombstone:                 true,
+	MetadataSystemEvent:               true,
+	MetadataActorID:                   true,
+	MetadataTargetIDs:                 true,
+	MetadataTTLSeconds:                true,
+}
+
+// CheckClientMetadata rejects metadata from a client that sets a key only the
+// server may write, such as forwarding provenance or the attachment key
+func CheckClientMetadata(metadata map[string]string) error {
+	for key := range metadata {
+		if reservedMetadata[key] {
+			return ErrReservedMetadata
+		}
+	}
+	return nil
+}
+
 type Message struct {
 	MessageID       gocql.UUID  // TIMEUUID from Cassandra
 	ConversationID  int64
@@ -33,11 +72,15 @@ type Message struct {
 	Type            MessageType
 	Content         string
 	Metadata        map[string]string // Store media URLs, file info, etc.
+	Mentions        []Mention
+	Entities        []TextEntity
+	LinkPreview     *LinkPreview // Attached after the message is sent
 	Status          MessageStatus
 	IsEdited        bool
 	IsDeleted       bool
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	ExpiresAt       *time.Time // Set for disappearing messages
 }
 
 // NewMessage creates a new message
@@ -64,10 +107,32 @@ func NewReplyMessage(conversationID, senderID int64, parentMessageID gocql.UUID,
 	return msg
 }
 
+// NewForwardedMessage copies a message into another conversation. Attachments are
+// shared by reference through the metadata URLs, and the provenance always points
+// at the first sender even when forwarding a forwarded message.
+func NewForwardedMessage(original *Message, conversationID, senderID int64) *Message {
+	msg := NewMessage(conversationID, senderID, original.Type, original.Content)
+	msg.Entities = original.Entities
+	msg.LinkPreview = original.LinkPreview
+	for key, value := range original.Metadata {
+		msg.Metadata[key] = value
+	}
+
+	if !original.IsForwarded() {
+		msg.Metadata[MetadataForwardedFromSender] = strconv.FormatInt(original.SenderID, 10)
+		msg.Metadata[MetadataForwardedFromConversation] = strconv.FormatInt(original.ConversationID, 10)
+		msg.Metadata[MetadataForwardedFromMessage] = original.MessageID.String()
+	}
+
+	return msg
+}
+
 // Domain methods
 
-func (m *Message) Edit(newContent string) {
+func (m *Message) Edit(newContent string, entities []TextEntity) {
 	m.Content = newContent
+	m.Entities = entities
+	m.LinkPreview = nil // The links may have changed
 	m.IsEdited = true
 	m.UpdatedAt = time.Now()
 }
@@ -77,6 +142,23 @@ func (m *Message) Delete() {
 	m.UpdatedAt = time.Now()
 }
 
+// Tombstone erases what the sender wrote when their account is deleted. The
+// message keeps its place in the conversation so replies still make sense.
+func (m *Message) Tombstone() {
+	m.Content = ""
+	m.Metadata = map[string]string{MetadataTombstone: "account_deleted"}
+	m.Mentions = nil
+	m.Entities = nil
+	m.LinkPreview = nil
+	m.UpdatedAt = time.Now()
+}
+
+// IsTombstone reports whether the message was erased by Tombstone
+func (m *Message) IsTombstone() bool {
+	_, ok := m.Metadata[MetadataTombstone]
+	return ok
+}
+
 func (m *Message) MarkAsDelivered() {
 	m.Status = MessageStatusDelivered
 	m.UpdatedAt = time.Now()
@@ -92,16 +174,72 @@ func (m *Message) IsTextMessage() bool {
 }
 
 func (m *Message) IsMediaMessage() bool {
-	return m.Type == MessageTypeImage ||
-		m.Type == MessageTypeVideo ||
-		m.Type == MessageTypeAudio ||
-		m.Type == MessageTypeFile
+	return m.Type.IsMedia()
+}
+
+// IsMedia reports whether messages of this type carry an uploaded file
+func (t MessageType) IsMedia() bool {
+	return t == MessageTypeImage ||
+		t == MessageTypeVideo ||
+		t == MessageTypeAudio ||
+		t == MessageTypeFile
+}
+
+// ExpireAfter makes the message disappear ttl after it was created
+func (m *Message) ExpireAfter(ttl time.Duration) {
+	if ttl <= 0 {
+		m.ExpiresAt = nil
+		return
+	}
+	expiresAt := m.CreatedAt.Add(ttl)
+	m.ExpiresAt = &expiresAt
+}
+
+// RemainingTTL returns how long the message has left, or zero if it never expires
+func (m *Message) RemainingTTL(now time.Time) time.Duration {
+	if m.ExpiresAt == nil {
+		return 0
+	}
+	remaining := m.ExpiresAt.Sub(now)
+	if remaining < time.Second {
+		// Zero would mean "never expires" to the store
+		remaining = time.Second
+	}
+	return remaining
+}
+
+// AttachmentKey returns the storage key of the message's uploaded blob, if any
+func (m *Message) AttachmentKey() (string, bool) {
+	key, ok := m.Metadata[MetadataAttachmentKey]
+	return key, ok && key != ""
+}
+
+func (m *Message) IsSystemMessage() bool {
+	return m.Type == MessageTypeSystem
+}
+
+func (m *Message) IsForwarded() bool {
+	_, ok := m.Metadata[MetadataForwardedFromSender]
+	return ok
 }
 
 func (m *Message) IsReply() bool {
 	return m.ParentMessageID != nil
 }
 
+// Preview returns a short summary of the message for lists and notifications
+func (m *Message) Preview(maxRunes int) string {
+	if !m.IsTextMessage() {
+		return "[" + string(m.Type) + "]"
+	}
+
+	runes := []rune(m.Content)
+	if len(runes) <= maxRunes {
+		return m.Content
+	}
+	return string(runes[:maxRunes]) + "…"
+}
+
 func (m *Message) AddMetadata(key, value string) {
 	if m.Metadata == nil {
 		m.Metadata = make(map[string]string)
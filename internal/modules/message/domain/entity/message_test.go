@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestCheckClientMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     error
+	}{
+		{name: "no metadata", metadata: nil},
+		{name: "client keys", metadata: map[string]string{"file_name": "a.png", "width": "640"}},
+		{name: "forwarded sender", metadata: map[string]string{MetadataForwardedFromSender: "1"}, want: ErrReservedMetadata},
+		{name: "forwarded conversation", metadata: map[string]string{MetadataForwardedFromConversation: "1"}, want: ErrReservedMetadata},
+		{name: "forwarded message", metadata: map[string]string{MetadataForwardedFromMessage: "x"}, want: ErrReservedMetadata},
+		{name: "attachment key", metadata: map[string]string{MetadataAttachmentKey: "exports/2/archive.zip"}, want: ErrReservedMetadata},
+		{name: "tombstone", metadata: map[string]string{MetadataTombstone: "account_deleted"}, want: ErrReservedMetadata},
+		{name: "system event", metadata: map[string]string{MetadataSystemEvent: "members_added"}, want: ErrReservedMetadata},
+		{name: "disappearing ttl", metadata: map[string]string{MetadataTTLSeconds: "60"}, want: ErrReservedMetadata},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckClientMetadata(tt.metadata); !errors.Is(err, tt.want) {
+				t.Fatalf("CheckClientMetadata() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardedMessageKeepsFirstProvenance(t *testing.T) {
+	original := NewMessage(1, 10, MessageTypeText, "hello")
+	first := NewForwardedMessage(original, 2, 20)
+	second := NewForwardedMessage(first, 3, 30)
+
+	if got := second.Metadata[MetadataForwardedFromSender]; got != strconv.FormatInt(original.SenderID, 10) {
+		t.Fatalf("forwarded from sender %s, want %d", got, original.SenderID)
+	}
+	if got := second.Metadata[MetadataForwardedFromMessage]; got != original.MessageID.String() {
+		t.Fatalf("forwarded from message %s, want %s", got, original.MessageID)
+	}
+}
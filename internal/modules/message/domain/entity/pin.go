@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// Pin marks a message as pinned in its conversation
+type Pin struct {
+	ConversationID int64
+	MessageID      gocql.UUID
+	PinnedBy       int64
+	PinnedAt       time.Time
+	ExpiresAt      *time.Time // Set when the pinned message disappears
+}
+
+// NewPin creates a pin of a message by a conversation member. The pin expires
+// with the message.
+func NewPin(message *Message, pinnedBy int64) *Pin {
+	return &Pin{
+		ConversationID: message.ConversationID,
+		MessageID:      message.MessageID,
+		PinnedBy:       pinnedBy,
+		PinnedAt:       time.Now(),
+		ExpiresAt:      message.ExpiresAt,
+	}
+}
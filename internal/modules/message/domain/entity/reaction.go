@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"unicode/utf8"
+
+	"github.com/gocql/gocql"
+)
+
+// UserReaction is one reaction a user left on a message
+type UserReaction struct {
+	MessageID gocql.UUID
+	Emoji     string
+}
+
+// ValidateEmoji checks that a reaction is a single emoji. If allowed is not
+// empty the emoji must also be one of the allowed reactions.
+func ValidateEmoji(emoji string, allowed []string) error {
+	if len(allowed) > 0 {
+		for _, candidate := range allowed {
+			if emoji == candidate {
+				return nil
+			}
+		}
+		return ErrInvalidReaction
+	}
+
+	if !isSingleEmoji(emoji) {
+		return ErrInvalidReaction
+	}
+	return nil
+}
+
+// isSingleEmoji reports whether s is exactly one emoji grapheme: a flag, a
+// keycap, or a pictographic base with optional modifiers, variation selectors
+// and tags, possibly joined to further bases with zero width joiners.
+func isSingleEmoji(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return false
+	}
+
+	runes := []rune(s)
+
+	// Flags are exactly two regional indicators
+	if isRegionalIndicator(runes[0]) {
+		return len(runes) == 2 && isRegionalIndicator(runes[1])
+	}
+
+	// Keycaps: digit, # or * followed by an optional variation selector and the keycap mark
+	if isKeycapBase(runes[0]) {
+		rest := runes[1:]
+		if len(rest) > 0 && rest[0] == variationSelector16 {
+			rest = rest[1:]
+		}
+		return len(rest) == 1 && rest[0] == combiningKeycap
+	}
+
+	expectBase := true
+	for _, r := range runes {
+		switch {
+		case expectBase:
+			if !isPictographic(r) {
+				return false
+			}
+			expectBase = false
+		case r == zeroWidthJoiner:
+			expectBase = true
+		case isSkinTone(r), r == variationSelector16, isTag(r):
+			// Modifiers attach to the preceding base
+		default:
+			return false
+		}
+	}
+
+	return !expectBase
+}
+
+const (
+	zeroWidthJoiner     = 0x200D
+	variationSelector16 = 0xFE0F
+	combiningKeycap     = 0x20E3
+)
+
+func isRegionalIndicator(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+func isSkinTone(r rune) bool          { return r >= 0x1F3FB && r <= 0x1F3FF }
+func isTag(r rune) bool               { return r >= 0xE0020 && r <= 0xE007F }
+
+func isKeycapBase(r rune) bool {
+	return (r >= '0' && r <= '9') || r == '#' || r == '*'
+}
+
+// isPictographic approximates the Unicode Extended_Pictographic property
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139:
+		return true
+	case r >= 0x2194 && r <= 0x21AA:
+		return true
+	case r >= 0x231A && r <= 0x23FF:
+		return true
+	case r >= 0x24C2 && r <= 0x25FE:
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols, dingbats
+		return true
+	case r >= 0x2934 && r <= 0x2935, r >= 0x2B05 && r <= 0x2B55:
+		return true
+	case r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF: // Emoticons, pictographs, transport, supplemental symbols
+		return !isRegionalIndicator(r) && !isSkinTone(r)
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+type ScheduledStatus string
+
+const (
+	ScheduledStatusPending  ScheduledStatus = "PENDING"
+	ScheduledStatusSending  ScheduledStatus = "SENDING"
+	ScheduledStatusSent     ScheduledStatus = "SENT"
+	ScheduledStatusCanceled ScheduledStatus = "CANCELED"
+	ScheduledStatusFailed   ScheduledStatus = "FAILED"
+)
+
+// ScheduledMessage is a message written now and sent by the scheduler at SendAt
+type ScheduledMessage struct {
+	ID              int64
+	ConversationID  int64
+	SenderID        int64
+	ParentMessageID *gocql.UUID
+	Type            MessageType
+	Content         string
+	Metadata        map[string]string
+	Mentions        []Mention
+	Entities        []TextEntity
+	SendAt          time.Time
+	Status          ScheduledStatus
+	MessageID       *gocql.UUID // The sent message, once delivered
+	FailureReason   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// NewScheduledMessage creates a pending scheduled message
+func NewScheduledMessage(conversationID, senderID int64, msgType MessageType, content string, sendAt time.Time) *ScheduledMessage {
+	return &ScheduledMessage{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Type:           msgType,
+		Content:        content,
+		Metadata:       make(map[string]string),
+		SendAt:         sendAt,
+		Status:         ScheduledStatusPending,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
+
+// Domain methods
+
+// IsEditable reports whether the author may still change or cancel the message.
+// Failed messages can be rescheduled.
+func (m *ScheduledMessage) IsEditable() bool {
+	return m.Status == ScheduledStatusPending || m.Status == ScheduledStatusFailed
+}
+
+func (m *ScheduledMessage) Reschedule(content string, sendAt time.Time) {
+	m.Content = content
+	m.SendAt = sendAt
+	m.Status = ScheduledStatusPending
+	m.FailureReason = ""
+	m.UpdatedAt = time.Now()
+}
+
+func (m *ScheduledMessage) Cancel() {
+	m.Status = ScheduledStatusCanceled
+	m.UpdatedAt = time.Now()
+}
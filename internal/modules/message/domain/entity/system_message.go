@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SystemEventKind identifies a conversation lifecycle event
+type SystemEventKind string
+
+const (
+	SystemEventMembersAdded        SystemEventKind = "members_added"
+	SystemEventMemberLeft          SystemEventKind = "member_left"
+	SystemEventMemberRemoved       SystemEventKind = "member_removed"
+	SystemEventMemberRoleChanged   SystemEventKind = "member_role_changed"
+	SystemEventConversationRenamed SystemEventKind = "conversation_renamed"
+	SystemEventDisappearingChanged SystemEventKind = "disappearing_messages_changed"
+)
+
+// Metadata keys of system messages
+const (
+	MetadataSystemEvent = "system_event"
+	MetadataActorID     = "actor_id"
+	MetadataTargetIDs   = "target_ids" // Comma separated user IDs
+	MetadataName        = "name"
+	MetadataRole        = "role"
+	MetadataTTLSeconds  = "ttl_seconds"
+)
+
+// SystemEvent is the structured payload of a system message. Clients and the
+// server render it into text in the reader's language.
+type SystemEvent struct {
+	Kind      SystemEventKind
+	ActorID   int64
+	TargetIDs []int64
+	Params    map[string]string // Event specific values such as MetadataName or MetadataRole
+}
+
+// UserIDs returns the actor and targets, e.g. to look up their names
+func (e SystemEvent) UserIDs() []int64 {
+	return append([]int64{e.ActorID}, e.TargetIDs...)
+}
+
+// NewSystemMessage creates a server generated message for an event. The actor
+// is stored as sender; content is a rendering used by clients that do not
+// render the event themselves.
+func NewSystemMessage(conversationID int64, event SystemEvent, content string) *Message {
+	msg := NewMessage(conversationID, event.ActorID, MessageTypeSystem, content)
+	for key, value := range event.Params {
+		msg.Metadata[key] = value
+	}
+
+	targets := make([]string, 0, len(event.TargetIDs))
+	for _, id := range event.TargetIDs {
+		targets = append(targets, strconv.FormatInt(id, 10))
+	}
+
+	msg.Metadata[MetadataSystemEvent] = string(event.Kind)
+	msg.Metadata[MetadataActorID] = strconv.FormatInt(event.ActorID, 10)
+	if len(targets) > 0 {
+		msg.Metadata[MetadataTargetIDs] = strings.Join(targets, ",")
+	}
+
+	return msg
+}
+
+// SystemEvent restores the event of a system message
+func (m *Message) SystemEvent() (SystemEvent, bool) {
+	kind, ok := m.Metadata[MetadataSystemEvent]
+	if !m.IsSystemMessage() || !ok {
+		return SystemEvent{}, false
+	}
+
+	event := SystemEvent{
+		Kind:    SystemEventKind(kind),
+		ActorID: m.SenderID,
+		Params:  make(map[string]string),
+	}
+
+	for key, value := range m.Metadata {
+		switch key {
+		case MetadataSystemEvent, MetadataActorID:
+		case MetadataTargetIDs:
+			for _, raw := range strings.Split(value, ",") {
+				if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+					event.TargetIDs = append(event.TargetIDs, id)
+				}
+			}
+		default:
+			event.Params[key] = value
+		}
+	}
+
+	return event, true
+}
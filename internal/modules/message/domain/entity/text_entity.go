@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type TextEntityType string
+
+const (
+	TextEntityBold   TextEntityType = "bold"
+	TextEntityItalic TextEntityType = "italic"
+	TextEntityCode   TextEntityType = "code"
+	TextEntityLink   TextEntityType = "link"
+)
+
+// TextEntity formats a span of a text message. Offset and Length count
+// characters (Unicode code points) of the content, like Mention.
+// Mentions are kept apart in Message.Mentions because they drive notifications.
+type TextEntity struct {
+	Type   TextEntityType
+	Offset int
+	Length int
+	URL    string // Link target; empty when the linked text is the URL itself
+}
+
+// LinkPreview summarizes the page behind the first link of a text message
+type LinkPreview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// Bare URLs in message text; trailing punctuation is trimmed afterwards
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// ValidateTextEntities checks that entities are well formed and lie within the content
+func ValidateTextEntities(content string, entities []TextEntity) error {
+	length := utf8.RuneCountInString(content)
+
+	for _, span := range entities {
+		switch span.Type {
+		case TextEntityBold, TextEntityItalic, TextEntityCode:
+			if span.URL != "" {
+				return ErrInvalidTextEntity
+			}
+		case TextEntityLink:
+			if span.URL != "" && !isWebURL(span.URL) {
+				return ErrInvalidTextEntity
+			}
+		default:
+			return ErrInvalidTextEntity
+		}
+
+		if span.Offset < 0 || span.Length <= 0 || span.Offset+span.Length > length {
+			return ErrInvalidTextEntity
+		}
+	}
+
+	return nil
+}
+
+// PreviewURL returns the link a preview should be generated for: the first
+// link entity, or else the first URL in the content
+func (m *Message) PreviewURL() (string, bool) {
+	if !m.IsTextMessage() {
+		return "", false
+	}
+
+	runes := []rune(m.Content)
+	for _, span := range m.Entities {
+		if span.Type != TextEntityLink {
+			continue
+		}
+		target := span.URL
+		if target == "" {
+			target = string(runes[span.Offset : span.Offset+span.Length])
+		}
+		if isWebURL(target) {
+			return target, true
+		}
+	}
+
+	for _, match := range urlPattern.FindAllString(m.Content, -1) {
+		if target := strings.TrimRight(match, ".,;:!?)]}'"); isWebURL(target) {
+			return target, true
+		}
+	}
+
+	return "", false
+}
+
+func isWebURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
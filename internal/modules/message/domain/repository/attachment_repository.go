@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+// AttachmentRepository records who uploaded each message attachment
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *entity.Attachment) error
+
+	// GetByKey fails with ErrAttachmentNotFound for keys that were never uploaded
+	GetByKey(ctx context.Context, storageKey string) (*entity.Attachment, error)
+
+	// ListByUploader returns every attachment the user uploaded
+	ListByUploader(ctx context.Context, uploaderID int64) ([]*entity.Attachment, error)
+
+	// Delete drops the record of an upload. Deleting a missing record is not an error.
+	Delete(ctx context.Context, storageKey string) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+// ConversationRepository gives the message module read access to conversation settings
+type ConversationRepository interface {
+	// GetSettings returns ErrConversationNotFound for missing or deleted conversations
+	GetSettings(ctx context.Context, conversationID int64) (*entity.ConversationSettings, error)
+
+	// SetMessageTTL turns disappearing messages on, or off with a zero ttl
+	SetMessageTTL(ctx context.Context, conversationID int64, ttl time.Duration) error
+
+	Rename(ctx context.Context, conversationID int64, name string) error
+
+	// FindDirect returns the direct conversation between two users, or
+	// ErrConversationNotFound if they have none
+	FindDirect(ctx context.Context, userID int64, otherID int64) (int64, error)
+
+	// CreateDirect starts a direct conversation between two users with both as
+	// members. If one was started concurrently, that one is returned instead.
+	CreateDirect(ctx context.Context, creatorID int64, otherID int64) (conversationID int64, created bool, err error)
+}
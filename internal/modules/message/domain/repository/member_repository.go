@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+// MemberRepository gives the message module access to conversation membership
+type MemberRepository interface {
+	IsMember(ctx context.Context, conversationID int64, userID int64) (bool, error)
+	GetMemberIDs(ctx context.Context, conversationID int64) ([]int64, error)
+
+	// GetRole returns ErrUserNotInConversation for non-members
+	GetRole(ctx context.Context, conversationID int64, userID int64) (entity.MemberRole, error)
+
+	// RecordMessage updates the conversation's last message and, when countsAsUnread,
+	// the unread counts of other members
+	RecordMessage(ctx context.Context, conversationID int64, senderID int64, messageID string, sentAt time.Time, countsAsUnread bool) error
+
+	// RecordMentions increments the mention counts of the mentioned members
+	RecordMentions(ctx context.Context, conversationID int64, userIDs []int64) error
+
+	// MarkRead resets the member's unread and mention counts up to messageID
+	MarkRead(ctx context.Context, conversationID int64, userID int64, messageID string) error
+
+	// AddMembers adds users as members, rejoining former members, and returns who was added
+	AddMembers(ctx context.Context, conversationID int64, userIDs []int64) ([]int64, error)
+	RemoveMember(ctx context.Context, conversationID int64, userID int64) (removed bool, err error)
+	SetRole(ctx context.Context, conversationID int64, userID int64, role entity.MemberRole) error
+
+	// ListMemberships returns every conversation the user joined, left ones
+	// included, most recently joined first
+	ListMemberships(ctx context.Context, userID int64) ([]*entity.Membership, error)
+}
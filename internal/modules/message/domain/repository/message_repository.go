@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/gocql/gocql"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
@@ -23,14 +24,39 @@ type MessageRepository interface {
 	UpdateDeliveryStatus(ctx context.Context, messageID gocql.UUID, userID int64, status entity.MessageStatus) error
 	GetDeliveryStatus(ctx context.Context, messageID gocql.UUID) (map[int64]entity.MessageStatus, error)
 
-	// Reactions
-	AddReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string) error
-	RemoveReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string) error
+	// Reactions are idempotent: added/removed report whether anything changed
+	// A reaction to a disappearing message expires after ttl; zero means never
+	AddReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string, ttl time.Duration) (added bool, err error)
+	RemoveReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string) (removed bool, err error)
 	GetReactions(ctx context.Context, messageID gocql.UUID) (map[string][]int64, error)
+	GetUserReactions(ctx context.Context, messageID gocql.UUID, userID int64) ([]string, error)
 	GetReactionCounts(ctx context.Context, messageID gocql.UUID) (map[string]int64, error)
 
-	// Typing indicators
-	SetTypingIndicator(ctx context.Context, conversationID int64, userID int64, isTyping bool) error
-	GetTypingUsers(ctx context.Context, conversationID int64) ([]int64, error)
+	// ReplaceReaction makes emoji the user's only reaction and returns the emojis it replaced.
+	// Concurrent replacements by one user are serialized, so exactly one reaction survives.
+	ReplaceReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string, ttl time.Duration) (replaced []string, added bool, err error)
+
+	// SetLinkPreview attaches a preview unless the message was edited or recalled
+	// since it was loaded; it reports whether the preview was attached
+	SetLinkPreview(ctx context.Context, message *entity.Message, preview *entity.LinkPreview) (bool, error)
+
+	// Mentions inbox: AddMentions files the message for each mentioned user,
+	// RemoveMentions takes it out again and GetMentions lists a user's mentions newest first
+	AddMentions(ctx context.Context, message *entity.Message, userIDs []int64) error
+	RemoveMentions(ctx context.Context, messageID gocql.UUID, userIDs []int64) error
+	GetMentions(ctx context.Context, userID int64, limit int, pagingState []byte) ([]*entity.Message, []byte, error)
+
+	// Account data. ListMessagesByUser pages through the messages a user sent,
+	// newest first, with only IDs, conversation and created_at set.
+	ListMessagesByUser(ctx context.Context, userID int64, limit int, pagingState []byte) ([]*entity.Message, []byte, error)
+	ListReactionsByUser(ctx context.Context, userID int64) ([]entity.UserReaction, error)
+
+	// Tombstone saves a message erased by entity.Message.Tombstone, including
+	// the copy in its parent's thread
+	Tombstone(ctx context.Context, message *entity.Message) error
+
+	// DeleteUserIndexes drops the user's sent messages, mentions inbox and
+	// reactions indexes once their contents have been purged
+	DeleteUserIndexes(ctx context.Context, userID int64) error
 }
 
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+// PinRepository stores the pinned messages of conversations
+type PinRepository interface {
+	// Add pins a message unless it is already pinned; it fails with ErrPinLimitReached
+	// when the conversation already has limit pins. Expired pins do not count.
+	Add(ctx context.Context, pin *entity.Pin, limit int) (added bool, err error)
+	Remove(ctx context.Context, conversationID int64, messageID gocql.UUID) (removed bool, err error)
+
+	// List returns the conversation's unexpired pins, most recently pinned first
+	List(ctx context.Context, conversationID int64) ([]*entity.Pin, error)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+)
+
+// ScheduledMessageRepository stores messages waiting to be sent
+type ScheduledMessageRepository interface {
+	Create(ctx context.Context, message *entity.ScheduledMessage) error
+	GetByID(ctx context.Context, id int64) (*entity.ScheduledMessage, error)
+	ListBySender(ctx context.Context, senderID int64) ([]*entity.ScheduledMessage, error)
+
+	// Update saves author changes only while the message is still editable;
+	// it returns ErrScheduledNotEditable if the scheduler claimed it first
+	Update(ctx context.Context, message *entity.ScheduledMessage) error
+
+	// ClaimDue atomically moves up to limit due messages to SENDING so that
+	// each message is claimed by exactly one replica
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]*entity.ScheduledMessage, error)
+
+	// AssignMessageID records the ID a claimed message will be sent under before
+	// it is sent, so an interrupted send can later be checked
+	AssignMessageID(ctx context.Context, id int64, messageID gocql.UUID) error
+
+	// MarkSent and MarkFailed settle a claimed message; they do nothing once it
+	// is settled. MarkFailed forgets the assigned ID so a retry gets a new one.
+	MarkSent(ctx context.Context, id int64, messageID gocql.UUID) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+
+	// ListStale returns up to limit messages claimed before the cutoff; their
+	// replica stopped mid-send and they may or may not have been sent
+	ListStale(ctx context.Context, claimedBefore time.Time, limit int) ([]*entity.ScheduledMessage, error)
+
+	// DeleteBySender removes every scheduled message of a deleted account
+	DeleteBySender(ctx context.Context, senderID int64) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// TypingStore keeps ephemeral typing state shared by all nodes
+type TypingStore interface {
+	// Start marks the user as typing until expiresAt and reports whether they were not typing before
+	Start(ctx context.Context, conversationID int64, userID int64, expiresAt time.Time) (bool, error)
+
+	// Stop clears the user's typing state and reports whether they were typing
+	Stop(ctx context.Context, conversationID int64, userID int64) (bool, error)
+
+	// StopAll clears the user's typing state everywhere and returns the affected conversations
+	StopAll(ctx context.Context, userID int64) ([]int64, error)
+
+	// List returns users currently typing in the conversation
+	List(ctx context.Context, conversationID int64) ([]int64, error)
+
+	// Expire removes typing state that expired before now and returns it per conversation.
+	// Each expired entry is returned to exactly one caller, even across nodes.
+	Expire(ctx context.Context, now time.Time) (map[int64][]int64, error)
+}
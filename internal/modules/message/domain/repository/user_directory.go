@@ -0,0 +1,17 @@
+package repository
+
+import "context"
+
+// UserDirectory gives the message module read access to user details
+type UserDirectory interface {
+	// GetLanguage returns the user's preferred language code, e.g. "en" or "vi"
+	GetLanguage(ctx context.Context, userID int64) (string, error)
+
+	// GetUsernames returns usernames of active users; unknown IDs are left out
+	GetUsernames(ctx context.Context, userIDs []int64) (map[int64]string, error)
+
+	// AcceptsDirectMessages reports whether recipientID lets senderID start a
+	// direct conversation: either anyone may, or only friends may and they
+	// are friends. Returns ErrUserNotFound for missing or deleted recipients.
+	AcceptsDirectMessages(ctx context.Context, recipientID int64, senderID int64) (bool, error)
+}
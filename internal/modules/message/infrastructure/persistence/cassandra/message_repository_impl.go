@@ -2,7 +2,9 @@ package cassandra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -10,6 +12,9 @@ import (
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
 )
 
+// Attempts to win the user's reaction choice before giving up
+const maxReplaceAttempts = 5
+
 type messageRepositoryImpl struct {
 	session *gocql.Session
 }
@@ -20,14 +25,51 @@ func NewMessageRepository(session *gocql.Session) repository.MessageRepository {
 	}
 }
 
+// ttlSeconds converts a remaining lifetime to a Cassandra TTL; 0 means no expiry
+func ttlSeconds(ttl time.Duration) int {
+	if ttl <= 0 {
+		return 0
+	}
+	return int(math.Ceil(ttl.Seconds()))
+}
+
+// expiresAt turns the TTL(content) of a row read now into an expiry time
+func expiresAt(ttl *int, now time.Time) *time.Time {
+	if ttl == nil || *ttl <= 0 {
+		return nil
+	}
+	at := now.Add(time.Duration(*ttl) * time.Second)
+	return &at
+}
+
 func (r *messageRepositoryImpl) Create(ctx context.Context, message *entity.Message) error {
-	query := `INSERT INTO messages (
-		conversation_id, message_id, sender_id, parent_message_id,
-		message_type, content, metadata, status, is_edited, is_deleted,
-		created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	// Disappearing messages are written with a TTL; derived rows get the same TTL
+	ttl := ttlSeconds(message.RemainingTTL(time.Now()))
 
-	err := r.session.Query(query,
+	mentions, err := encodeMentions(message.Mentions)
+	if err != nil {
+		return err
+	}
+	entities, err := encodeTextEntities(message.Entities)
+	if err != nil {
+		return err
+	}
+	preview, err := encodeLinkPreview(message.LinkPreview)
+	if err != nil {
+		return err
+	}
+
+	// The message and its messages_by_user row go in one logged batch. Account
+	// purges find a user's messages through that index, so a message must
+	// never be stored without it.
+	batch := r.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+
+	batch.Query(`INSERT INTO messages (
+		conversation_id, message_id, sender_id, parent_message_id,
+		message_type, content, metadata, mentions, entities, link_preview,
+		status, is_edited, is_deleted, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	USING TTL ?`,
 		message.ConversationID,
 		message.MessageID,
 		message.SenderID,
@@ -35,48 +77,215 @@ func (r *messageRepositoryImpl) Create(ctx context.Context, message *entity.Mess
 		string(message.Type),
 		message.Content,
 		message.Metadata,
+		mentions,
+		entities,
+		preview,
 		string(message.Status),
 		message.IsEdited,
 		message.IsDeleted,
 		message.CreatedAt,
 		message.UpdatedAt,
-	).WithContext(ctx).Exec()
+		ttl,
+	)
 
-	if err != nil {
+	batch.Query(`INSERT INTO messages_by_user (
+		user_id, message_id, conversation_id, sender_id, content, created_at
+	) VALUES (?, ?, ?, ?, ?, ?)
+	USING TTL ?`,
+		message.SenderID,
+		message.MessageID,
+		message.ConversationID,
+		message.SenderID,
+		message.Content,
+		message.CreatedAt,
+		ttl,
+	)
+
+	if err := r.session.ExecuteBatch(batch); err != nil {
 		return fmt.Errorf("failed to create message: %w", err)
 	}
 
-	// Also insert into messages_by_user for search
-	go r.insertMessageByUser(context.Background(), message)
+	// Also insert into the thread of a reply
+	if message.IsReply() {
+		go r.insertThreadReply(context.Background(), message, ttl)
+	}
 
 	return nil
 }
 
-func (r *messageRepositoryImpl) insertMessageByUser(ctx context.Context, message *entity.Message) error {
-	query := `INSERT INTO messages_by_user (
-		user_id, message_id, conversation_id, sender_id, content, created_at
-	) VALUES (?, ?, ?, ?, ?, ?)`
+func (r *messageRepositoryImpl) insertThreadReply(ctx context.Context, message *entity.Message, ttl int) error {
+	query := `INSERT INTO message_threads (
+		parent_message_id, reply_message_id, sender_id, content, created_at
+	) VALUES (?, ?, ?, ?, ?)
+	USING TTL ?`
 
 	return r.session.Query(query,
-		message.SenderID,
+		*message.ParentMessageID,
 		message.MessageID,
-		message.ConversationID,
 		message.SenderID,
 		message.Content,
 		message.CreatedAt,
+		ttl,
 	).WithContext(ctx).Exec()
 }
 
+// mentionRecord is the stored form of a mention
+type mentionRecord struct {
+	Type   string `json:"type"`
+	UserID int64  `json:"user_id,omitempty"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+func encodeMentions(mentions []entity.Mention) (*string, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	records := make([]mentionRecord, 0, len(mentions))
+	for _, mention := range mentions {
+		records = append(records, mentionRecord{
+			Type:   string(mention.Type),
+			UserID: mention.UserID,
+			Offset: mention.Offset,
+			Length: mention.Length,
+		})
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mentions: %w", err)
+	}
+
+	encoded := string(data)
+	return &encoded, nil
+}
+
+func decodeMentions(encoded *string) []entity.Mention {
+	if encoded == nil || *encoded == "" {
+		return nil
+	}
+
+	var records []mentionRecord
+	if err := json.Unmarshal([]byte(*encoded), &records); err != nil {
+		return nil
+	}
+
+	mentions := make([]entity.Mention, 0, len(records))
+	for _, record := range records {
+		mentions = append(mentions, entity.Mention{
+			Type:   entity.MentionType(record.Type),
+			UserID: record.UserID,
+			Offset: record.Offset,
+			Length: record.Length,
+		})
+	}
+	return mentions
+}
+
+// textEntityRecord is the stored form of a formatting entity
+type textEntityRecord struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
+}
+
+func encodeTextEntities(entities []entity.TextEntity) (*string, error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+
+	records := make([]textEntityRecord, 0, len(entities))
+	for _, span := range entities {
+		records = append(records, textEntityRecord{
+			Type:   string(span.Type),
+			Offset: span.Offset,
+			Length: span.Length,
+			URL:    span.URL,
+		})
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode text entities: %w", err)
+	}
+
+	encoded := string(data)
+	return &encoded, nil
+}
+
+func decodeTextEntities(encoded *string) []entity.TextEntity {
+	if encoded == nil || *encoded == "" {
+		return nil
+	}
+
+	var records []textEntityRecord
+	if err := json.Unmarshal([]byte(*encoded), &records); err != nil {
+		return nil
+	}
+
+	entities := make([]entity.TextEntity, 0, len(records))
+	for _, record := range records {
+		entities = append(entities, entity.TextEntity{
+			Type:   entity.TextEntityType(record.Type),
+			Offset: record.Offset,
+			Length: record.Length,
+			URL:    record.URL,
+		})
+	}
+	return entities
+}
+
+// linkPreviewRecord is the stored form of a link preview
+type linkPreviewRecord struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+func encodeLinkPreview(preview *entity.LinkPreview) (*string, error) {
+	if preview == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(linkPreviewRecord(*preview))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode link preview: %w", err)
+	}
+
+	encoded := string(data)
+	return &encoded, nil
+}
+
+func decodeLinkPreview(encoded *string) *entity.LinkPreview {
+	if encoded == nil || *encoded == "" {
+		return nil
+	}
+
+	var record linkPreviewRecord
+	if err := json.Unmarshal([]byte(*encoded), &record); err != nil {
+		return nil
+	}
+
+	preview := entity.LinkPreview(record)
+	return &preview
+}
+
 func (r *messageRepositoryImpl) GetByID(ctx context.Context, conversationID int64, messageID gocql.UUID) (*entity.Message, error) {
 	query := `SELECT 
 		conversation_id, message_id, sender_id, parent_message_id,
-		message_type, content, metadata, status, is_edited, is_deleted,
-		created_at, updated_at
+		message_type, content, metadata, mentions, entities, link_preview,
+		status, is_edited, is_deleted, created_at, updated_at, TTL(content)
 	FROM messages 
 	WHERE conversation_id = ? AND message_id = ?`
 
 	message := &entity.Message{}
 	var msgType, status string
+	var ttl *int
+	var mentions, entities, preview *string
 
 	err := r.session.Query(query, conversationID, messageID).
 		WithContext(ctx).
@@ -88,11 +297,15 @@ func (r *messageRepositoryImpl) GetByID(ctx context.Context, conversationID int6
 			&msgType,
 			&message.Content,
 			&message.Metadata,
+			&mentions,
+			&entities,
+			&preview,
 			&status,
 			&message.IsEdited,
 			&message.IsDeleted,
 			&message.CreatedAt,
 			&message.UpdatedAt,
+			&ttl,
 		)
 
 	if err != nil {
@@ -104,6 +317,10 @@ func (r *messageRepositoryImpl) GetByID(ctx context.Context, conversationID int6
 
 	message.Type = entity.MessageType(msgType)
 	message.Status = entity.MessageStatus(status)
+	message.ExpiresAt = expiresAt(ttl, time.Now())
+	message.Mentions = decodeMentions(mentions)
+	message.Entities = decodeTextEntities(entities)
+	message.LinkPreview = decodeLinkPreview(preview)
 
 	return message, nil
 }
@@ -111,8 +328,8 @@ func (r *messageRepositoryImpl) GetByID(ctx context.Context, conversationID int6
 func (r *messageRepositoryImpl) GetByConversation(ctx context.Context, conversationID int64, limit int, pagingState []byte) ([]*entity.Message, []byte, error) {
 	query := `SELECT 
 		conversation_id, message_id, sender_id, parent_message_id,
-		message_type, content, metadata, status, is_edited, is_deleted,
-		created_at, updated_at
+		message_type, content, metadata, mentions, entities, link_preview,
+		status, is_edited, is_deleted, created_at, updated_at, TTL(content)
 	FROM messages 
 	WHERE conversation_id = ?
 	LIMIT ?`
@@ -128,6 +345,8 @@ func (r *messageRepositoryImpl) GetByConversation(ctx context.Context, conversat
 	for {
 		message := &entity.Message{}
 		var msgType, status string
+		var ttl *int
+		var mentions, entities, preview *string
 
 		if !iter.Scan(
 			&message.ConversationID,
@@ -137,17 +356,25 @@ func (r *messageRepositoryImpl) GetByConversation(ctx context.Context, conversat
 			&msgType,
 			&message.Content,
 			&message.Metadata,
+			&mentions,
+			&entities,
+			&preview,
 			&status,
 			&message.IsEdited,
 			&message.IsDeleted,
 			&message.CreatedAt,
 			&message.UpdatedAt,
+			&ttl,
 		) {
 			break
 		}
 
 		message.Type = entity.MessageType(msgType)
 		message.Status = entity.MessageStatus(status)
+		message.ExpiresAt = expiresAt(ttl, time.Now())
+		message.Mentions = decodeMentions(mentions)
+		message.Entities = decodeTextEntities(entities)
+		message.LinkPreview = decodeLinkPreview(preview)
 
 		if !message.IsDeleted {
 			messages = append(messages, message)
@@ -164,15 +391,30 @@ func (r *messageRepositoryImpl) GetByConversation(ctx context.Context, conversat
 }
 
 func (r *messageRepositoryImpl) Update(ctx context.Context, message *entity.Message) error {
-	query := `UPDATE messages SET 
+	// Rewritten cells keep the remaining TTL so edited messages still disappear
+	query := `UPDATE messages USING TTL ? SET 
 		content = ?, 
+		entities = ?,
+		link_preview = ?,
 		is_edited = ?, 
 		is_deleted = ?,
 		updated_at = ?
 	WHERE conversation_id = ? AND message_id = ?`
 
-	err := r.session.Query(query,
+	entities, err := encodeTextEntities(message.Entities)
+	if err != nil {
+		return err
+	}
+	preview, err := encodeLinkPreview(message.LinkPreview)
+	if err != nil {
+		return err
+	}
+
+	err = r.session.Query(query,
+		ttlSeconds(message.RemainingTTL(time.Now())),
 		message.Content,
+		entities,
+		preview,
 		message.IsEdited,
 		message.IsDeleted,
 		time.Now(),
@@ -187,14 +429,53 @@ func (r *messageRepositoryImpl) Update(ctx context.Context, message *entity.Mess
 	return nil
 }
 
+func (r *messageRepositoryImpl) SetLinkPreview(ctx context.Context, message *entity.Message, preview *entity.LinkPreview) (bool, error) {
+	// The condition drops previews of links that were edited away or recalled meanwhile
+	query := `UPDATE messages USING TTL ? SET link_preview = ?
+	WHERE conversation_id = ? AND message_id = ?
+	IF content = ? AND is_deleted = false`
+
+	encoded, err := encodeLinkPreview(preview)
+	if err != nil {
+		return false, err
+	}
+
+	applied, err := r.session.Query(query,
+		ttlSeconds(message.RemainingTTL(time.Now())),
+		encoded,
+		message.ConversationID,
+		message.MessageID,
+		message.Content,
+	).WithContext(ctx).MapScanCAS(map[string]interface{}{})
+
+	if err != nil {
+		return false, fmt.Errorf("failed to set link preview: %w", err)
+	}
+
+	return applied, nil
+}
+
 func (r *messageRepositoryImpl) Delete(ctx context.Context, conversationID int64, messageID gocql.UUID) error {
+	// Keep the TTL of disappearing messages so the soft deleted row still expires
+	var ttl *int
+	err := r.session.Query(`SELECT TTL(content) FROM messages WHERE conversation_id = ? AND message_id = ?`,
+		conversationID, messageID).WithContext(ctx).Scan(&ttl)
+	if err != nil && err != gocql.ErrNotFound {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	remaining := 0
+	if ttl != nil {
+		remaining = *ttl
+	}
+
 	// Soft delete by updating is_deleted flag
-	query := `UPDATE messages SET 
+	query := `UPDATE messages USING TTL ? SET 
 		is_deleted = ?,
 		updated_at = ?
 	WHERE conversation_id = ? AND message_id = ?`
 
-	err := r.session.Query(query, true, time.Now(), conversationID, messageID).
+	err = r.session.Query(query, remaining, true, time.Now(), conversationID, messageID).
 		WithContext(ctx).Exec()
 
 	if err != nil {
@@ -242,6 +523,85 @@ func (r *messageRepositoryImpl) GetMessagesByUser(ctx context.Context, userID in
 	return messages, nil
 }
 
+func (r *messageRepositoryImpl) AddMentions(ctx context.Context, message *entity.Message, userIDs []int64) error {
+	query := `INSERT INTO mentions_by_user (
+		user_id, message_id, conversation_id, sender_id, content, created_at
+	) VALUES (?, ?, ?, ?, ?, ?)
+	USING TTL ?`
+
+	ttl := ttlSeconds(message.RemainingTTL(time.Now()))
+
+	// One partition per user, so an unlogged batch would not save round trips
+	for _, userID := range userIDs {
+		err := r.session.Query(query,
+			userID,
+			message.MessageID,
+			message.ConversationID,
+			message.SenderID,
+			message.Content,
+			message.CreatedAt,
+			ttl,
+		).WithContext(ctx).Exec()
+		if err != nil {
+			return fmt.Errorf("failed to add mention: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *messageRepositoryImpl) RemoveMentions(ctx context.Context, messageID gocql.UUID, userIDs []int64) error {
+	query := `DELETE FROM mentions_by_user WHERE user_id = ? AND message_id = ?`
+
+	for _, userID := range userIDs {
+		if err := r.session.Query(query, userID, messageID).WithContext(ctx).Exec(); err != nil {
+			return fmt.Errorf("failed to remove mention: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *messageRepositoryImpl) GetMentions(ctx context.Context, userID int64, limit int, pagingState []byte) ([]*entity.Message, []byte, error) {
+	query := `SELECT 
+		message_id, conversation_id, sender_id, content, created_at
+	FROM mentions_by_user 
+	WHERE user_id = ?
+	LIMIT ?`
+
+	iter := r.session.Query(query, userID, limit).
+		WithContext(ctx).
+		PageSize(limit).
+		PageState(pagingState).
+		Iter()
+
+	var messages []*entity.Message
+
+	for {
+		message := &entity.Message{}
+
+		if !iter.Scan(
+			&message.MessageID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.Content,
+			&message.CreatedAt,
+		) {
+			break
+		}
+
+		messages = append(messages, message)
+	}
+
+	nextPageState := iter.PageState()
+
+	if err := iter.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get mentions: %w", err)
+	}
+
+	return messages, nextPageState, nil
+}
+
 func (r *messageRepositoryImpl) UpdateDeliveryStatus(ctx context.Context, messageID gocql.UUID, userID int64, status entity.MessageStatus) error {
 	query := `INSERT INTO message_delivery (
 		message_id, user_id, status, delivered_at, read_at
@@ -288,50 +648,166 @@ func (r *messageRepositoryImpl) GetDeliveryStatus(ctx context.Context, messageID
 	return statuses, nil
 }
 
-func (r *messageRepositoryImpl) AddReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string) error {
-	// Insert reaction
+func (r *messageRepositoryImpl) AddReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string, ttl time.Duration) (bool, error) {
+	// Insert reaction; the lightweight transaction makes repeated reactions a no-op.
+	// Reactions to disappearing messages expire with them.
 	query1 := `INSERT INTO message_reactions (message_id, user_id, emoji, created_at) 
-			   VALUES (?, ?, ?, ?)`
+			   VALUES (?, ?, ?, ?) IF NOT EXISTS USING TTL ?`
 
-	if err := r.session.Query(query1, messageID, userID, emoji, time.Now()).
-		WithContext(ctx).Exec(); err != nil {
-		return fmt.Errorf("failed to add reaction: %w", err)
+	applied, err := r.session.Query(query1, messageID, userID, emoji, time.Now(), ttlSeconds(ttl)).
+		WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+	if err != nil {
+		return false, fmt.Errorf("failed to add reaction: %w", err)
+	}
+	if !applied {
+		return false, nil
 	}
 
-	// Increment counter
-	query2 := `UPDATE reaction_counts SET count = count + 1 
-			   WHERE message_id = ? AND emoji = ?`
+	query2 := `INSERT INTO reactions_by_user (user_id, message_id, emoji, created_at)
+			   VALUES (?, ?, ?, ?) USING TTL ?`
 
-	if err := r.session.Query(query2, messageID, emoji).
+	if err := r.session.Query(query2, userID, messageID, emoji, time.Now(), ttlSeconds(ttl)).
 		WithContext(ctx).Exec(); err != nil {
-		return fmt.Errorf("failed to update reaction count: %w", err)
+		return true, fmt.Errorf("failed to index reaction: %w", err)
 	}
 
-	return nil
+	return true, nil
 }
 
-func (r *messageRepositoryImpl) RemoveReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string) error {
-	// Delete reaction
+func (r *messageRepositoryImpl) RemoveReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string) (bool, error) {
+	// Delete reaction; only an existing reaction is counted down
 	query1 := `DELETE FROM message_reactions 
-			   WHERE message_id = ? AND user_id = ? AND emoji = ?`
+			   WHERE message_id = ? AND user_id = ? AND emoji = ? IF EXISTS`
 
-	if err := r.session.Query(query1, messageID, userID, emoji).
-		WithContext(ctx).Exec(); err != nil {
-		return fmt.Errorf("failed to remove reaction: %w", err)
+	applied, err := r.session.Query(query1, messageID, userID, emoji).
+		WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	if !applied {
+		return false, nil
 	}
 
-	// Decrement counter
-	query2 := `UPDATE reaction_counts SET count = count - 1 
-			   WHERE message_id = ? AND emoji = ?`
+	query2 := `DELETE FROM reactions_by_user WHERE user_id = ? AND message_id = ? AND emoji = ?`
 
-	if err := r.session.Query(query2, messageID, emoji).
+	if err := r.session.Query(query2, userID, messageID, emoji).
 		WithContext(ctx).Exec(); err != nil {
-		return fmt.Errorf("failed to update reaction count: %w", err)
+		return true, fmt.Errorf("failed to unindex reaction: %w", err)
+	}
+
+	// Forget the single reaction choice, unless it was replaced meanwhile
+	query3 := `DELETE FROM reaction_choices
+			   WHERE message_id = ? AND user_id = ? IF emoji = ?`
+
+	if _, err := r.session.Query(query3, messageID, userID, emoji).
+		WithContext(ctx).MapScanCAS(make(map[string]interface{})); err != nil {
+		return true, fmt.Errorf("failed to clear reaction choice: %w", err)
 	}
 
+	return true, nil
+}
+
+func (r *messageRepositoryImpl) ReplaceReaction(ctx context.Context, messageID gocql.UUID, userID int64, emoji string, ttl time.Duration) ([]string, bool, error) {
+	for attempt := 0; attempt < maxReplaceAttempts; attempt++ {
+		// The user's choice row orders their replacements: only the writer whose
+		// lightweight transaction sees the version it read gets to write
+		var current string
+		var version int64
+		err := r.session.Query(`SELECT emoji, version FROM reaction_choices WHERE message_id = ? AND user_id = ?`,
+			messageID, userID).WithContext(ctx).Scan(&current, &version)
+		if err != nil && err != gocql.ErrNotFound {
+			return nil, false, fmt.Errorf("failed to get reaction choice: %w", err)
+		}
+		exists := err == nil
+
+		if exists && current == emoji {
+			// Rewrite at the winning version so an interrupted replacement is repaired
+			if err := r.writeChoice(ctx, messageID, userID, emoji, nil, ttl, version); err != nil {
+				return nil, false, err
+			}
+			return nil, false, nil
+		}
+
+		next := time.Now().UnixMicro()
+		if next <= version {
+			next = version + 1
+		}
+
+		var applied bool
+		if exists {
+			applied, err = r.session.Query(`UPDATE reaction_choices USING TTL ? SET emoji = ?, version = ?
+			   WHERE message_id = ? AND user_id = ? IF version = ?`,
+				ttlSeconds(ttl), emoji, next, messageID, userID, version).
+				WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+		} else {
+			applied, err = r.session.Query(`INSERT INTO reaction_choices (message_id, user_id, emoji, version)
+			   VALUES (?, ?, ?, ?) IF NOT EXISTS USING TTL ?`,
+				messageID, userID, emoji, next, ttlSeconds(ttl)).
+				WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to replace reaction choice: %w", err)
+		}
+		if !applied {
+			continue // Another replacement won; read its result and try again
+		}
+
+		// Remove whatever the user reacted with before, including the choice we
+		// replaced even if its reaction row has not been written yet
+		replaced, err := r.GetUserReactions(ctx, messageID, userID)
+		if err != nil {
+			return nil, false, err
+		}
+		if exists {
+			replaced = append(replaced, current)
+		}
+
+		if err := r.writeChoice(ctx, messageID, userID, emoji, replaced, ttl, next); err != nil {
+			return nil, false, err
+		}
+		return uniqueExcept(replaced, emoji), true, nil
+	}
+
+	return nil, false, fmt.Errorf("failed to replace reaction: too many concurrent replacements")
+}
+
+// writeChoice applies a won reaction choice at its version. Writes carry the
+// version as their timestamp, so a slower, older replacement can never
+// resurrect a reaction that a newer one removed.
+func (r *messageRepositoryImpl) writeChoice(ctx context.Context, messageID gocql.UUID, userID int64, emoji string, replaced []string, ttl time.Duration, version int64) error {
+	batch := r.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+
+	for _, old := range uniqueExcept(replaced, emoji) {
+		batch.Query(`DELETE FROM message_reactions USING TIMESTAMP ?
+			   WHERE message_id = ? AND user_id = ? AND emoji = ?`, version, messageID, userID, old)
+		batch.Query(`DELETE FROM reactions_by_user USING TIMESTAMP ?
+			   WHERE user_id = ? AND message_id = ? AND emoji = ?`, version, userID, messageID, old)
+	}
+
+	createdAt := time.UnixMicro(version)
+	batch.Query(`INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+			   VALUES (?, ?, ?, ?) USING TTL ? AND TIMESTAMP ?`, messageID, userID, emoji, createdAt, ttlSeconds(ttl), version)
+	batch.Query(`INSERT INTO reactions_by_user (user_id, message_id, emoji, created_at)
+			   VALUES (?, ?, ?, ?) USING TTL ? AND TIMESTAMP ?`, userID, messageID, emoji, createdAt, ttlSeconds(ttl), version)
+
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to replace reaction: %w", err)
+	}
 	return nil
 }
 
+func uniqueExcept(values []string, except string) []string {
+	seen := map[string]bool{except: true}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
 func (r *messageRepositoryImpl) GetReactions(ctx context.Context, messageID gocql.UUID) (map[string][]int64, error) {
 	query := `SELECT emoji, user_id FROM message_reactions WHERE message_id = ?`
 
@@ -359,8 +835,34 @@ func (r *messageRepositoryImpl) GetReactions(ctx context.Context, messageID gocq
 	return reactions, nil
 }
 
+func (r *messageRepositoryImpl) GetUserReactions(ctx context.Context, messageID gocql.UUID, userID int64) ([]string, error) {
+	query := `SELECT emoji FROM message_reactions WHERE message_id = ? AND user_id = ?`
+
+	iter := r.session.Query(query, messageID, userID).
+		WithContext(ctx).
+		Iter()
+
+	var emojis []string
+
+	for {
+		var emoji string
+		if !iter.Scan(&emoji) {
+			break
+		}
+		emojis = append(emojis, emoji)
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to get user reactions: %w", err)
+	}
+
+	return emojis, nil
+}
+
 func (r *messageRepositoryImpl) GetReactionCounts(ctx context.Context, messageID gocql.UUID) (map[string]int64, error) {
-	query := `SELECT emoji, count FROM reaction_counts WHERE message_id = ?`
+	// Counted from the reactions themselves, so counts never drift from them and
+	// drop as reactions to disappearing messages expire
+	query := `SELECT emoji FROM message_reactions WHERE message_id = ?`
 
 	iter := r.session.Query(query, messageID).
 		WithContext(ctx).
@@ -370,13 +872,10 @@ func (r *messageRepositoryImpl) GetReactionCounts(ctx context.Context, messageID
 
 	for {
 		var emoji string
-		var count int64
-
-		if !iter.Scan(&emoji, &count) {
+		if !iter.Scan(&emoji) {
 			break
 		}
-
-		counts[emoji] = count
+		counts[emoji]++
 	}
 
 	if err := iter.Close(); err != nil {
@@ -386,36 +885,119 @@ func (r *messageRepositoryImpl) GetReactionCounts(ctx context.Context, messageID
 	return counts, nil
 }
 
-func (r *messageRepositoryImpl) SetTypingIndicator(ctx context.Context, conversationID int64, userID int64, isTyping bool) error {
-	query := `INSERT INTO typing_indicators (conversation_id, user_id, is_typing, updated_at) 
-			  VALUES (?, ?, ?, ?) USING TTL 10`
+func (r *messageRepositoryImpl) ListMessagesByUser(ctx context.Context, userID int64, limit int, pagingState []byte) ([]*entity.Message, []byte, error) {
+	query := `SELECT message_id, conversation_id, created_at
+	FROM messages_by_user
+	WHERE user_id = ?`
 
-	return r.session.Query(query, conversationID, userID, isTyping, time.Now()).
-		WithContext(ctx).Exec()
+	iter := r.session.Query(query, userID).
+		WithContext(ctx).
+		PageSize(limit).
+		PageState(pagingState).
+		Iter()
+
+	var messages []*entity.Message
+
+	for {
+		message := &entity.Message{SenderID: userID}
+
+		if !iter.Scan(&message.MessageID, &message.ConversationID, &message.CreatedAt) {
+			break
+		}
+
+		messages = append(messages, message)
+	}
+
+	nextPageState := iter.PageState()
+
+	if err := iter.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list messages by user: %w", err)
+	}
+
+	return messages, nextPageState, nil
 }
 
-func (r *messageRepositoryImpl) GetTypingUsers(ctx context.Context, conversationID int64) ([]int64, error) {
-	query := `SELECT user_id FROM typing_indicators 
-			  WHERE conversation_id = ? AND is_typing = true`
+func (r *messageRepositoryImpl) ListReactionsByUser(ctx context.Context, userID int64) ([]entity.UserReaction, error) {
+	query := `SELECT message_id, emoji FROM reactions_by_user WHERE user_id = ?`
 
-	iter := r.session.Query(query, conversationID).
+	iter := r.session.Query(query, userID).
 		WithContext(ctx).
 		Iter()
 
-	var userIDs []int64
+	var reactions []entity.UserReaction
 
 	for {
-		var userID int64
-		if !iter.Scan(&userID) {
+		var reaction entity.UserReaction
+		if !iter.Scan(&reaction.MessageID, &reaction.Emoji) {
 			break
 		}
-		userIDs = append(userIDs, userID)
+		reactions = append(reactions, reaction)
 	}
 
 	if err := iter.Close(); err != nil {
-		return nil, fmt.Errorf("failed to get typing users: %w", err)
+		return nil, fmt.Errorf("failed to list reactions by user: %w", err)
+	}
+
+	return reactions, nil
+}
+
+func (r *messageRepositoryImpl) Tombstone(ctx context.Context, message *entity.Message) error {
+	// IF EXISTS keeps an update from recreating a row that expired meanwhile
+	query := `UPDATE messages USING TTL ? SET
+		content = ?,
+		metadata = ?,
+		mentions = null,
+		entities = null,
+		link_preview = null,
+		updated_at = ?
+	WHERE conversation_id = ? AND message_id = ?
+	IF EXISTS`
+
+	ttl := ttlSeconds(message.RemainingTTL(time.Now()))
+
+	_, err := r.session.Query(query,
+		ttl,
+		message.Content,
+		message.Metadata,
+		message.UpdatedAt,
+		message.ConversationID,
+		message.MessageID,
+	).WithContext(ctx).MapScanCAS(map[string]interface{}{})
+
+	if err != nil {
+		return fmt.Errorf("failed to tombstone message: %w", err)
+	}
+
+	if !message.IsReply() {
+		return nil
+	}
+
+	threadQuery := `UPDATE message_threads USING TTL ? SET content = ?
+	WHERE parent_message_id = ? AND reply_message_id = ?
+	IF EXISTS`
+
+	_, err = r.session.Query(threadQuery, ttl, message.Content, *message.ParentMessageID, message.MessageID).
+		WithContext(ctx).MapScanCAS(map[string]interface{}{})
+
+	if err != nil {
+		return fmt.Errorf("failed to tombstone thread reply: %w", err)
 	}
 
-	return userIDs, nil
+	return nil
 }
 
+func (r *messageRepositoryImpl) DeleteUserIndexes(ctx context.Context, userID int64) error {
+	queries := []string{
+		`DELETE FROM messages_by_user WHERE user_id = ?`,
+		`DELETE FROM mentions_by_user WHERE user_id = ?`,
+		`DELETE FROM reactions_by_user WHERE user_id = ?`,
+	}
+
+	for _, query := range queries {
+		if err := r.session.Query(query, userID).WithContext(ctx).Exec(); err != nil {
+			return fmt.Errorf("failed to delete user indexes: %w", err)
+		}
+	}
+
+	return nil
+}
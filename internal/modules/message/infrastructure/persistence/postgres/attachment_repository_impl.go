@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// attachmentModel maps the message_attachments table
+type attachmentModel struct {
+	StorageKey     string    `gorm:"column:storage_key;primaryKey"`
+	UploaderID     int64     `gorm:"column:uploader_id;not null"`
+	ConversationID int64     `gorm:"column:conversation_id;not null"`
+	ContentType    string    `gorm:"column:content_type;not null"`
+	SizeBytes      int64     `gorm:"column:size_bytes;not null"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+func (attachmentModel) TableName() string {
+	return "message_attachments"
+}
+
+func (m *attachmentModel) toEntity() *entity.Attachment {
+	return &entity.Attachment{
+		StorageKey:     m.StorageKey,
+		UploaderID:     m.UploaderID,
+		ConversationID: m.ConversationID,
+		ContentType:    m.ContentType,
+		SizeBytes:      m.SizeBytes,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+type attachmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a message attachment repository
+func NewAttachmentRepository(db *gorm.DB) repository.AttachmentRepository {
+	return &attachmentRepositoryImpl{db: db}
+}
+
+func (r *attachmentRepositoryImpl) Create(ctx context.Context, attachment *entity.Attachment) error {
+	model := &attachmentModel{
+		StorageKey:     attachment.StorageKey,
+		UploaderID:     attachment.UploaderID,
+		ConversationID: attachment.ConversationID,
+		ContentType:    attachment.ContentType,
+		SizeBytes:      attachment.SizeBytes,
+		CreatedAt:      attachment.CreatedAt,
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to record attachment: %w", err)
+	}
+	return nil
+}
+
+func (r *attachmentRepositoryImpl) GetByKey(ctx context.Context, storageKey string) (*entity.Attachment, error) {
+	var model attachmentModel
+	err := r.db.WithContext(ctx).Where("storage_key = ?", storageKey).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return model.toEntity(), nil
+}
+
+func (r *attachmentRepositoryImpl) ListByUploader(ctx context.Context, uploaderID int64) ([]*entity.Attachment, error) {
+	var models []attachmentModel
+	err := r.db.WithContext(ctx).
+		Where("uploader_id = ?", uploaderID).
+		Order("created_at").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	attachments := make([]*entity.Attachment, 0, len(models))
+	for i := range models {
+		attachments = append(attachments, models[i].toEntity())
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepositoryImpl) Delete(ctx context.Context, storageKey string) error {
+	err := r.db.WithContext(ctx).Where("storage_key = ?", storageKey).Delete(&attachmentModel{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
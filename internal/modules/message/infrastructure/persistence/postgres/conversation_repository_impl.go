@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// conversationSettingsRow is the subset of conversations read by the message module
+type conversationSettingsRow struct {
+	ID                 int64
+	Type               string
+	ForwardingDisabled *bool
+	MessageTTL         *int64
+}
+
+type conversationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewConversationRepository creates a repository over the conversations table
+func NewConversationRepository(db *gorm.DB) repository.ConversationRepository {
+	return &conversationRepositoryImpl{db: db}
+}
+
+func (r *conversationRepositoryImpl) GetSettings(ctx context.Context, conversationID int64) (*entity.ConversationSettings, error) {
+	var rows []conversationSettingsRow
+
+	err := r.db.WithContext(ctx).
+		Table("conversations").
+		Select("id, type, forwarding_disabled, message_ttl").
+		Where("id = ? AND is_deleted = FALSE", conversationID).
+		Limit(1).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation settings: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, entity.ErrConversationNotFound
+	}
+
+	row := rows[0]
+	settings := &entity.ConversationSettings{
+		ConversationID:     row.ID,
+		IsDirect:           row.Type == "DIRECT",
+		ForwardingDisabled: row.ForwardingDisabled != nil && *row.ForwardingDisabled,
+	}
+	if row.MessageTTL != nil {
+		settings.MessageTTL = time.Duration(*row.MessageTTL) * time.Second
+	}
+
+	return settings, nil
+}
+
+func (r *conversationRepositoryImpl) SetMessageTTL(ctx context.Context, conversationID int64, ttl time.Duration) error {
+	result := r.db.WithContext(ctx).
+		Table("conversations").
+		Where("id = ? AND is_deleted = FALSE", conversationID).
+		Updates(map[string]interface{}{
+			"message_ttl": int64(ttl / time.Second),
+			"updated_at":  time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update message TTL: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrConversationNotFound
+	}
+
+	return nil
+}
+
+func (r *conversationRepositoryImpl) Rename(ctx context.Context, conversationID int64, name string) error {
+	result := r.db.WithContext(ctx).
+		Table("conversations").
+		Where("id = ? AND is_deleted = FALSE", conversationID).
+		Updates(map[string]interface{}{
+			"name":       name,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to rename conversation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrConversationNotFound
+	}
+
+	return nil
+}
+
+func (r *conversationRepositoryImpl) FindDirect(ctx context.Context, userID int64, otherID int64) (int64, error) {
+	return findDirect(r.db.WithContext(ctx), userID, otherID)
+}
+
+func (r *conversationRepositoryImpl) CreateDirect(ctx context.Context, creatorID int64, otherID int64) (int64, bool, error) {
+	var conversationID int64
+	created := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serialize creation per pair so two requests cannot both create one
+		low, high := creatorID, otherID
+		if low > high {
+			low, high = high, low
+		}
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fmt.Sprintf("direct:%d:%d", low, high)).Error; err != nil {
+			return fmt.Errorf("failed to lock direct conversation: %w", err)
+		}
+
+		existingID, err := findDirect(tx, creatorID, otherID)
+		if err == nil {
+			conversationID = existingID
+			return nil
+		}
+		if !errors.Is(err, entity.ErrConversationNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		err = tx.Raw(`
+			INSERT INTO conversations (type, creator_id, member_count, created_at, updated_at)
+			VALUES ('DIRECT', ?, 2, ?, ?)
+			RETURNING id`,
+			creatorID, now, now,
+		).Scan(&conversationID).Error
+		if err != nil {
+			return fmt.Errorf("failed to create direct conversation: %w", err)
+		}
+
+		err = tx.Exec(`
+			INSERT INTO conversation_members (conversation_id, user_id, role, joined_at)
+			VALUES (?, ?, ?, ?), (?, ?, ?, ?)`,
+			conversationID, creatorID, string(entity.MemberRoleMember), now,
+			conversationID, otherID, string(entity.MemberRoleMember), now,
+		).Error
+		if err != nil {
+			return fmt.Errorf("failed to add direct conversation members: %w", err)
+		}
+
+		created = true
+		return nil
+	})
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return conversationID, created, nil
+}
+
+// findDirect looks up the direct conversation between two users with db,
+// which may be a transaction
+func findDirect(db *gorm.DB, userID int64, otherID int64) (int64, error) {
+	var ids []int64
+
+	err := db.Raw(`
+		SELECT conversations.id FROM conversations
+		JOIN conversation_members mine ON mine.conversation_id = conversations.id AND mine.user_id = ?
+		JOIN conversation_members theirs ON theirs.conversation_id = conversations.id AND theirs.user_id = ?
+		WHERE conversations.type = 'DIRECT' AND conversations.is_deleted = FALSE
+		ORDER BY conversations.id
+		LIMIT 1`,
+		userID, otherID,
+	).Scan(&ids).Error
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to find direct conversation: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, entity.ErrConversationNotFound
+	}
+
+	return ids[0], nil
+}
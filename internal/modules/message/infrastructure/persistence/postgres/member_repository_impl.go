@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+type memberRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewMemberRepository creates a membership repository over conversation_members
+func NewMemberRepository(db *gorm.DB) repository.MemberRepository {
+	return &memberRepositoryImpl{db: db}
+}
+
+// activeMembers scopes a query to current members of the conversation
+func (r *memberRepositoryImpl) activeMembers(ctx context.Context, conversationID int64) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("conversation_members").
+		Where("conversation_id = ? AND left_at IS NULL", conversationID)
+}
+
+func (r *memberRepositoryImpl) IsMember(ctx context.Context, conversationID int64, userID int64) (bool, error) {
+	var count int64
+
+	err := r.activeMembers(ctx, conversationID).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *memberRepositoryImpl) GetMemberIDs(ctx context.Context, conversationID int64) ([]int64, error) {
+	var userIDs []int64
+
+	err := r.activeMembers(ctx, conversationID).
+		Pluck("user_id", &userIDs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation members: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+func (r *memberRepositoryImpl) GetRole(ctx context.Context, conversationID int64, userID int64) (entity.MemberRole, error) {
+	var roles []*string
+
+	err := r.activeMembers(ctx, conversationID).
+		Where("user_id = ?", userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get member role: %w", err)
+	}
+	if len(roles) == 0 {
+		return "", entity.ErrUserNotInConversation
+	}
+	if roles[0] == nil {
+		return entity.MemberRoleMember, nil
+	}
+
+	return entity.MemberRole(*roles[0]), nil
+}
+
+func (r *memberRepositoryImpl) RecordMessage(ctx context.Context, conversationID int64, senderID int64, messageID string, sentAt time.Time, countsAsUnread bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("conversations").
+			Where("id = ?", conversationID).
+			Updates(map[string]interface{}{
+				"last_message_id": messageID,
+				"last_message_at": sentAt,
+				"updated_at":      time.Now(),
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update last message: %w", err)
+		}
+
+		if !countsAsUnread {
+			return nil
+		}
+
+		err = tx.Table("conversation_members").
+			Where("conversation_id = ? AND user_id <> ? AND left_at IS NULL", conversationID, senderID).
+			UpdateColumn("unread_count", gorm.Expr("unread_count + 1")).Error
+		if err != nil {
+			return fmt.Errorf("failed to update unread counts: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *memberRepositoryImpl) RecordMentions(ctx context.Context, conversationID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	err := r.activeMembers(ctx, conversationID).
+		Where("user_id IN ?", userIDs).
+		UpdateColumn("mention_count", gorm.Expr("mention_count + 1")).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to update mention counts: %w", err)
+	}
+
+	return nil
+}
+
+func (r *memberRepositoryImpl) MarkRead(ctx context.Context, conversationID int64, userID int64, messageID string) error {
+	err := r.activeMembers(ctx, conversationID).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"last_read_message_id": messageID,
+			"last_read_at":         time.Now(),
+			"unread_count":         0,
+			"mention_count":        0,
+		}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to mark as read: %w", err)
+	}
+
+	return nil
+}
+
+func (r *memberRepositoryImpl) AddMembers(ctx context.Context, conversationID int64, userIDs []int64) ([]int64, error) {
+	var added []int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		for _, userID := range userIDs {
+			// Insert new members and bring back former ones; current members are left untouched
+			var rows []int64
+			err := tx.Raw(`
+				INSERT INTO conversation_members (conversation_id, user_id, role, joined_at)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT (conversation_id, user_id) DO UPDATE
+				SET left_at = NULL, role = EXCLUDED.role, joined_at = EXCLUDED.joined_at, unread_count = 0
+				WHERE conversation_members.left_at IS NOT NULL
+				RETURNING user_id`,
+				conversationID, userID, string(entity.MemberRoleMember), now,
+			).Scan(&rows).Error
+			if err != nil {
+				return fmt.Errorf("failed to add member: %w", err)
+			}
+			added = append(added, rows...)
+		}
+
+		return r.refreshMemberCount(tx, conversationID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+func (r *memberRepositoryImpl) RemoveMember(ctx context.Context, conversationID int64, userID int64) (bool, error) {
+	removed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table("conversation_members").
+			Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+			Update("left_at", time.Now())
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove member: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		removed = true
+		return r.refreshMemberCount(tx, conversationID)
+	})
+
+	return removed, err
+}
+
+func (r *memberRepositoryImpl) SetRole(ctx context.Context, conversationID int64, userID int64, role entity.MemberRole) error {
+	result := r.activeMembers(ctx, conversationID).
+		Where("user_id = ?", userID).
+		Update("role", string(role))
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to change member role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrUserNotInConversation
+	}
+
+	return nil
+}
+
+func (r *memberRepositoryImpl) ListMemberships(ctx context.Context, userID int64) ([]*entity.Membership, error) {
+	var rows []struct {
+		ConversationID int64
+		Type           string
+		Name           *string
+		Role           *string
+		JoinedAt       *time.Time
+		LeftAt         *time.Time
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("conversation_members AS m").
+		Select("m.conversation_id, c.type, c.name, m.role, m.joined_at, m.left_at").
+		Joins("JOIN conversations c ON c.id = m.conversation_id").
+		Where("m.user_id = ?", userID).
+		Order("m.joined_at DESC").
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	memberships := make([]*entity.Membership, 0, len(rows))
+	for _, row := range rows {
+		membership := &entity.Membership{
+			ConversationID:   row.ConversationID,
+			ConversationType: row.Type,
+			Role:             entity.MemberRoleMember,
+			JoinedAt:         row.JoinedAt,
+			LeftAt:           row.LeftAt,
+		}
+		if row.Name != nil {
+			membership.ConversationName = *row.Name
+		}
+		if row.Role != nil {
+			membership.Role = entity.MemberRole(*row.Role)
+		}
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, nil
+}
+
+// refreshMemberCount recomputes the denormalized member count of a conversation
+func (r *memberRepositoryImpl) refreshMemberCount(tx *gorm.DB, conversationID int64) error {
+	err := tx.Exec(`
+		UPDATE conversations
+		SET member_count = (
+			SELECT COUNT(*) FROM conversation_members
+			WHERE conversation_id = ? AND left_at IS NULL
+		), updated_at = ?
+		WHERE id = ?`,
+		conversationID, time.Now(), conversationID,
+	).Error
+	if err != nil {
+		return fmt.Errorf("failed to update member count: %w", err)
+	}
+	return nil
+}
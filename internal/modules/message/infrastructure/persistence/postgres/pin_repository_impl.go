@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocql/gocql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// pinnedMessageModel maps the pinned_messages table
+type pinnedMessageModel struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement"`
+	ConversationID int64      `gorm:"not null"`
+	MessageID      string     `gorm:"type:varchar(100);not null"`
+	PinnedBy       int64      `gorm:"column:pinned_by"`
+	PinnedAt       time.Time  `gorm:"column:pinned_at"`
+	ExpiresAt      *time.Time `gorm:"column:expires_at"`
+}
+
+func (pinnedMessageModel) TableName() string {
+	return "pinned_messages"
+}
+
+type pinRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPinRepository creates a pinned message repository
+func NewPinRepository(db *gorm.DB) repository.PinRepository {
+	return &pinRepositoryImpl{db: db}
+}
+
+func (r *pinRepositoryImpl) Add(ctx context.Context, pin *entity.Pin, limit int) (bool, error) {
+	added := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the conversation so concurrent pins cannot exceed the limit
+		var locked []int64
+		err := tx.Table("conversations").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", pin.ConversationID).
+			Pluck("id", &locked).Error
+		if err != nil {
+			return fmt.Errorf("failed to lock conversation: %w", err)
+		}
+		if len(locked) == 0 {
+			return entity.ErrConversationNotFound
+		}
+
+		// Pins of messages that disappeared are dropped so they free their slot
+		err = tx.Where("conversation_id = ? AND expires_at <= ?", pin.ConversationID, time.Now()).
+			Delete(&pinnedMessageModel{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete expired pins: %w", err)
+		}
+
+		var existing int64
+		err = tx.Model(&pinnedMessageModel{}).
+			Where("conversation_id = ? AND message_id = ?", pin.ConversationID, pin.MessageID.String()).
+			Count(&existing).Error
+		if err != nil {
+			return fmt.Errorf("failed to check pin: %w", err)
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		var count int64
+		err = tx.Model(&pinnedMessageModel{}).
+			Where("conversation_id = ?", pin.ConversationID).
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to count pins: %w", err)
+		}
+		if limit > 0 && count >= int64(limit) {
+			return entity.ErrPinLimitReached
+		}
+
+		model := &pinnedMessageModel{
+			ConversationID: pin.ConversationID,
+			MessageID:      pin.MessageID.String(),
+			PinnedBy:       pin.PinnedBy,
+			PinnedAt:       pin.PinnedAt,
+			ExpiresAt:      pin.ExpiresAt,
+		}
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("failed to pin message: %w", err)
+		}
+
+		added = true
+		return nil
+	})
+
+	return added, err
+}
+
+func (r *pinRepositoryImpl) Remove(ctx context.Context, conversationID int64, messageID gocql.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND message_id = ?", conversationID, messageID.String()).
+		Delete(&pinnedMessageModel{})
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to unpin message: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *pinRepositoryImpl) List(ctx context.Context, conversationID int64) ([]*entity.Pin, error) {
+	var models []pinnedMessageModel
+
+	err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND (expires_at IS NULL OR expires_at > ?)", conversationID, time.Now()).
+		Order("pinned_at DESC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned messages: %w", err)
+	}
+
+	pins := make([]*entity.Pin, 0, len(models))
+	for _, model := range models {
+		messageID, err := gocql.ParseUUID(model.MessageID)
+		if err != nil {
+			log.Printf("⚠️  Skipping pin with invalid message ID %q: %v", model.MessageID, err)
+			continue
+		}
+		pins = append(pins, &entity.Pin{
+			ConversationID: model.ConversationID,
+			MessageID:      messageID,
+			PinnedBy:       model.PinnedBy,
+			PinnedAt:       model.PinnedAt,
+			ExpiresAt:      model.ExpiresAt,
+		})
+	}
+
+	return pins, nil
+}
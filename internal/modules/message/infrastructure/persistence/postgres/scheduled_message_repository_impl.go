@@ -0,0 +1,405 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// scheduledMessageModel maps the scheduled_messages table
+type scheduledMessageModel struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement"`
+	ConversationID  int64     `gorm:"not null"`
+	SenderID        int64     `gorm:"not null"`
+	ParentMessageID *string   `gorm:"type:varchar(100)"`
+	Type            string    `gorm:"type:varchar(20);not null"`
+	Content         string    `gorm:"type:text;not null"`
+	Metadata        string    `gorm:"type:jsonb"`
+	Mentions        string    `gorm:"type:jsonb"`
+	Entities        string    `gorm:"type:jsonb"`
+	SendAt          time.Time `gorm:"not null"`
+	Status          string    `gorm:"type:varchar(20)"`
+	ClaimedAt       *time.Time
+	MessageID       *string `gorm:"type:varchar(100)"`
+	FailureReason   *string `gorm:"type:text"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (scheduledMessageModel) TableName() string {
+	return "scheduled_messages"
+}
+
+// editableStatuses are the statuses an author may still change
+var editableStatuses = []string{
+	string(entity.ScheduledStatusPending),
+	string(entity.ScheduledStatusFailed),
+}
+
+type scheduledMessageRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewScheduledMessageRepository creates a scheduled message repository
+func NewScheduledMessageRepository(db *gorm.DB) repository.ScheduledMessageRepository {
+	return &scheduledMessageRepositoryImpl{db: db}
+}
+
+func (r *scheduledMessageRepositoryImpl) Create(ctx context.Context, message *entity.ScheduledMessage) error {
+	model, err := toScheduledModel(message)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create scheduled message: %w", err)
+	}
+
+	message.ID = model.ID
+	return nil
+}
+
+func (r *scheduledMessageRepositoryImpl) GetByID(ctx context.Context, id int64) (*entity.ScheduledMessage, error) {
+	var model scheduledMessageModel
+
+	err := r.db.WithContext(ctx).First(&model, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrScheduledNotFound
+		}
+		return nil, fmt.Errorf("failed to get scheduled message: %w", err)
+	}
+
+	return toScheduledEntity(&model), nil
+}
+
+func (r *scheduledMessageRepositoryImpl) ListBySender(ctx context.Context, senderID int64) ([]*entity.ScheduledMessage, error) {
+	var models []scheduledMessageModel
+
+	err := r.db.WithContext(ctx).
+		Where("sender_id = ? AND status IN ?", senderID, []string{
+			string(entity.ScheduledStatusPending),
+			string(entity.ScheduledStatusSending),
+			string(entity.ScheduledStatusFailed),
+		}).
+		Order("send_at ASC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled messages: %w", err)
+	}
+
+	return toScheduledEntities(models), nil
+}
+
+func (r *scheduledMessageRepositoryImpl) Update(ctx context.Context, message *entity.ScheduledMessage) error {
+	var failureReason *string
+	if message.FailureReason != "" {
+		failureReason = &message.FailureReason
+	}
+
+	mentions, err := encodeScheduledMentions(message.Mentions)
+	if err != nil {
+		return err
+	}
+	entities, err := encodeScheduledEntities(message.Entities)
+	if err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&scheduledMessageModel{}).
+		Where("id = ? AND status IN ?", message.ID, editableStatuses).
+		Updates(map[string]interface{}{
+			"content":        message.Content,
+			"mentions":       mentions,
+			"entities":       entities,
+			"send_at":        message.SendAt,
+			"status":         string(message.Status),
+			"failure_reason": failureReason,
+			"updated_at":     message.UpdatedAt,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update scheduled message: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrScheduledNotEditable
+	}
+
+	return nil
+}
+
+func (r *scheduledMessageRepositoryImpl) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*entity.ScheduledMessage, error) {
+	var models []scheduledMessageModel
+
+	// SKIP LOCKED lets replicas claim disjoint batches without waiting on each other
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE scheduled_messages
+		SET status = ?, claimed_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM scheduled_messages
+			WHERE status = ? AND send_at <= ?
+			ORDER BY send_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		string(entity.ScheduledStatusSending), now, now,
+		string(entity.ScheduledStatusPending), now, limit,
+	).Scan(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim scheduled messages: %w", err)
+	}
+
+	return toScheduledEntities(models), nil
+}
+
+func (r *scheduledMessageRepositoryImpl) AssignMessageID(ctx context.Context, id int64, messageID gocql.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&scheduledMessageModel{}).
+		Where("id = ? AND status = ?", id, string(entity.ScheduledStatusSending)).
+		Updates(map[string]interface{}{
+			"message_id": messageID.String(),
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to assign scheduled message ID: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrScheduledNotEditable
+	}
+
+	return nil
+}
+
+func (r *scheduledMessageRepositoryImpl) MarkSent(ctx context.Context, id int64, messageID gocql.UUID) error {
+	err := r.db.WithContext(ctx).
+		Model(&scheduledMessageModel{}).
+		Where("id = ? AND status = ?", id, string(entity.ScheduledStatusSending)).
+		Updates(map[string]interface{}{
+			"status":     string(entity.ScheduledStatusSent),
+			"message_id": messageID.String(),
+			"updated_at": time.Now(),
+		}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled message as sent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scheduledMessageRepositoryImpl) MarkFailed(ctx context.Context, id int64, reason string) error {
+	err := r.db.WithContext(ctx).
+		Model(&scheduledMessageModel{}).
+		Where("id = ? AND status = ?", id, string(entity.ScheduledStatusSending)).
+		Updates(map[string]interface{}{
+			"status":         string(entity.ScheduledStatusFailed),
+			"message_id":     nil,
+			"failure_reason": reason,
+			"updated_at":     time.Now(),
+		}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled message as failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scheduledMessageRepositoryImpl) ListStale(ctx context.Context, claimedBefore time.Time, limit int) ([]*entity.ScheduledMessage, error) {
+	var models []scheduledMessageModel
+
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND claimed_at < ?", string(entity.ScheduledStatusSending), claimedBefore).
+		Order("claimed_at ASC").
+		Limit(limit).
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale scheduled messages: %w", err)
+	}
+
+	return toScheduledEntities(models), nil
+}
+
+func (r *scheduledMessageRepositoryImpl) DeleteBySender(ctx context.Context, senderID int64) error {
+	err := r.db.WithContext(ctx).
+		Where("sender_id = ?", senderID).
+		Delete(&scheduledMessageModel{}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled messages: %w", err)
+	}
+
+	return nil
+}
+
+func toScheduledModel(message *entity.ScheduledMessage) (*scheduledMessageModel, error) {
+	metadata, err := json.Marshal(message.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	mentions, err := encodeScheduledMentions(message.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := encodeScheduledEntities(message.Entities)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &scheduledMessageModel{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Type:           string(message.Type),
+		Content:        message.Content,
+		Metadata:       string(metadata),
+		Mentions:       mentions,
+		Entities:       entities,
+		SendAt:         message.SendAt,
+		Status:         string(message.Status),
+		CreatedAt:      message.CreatedAt,
+		UpdatedAt:      message.UpdatedAt,
+	}
+
+	if message.ParentMessageID != nil {
+		parentID := message.ParentMessageID.String()
+		model.ParentMessageID = &parentID
+	}
+
+	return model, nil
+}
+
+func toScheduledEntity(model *scheduledMessageModel) *entity.ScheduledMessage {
+	message := &entity.ScheduledMessage{
+		ID:             model.ID,
+		ConversationID: model.ConversationID,
+		SenderID:       model.SenderID,
+		Type:           entity.MessageType(model.Type),
+		Content:        model.Content,
+		Metadata:       make(map[string]string),
+		SendAt:         model.SendAt,
+		Status:         entity.ScheduledStatus(model.Status),
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+
+	if model.Metadata != "" {
+		_ = json.Unmarshal([]byte(model.Metadata), &message.Metadata)
+	}
+	if model.Mentions != "" {
+		var records []scheduledMention
+		if err := json.Unmarshal([]byte(model.Mentions), &records); err == nil {
+			for _, record := range records {
+				message.Mentions = append(message.Mentions, entity.Mention{
+					Type:   entity.MentionType(record.Type),
+					UserID: record.UserID,
+					Offset: record.Offset,
+					Length: record.Length,
+				})
+			}
+		}
+	}
+	if model.Entities != "" {
+		var records []scheduledEntity
+		if err := json.Unmarshal([]byte(model.Entities), &records); err == nil {
+			for _, record := range records {
+				message.Entities = append(message.Entities, entity.TextEntity{
+					Type:   entity.TextEntityType(record.Type),
+					Offset: record.Offset,
+					Length: record.Length,
+					URL:    record.URL,
+				})
+			}
+		}
+	}
+	if model.ParentMessageID != nil {
+		if parentID, err := gocql.ParseUUID(*model.ParentMessageID); err == nil {
+			message.ParentMessageID = &parentID
+		}
+	}
+	if model.MessageID != nil {
+		if messageID, err := gocql.ParseUUID(*model.MessageID); err == nil {
+			message.MessageID = &messageID
+		}
+	}
+	if model.FailureReason != nil {
+		message.FailureReason = *model.FailureReason
+	}
+
+	return message
+}
+
+// scheduledMention is the stored form of a mention
+type scheduledMention struct {
+	Type   string `json:"type"`
+	UserID int64  `json:"user_id,omitempty"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+func encodeScheduledMentions(mentions []entity.Mention) (string, error) {
+	records := make([]scheduledMention, 0, len(mentions))
+	for _, mention := range mentions {
+		records = append(records, scheduledMention{
+			Type:   string(mention.Type),
+			UserID: mention.UserID,
+			Offset: mention.Offset,
+			Length: mention.Length,
+		})
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode mentions: %w", err)
+	}
+	return string(data), nil
+}
+
+// scheduledEntity is the stored form of a formatting entity
+type scheduledEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
+}
+
+func encodeScheduledEntities(entities []entity.TextEntity) (string, error) {
+	records := make([]scheduledEntity, 0, len(entities))
+	for _, span := range entities {
+		records = append(records, scheduledEntity{
+			Type:   string(span.Type),
+			Offset: span.Offset,
+			Length: span.Length,
+			URL:    span.URL,
+		})
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode text entities: %w", err)
+	}
+	return string(data), nil
+}
+
+func toScheduledEntities(models []scheduledMessageModel) []*entity.ScheduledMessage {
+	messages := make([]*entity.ScheduledMessage, 0, len(models))
+	for i := range models {
+		messages = append(messages, toScheduledEntity(&models[i]))
+	}
+	return messages
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+// Language used when a user has none set
+const defaultLanguage = "en"
+
+type userDirectoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserDirectory creates a read-only view of the users table
+func NewUserDirectory(db *gorm.DB) repository.UserDirectory {
+	return &userDirectoryImpl{db: db}
+}
+
+func (r *userDirectoryImpl) GetLanguage(ctx context.Context, userID int64) (string, error) {
+	var languages []*string
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ? AND is_deleted = FALSE", userID).
+		Limit(1).
+		Pluck("language", &languages).Error
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get user language: %w", err)
+	}
+	if len(languages) == 0 {
+		return "", entity.ErrUserNotFound
+	}
+	if languages[0] == nil || *languages[0] == "" {
+		return defaultLanguage, nil
+	}
+
+	return *languages[0], nil
+}
+
+func (r *userDirectoryImpl) GetUsernames(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	usernames := make(map[int64]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return usernames, nil
+	}
+
+	var rows []struct {
+		ID       int64
+		Username string
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, username").
+		Where("id IN ? AND is_deleted = FALSE", userIDs).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usernames: %w", err)
+	}
+
+	for _, row := range rows {
+		usernames[row.ID] = row.Username
+	}
+
+	return usernames, nil
+}
+
+func (r *userDirectoryImpl) AcceptsDirectMessages(ctx context.Context, recipientID int64, senderID int64) (bool, error) {
+	var accepts []bool
+
+	err := r.db.WithContext(ctx).
+		Raw(`
+			SELECT NOT users.only_friends_can_message OR EXISTS (
+				SELECT 1 FROM friendships
+				WHERE status = 'ACCEPTED'
+				AND ((requester_id = users.id AND addressee_id = ?) OR (requester_id = ? AND addressee_id = users.id))
+			)
+			FROM users
+			WHERE id = ? AND is_deleted = FALSE`,
+			senderID, senderID, recipientID,
+		).
+		Scan(&accepts).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check direct message setting: %w", err)
+	}
+	if len(accepts) == 0 {
+		return false, entity.ErrUserNotFound
+	}
+
+	return accepts[0], nil
+}
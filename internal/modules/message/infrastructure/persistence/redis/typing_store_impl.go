@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+const (
+	// Conversations that may have typing users, scanned by Expire
+	activeTypingKey = "typing:active"
+
+	// How long the per-user index outlives the last typing update
+	typingIndexTTL = time.Minute
+)
+
+// expireScript removes expired typing users from one conversation and drops
+// the conversation from the active set once it is empty, in one step so a
+// concurrent Start is never left out of the active set.
+// KEYS are the conversation set and the active set; ARGV is now in
+// milliseconds and the conversation ID.
+var expireScript = goredis.NewScript(`
+local expired = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+if #expired > 0 then
+	redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+end
+if redis.call("ZCARD", KEYS[1]) == 0 then
+	redis.call("SREM", KEYS[2], ARGV[2])
+end
+return expired
+`)
+
+type typingStoreImpl struct {
+	client *goredis.Client
+}
+
+// NewTypingStore creates a Redis typing store. Each conversation is a sorted
+// set of typing user IDs scored by expiry time in milliseconds.
+func NewTypingStore(client *goredis.Client) repository.TypingStore {
+	return &typingStoreImpl{client: client}
+}
+
+func typingKey(conversationID int64) string {
+	return fmt.Sprintf("typing:%d", conversationID)
+}
+
+func typingUserKey(userID int64) string {
+	return fmt.Sprintf("typing:user:%d", userID)
+}
+
+func (s *typingStoreImpl) Start(ctx context.Context, conversationID int64, userID int64, expiresAt time.Time) (bool, error) {
+	pipe := s.client.TxPipeline()
+	added := pipe.ZAdd(ctx, typingKey(conversationID), goredis.Z{
+		Score:  float64(expiresAt.UnixMilli()),
+		Member: userID,
+	})
+	pipe.SAdd(ctx, activeTypingKey, conversationID)
+	pipe.SAdd(ctx, typingUserKey(userID), conversationID)
+	pipe.Expire(ctx, typingUserKey(userID), typingIndexTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to start typing: %w", err)
+	}
+
+	return added.Val() > 0, nil
+}
+
+func (s *typingStoreImpl) Stop(ctx context.Context, conversationID int64, userID int64) (bool, error) {
+	pipe := s.client.TxPipeline()
+	removed := pipe.ZRem(ctx, typingKey(conversationID), userID)
+	pipe.SRem(ctx, typingUserKey(userID), conversationID)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to stop typing: %w", err)
+	}
+
+	return removed.Val() > 0, nil
+}
+
+func (s *typingStoreImpl) StopAll(ctx context.Context, userID int64) ([]int64, error) {
+	members, err := s.client.SMembers(ctx, typingUserKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get typing conversations: %w", err)
+	}
+
+	var stopped []int64
+	for _, member := range members {
+		conversationID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		removed, err := s.client.ZRem(ctx, typingKey(conversationID), userID).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stop typing: %w", err)
+		}
+		if removed > 0 {
+			stopped = append(stopped, conversationID)
+		}
+	}
+
+	s.client.Del(ctx, typingUserKey(userID))
+	return stopped, nil
+}
+
+func (s *typingStoreImpl) List(ctx context.Context, conversationID int64) ([]int64, error) {
+	members, err := s.client.ZRangeByScore(ctx, typingKey(conversationID), &goredis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get typing users: %w", err)
+	}
+
+	return parseIDs(members), nil
+}
+
+func (s *typingStoreImpl) Expire(ctx context.Context, now time.Time) (map[int64][]int64, error) {
+	conversations, err := s.client.SMembers(ctx, activeTypingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active typing conversations: %w", err)
+	}
+
+	expired := make(map[int64][]int64)
+	for _, conversationID := range parseIDs(conversations) {
+		// Only the node whose script removes an entry reports it
+		members, err := expireScript.Run(ctx, s.client,
+			[]string{typingKey(conversationID), activeTypingKey},
+			now.UnixMilli(), conversationID,
+		).StringSlice()
+		if err != nil {
+			return nil, fmt.Errorf("failed to expire typing users: %w", err)
+		}
+
+		if userIDs := parseIDs(members); len(userIDs) > 0 {
+			expired[conversationID] = userIDs
+		}
+	}
+
+	return expired, nil
+}
+
+func parseIDs(values []string) []int64 {
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
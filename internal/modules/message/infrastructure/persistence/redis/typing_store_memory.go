@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
+)
+
+type memoryTypingStore struct {
+	// conversationID -> userID -> expiry
+	typing map[int64]map[int64]time.Time
+	mu     sync.Mutex
+}
+
+// NewMemoryTypingStore creates an in-process typing store used when Redis is unavailable.
+// It only sees typing users connected to the local node.
+func NewMemoryTypingStore() repository.TypingStore {
+	return &memoryTypingStore{
+		typing: make(map[int64]map[int64]time.Time),
+	}
+}
+
+func (s *memoryTypingStore) Start(ctx context.Context, conversationID int64, userID int64, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, ok := s.typing[conversationID]
+	if !ok {
+		users = make(map[int64]time.Time)
+		s.typing[conversationID] = users
+	}
+
+	_, existed := users[userID]
+	users[userID] = expiresAt
+
+	return !existed, nil
+}
+
+func (s *memoryTypingStore) Stop(ctx context.Context, conversationID int64, userID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.remove(conversationID, userID), nil
+}
+
+func (s *memoryTypingStore) StopAll(ctx context.Context, userID int64) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stopped []int64
+	for conversationID := range s.typing {
+		if s.remove(conversationID, userID) {
+			stopped = append(stopped, conversationID)
+		}
+	}
+
+	return stopped, nil
+}
+
+func (s *memoryTypingStore) List(ctx context.Context, conversationID int64) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var userIDs []int64
+	for userID, expiresAt := range s.typing[conversationID] {
+		if expiresAt.After(now) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (s *memoryTypingStore) Expire(ctx context.Context, now time.Time) (map[int64][]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := make(map[int64][]int64)
+	for conversationID, users := range s.typing {
+		for userID, expiresAt := range users {
+			if !expiresAt.After(now) {
+				expired[conversationID] = append(expired[conversationID], userID)
+				s.remove(conversationID, userID)
+			}
+		}
+	}
+
+	return expired, nil
+}
+
+func (s *memoryTypingStore) remove(conversationID int64, userID int64) bool {
+	users, ok := s.typing[conversationID]
+	if !ok {
+		return false
+	}
+
+	_, existed := users[userID]
+	delete(users, userID)
+	if len(users) == 0 {
+		delete(s.typing, conversationID)
+	}
+
+	return existed
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+var ErrInvalidUserID = errors.New("invalid user ID")
+
+type ConversationHandler struct {
+	conversationService service.ConversationService
+}
+
+func NewConversationHandler(conversationService service.ConversationService) *ConversationHandler {
+	return &ConversationHandler{
+		conversationService: conversationService,
+	}
+}
+
+// OpenDirect godoc
+// @Summary Start a direct conversation
+// @Description Return the direct conversation with a user, starting it if there is none. Users who only accept messages from friends refuse new conversations from anyone else.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.OpenDirectConversationRequest true "User to talk to"
+// @Success 200 {object} response.Response{data=dto.DirectConversationResponse}
+// @Success 201 {object} response.Response{data=dto.DirectConversationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /conversations/direct [post]
+func (h *ConversationHandler) OpenDirect(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.OpenDirectConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.UserID <= 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", ErrInvalidUserID)
+		return
+	}
+
+	conversation, err := h.conversationService.OpenDirect(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, "Failed to open direct conversation", err)
+		return
+	}
+
+	if conversation.Created {
+		response.Success(c, http.StatusCreated, "Conversation created successfully", conversation)
+		return
+	}
+	response.Success(c, http.StatusOK, "Conversation retrieved successfully", conversation)
+}
+
+// AddMembers godoc
+// @Summary Add members to a group
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param request body dto.AddMembersRequest true "Add members request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /conversations/{id}/members [post]
+func (h *ConversationHandler) AddMembers(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.conversationService.AddMembers(c.Request.Context(), conversationID, userID, req); err != nil {
+		respondError(c, "Failed to add members", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Members added successfully", nil)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member or leave a group
+// @Description Remove a member from a group; removing yourself leaves the group
+// @Tags conversations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/members/{user_id} [delete]
+func (h *ConversationHandler) RemoveMember(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, targetID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.conversationService.RemoveMember(c.Request.Context(), conversationID, userID, targetID); err != nil {
+		respondError(c, "Failed to remove member", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Member removed successfully", nil)
+}
+
+// ChangeRole godoc
+// @Summary Promote or demote a member
+// @Description Change a member's role; only the owner can do this
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param user_id path int true "User ID"
+// @Param request body dto.ChangeRoleRequest true "Change role request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/members/{user_id}/role [put]
+func (h *ConversationHandler) ChangeRole(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, targetID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.conversationService.ChangeRole(c.Request.Context(), conversationID, userID, targetID, req); err != nil {
+		respondError(c, "Failed to change member role", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Member role changed successfully", nil)
+}
+
+// Rename godoc
+// @Summary Rename a group
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param request body dto.RenameConversationRequest true "Rename request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/name [put]
+func (h *ConversationHandler) Rename(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RenameConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.conversationService.Rename(c.Request.Context(), conversationID, userID, req); err != nil {
+		respondError(c, "Failed to rename conversation", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Conversation renamed successfully", nil)
+}
+
+func memberParams(c *gin.Context) (int64, int64, bool) {
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", ErrInvalidUserID)
+		return 0, 0, false
+	}
+
+	return conversationID, userID, true
+}
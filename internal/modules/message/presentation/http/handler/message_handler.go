@@ -0,0 +1,795 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocql/gocql"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+var (
+	ErrInvalidConversationID = errors.New("invalid conversation ID")
+	ErrInvalidMessageID      = errors.New("invalid message ID")
+	ErrInvalidScheduledID    = errors.New("invalid scheduled message ID")
+)
+
+// Room for the multipart framing around an uploaded file
+const multipartOverhead = 64 << 10
+
+type MessageHandler struct {
+	messageService     service.MessageService
+	scheduledService   service.ScheduledMessageService
+	maxAttachmentBytes int64
+}
+
+func NewMessageHandler(messageService service.MessageService, scheduledService service.ScheduledMessageService, maxAttachmentBytes int64) *MessageHandler {
+	return &MessageHandler{
+		messageService:     messageService,
+		scheduledService:   scheduledService,
+		maxAttachmentBytes: maxAttachmentBytes,
+	}
+}
+
+// SendMessage godoc
+// @Summary Send a message
+// @Description Send a message to a conversation the caller is a member of. With send_at the message is scheduled instead. Media messages reference their file by the attachment_key returned by POST /conversations/{id}/attachments; metadata keys the server sets, such as attachment_key and forwarded_from_*, are rejected.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SendMessageRequest true "Send message request"
+// @Success 201 {object} response.Response{data=dto.MessageResponse}
+// @Success 202 {object} response.Response{data=dto.ScheduledMessageResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /messages [post]
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.SendAt != nil {
+		scheduled, err := h.scheduledService.Schedule(c.Request.Context(), userID, req)
+		if err != nil {
+			respondError(c, "Failed to schedule message", err)
+			return
+		}
+
+		response.Success(c, http.StatusAccepted, "Message scheduled successfully", scheduled)
+		return
+	}
+
+	message, err := h.messageService.SendMessage(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, "Failed to send message", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Message sent successfully", message)
+}
+
+// GetMessages godoc
+// @Summary List conversation messages
+// @Description Get messages of a conversation, newest first
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param limit query int false "Page size" default(50)
+// @Param page_state query string false "Opaque page state from the previous page"
+// @Param localize query bool false "Render system messages in the caller's language"
+// @Success 200 {object} response.Response{data=dto.MessageListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/messages [get]
+func (h *MessageHandler) GetMessages(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	localize, _ := strconv.ParseBool(c.DefaultQuery("localize", "false"))
+
+	messages, err := h.messageService.GetConversationMessages(c.Request.Context(), conversationID, userID, limit, c.Query("page_state"), localize)
+	if err != nil {
+		respondError(c, "Failed to get messages", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Messages retrieved successfully", messages)
+}
+
+// GetMentions godoc
+// @Summary List mentions
+// @Description Get the messages that mention the caller, newest first
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size" default(50)
+// @Param page_state query string false "Opaque page state from the previous page"
+// @Success 200 {object} response.Response{data=dto.MentionListResponse}
+// @Failure 400 {object} response.Response
+// @Router /mentions [get]
+func (h *MessageHandler) GetMentions(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	mentions, err := h.messageService.GetMentions(c.Request.Context(), userID, limit, c.Query("page_state"))
+	if err != nil {
+		respondError(c, "Failed to get mentions", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Mentions retrieved successfully", mentions)
+}
+
+// EditMessage godoc
+// @Summary Edit a message
+// @Description Edit the content of the caller's own text message
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Param request body dto.EditMessageRequest true "Edit message request"
+// @Success 200 {object} response.Response{data=dto.MessageResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/messages/{message_id} [put]
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	message, err := h.messageService.EditMessage(c.Request.Context(), conversationID, messageID, userID, req)
+	if err != nil {
+		respondError(c, "Failed to edit message", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Message edited successfully", message)
+}
+
+// DeleteMessage godoc
+// @Summary Delete a message
+// @Description Delete the caller's own message (soft delete)
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/messages/{message_id} [delete]
+func (h *MessageHandler) DeleteMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.DeleteMessage(c.Request.Context(), conversationID, messageID, userID); err != nil {
+		respondError(c, "Failed to delete message", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Message deleted successfully", nil)
+}
+
+// AddReaction godoc
+// @Summary React to a message
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Param request body dto.ReactToMessageRequest true "Reaction"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/messages/{message_id}/reactions [post]
+func (h *MessageHandler) AddReaction(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReactToMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.messageService.AddReaction(c.Request.Context(), conversationID, messageID, userID, req.Emoji); err != nil {
+		respondError(c, "Failed to add reaction", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Reaction added successfully", nil)
+}
+
+// RemoveReaction godoc
+// @Summary Remove a reaction
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Param emoji path string true "Emoji"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/messages/{message_id}/reactions/{emoji} [delete]
+func (h *MessageHandler) RemoveReaction(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.RemoveReaction(c.Request.Context(), conversationID, messageID, userID, c.Param("emoji")); err != nil {
+		respondError(c, "Failed to remove reaction", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Reaction removed successfully", nil)
+}
+
+// MarkAsRead godoc
+// @Summary Mark a message as read
+// @Description Mark the conversation as read up to this message
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/messages/{message_id}/read [post]
+func (h *MessageHandler) MarkAsRead(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.MarkAsRead(c.Request.Context(), conversationID, messageID, userID); err != nil {
+		respondError(c, "Failed to mark message as read", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Message marked as read", nil)
+}
+
+// SetTyping godoc
+// @Summary Update typing status
+// @Description Prefer the "typing" WebSocket message; this endpoint is for clients without a socket
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param request body dto.TypingIndicatorRequest true "Typing status"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/typing [post]
+func (h *MessageHandler) SetTyping(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.TypingIndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.messageService.SetTyping(c.Request.Context(), conversationID, userID, req.IsTyping); err != nil {
+		respondError(c, "Failed to update typing status", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Typing status updated", nil)
+}
+
+// UploadAttachment godoc
+// @Summary Upload an attachment
+// @Description Upload the file of a media message. Send the returned attachment_key with an IMAGE, VIDEO, AUDIO or FILE message in the same conversation.
+// @Tags messages
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param file formData file true "File"
+// @Success 201 {object} response.Response{data=dto.AttachmentResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Router /conversations/{id}/attachments [post]
+func (h *MessageHandler) UploadAttachment(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxAttachmentBytes+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "Attachment is too large", err)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Missing attachment file", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.maxAttachmentBytes {
+		response.Error(c, http.StatusRequestEntityTooLarge, "Attachment is too large", errors.New("file exceeds the upload limit"))
+		return
+	}
+
+	attachment, err := h.messageService.UploadAttachment(c.Request.Context(), conversationID, userID, file, header.Header.Get("Content-Type"), header.Size)
+	if err != nil {
+		respondError(c, "Failed to upload attachment", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Attachment uploaded successfully", attachment)
+}
+
+// GetTypingUsers godoc
+// @Summary List typing users
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} response.Response{data=dto.TypingUsersResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/typing [get]
+func (h *MessageHandler) GetTypingUsers(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	userIDs, err := h.messageService.GetTypingUsers(c.Request.Context(), conversationID, userID)
+	if err != nil {
+		respondError(c, "Failed to get typing users", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Typing users retrieved successfully", dto.TypingUsersResponse{
+		ConversationID: conversationID,
+		UserIDs:        userIDs,
+	})
+}
+
+// ForwardMessage godoc
+// @Summary Forward a message
+// @Description Forward a message to one or more conversations the caller is a member of
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Param request body dto.ForwardMessageRequest true "Forward message request"
+// @Success 201 {object} response.Response{data=dto.ForwardMessageResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /messages/{id}/forward [post]
+func (h *MessageHandler) ForwardMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	messageID, err := gocql.ParseUUID(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid message ID", ErrInvalidMessageID)
+		return
+	}
+
+	var req dto.ForwardMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	forwarded, err := h.messageService.ForwardMessage(c.Request.Context(), messageID, userID, req)
+	if err != nil {
+		respondError(c, "Failed to forward message", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Message forwarded successfully", forwarded)
+}
+
+// GetScheduledMessages godoc
+// @Summary List scheduled messages
+// @Description Get the caller's messages that are waiting to be sent or failed to send
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]dto.ScheduledMessageResponse}
+// @Router /messages/scheduled [get]
+func (h *MessageHandler) GetScheduledMessages(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.scheduledService.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, "Failed to get scheduled messages", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scheduled messages retrieved successfully", messages)
+}
+
+// UpdateScheduledMessage godoc
+// @Summary Edit a scheduled message
+// @Description Change the content or send time of a scheduled message that has not been sent
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Scheduled message ID"
+// @Param request body dto.UpdateScheduledMessageRequest true "Update scheduled message request"
+// @Success 200 {object} response.Response{data=dto.ScheduledMessageResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /messages/scheduled/{id} [put]
+func (h *MessageHandler) UpdateScheduledMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scheduledParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateScheduledMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	message, err := h.scheduledService.Update(c.Request.Context(), id, userID, req)
+	if err != nil {
+		respondError(c, "Failed to update scheduled message", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scheduled message updated successfully", message)
+}
+
+// CancelScheduledMessage godoc
+// @Summary Cancel a scheduled message
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Scheduled message ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /messages/scheduled/{id} [delete]
+func (h *MessageHandler) CancelScheduledMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scheduledParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduledService.Cancel(c.Request.Context(), id, userID); err != nil {
+		respondError(c, "Failed to cancel scheduled message", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scheduled message canceled successfully", nil)
+}
+
+// SetDisappearingMessages godoc
+// @Summary Set disappearing messages
+// @Description Make new messages in a conversation disappear after a fixed time, or turn it off with 0
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param request body dto.DisappearingMessagesRequest true "Disappearing messages request"
+// @Success 200 {object} response.Response{data=dto.DisappearingMessagesResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/disappearing [put]
+func (h *MessageHandler) SetDisappearingMessages(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.DisappearingMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	setting, err := h.messageService.SetDisappearingMessages(c.Request.Context(), conversationID, userID, req)
+	if err != nil {
+		respondError(c, "Failed to update disappearing messages", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Disappearing messages updated successfully", setting)
+}
+
+// PinMessage godoc
+// @Summary Pin a message
+// @Description Pin a message in a conversation; only owners and admins can pin
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.Response{data=dto.PinnedMessageResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /conversations/{id}/pins/{message_id} [put]
+func (h *MessageHandler) PinMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	pin, err := h.messageService.PinMessage(c.Request.Context(), conversationID, messageID, userID)
+	if err != nil {
+		respondError(c, "Failed to pin message", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Message pinned successfully", pin)
+}
+
+// UnpinMessage godoc
+// @Summary Unpin a message
+// @Description Unpin a message in a conversation; only owners and admins can unpin
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/pins/{message_id} [delete]
+func (h *MessageHandler) UnpinMessage(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, messageID, ok := messageParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.UnpinMessage(c.Request.Context(), conversationID, messageID, userID); err != nil {
+		respondError(c, "Failed to unpin message", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Message unpinned successfully", nil)
+}
+
+// GetPinnedMessages godoc
+// @Summary List pinned messages
+// @Description Get the pinned messages of a conversation, most recently pinned first
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} response.Response{data=[]dto.PinnedMessageResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/pins [get]
+func (h *MessageHandler) GetPinnedMessages(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	pins, err := h.messageService.GetPinnedMessages(c.Request.Context(), conversationID, userID)
+	if err != nil {
+		respondError(c, "Failed to get pinned messages", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Pinned messages retrieved successfully", pins)
+}
+
+func currentUser(c *gin.Context) (int64, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+	}
+	return userID, ok
+}
+
+func conversationParam(c *gin.Context) (int64, bool) {
+	conversationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid conversation ID", ErrInvalidConversationID)
+		return 0, false
+	}
+	return conversationID, true
+}
+
+func messageParams(c *gin.Context) (int64, gocql.UUID, bool) {
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return 0, gocql.UUID{}, false
+	}
+
+	messageID, err := gocql.ParseUUID(c.Param("message_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid message ID", ErrInvalidMessageID)
+		return 0, gocql.UUID{}, false
+	}
+
+	return conversationID, messageID, true
+}
+
+func scheduledParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid scheduled message ID", ErrInvalidScheduledID)
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps domain errors to HTTP status codes
+func respondError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrUserNotInConversation),
+		errors.Is(err, entity.ErrCannotEditMessage),
+		errors.Is(err, entity.ErrCannotDeleteMessage),
+		errors.Is(err, entity.ErrNotConversationAdmin),
+		errors.Is(err, entity.ErrForwardingDisabled),
+		errors.Is(err, entity.ErrNotConversationOwner),
+		errors.Is(err, entity.ErrCannotRemoveOwner),
+		errors.Is(err, entity.ErrMentionAllNotAllowed),
+		errors.Is(err, entity.ErrOnlyFriendsCanMessage):
+		response.Error(c, http.StatusForbidden, message, err)
+	case errors.Is(err, entity.ErrPinLimitReached),
+		errors.Is(err, entity.ErrScheduledNotEditable):
+		response.Error(c, http.StatusConflict, message, err)
+	case errors.Is(err, entity.ErrMessageNotFound),
+		errors.Is(err, entity.ErrConversationNotFound),
+		errors.Is(err, entity.ErrScheduledNotFound),
+		errors.Is(err, entity.ErrUserNotFound),
+		errors.Is(err, entity.ErrAttachmentNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.Is(err, entity.ErrInvalidMessageType),
+		errors.Is(err, entity.ErrEmptyMessageContent),
+		errors.Is(err, entity.ErrInvalidReaction),
+		errors.Is(err, entity.ErrInvalidPageState),
+		errors.Is(err, entity.ErrInvalidSendAt),
+		errors.Is(err, entity.ErrNotGroupConversation),
+		errors.Is(err, entity.ErrInvalidMention),
+		errors.Is(err, entity.ErrInvalidTextEntity),
+		errors.Is(err, entity.ErrCannotMessageSelf),
+		errors.Is(err, entity.ErrReservedMetadata),
+		errors.Is(err, entity.ErrAttachmentNotAllowed):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gorillaws "github.com/gorilla/websocket"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type WebSocketHandler struct {
+	hub      *websocket.Hub
+	upgrader gorillaws.Upgrader
+}
+
+func NewWebSocketHandler(hub *websocket.Hub, readBufferSize, writeBufferSize int) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub: hub,
+		upgrader: gorillaws.Upgrader{
+			ReadBufferSize:  readBufferSize,
+			WriteBufferSize: writeBufferSize,
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Origins are already handled by the CORS policy
+			},
+		},
+	}
+}
+
+// Connect godoc
+// @Summary Open a WebSocket connection
+// @Description Upgrade to a WebSocket. Server events arrive as {"type":"event","id":...,"data":...} and must be acked with {"type":"ack","data":{"id":...}}. Unacked events are replayed after last_event_id on reconnect.
+// @Tags realtime
+// @Param access_token query string true "Access token"
+// @Param last_event_id query string false "Last event ID processed by the client"
+// @Success 101
+// @Failure 401 {object} response.Response
+// @Router /ws [get]
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	sessionID, _ := middleware.GetSessionID(c)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed for user %d: %v", userID, err)
+		return
+	}
+
+	client := websocket.NewClient(h.hub, conn, userID, sessionID)
+	h.hub.Register(client, c.Query("last_event_id"))
+
+	go client.WritePump()
+	go client.ReadPump()
+}
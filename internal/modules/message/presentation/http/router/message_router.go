@@ -0,0 +1,54 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/presentation/http/handler"
+)
+
+// RegisterMessageRoutes registers all message-related routes
+func RegisterMessageRoutes(router *gin.RouterGroup, messageHandler *handler.MessageHandler, conversationHandler *handler.ConversationHandler, auth gin.HandlerFunc) {
+	messages := router.Group("/messages", auth)
+	{
+		messages.POST("", messageHandler.SendMessage)
+		messages.POST("/:id/forward", messageHandler.ForwardMessage)
+
+		messages.GET("/scheduled", messageHandler.GetScheduledMessages)
+		messages.PUT("/scheduled/:id", messageHandler.UpdateScheduledMessage)
+		messages.DELETE("/scheduled/:id", messageHandler.CancelScheduledMessage)
+	}
+
+	router.GET("/mentions", auth, messageHandler.GetMentions)
+
+	router.POST("/conversations/direct", auth, conversationHandler.OpenDirect)
+
+	conversations := router.Group("/conversations/:id", auth)
+	{
+		conversations.GET("/messages", messageHandler.GetMessages)
+		conversations.PUT("/messages/:message_id", messageHandler.EditMessage)
+		conversations.DELETE("/messages/:message_id", messageHandler.DeleteMessage)
+		conversations.POST("/messages/:message_id/reactions", messageHandler.AddReaction)
+		conversations.DELETE("/messages/:message_id/reactions/:emoji", messageHandler.RemoveReaction)
+		conversations.POST("/messages/:message_id/read", messageHandler.MarkAsRead)
+		conversations.POST("/attachments", messageHandler.UploadAttachment)
+
+		conversations.PUT("/disappearing", messageHandler.SetDisappearingMessages)
+
+		conversations.POST("/members", conversationHandler.AddMembers)
+		conversations.DELETE("/members/:user_id", conversationHandler.RemoveMember)
+		conversations.PUT("/members/:user_id/role", conversationHandler.ChangeRole)
+		conversations.PUT("/name", conversationHandler.Rename)
+
+		conversations.GET("/pins", messageHandler.GetPinnedMessages)
+		conversations.PUT("/pins/:message_id", messageHandler.PinMessage)
+		conversations.DELETE("/pins/:message_id", messageHandler.UnpinMessage)
+
+		conversations.GET("/typing", messageHandler.GetTypingUsers)
+		conversations.POST("/typing", messageHandler.SetTyping)
+	}
+}
+
+// RegisterWebSocketRoutes registers the real-time connection endpoint
+func RegisterWebSocketRoutes(router *gin.RouterGroup, wsHandler *handler.WebSocketHandler, auth gin.HandlerFunc) {
+	router.GET("/ws", auth, wsHandler.Connect)
+}
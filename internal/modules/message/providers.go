@@ -1,33 +1,62 @@
 package message
 
 import (
+	"context"
 	"log"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/gocql/gocql"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
 
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/linkpreview"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/repository"
 	messageRepo "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/infrastructure/persistence/cassandra"
+	memberRepo "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/infrastructure/persistence/postgres"
+	typingStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/infrastructure/persistence/redis"
+	messageHandler "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/presentation/http/handler"
+	messageRouter "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/presentation/http/router"
+	notificationService "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/service"
+	presenceService "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
 )
 
-// TODO: Uncomment when handlers are ready
-// import "github.com/gin-gonic/gin"
-
 // Module provides message module dependencies
 var Module = fx.Options(
 	fx.Provide(provideRepository),
-	// TODO: Add when service and handlers are implemented
-	// fx.Provide(provideService),
-	// fx.Provide(provideHandler),
-	// fx.Provide(provideWebSocketHandler),
-	// fx.Provide(
-	// 	fx.Annotate(
-	// 		provideRouteRegistration,
-	// 		fx.ResultTags(`group:"routes"`),
-	// 	),
-	// ),
+	fx.Provide(provideMemberRepository),
+	fx.Provide(providePinRepository),
+	fx.Provide(provideAttachmentRepository),
+	fx.Provide(provideConversationRepository),
+	fx.Provide(provideScheduledRepository),
+	fx.Provide(provideUserDirectory),
+	fx.Provide(provideTypingStore),
+	fx.Provide(provideTypingService),
+	fx.Provide(provideService),
+	fx.Provide(provideScheduledService),
+	fx.Provide(provideConversationService),
+	fx.Provide(provideAccountDataService),
+	fx.Provide(provideHandler),
+	fx.Provide(provideConversationHandler),
+	fx.Provide(provideWebSocketHandler),
+	fx.Provide(
+		fx.Annotate(
+			provideRouteRegistration,
+			fx.ResultTags(`group:"routes"`),
+		),
+	),
+	fx.Invoke(runTypingService),
+	fx.Invoke(runScheduledWorker),
 )
 
-func provideRepository(cassandra *gocql.Session) interface{} {
+func provideRepository(cassandra *gocql.Session) repository.MessageRepository {
 	if cassandra == nil {
 		log.Println("⚠️  Message repository not available (Cassandra not connected)")
 		return nil
@@ -37,27 +66,200 @@ func provideRepository(cassandra *gocql.Session) interface{} {
 	return messageRepo.NewMessageRepository(cassandra)
 }
 
-// TODO: Uncomment when message service is implemented
-/*
+func provideMemberRepository(db *gorm.DB) repository.MemberRepository {
+	log.Println("📦 Creating conversation member repository...")
+	return memberRepo.NewMemberRepository(db)
+}
+
+func providePinRepository(db *gorm.DB) repository.PinRepository {
+	log.Println("📦 Creating pinned message repository...")
+	return memberRepo.NewPinRepository(db)
+}
+
+func provideAttachmentRepository(db *gorm.DB) repository.AttachmentRepository {
+	log.Println("📦 Creating message attachment repository...")
+	return memberRepo.NewAttachmentRepository(db)
+}
+
+func provideConversationRepository(db *gorm.DB) repository.ConversationRepository {
+	log.Println("📦 Creating conversation settings repository...")
+	return memberRepo.NewConversationRepository(db)
+}
+
+func provideScheduledRepository(db *gorm.DB) repository.ScheduledMessageRepository {
+	log.Println("📦 Creating scheduled message repository...")
+	return memberRepo.NewScheduledMessageRepository(db)
+}
+
+func provideUserDirectory(db *gorm.DB) repository.UserDirectory {
+	log.Println("📦 Creating user directory...")
+	return memberRepo.NewUserDirectory(db)
+}
+
+func provideTypingStore(redisClient *redis.Client) repository.TypingStore {
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, typing indicators are tracked for this node only")
+		return typingStore.NewMemoryTypingStore()
+	}
+
+	log.Println("📦 Creating typing store...")
+	return typingStore.NewTypingStore(redisClient)
+}
+
+func provideTypingService(
+	store repository.TypingStore,
+	members repository.MemberRepository,
+	hub *websocket.Hub,
+	presence presenceService.PresenceService,
+	cfg infrastructure.Config,
+) service.TypingService {
+	log.Println("⚙️  Creating typing service...")
+	typingCfg := cfg.GetTypingConfig()
+	return service.NewTypingService(store, members, hub, presence, presence, service.TypingConfig{
+		TTL:                time.Duration(typingCfg.TTL) * time.Second,
+		Throttle:           time.Duration(typingCfg.Throttle) * time.Millisecond,
+		AggregateThreshold: typingCfg.AggregateThreshold,
+	})
+}
+
 func provideService(
 	repo repository.MessageRepository,
+	members repository.MemberRepository,
+	pins repository.PinRepository,
+	conversations repository.ConversationRepository,
+	attachments repository.AttachmentRepository,
+	typing service.TypingService,
 	hub *websocket.Hub,
-	redis *redis.Client,
+	users repository.UserDirectory,
+	blobs storage.Storage,
+	previews linkpreview.Service,
+	pushes notificationService.NotificationService,
+	presence presenceService.PresenceService,
+	cfg infrastructure.Config,
 ) service.MessageService {
 	log.Println("⚙️  Creating message service...")
-	return messageService.NewMessageService(repo, hub, redis)
+	reactionCfg := cfg.GetReactionConfig()
+	return service.NewMessageService(repo, members, pins, conversations, attachments, typing, hub, users, blobs, previews, pushes, presence, service.MessageConfig{
+		Reactions: service.ReactionConfig{
+			SinglePerUser: reactionCfg.SinglePerUser,
+			Allowed:       reactionCfg.Allowed,
+		},
+		MaxPins:                  cfg.GetPinConfig().MaxPerConversation,
+		MentionAllAdminOnlyAbove: cfg.GetMentionConfig().AllAdminOnlyAbove,
+	})
+}
+
+func provideScheduledService(
+	repo repository.ScheduledMessageRepository,
+	members repository.MemberRepository,
+	attachments repository.AttachmentRepository,
+	messages service.MessageService,
+	cfg infrastructure.Config,
+) service.ScheduledMessageService {
+	log.Println("⚙️  Creating scheduled message service...")
+	scheduledCfg := cfg.GetScheduledConfig()
+	return service.NewScheduledMessageService(repo, members, attachments, messages, service.ScheduledConfig{
+		PollInterval: time.Duration(scheduledCfg.PollInterval) * time.Second,
+		BatchSize:    scheduledCfg.BatchSize,
+		ClaimTimeout: time.Duration(scheduledCfg.ClaimTimeout) * time.Second,
+	})
+}
+
+func provideConversationService(
+	members repository.MemberRepository,
+	conversations repository.ConversationRepository,
+	users repository.UserDirectory,
+	messages service.MessageService,
+) service.ConversationService {
+	log.Println("⚙️  Creating conversation service...")
+	return service.NewConversationService(members, conversations, users, messages)
+}
+
+// provideAccountDataService lets the user module export and purge the data of deleted accounts
+func provideAccountDataService(
+	repo repository.MessageRepository,
+	members repository.MemberRepository,
+	scheduled repository.ScheduledMessageRepository,
+	attachments repository.AttachmentRepository,
+	blobs storage.Storage,
+) service.AccountDataService {
+	log.Println("⚙️  Creating account data service...")
+	return service.NewAccountDataService(repo, members, scheduled, attachments, blobs)
 }
 
-func provideHandler(svc service.MessageService) *handler.MessageHandler {
+func provideConversationHandler(svc service.ConversationService) *messageHandler.ConversationHandler {
+	log.Println("🎯 Creating conversation handler...")
+	return messageHandler.NewConversationHandler(svc)
+}
+
+func provideHandler(svc service.MessageService, scheduled service.ScheduledMessageService, cfg infrastructure.Config) *messageHandler.MessageHandler {
 	log.Println("🎯 Creating message handler...")
-	return messageHandler.NewMessageHandler(svc)
+	return messageHandler.NewMessageHandler(svc, scheduled, cfg.GetStorageConfig().MaxAttachmentBytes)
 }
 
-func provideWebSocketHandler(
-	hub *websocket.Hub,
-	svc service.MessageService,
-) *handler.WebSocketHandler {
+func provideWebSocketHandler(hub *websocket.Hub, cfg infrastructure.Config) *messageHandler.WebSocketHandler {
 	log.Println("🔌 Creating WebSocket handler...")
-	return messageHandler.NewWebSocketHandler(hub, svc)
+	wsCfg := cfg.GetWebSocketConfig()
+	return messageHandler.NewWebSocketHandler(hub, wsCfg.ReadBufferSize, wsCfg.WriteBufferSize)
+}
+
+// runTypingService hooks typing into the Hub and runs its expiry loop with the app lifecycle
+func runTypingService(lc fx.Lifecycle, hub *websocket.Hub, typing service.TypingService) {
+	hub.AddObserver(typing)
+	hub.Handle("typing", typing.HandleClientMessage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go typing.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// runScheduledWorker sends due scheduled messages for the app lifetime. Every
+// replica runs it; claiming in the repository keeps sends exactly-once.
+func runScheduledWorker(lc fx.Lifecycle, repo repository.MessageRepository, scheduled service.ScheduledMessageService) {
+	if repo == nil {
+		log.Println("⚠️  Scheduled message worker not started (Cassandra not connected)")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go scheduled.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// provideRouteRegistration returns a function to register message routes
+func provideRouteRegistration(
+	repo repository.MessageRepository,
+	h *messageHandler.MessageHandler,
+	conversationHandler *messageHandler.ConversationHandler,
+	wsHandler *messageHandler.WebSocketHandler,
+	tokens *token.Manager,
+	sessions middleware.SessionChecker,
+) func(*gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		log.Println("✅ Registering message routes...")
+		auth := middleware.Auth(tokens, sessions)
+
+		messageRouter.RegisterWebSocketRoutes(router, wsHandler, auth)
+		if repo == nil {
+			log.Println("⚠️  Skipping message HTTP routes (Cassandra not connected)")
+			return
+		}
+		messageRouter.RegisterMessageRoutes(router, h, conversationHandler, auth)
+	}
 }
-*/
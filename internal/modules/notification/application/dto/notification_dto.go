@@ -0,0 +1,128 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+)
+
+// RegisterDeviceRequest registers a push notification token of the caller's app
+type RegisterDeviceRequest struct {
+	Platform string `json:"platform" validate:"required,oneof=fcm apns"` // fcm for Android and web, apns for iOS
+	Token    string `json:"token" validate:"required,max=4096"`
+}
+
+// DeviceResponse represents a registered device
+type DeviceResponse struct {
+	ID        int64     `json:"id"`
+	Platform  string    `json:"platform"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewDeviceResponse converts domain entity to DTO
+func NewDeviceResponse(device *entity.Device) *DeviceResponse {
+	return &DeviceResponse{
+		ID:        device.ID,
+		Platform:  device.Platform,
+		Token:     device.Token,
+		CreatedAt: device.CreatedAt,
+		UpdatedAt: device.UpdatedAt,
+	}
+}
+
+// MessageNotification describes a new message for the push dispatcher
+type MessageNotification struct {
+	ConversationID   int64
+	MessageID        string
+	SenderID         int64
+	SenderName       string
+	Preview          string
+	MentionedUserIDs []int64 // Notified even when they muted the conversation
+}
+
+// UpdateConversationNotificationsRequest sets how the caller is notified about a conversation
+type UpdateConversationNotificationsRequest struct {
+	Level string `json:"level" validate:"required,oneof=all mentions none"`
+
+	// Only mentions are notified until then; null unmutes
+	MutedUntil *time.Time `json:"muted_until"`
+}
+
+// ConversationNotificationsResponse represents the caller's notifications for a conversation
+type ConversationNotificationsResponse struct {
+	ConversationID int64      `json:"conversation_id"`
+	Level          string     `json:"level"`
+	MutedUntil     *time.Time `json:"muted_until,omitempty"`
+	Muted          bool       `json:"muted"`
+}
+
+// NewConversationNotificationsResponse converts domain entity to DTO
+func NewConversationNotificationsResponse(prefs *entity.ConversationPreferences, now time.Time) *ConversationNotificationsResponse {
+	resp := &ConversationNotificationsResponse{
+		ConversationID: prefs.ConversationID,
+		Level:          string(prefs.Level),
+		Muted:          prefs.IsMuted(now),
+	}
+	// An expired mute is history, not a setting
+	if resp.Muted {
+		resp.MutedUntil = prefs.MutedUntil
+	}
+	return resp
+}
+
+// QuietHours is a daily window without pushes as "HH:MM" in the user's timezone.
+// It spans midnight when End is before Start, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	Start string `json:"start" validate:"required"`
+	End   string `json:"end" validate:"required"`
+}
+
+// UpdateNotificationSettingsRequest replaces the caller's global notification settings
+type UpdateNotificationSettingsRequest struct {
+	Timezone string `json:"timezone" validate:"required,max=64"` // IANA name, e.g. Asia/Ho_Chi_Minh
+
+	// Null turns quiet hours off
+	QuietHours *QuietHours `json:"quiet_hours"`
+
+	// false keeps message content out of pushes, showing only who wrote
+	ShowPreviews *bool `json:"show_previews" validate:"required"`
+}
+
+// NotificationSettingsResponse represents the caller's global notification settings
+type NotificationSettingsResponse struct {
+	Timezone     string      `json:"timezone"`
+	QuietHours   *QuietHours `json:"quiet_hours,omitempty"`
+	ShowPreviews bool        `json:"show_previews"`
+}
+
+// NewNotificationSettingsResponse converts domain entity to DTO
+func NewNotificationSettingsResponse(prefs *entity.UserPreferences) *NotificationSettingsResponse {
+	resp := &NotificationSettingsResponse{
+		Timezone:     prefs.Timezone,
+		ShowPreviews: prefs.ShowPreviews,
+	}
+	if prefs.QuietHoursStart != nil && prefs.QuietHoursEnd != nil {
+		resp.QuietHours = &QuietHours{
+			Start: FormatClock(*prefs.QuietHoursStart),
+			End:   FormatClock(*prefs.QuietHoursEnd),
+		}
+	}
+	return resp
+}
+
+// FormatClock renders minutes after midnight as "HH:MM"
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// ParseClock reads "HH:MM" as minutes after midnight
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, entity.ErrInvalidQuietHours
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/dto"
+)
+
+// NotificationService defines application use cases for push notifications
+type NotificationService interface {
+	// Device tokens
+	RegisterDevice(ctx context.Context, userID int64, req dto.RegisterDeviceRequest) (*dto.DeviceResponse, error)
+	UnregisterDevice(ctx context.Context, userID int64, token string) error
+	ListDevices(ctx context.Context, userID int64) ([]*dto.DeviceResponse, error)
+
+	// Preferences
+	GetConversationNotifications(ctx context.Context, userID int64, conversationID int64) (*dto.ConversationNotificationsResponse, error)
+	UpdateConversationNotifications(ctx context.Context, userID int64, conversationID int64, req dto.UpdateConversationNotificationsRequest) (*dto.ConversationNotificationsResponse, error)
+	GetSettings(ctx context.Context, userID int64) (*dto.NotificationSettingsResponse, error)
+	UpdateSettings(ctx context.Context, userID int64, req dto.UpdateNotificationSettingsRequest) (*dto.NotificationSettingsResponse, error)
+
+	// NotifyMessage queues pushes about a new message for members without a live
+	// connection. It never blocks the caller; pushes are dropped when the queue is full.
+	NotifyMessage(ctx context.Context, notification dto.MessageNotification)
+
+	// Run sends queued and collapsed pushes until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+// PresenceChecker tells which users have a live connection on any node
+type PresenceChecker interface {
+	OnlineUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+}
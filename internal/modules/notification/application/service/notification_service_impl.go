@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/push"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+const (
+	// Time allowed to dispatch one message or one batch of collapsed pushes
+	dispatchTimeout = 30 * time.Second
+
+	// How often held pushes are checked
+	flushInterval = time.Second
+)
+
+// Config tunes the push dispatcher
+type Config struct {
+	// Goroutines sending pushes
+	Workers int
+
+	// Messages waiting for a worker; more are dropped
+	QueueSize int
+}
+
+type notificationServiceImpl struct {
+	devices    repository.DeviceRepository
+	recipients repository.RecipientRepository
+	prefs      repository.PreferenceRepository
+	collapse   repository.CollapseStore
+	presence   PresenceChecker
+	senders    push.Senders
+	cfg        Config
+
+	jobs chan dto.MessageNotification
+}
+
+// NewNotificationService creates a new notification application service
+func NewNotificationService(
+	devices repository.DeviceRepository,
+	recipients repository.RecipientRepository,
+	prefs repository.PreferenceRepository,
+	collapse repository.CollapseStore,
+	presence PresenceChecker,
+	senders push.Senders,
+	cfg Config,
+) NotificationService {
+	return &notificationServiceImpl{
+		devices:    devices,
+		recipients: recipients,
+		prefs:      prefs,
+		collapse:   collapse,
+		presence:   presence,
+		senders:    senders,
+		cfg:        cfg,
+		jobs:       make(chan dto.MessageNotification, cfg.QueueSize),
+	}
+}
+
+func (s *notificationServiceImpl) RegisterDevice(ctx context.Context, userID int64, req dto.RegisterDeviceRequest) (*dto.DeviceResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	device := entity.NewDevice(userID, req.Platform, req.Token)
+	if err := s.devices.Register(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return dto.NewDeviceResponse(device), nil
+}
+
+func (s *notificationServiceImpl) UnregisterDevice(ctx context.Context, userID int64, token string) error {
+	removed, err := s.devices.Unregister(ctx, userID, token)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return entity.ErrDeviceNotFound
+	}
+	return nil
+}
+
+func (s *notificationServiceImpl) ListDevices(ctx context.Context, userID int64) ([]*dto.DeviceResponse, error) {
+	devices, err := s.devices.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.DeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		responses = append(responses, dto.NewDeviceResponse(device))
+	}
+
+	return responses, nil
+}
+
+func (s *notificationServiceImpl) GetConversationNotifications(ctx context.Context, userID int64, conversationID int64) (*dto.ConversationNotificationsResponse, error) {
+	prefs, err := s.prefs.GetConversationPreferences(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewConversationNotificationsResponse(prefs, time.Now()), nil
+}
+
+func (s *notificationServiceImpl) UpdateConversationNotifications(ctx context.Context, userID int64, conversationID int64, req dto.UpdateConversationNotificationsRequest) (*dto.ConversationNotificationsResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	level := entity.NotificationLevel(req.Level)
+	if !level.Valid() {
+		return nil, entity.ErrInvalidLevel
+	}
+
+	now := time.Now()
+	if req.MutedUntil != nil && !req.MutedUntil.After(now) {
+		return nil, entity.ErrInvalidMuteDuration
+	}
+
+	prefs := &entity.ConversationPreferences{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Level:          level,
+		MutedUntil:     req.MutedUntil,
+	}
+	if err := s.prefs.UpdateConversationPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+
+	return dto.NewConversationNotificationsResponse(prefs, now), nil
+}
+
+func (s *notificationServiceImpl) GetSettings(ctx context.Context, userID int64) (*dto.NotificationSettingsResponse, error) {
+	prefs, err := s.prefs.GetUserPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewNotificationSettingsResponse(prefs), nil
+}
+
+func (s *notificationServiceImpl) UpdateSettings(ctx context.Context, userID int64, req dto.UpdateNotificationSettingsRequest) (*dto.NotificationSettingsResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	prefs := &entity.UserPreferences{
+		UserID:       userID,
+		Timezone:     req.Timezone,
+		ShowPreviews: *req.ShowPreviews,
+	}
+	if req.QuietHours != nil {
+		start, err := dto.ParseClock(req.QuietHours.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := dto.ParseClock(req.QuietHours.End)
+		if err != nil {
+			return nil, err
+		}
+		prefs.QuietHoursStart = &start
+		prefs.QuietHoursEnd = &end
+	}
+
+	if err := prefs.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.prefs.UpdateUserPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+
+	return dto.NewNotificationSettingsResponse(prefs), nil
+}
+
+func (s *notificationServiceImpl) NotifyMessage(_ context.Context, notification dto.MessageNotification) {
+	select {
+	case s.jobs <- notification:
+	default:
+		log.Printf("⚠️  Push queue full, dropping notifications for message %s", notification.MessageID)
+	}
+}
+
+func (s *notificationServiceImpl) Run(ctx context.Context) {
+	for i := 0; i < s.cfg.Workers; i++ {
+		go s.work(ctx)
+	}
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.flush(ctx, now)
+		}
+	}
+}
+
+func (s *notificationServiceImpl) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-s.jobs:
+			s.dispatch(ctx, notification)
+		}
+	}
+}
+
+// dispatch pushes a message to every member who is offline and wants to hear about it
+func (s *notificationServiceImpl) dispatch(ctx context.Context, notification dto.MessageNotification) {
+	ctx, cancel := context.WithTimeout(ctx, dispatchTimeout)
+	defer cancel()
+
+	recipients, err := s.recipients.GetRecipients(ctx, notification.ConversationID, notification.SenderID)
+	if err != nil {
+		log.Printf("Push: failed to get recipients of conversation %d: %v", notification.ConversationID, err)
+		return
+	}
+
+	online := s.onlineUsers(ctx, recipientIDs(recipients))
+
+	mentioned := make(map[int64]bool, len(notification.MentionedUserIDs))
+	for _, userID := range notification.MentionedUserIDs {
+		mentioned[userID] = true
+	}
+
+	now := time.Now()
+	for _, recipient := range recipients {
+		if online[recipient.UserID] || !recipient.ShouldNotify(mentioned[recipient.UserID], now) {
+			continue
+		}
+
+		p := &entity.Push{
+			UserID:         recipient.UserID,
+			ConversationID: notification.ConversationID,
+			MessageID:      notification.MessageID,
+			SenderName:     notification.SenderName,
+			Preview:        notification.Preview,
+			Language:       recipient.Language,
+			Mention:        mentioned[recipient.UserID],
+			Count:          1,
+		}
+		if !recipient.ShowPreviews {
+			p.Preview = ""
+		}
+
+		admitted, err := s.collapse.Admit(ctx, p)
+		if err != nil {
+			// Better a burst of pushes than none
+			log.Printf("Push: failed to collapse push for user %d: %v", recipient.UserID, err)
+			admitted = true
+		}
+		if admitted {
+			s.send(ctx, p)
+		}
+	}
+}
+
+// flush sends the pushes held back while their burst window was open
+func (s *notificationServiceImpl) flush(ctx context.Context, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, dispatchTimeout)
+	defer cancel()
+
+	pushes, err := s.collapse.Due(ctx, now)
+	if err != nil {
+		log.Printf("Push: %v", err)
+	}
+	if len(pushes) == 0 {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(pushes))
+	for _, p := range pushes {
+		userIDs = append(userIDs, p.UserID)
+	}
+
+	// Users who came back online meanwhile have already seen the messages
+	online := s.onlineUsers(ctx, userIDs)
+	for _, p := range pushes {
+		if !online[p.UserID] {
+			s.send(ctx, p)
+		}
+	}
+}
+
+// send delivers a push to each of the user's devices and forgets tokens the provider rejects
+func (s *notificationServiceImpl) send(ctx context.Context, p *entity.Push) {
+	devices, err := s.devices.ListByUser(ctx, p.UserID)
+	if err != nil {
+		log.Printf("Push: failed to get devices of user %d: %v", p.UserID, err)
+		return
+	}
+
+	kind := "message"
+	if p.Mention {
+		kind = "mention"
+	}
+
+	for _, device := range devices {
+		sender, ok := s.senders[device.Platform]
+		if !ok {
+			continue
+		}
+
+		err := sender.Send(ctx, push.Message{
+			Token: device.Token,
+			Title: p.SenderName,
+			Body:  pushBody(p.Language, p.Preview, p.Count, p.Mention),
+			Data: map[string]string{
+				"kind":            kind,
+				"conversation_id": strconv.FormatInt(p.ConversationID, 10),
+				"message_id":      p.MessageID,
+			},
+			CollapseKey: "conversation-" + strconv.FormatInt(p.ConversationID, 10),
+		})
+
+		switch {
+		case errors.Is(err, push.ErrInvalidToken):
+			if err := s.devices.DeleteToken(ctx, device.Token); err != nil {
+				log.Printf("Push: %v", err)
+			} else {
+				log.Printf("Push: pruned invalid %s token of user %d", device.Platform, p.UserID)
+			}
+		case err != nil:
+			log.Printf("Push: failed to send to user %d: %v", p.UserID, err)
+		}
+	}
+}
+
+// onlineUsers reports connected users; if presence is unavailable everyone counts as offline
+func (s *notificationServiceImpl) onlineUsers(ctx context.Context, userIDs []int64) map[int64]bool {
+	online, err := s.presence.OnlineUsers(ctx, userIDs)
+	if err != nil {
+		log.Printf("Push: failed to check presence: %v", err)
+		return map[int64]bool{}
+	}
+	return online
+}
+
+func recipientIDs(recipients []*entity.Recipient) []int64 {
+	userIDs := make([]int64, 0, len(recipients))
+	for _, recipient := range recipients {
+		userIDs = append(userIDs, recipient.UserID)
+	}
+	return userIDs
+}
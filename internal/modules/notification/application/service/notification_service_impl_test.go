@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/push"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+	collapseStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/infrastructure/persistence/redis"
+)
+
+// fakeDevices gives every user one FCM token named after them
+type fakeDevices struct {
+	repository.DeviceRepository
+	deleted []string
+}
+
+func (f *fakeDevices) ListByUser(ctx context.Context, userID int64) ([]*entity.Device, error) {
+	return []*entity.Device{entity.NewDevice(userID, push.PlatformFCM, deviceToken(userID))}, nil
+}
+
+func (f *fakeDevices) DeleteToken(ctx context.Context, token string) error {
+	f.deleted = append(f.deleted, token)
+	return nil
+}
+
+func deviceToken(userID int64) string {
+	return "token-" + strconv.FormatInt(userID, 10)
+}
+
+type fakeRecipients struct {
+	recipients []*entity.Recipient
+}
+
+func (f *fakeRecipients) GetRecipients(ctx context.Context, conversationID int64, excludeUserID int64) ([]*entity.Recipient, error) {
+	return f.recipients, nil
+}
+
+type fakePresence struct {
+	online map[int64]bool
+}
+
+func (f *fakePresence) OnlineUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	return f.online, nil
+}
+
+func TestDispatch(t *testing.T) {
+	mutedUntil := time.Now().Add(time.Hour)
+	allDay := &entity.QuietHours{Start: 0, End: 24 * 60, Location: time.UTC}
+
+	tests := []struct {
+		name         string
+		recipient    entity.Recipient
+		online       bool
+		mentioned    bool
+		invalidToken bool
+		wantBody     string // Empty when nothing is sent
+		wantPruned   bool
+	}{
+		{
+			name:      "offline member",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelAll}, ShowPreviews: true},
+			wantBody:  "hello",
+		},
+		{
+			name:      "online member",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelAll}, ShowPreviews: true},
+			online:    true,
+		},
+		{
+			name:      "previews hidden",
+			recipient: entity.Recipient{Language: "vi", Preferences: entity.ConversationPreferences{Level: entity.LevelAll}},
+			wantBody:  "Tin nhắn mới",
+		},
+		{
+			name:      "level none",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelNone}, ShowPreviews: true},
+			mentioned: true,
+		},
+		{
+			name:      "muted",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelAll, MutedUntil: &mutedUntil}, ShowPreviews: true},
+		},
+		{
+			name:      "muted but mentioned",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelAll, MutedUntil: &mutedUntil}, ShowPreviews: true},
+			mentioned: true,
+			wantBody:  "Mentioned you: hello",
+		},
+		{
+			name:      "mentions level without mention",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelMentions}, ShowPreviews: true},
+		},
+		{
+			name:      "quiet hours",
+			recipient: entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelAll}, QuietHours: allDay, ShowPreviews: true},
+			mentioned: true,
+		},
+		{
+			name:         "invalid token",
+			recipient:    entity.Recipient{Preferences: entity.ConversationPreferences{Level: entity.LevelAll}, ShowPreviews: true},
+			invalidToken: true,
+			wantPruned:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipient := tt.recipient
+			recipient.UserID = 2
+
+			sender := push.NewFakeSender()
+			if tt.invalidToken {
+				sender.MarkInvalid(deviceToken(recipient.UserID))
+			}
+			devices := &fakeDevices{}
+
+			svc := NewNotificationService(
+				devices,
+				&fakeRecipients{recipients: []*entity.Recipient{&recipient}},
+				nil,
+				collapseStore.NewMemoryCollapseStore(time.Minute),
+				&fakePresence{online: map[int64]bool{recipient.UserID: tt.online}},
+				push.Senders{push.PlatformFCM: sender},
+				Config{Workers: 1, QueueSize: 1},
+			).(*notificationServiceImpl)
+
+			notification := dto.MessageNotification{
+				ConversationID: 7,
+				MessageID:      "message-1",
+				SenderID:       1,
+				SenderName:     "An",
+				Preview:        "hello",
+			}
+			if tt.mentioned {
+				notification.MentionedUserIDs = []int64{recipient.UserID}
+			}
+			svc.dispatch(context.Background(), notification)
+
+			sent := sender.Sent()
+			switch {
+			case tt.wantBody == "" && len(sent) != 0:
+				t.Fatalf("sent %+v, want nothing", sent)
+			case tt.wantBody != "" && len(sent) != 1:
+				t.Fatalf("sent %d pushes, want 1", len(sent))
+			case tt.wantBody != "":
+				if sent[0].Body != tt.wantBody || sent[0].Title != "An" || sent[0].Data["conversation_id"] != "7" {
+					t.Fatalf("sent %+v, want body %q", sent[0], tt.wantBody)
+				}
+			}
+			if pruned := len(devices.deleted) > 0; pruned != tt.wantPruned {
+				t.Fatalf("pruned tokens %v, want pruned %v", devices.deleted, tt.wantPruned)
+			}
+		})
+	}
+}
+
+func TestDispatchCollapsesBursts(t *testing.T) {
+	sender := push.NewFakeSender()
+	collapse := collapseStore.NewMemoryCollapseStore(time.Minute)
+	svc := NewNotificationService(
+		&fakeDevices{},
+		&fakeRecipients{recipients: []*entity.Recipient{{UserID: 2, Preferences: entity.ConversationPreferences{Level: entity.LevelAll}, ShowPreviews: true}}},
+		nil,
+		collapse,
+		&fakePresence{},
+		push.Senders{push.PlatformFCM: sender},
+		Config{Workers: 1, QueueSize: 1},
+	).(*notificationServiceImpl)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		svc.dispatch(ctx, dto.MessageNotification{ConversationID: 7, SenderID: 1, SenderName: "An", Preview: "hello"})
+	}
+	if sent := sender.Sent(); len(sent) != 1 {
+		t.Fatalf("sent %d pushes during the burst, want 1", len(sent))
+	}
+
+	svc.flush(ctx, time.Now().Add(2*time.Minute))
+	sent := sender.Sent()
+	if len(sent) != 2 || sent[1].Body != "2 new messages" {
+		t.Fatalf("sent %+v, want the held messages as one push", sent)
+	}
+}
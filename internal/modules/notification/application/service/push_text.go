@@ -0,0 +1,53 @@
+package service
+
+import "fmt"
+
+// Language pushes fall back to
+const fallbackLanguage = "en"
+
+// collapsedTemplates word a push that stands for several messages, per language
+var collapsedTemplates = map[string]string{
+	"en": "%d new messages",
+	"vi": "%d tin nhắn mới",
+}
+
+// mentionTemplates prefix the preview of a mention, per language
+var mentionTemplates = map[string]string{
+	"en": "Mentioned you: %s",
+	"vi": "Đã nhắc đến bạn: %s",
+}
+
+// hiddenTexts stand in for the preview when the recipient hides message content
+var hiddenTexts = map[string]string{
+	"en": "New message",
+	"vi": "Tin nhắn mới",
+}
+
+// hiddenMentionTexts stand in for the preview of a mention when the recipient hides message content
+var hiddenMentionTexts = map[string]string{
+	"en": "Mentioned you",
+	"vi": "Đã nhắc đến bạn",
+}
+
+// pushBody renders the text of a push in the recipient's language. An empty
+// preview means the recipient keeps message content out of pushes.
+func pushBody(language string, preview string, count int, mention bool) string {
+	switch {
+	case count > 1:
+		return fmt.Sprintf(localized(collapsedTemplates, language), count)
+	case mention && preview == "":
+		return localized(hiddenMentionTexts, language)
+	case mention:
+		return fmt.Sprintf(localized(mentionTemplates, language), preview)
+	case preview == "":
+		return localized(hiddenTexts, language)
+	}
+	return preview
+}
+
+func localized(templates map[string]string, language string) string {
+	if template, ok := templates[language]; ok {
+		return template
+	}
+	return templates[fallbackLanguage]
+}
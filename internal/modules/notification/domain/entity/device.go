@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Device is a push notification token registered by a user's app
+type Device struct {
+	ID        int64
+	UserID    int64
+	Platform  string // push.PlatformFCM or push.PlatformAPNs
+	Token     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewDevice creates a device registration
+func NewDevice(userID int64, platform, token string) *Device {
+	now := time.Now()
+	return &Device{
+		UserID:    userID,
+		Platform:  platform,
+		Token:     token,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
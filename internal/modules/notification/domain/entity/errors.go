@@ -0,0 +1,13 @@
+package entity
+
+import "errors"
+
+var (
+	ErrDeviceNotFound        = errors.New("device not found")
+	ErrInvalidPlatform       = errors.New("invalid device platform")
+	ErrNotConversationMember = errors.New("user is not a member of the conversation")
+	ErrInvalidLevel          = errors.New("invalid notification level")
+	ErrInvalidTimezone       = errors.New("invalid timezone")
+	ErrInvalidQuietHours     = errors.New("invalid quiet hours")
+	ErrInvalidMuteDuration   = errors.New("mute must end in the future")
+)
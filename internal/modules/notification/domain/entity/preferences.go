@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// NotificationLevel is what a member is notified about in a conversation
+type NotificationLevel string
+
+const (
+	LevelAll      NotificationLevel = "all"      // every message
+	LevelMentions NotificationLevel = "mentions" // only messages that mention the member
+	LevelNone     NotificationLevel = "none"     // nothing, mentions included
+)
+
+// Valid reports whether the level is one of the known levels
+func (l NotificationLevel) Valid() bool {
+	switch l {
+	case LevelAll, LevelMentions, LevelNone:
+		return true
+	}
+	return false
+}
+
+// ConversationPreferences is how a member is notified about one conversation
+type ConversationPreferences struct {
+	ConversationID int64
+	UserID         int64
+	Level          NotificationLevel
+
+	// Until MutedUntil only mentions are notified, whatever the level
+	MutedUntil *time.Time
+}
+
+// IsMuted reports whether a temporary mute is in effect at now
+func (p *ConversationPreferences) IsMuted(now time.Time) bool {
+	return p.MutedUntil != nil && p.MutedUntil.After(now)
+}
+
+// UserPreferences are a user's notification settings across all conversations
+type UserPreferences struct {
+	UserID   int64
+	Timezone string
+
+	// Minutes after midnight in Timezone; both nil when quiet hours are off
+	QuietHoursStart *int
+	QuietHoursEnd   *int
+
+	// ShowPreviews false keeps message content out of pushes
+	ShowPreviews bool
+}
+
+// Validate checks the timezone and the quiet hours window
+func (p *UserPreferences) Validate() error {
+	if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "" {
+		return ErrInvalidTimezone
+	}
+	if (p.QuietHoursStart == nil) != (p.QuietHoursEnd == nil) {
+		return ErrInvalidQuietHours
+	}
+	if p.QuietHoursStart != nil && (!validMinute(*p.QuietHoursStart) || !validMinute(*p.QuietHoursEnd)) {
+		return ErrInvalidQuietHours
+	}
+	return nil
+}
+
+func validMinute(minute int) bool {
+	return minute >= 0 && minute < 24*60
+}
@@ -0,0 +1,16 @@
+package entity
+
+// Push is a notification about a conversation for one user, before it is
+// addressed to each of their devices
+type Push struct {
+	UserID         int64
+	ConversationID int64
+	MessageID      string
+	SenderName     string
+	Preview        string
+	Language       string
+	Mention        bool
+
+	// Messages this push stands for; more than one when a burst was collapsed
+	Count int
+}
@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+// Recipient is a conversation member together with how they want to be notified
+type Recipient struct {
+	UserID   int64
+	Language string
+
+	Preferences ConversationPreferences
+	QuietHours  *QuietHours
+
+	// ShowPreviews false keeps message content out of the recipient's pushes
+	ShowPreviews bool
+}
+
+// ShouldNotify reports whether a new message is pushed to the recipient at now.
+// Mentions get through a mute and the mentions level, never the none level or quiet hours.
+func (r *Recipient) ShouldNotify(mentioned bool, now time.Time) bool {
+	switch {
+	case r.Preferences.Level == LevelNone:
+		return false
+	case r.Preferences.Level == LevelMentions || r.Preferences.IsMuted(now):
+		if !mentioned {
+			return false
+		}
+	}
+	if r.QuietHours != nil && r.QuietHours.Contains(now) {
+		return false
+	}
+	return true
+}
+
+// QuietHours is a daily window without pushes. Start and End are minutes after
+// midnight in Location; a window that ends before it starts spans midnight.
+type QuietHours struct {
+	Start    int
+	End      int
+	Location *time.Location
+}
+
+// Contains reports whether t falls inside the window
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+
+	local := t.In(q.Location)
+	minute := local.Hour()*60 + local.Minute()
+
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
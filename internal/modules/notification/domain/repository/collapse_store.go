@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+)
+
+// CollapseStore coalesces bursts of pushes per user and conversation, shared by all nodes.
+// The first push of a burst goes out at once; later ones within the window are held
+// and sent as a single push when the window ends.
+type CollapseStore interface {
+	// Admit reports whether the push may be sent now. Otherwise it is held,
+	// replacing any push already held for the same user and conversation.
+	Admit(ctx context.Context, push *entity.Push) (bool, error)
+
+	// Due removes and returns held pushes whose window has ended
+	Due(ctx context.Context, now time.Time) ([]*entity.Push, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+)
+
+// DeviceRepository stores push notification tokens
+type DeviceRepository interface {
+	// Register stores the token for the user, taking it over from any previous owner
+	Register(ctx context.Context, device *entity.Device) error
+
+	// Unregister removes one of the user's tokens and reports whether it existed
+	Unregister(ctx context.Context, userID int64, token string) (bool, error)
+
+	ListByUser(ctx context.Context, userID int64) ([]*entity.Device, error)
+
+	// DeleteToken forgets a token the provider no longer accepts
+	DeleteToken(ctx context.Context, token string) error
+}
+
+// RecipientRepository reads who is notified about a conversation and how
+type RecipientRepository interface {
+	// GetRecipients returns the active members of a conversation other than excludeUserID
+	GetRecipients(ctx context.Context, conversationID int64, excludeUserID int64) ([]*entity.Recipient, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+)
+
+// PreferenceRepository stores how users want to be notified
+type PreferenceRepository interface {
+	// GetConversationPreferences returns ErrNotConversationMember unless the user is an active member
+	GetConversationPreferences(ctx context.Context, conversationID int64, userID int64) (*entity.ConversationPreferences, error)
+	UpdateConversationPreferences(ctx context.Context, prefs *entity.ConversationPreferences) error
+
+	GetUserPreferences(ctx context.Context, userID int64) (*entity.UserPreferences, error)
+	UpdateUserPreferences(ctx context.Context, prefs *entity.UserPreferences) error
+}
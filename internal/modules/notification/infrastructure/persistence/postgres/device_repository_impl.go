@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+)
+
+// deviceModel maps the device_tokens table
+type deviceModel struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	UserID    int64  `gorm:"not null"`
+	Platform  string `gorm:"type:varchar(10);not null"`
+	Token     string `gorm:"type:varchar(4096);not null;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (deviceModel) TableName() string {
+	return "device_tokens"
+}
+
+type deviceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewDeviceRepository creates a device token repository
+func NewDeviceRepository(db *gorm.DB) repository.DeviceRepository {
+	return &deviceRepositoryImpl{db: db}
+}
+
+func (r *deviceRepositoryImpl) Register(ctx context.Context, device *entity.Device) error {
+	model := &deviceModel{
+		UserID:    device.UserID,
+		Platform:  device.Platform,
+		Token:     device.Token,
+		CreatedAt: device.CreatedAt,
+		UpdatedAt: device.UpdatedAt,
+	}
+
+	// A reinstalled app or a shared phone hands the token to whoever registers it last
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "updated_at"}),
+	}).Create(model).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to register device: %w", err)
+	}
+
+	device.ID = model.ID
+	return nil
+}
+
+func (r *deviceRepositoryImpl) Unregister(ctx context.Context, userID int64, token string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND token = ?", userID, token).
+		Delete(&deviceModel{})
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to unregister device: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *deviceRepositoryImpl) ListByUser(ctx context.Context, userID int64) ([]*entity.Device, error) {
+	var models []deviceModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	devices := make([]*entity.Device, 0, len(models))
+	for _, model := range models {
+		devices = append(devices, &entity.Device{
+			ID:        model.ID,
+			UserID:    model.UserID,
+			Platform:  model.Platform,
+			Token:     model.Token,
+			CreatedAt: model.CreatedAt,
+			UpdatedAt: model.UpdatedAt,
+		})
+	}
+
+	return devices, nil
+}
+
+func (r *deviceRepositoryImpl) DeleteToken(ctx context.Context, token string) error {
+	err := r.db.WithContext(ctx).
+		Where("token = ?", token).
+		Delete(&deviceModel{}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to delete device token: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+)
+
+// Timezone used when a user has none set
+const defaultTimezone = "UTC"
+
+type preferenceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPreferenceRepository creates a notification preference repository
+func NewPreferenceRepository(db *gorm.DB) repository.PreferenceRepository {
+	return &preferenceRepositoryImpl{db: db}
+}
+
+func (r *preferenceRepositoryImpl) GetConversationPreferences(ctx context.Context, conversationID int64, userID int64) (*entity.ConversationPreferences, error) {
+	var row struct {
+		NotificationLevel string
+		MutedUntil        *time.Time
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("conversation_members").
+		Select("notification_level, muted_until").
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Take(&row).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotConversationMember
+		}
+		return nil, fmt.Errorf("failed to get conversation preferences: %w", err)
+	}
+
+	return &entity.ConversationPreferences{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Level:          entity.NotificationLevel(row.NotificationLevel),
+		MutedUntil:     row.MutedUntil,
+	}, nil
+}
+
+func (r *preferenceRepositoryImpl) UpdateConversationPreferences(ctx context.Context, prefs *entity.ConversationPreferences) error {
+	result := r.db.WithContext(ctx).
+		Table("conversation_members").
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", prefs.ConversationID, prefs.UserID).
+		Updates(map[string]interface{}{
+			"notification_level": string(prefs.Level),
+			"muted_until":        prefs.MutedUntil,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update conversation preferences: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotConversationMember
+	}
+
+	return nil
+}
+
+func (r *preferenceRepositoryImpl) GetUserPreferences(ctx context.Context, userID int64) (*entity.UserPreferences, error) {
+	var row struct {
+		Timezone        *string
+		QuietHoursStart *int
+		QuietHoursEnd   *int
+		PushPreviews    bool
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("timezone, quiet_hours_start, quiet_hours_end, push_previews").
+		Where("id = ? AND is_deleted = FALSE", userID).
+		Take(&row).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	prefs := &entity.UserPreferences{
+		UserID:          userID,
+		Timezone:        defaultTimezone,
+		QuietHoursStart: row.QuietHoursStart,
+		QuietHoursEnd:   row.QuietHoursEnd,
+		ShowPreviews:    row.PushPreviews,
+	}
+	if row.Timezone != nil && *row.Timezone != "" {
+		prefs.Timezone = *row.Timezone
+	}
+
+	return prefs, nil
+}
+
+func (r *preferenceRepositoryImpl) UpdateUserPreferences(ctx context.Context, prefs *entity.UserPreferences) error {
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ? AND is_deleted = FALSE", prefs.UserID).
+		Updates(map[string]interface{}{
+			"timezone":          prefs.Timezone,
+			"quiet_hours_start": prefs.QuietHoursStart,
+			"quiet_hours_end":   prefs.QuietHoursEnd,
+			"push_previews":     prefs.ShowPreviews,
+		}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to update notification settings: %w", err)
+	}
+
+	return nil
+}
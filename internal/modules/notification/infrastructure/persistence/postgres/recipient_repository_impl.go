@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+)
+
+// Language used when a user has none set
+const defaultLanguage = "en"
+
+type recipientRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewRecipientRepository creates a read-only view of members' notification settings
+func NewRecipientRepository(db *gorm.DB) repository.RecipientRepository {
+	return &recipientRepositoryImpl{db: db}
+}
+
+func (r *recipientRepositoryImpl) GetRecipients(ctx context.Context, conversationID int64, excludeUserID int64) ([]*entity.Recipient, error) {
+	var rows []struct {
+		UserID            int64
+		NotificationLevel string
+		MutedUntil        *time.Time
+		Language          *string
+		Timezone          *string
+		QuietHoursStart   *int
+		QuietHoursEnd     *int
+		PushPreviews      bool
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("conversation_members AS m").
+		Select(`m.user_id, m.notification_level, m.muted_until,
+			u.language, u.timezone, u.quiet_hours_start, u.quiet_hours_end, u.push_previews`).
+		Joins("JOIN users AS u ON u.id = m.user_id").
+		Where("m.conversation_id = ? AND m.left_at IS NULL AND m.user_id <> ? AND u.is_deleted = FALSE",
+			conversationID, excludeUserID).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	recipients := make([]*entity.Recipient, 0, len(rows))
+	for _, row := range rows {
+		recipient := &entity.Recipient{
+			UserID:   row.UserID,
+			Language: defaultLanguage,
+			Preferences: entity.ConversationPreferences{
+				ConversationID: conversationID,
+				UserID:         row.UserID,
+				Level:          entity.NotificationLevel(row.NotificationLevel),
+				MutedUntil:     row.MutedUntil,
+			},
+			ShowPreviews: row.PushPreviews,
+		}
+		if row.Language != nil && *row.Language != "" {
+			recipient.Language = *row.Language
+		}
+		if row.QuietHoursStart != nil && row.QuietHoursEnd != nil {
+			recipient.QuietHours = &entity.QuietHours{
+				Start:    *row.QuietHoursStart,
+				End:      *row.QuietHoursEnd,
+				Location: loadLocation(row.Timezone),
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+// loadLocation falls back to UTC for unset or unknown timezones
+func loadLocation(name *string) *time.Location {
+	if name == nil || *name == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(*name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
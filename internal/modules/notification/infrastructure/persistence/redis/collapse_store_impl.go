@@ -0,0 +1,168 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+)
+
+// Held pushes are indexed in one sorted set scored by the unix milliseconds they are due
+const heldIndexKey = "push:held"
+
+// pushRecord is the JSON stored for a held push
+type pushRecord struct {
+	MessageID  string `json:"message_id"`
+	SenderName string `json:"sender_name"`
+	Preview    string `json:"preview"`
+	Language   string `json:"language"`
+}
+
+type collapseStoreImpl struct {
+	client *goredis.Client
+	window time.Duration
+}
+
+// NewCollapseStore creates a Redis collapse store. At most one push per user and
+// conversation is sent per window.
+func NewCollapseStore(client *goredis.Client, window time.Duration) repository.CollapseStore {
+	return &collapseStoreImpl{
+		client: client,
+		window: window,
+	}
+}
+
+func burstMember(userID, conversationID int64) string {
+	return fmt.Sprintf("%d:%d", userID, conversationID)
+}
+
+func windowKey(member string) string {
+	return "push:window:" + member
+}
+
+func heldKey(member string) string {
+	return "push:held:" + member
+}
+
+func (s *collapseStoreImpl) Admit(ctx context.Context, push *entity.Push) (bool, error) {
+	member := burstMember(push.UserID, push.ConversationID)
+
+	opened, err := s.client.SetNX(ctx, windowKey(member), 1, s.window).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to open push window: %w", err)
+	}
+	if opened {
+		return true, nil
+	}
+
+	remaining, err := s.client.PTTL(ctx, windowKey(member)).Result()
+	if err != nil || remaining <= 0 {
+		remaining = s.window
+	}
+
+	record, err := json.Marshal(pushRecord{
+		MessageID:  push.MessageID,
+		SenderName: push.SenderName,
+		Preview:    push.Preview,
+		Language:   push.Language,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode push: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, heldKey(member), "push", record)
+		pipe.HIncrBy(ctx, heldKey(member), "count", 1)
+		if push.Mention {
+			// A held mention stays a mention even if plain messages follow it
+			pipe.HSet(ctx, heldKey(member), "mention", 1)
+		}
+		pipe.Expire(ctx, heldKey(member), 2*s.window+time.Minute)
+		pipe.ZAddNX(ctx, heldIndexKey, goredis.Z{
+			Score:  float64(time.Now().Add(remaining).UnixMilli()),
+			Member: member,
+		})
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to hold push: %w", err)
+	}
+
+	return false, nil
+}
+
+func (s *collapseStoreImpl) Due(ctx context.Context, now time.Time) ([]*entity.Push, error) {
+	members, err := s.client.ZRangeByScore(ctx, heldIndexKey, &goredis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load held pushes: %w", err)
+	}
+
+	var pushes []*entity.Push
+	for _, member := range members {
+		// Only the node that removes a member sends its push
+		removed, err := s.client.ZRem(ctx, heldIndexKey, member).Result()
+		if err != nil {
+			return pushes, fmt.Errorf("failed to claim held push: %w", err)
+		}
+		if removed == 0 {
+			continue
+		}
+
+		var fields *goredis.MapStringStringCmd
+		_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			fields = pipe.HGetAll(ctx, heldKey(member))
+			pipe.Del(ctx, heldKey(member))
+			// The flushed push opens a new window
+			pipe.Set(ctx, windowKey(member), 1, s.window)
+			return nil
+		})
+		if err != nil {
+			return pushes, fmt.Errorf("failed to take held push: %w", err)
+		}
+
+		if push := decodeHeldPush(member, fields.Val()); push != nil {
+			pushes = append(pushes, push)
+		}
+	}
+
+	return pushes, nil
+}
+
+func decodeHeldPush(member string, fields map[string]string) *entity.Push {
+	userPart, conversationPart, _ := strings.Cut(member, ":")
+	userID, err := strconv.ParseInt(userPart, 10, 64)
+	if err != nil {
+		return nil
+	}
+	conversationID, err := strconv.ParseInt(conversationPart, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	var record pushRecord
+	if err := json.Unmarshal([]byte(fields["push"]), &record); err != nil {
+		return nil
+	}
+	count, _ := strconv.Atoi(fields["count"])
+
+	return &entity.Push{
+		UserID:         userID,
+		ConversationID: conversationID,
+		MessageID:      record.MessageID,
+		SenderName:     record.SenderName,
+		Preview:        record.Preview,
+		Language:       record.Language,
+		Mention:        fields["mention"] != "",
+		Count:          count,
+	}
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+)
+
+type burstKey struct {
+	userID         int64
+	conversationID int64
+}
+
+type heldPush struct {
+	push *entity.Push
+	due  time.Time
+}
+
+type memoryCollapseStore struct {
+	windows map[burstKey]time.Time
+	held    map[burstKey]*heldPush
+	window  time.Duration
+	mu      sync.Mutex
+}
+
+// NewMemoryCollapseStore creates an in-process collapse store.
+// It is used when Redis is unavailable; bursts are then collapsed per node.
+func NewMemoryCollapseStore(window time.Duration) repository.CollapseStore {
+	return &memoryCollapseStore{
+		windows: make(map[burstKey]time.Time),
+		held:    make(map[burstKey]*heldPush),
+		window:  window,
+	}
+}
+
+func (s *memoryCollapseStore) Admit(_ context.Context, push *entity.Push) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := burstKey{userID: push.UserID, conversationID: push.ConversationID}
+
+	closesAt, open := s.windows[key]
+	if !open || !now.Before(closesAt) {
+		s.windows[key] = now.Add(s.window)
+		return true, nil
+	}
+
+	held, ok := s.held[key]
+	if !ok {
+		held = &heldPush{due: closesAt}
+		s.held[key] = held
+	}
+
+	count, mention := 1, push.Mention
+	if held.push != nil {
+		count = held.push.Count + 1
+		mention = mention || held.push.Mention
+	}
+
+	latest := *push
+	latest.Count = count
+	latest.Mention = mention
+	held.push = &latest
+
+	return false, nil
+}
+
+func (s *memoryCollapseStore) Due(_ context.Context, now time.Time) ([]*entity.Push, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pushes []*entity.Push
+	for key, held := range s.held {
+		if held.due.After(now) {
+			continue
+		}
+		delete(s.held, key)
+		s.windows[key] = now.Add(s.window)
+		pushes = append(pushes, held.push)
+	}
+
+	// Forget windows that closed with nothing held
+	for key, closesAt := range s.windows {
+		if _, ok := s.held[key]; !ok && !now.Before(closesAt) {
+			delete(s.windows, key)
+		}
+	}
+
+	return pushes, nil
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+var (
+	ErrUnauthenticated       = errors.New("unauthenticated")
+	ErrInvalidConversationID = errors.New("invalid conversation ID")
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// RegisterDevice godoc
+// @Summary Register a device for push notifications
+// @Description Store the FCM or APNs token of the caller's app. A token registered by another user is taken over.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RegisterDeviceRequest true "Device token"
+// @Success 201 {object} response.Response{data=dto.DeviceResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /devices [post]
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	device, err := h.notificationService.RegisterDevice(c.Request.Context(), userID, req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to register device", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Device registered successfully", device)
+}
+
+// ListDevices godoc
+// @Summary List registered devices
+// @Description Get the caller's push notification tokens
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]dto.DeviceResponse}
+// @Failure 401 {object} response.Response
+// @Router /devices [get]
+func (h *NotificationHandler) ListDevices(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	devices, err := h.notificationService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list devices", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Devices retrieved successfully", devices)
+}
+
+// UnregisterDevice godoc
+// @Summary Unregister a device
+// @Description Stop push notifications to one of the caller's tokens, e.g. on logout
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Device token"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /devices/{token} [delete]
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	err := h.notificationService.UnregisterDevice(c.Request.Context(), userID, c.Param("token"))
+	if err != nil {
+		respondError(c, "Failed to unregister device", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Device unregistered successfully", nil)
+}
+
+// GetConversationNotifications godoc
+// @Summary Get notifications of a conversation
+// @Description Get the caller's notification level and mute for a conversation
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} response.Response{data=dto.ConversationNotificationsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/notifications [get]
+func (h *NotificationHandler) GetConversationNotifications(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.notificationService.GetConversationNotifications(c.Request.Context(), userID, conversationID)
+	if err != nil {
+		respondError(c, "Failed to get conversation notifications", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Conversation notifications retrieved successfully", prefs)
+}
+
+// UpdateConversationNotifications godoc
+// @Summary Update notifications of a conversation
+// @Description Choose between all messages, mentions only or nothing, and optionally mute until a point in time. A muted conversation still notifies mentions.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Conversation ID"
+// @Param request body dto.UpdateConversationNotificationsRequest true "Notification level and mute"
+// @Success 200 {object} response.Response{data=dto.ConversationNotificationsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /conversations/{id}/notifications [put]
+func (h *NotificationHandler) UpdateConversationNotifications(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	conversationID, ok := conversationParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateConversationNotificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	prefs, err := h.notificationService.UpdateConversationNotifications(c.Request.Context(), userID, conversationID, req)
+	if err != nil {
+		respondError(c, "Failed to update conversation notifications", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Conversation notifications updated successfully", prefs)
+}
+
+// GetSettings godoc
+// @Summary Get notification settings
+// @Description Get the caller's timezone, quiet hours and preview privacy
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.NotificationSettingsResponse}
+// @Failure 401 {object} response.Response
+// @Router /notifications/settings [get]
+func (h *NotificationHandler) GetSettings(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	settings, err := h.notificationService.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, "Failed to get notification settings", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Notification settings retrieved successfully", settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update notification settings
+// @Description Set the caller's timezone, daily quiet hours without pushes and whether pushes show message content
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateNotificationSettingsRequest true "Notification settings"
+// @Success 200 {object} response.Response{data=dto.NotificationSettingsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /notifications/settings [put]
+func (h *NotificationHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.notificationService.UpdateSettings(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, "Failed to update notification settings", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Notification settings updated successfully", settings)
+}
+
+func conversationParam(c *gin.Context) (int64, bool) {
+	conversationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid conversation ID", ErrInvalidConversationID)
+		return 0, false
+	}
+	return conversationID, true
+}
+
+// respondError maps domain errors to HTTP status codes
+func respondError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrNotConversationMember):
+		response.Error(c, http.StatusForbidden, message, err)
+	case errors.Is(err, entity.ErrDeviceNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.Is(err, entity.ErrInvalidLevel),
+		errors.Is(err, entity.ErrInvalidTimezone),
+		errors.Is(err, entity.ErrInvalidQuietHours),
+		errors.Is(err, entity.ErrInvalidMuteDuration):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/presentation/http/handler"
+)
+
+// RegisterNotificationRoutes registers all notification-related routes
+func RegisterNotificationRoutes(router *gin.RouterGroup, notificationHandler *handler.NotificationHandler, auth gin.HandlerFunc) {
+	devices := router.Group("/devices", auth)
+	{
+		devices.GET("", notificationHandler.ListDevices)
+		devices.POST("", notificationHandler.RegisterDevice)
+		devices.DELETE("/:token", notificationHandler.UnregisterDevice)
+	}
+
+	notifications := router.Group("/notifications", auth)
+	{
+		notifications.GET("/settings", notificationHandler.GetSettings)
+		notifications.PUT("/settings", notificationHandler.UpdateSettings)
+	}
+
+	conversations := router.Group("/conversations/:id", auth)
+	{
+		conversations.GET("/notifications", notificationHandler.GetConversationNotifications)
+		conversations.PUT("/notifications", notificationHandler.UpdateConversationNotifications)
+	}
+}
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/push"
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/domain/repository"
+	notificationPostgres "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/infrastructure/persistence/postgres"
+	notificationStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/infrastructure/persistence/redis"
+	notificationHandler "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/presentation/http/handler"
+	notificationRouter "github.com/ndxbinh1922001/VNalo-be/internal/modules/notification/presentation/http/router"
+	presenceService "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+)
+
+// Module provides notification module dependencies
+var Module = fx.Options(
+	fx.Provide(provideDeviceRepository),
+	fx.Provide(provideRecipientRepository),
+	fx.Provide(providePreferenceRepository),
+	fx.Provide(provideCollapseStore),
+	fx.Provide(provideService),
+	fx.Provide(provideHandler),
+	fx.Provide(
+		fx.Annotate(
+			provideRouteRegistration,
+			fx.ResultTags(`group:"routes"`),
+		),
+	),
+	fx.Invoke(runDispatcher),
+)
+
+func provideDeviceRepository(db *gorm.DB) repository.DeviceRepository {
+	log.Println("📦 Creating device repository...")
+	return notificationPostgres.NewDeviceRepository(db)
+}
+
+func provideRecipientRepository(db *gorm.DB) repository.RecipientRepository {
+	log.Println("📦 Creating push recipient repository...")
+	return notificationPostgres.NewRecipientRepository(db)
+}
+
+func providePreferenceRepository(db *gorm.DB) repository.PreferenceRepository {
+	log.Println("📦 Creating notification preference repository...")
+	return notificationPostgres.NewPreferenceRepository(db)
+}
+
+func provideCollapseStore(redisClient *redis.Client, cfg infrastructure.Config) repository.CollapseStore {
+	window := time.Duration(cfg.GetPushConfig().CollapseWindow) * time.Second
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, push bursts are collapsed for this node only")
+		return notificationStore.NewMemoryCollapseStore(window)
+	}
+
+	log.Println("📦 Creating push collapse store...")
+	return notificationStore.NewCollapseStore(redisClient, window)
+}
+
+func provideService(
+	devices repository.DeviceRepository,
+	recipients repository.RecipientRepository,
+	prefs repository.PreferenceRepository,
+	collapse repository.CollapseStore,
+	presence presenceService.PresenceService,
+	senders push.Senders,
+	cfg infrastructure.Config,
+) service.NotificationService {
+	log.Println("⚙️  Creating notification service...")
+	pushCfg := cfg.GetPushConfig()
+	return service.NewNotificationService(devices, recipients, prefs, collapse, presence, senders, service.Config{
+		Workers:   pushCfg.Workers,
+		QueueSize: pushCfg.QueueSize,
+	})
+}
+
+func provideHandler(svc service.NotificationService) *notificationHandler.NotificationHandler {
+	log.Println("🎯 Creating notification handler...")
+	return notificationHandler.NewNotificationHandler(svc)
+}
+
+// runDispatcher sends pushes and flushes collapsed bursts for the app lifetime
+func runDispatcher(lc fx.Lifecycle, svc service.NotificationService) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go svc.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// provideRouteRegistration returns a function to register notification routes
+func provideRouteRegistration(h *notificationHandler.NotificationHandler, tokens *token.Manager, sessions middleware.SessionChecker) func(*gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		log.Println("✅ Registering notification routes...")
+		notificationRouter.RegisterNotificationRoutes(router, h, middleware.Auth(tokens, sessions))
+	}
+}
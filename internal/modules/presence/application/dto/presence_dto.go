@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+)
+
+// PresenceResponse represents a user's presence
+type PresenceResponse struct {
+	UserID   int64      `json:"user_id"`
+	Status   string     `json:"status"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
+
+// NewPresenceResponse converts domain entity to DTO
+func NewPresenceResponse(presence *entity.Presence) *PresenceResponse {
+	return &PresenceResponse{
+		UserID:   presence.UserID,
+		Status:   string(presence.Status),
+		LastSeen: presence.LastSeen,
+	}
+}
+
+// PresenceListResponse represents presence for a batch of users
+type PresenceListResponse struct {
+	Presences []*PresenceResponse `json:"presences"`
+}
+
+// UpdatePresenceSettingsRequest represents presence privacy settings
+type UpdatePresenceSettingsRequest struct {
+	HideLastSeen bool `json:"hide_last_seen"`
+}
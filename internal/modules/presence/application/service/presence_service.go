@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/dto"
+)
+
+// PresenceService defines application use cases for presence.
+// It observes WebSocket connections to derive online/away/offline state.
+type PresenceService interface {
+	websocket.ConnectionObserver
+
+	GetPresence(ctx context.Context, viewerID int64, userIDs []int64) (*dto.PresenceListResponse, error)
+	UpdateSettings(ctx context.Context, userID int64, req dto.UpdatePresenceSettingsRequest) error
+
+	// MarkActive records activity outside presence frames, such as sending a message or
+	// typing, so a user shown as away comes back online. Heartbeats alone never count.
+	MarkActive(userID int64)
+
+	// ConnectedElsewhere reports whether the user has a live connection other than connID on any node
+	ConnectedElsewhere(ctx context.Context, userID int64, connID string) (bool, error)
+
+	// OnlineUsers returns which users have a live connection on any node, away included
+	OnlineUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+
+	// Run publishes status changes no client reports, such as connections of
+	// a crashed node expiring or users going idle, until ctx is canceled
+	Run(ctx context.Context)
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/repository"
+)
+
+const (
+	// Maximum number of users in one presence query
+	maxPresenceBatch = 100
+
+	// Time allowed for presence bookkeeping triggered by connection events
+	observerTimeout = 5 * time.Second
+
+	// How often connections that expired or went idle are looked for
+	sweepInterval = 5 * time.Second
+
+	// Users checked per sweep batch, and how long a claimed check stays with
+	// this node before another may take it
+	sweepBatchSize = 100
+	sweepLease     = 30 * time.Second
+)
+
+// Config tunes how presence is derived from heartbeats
+type Config struct {
+	// A connection without heartbeat for this long is considered gone
+	TTL time.Duration
+
+	// A connected user without activity for this long is shown as away
+	AwayAfter time.Duration
+}
+
+type presenceServiceImpl struct {
+	store repository.PresenceStore
+	repo  repository.PresenceRepository
+	hub   *websocket.Hub
+	cfg   Config
+}
+
+// NewPresenceService creates a new presence application service
+func NewPresenceService(store repository.PresenceStore, repo repository.PresenceRepository, hub *websocket.Hub, cfg Config) PresenceService {
+	return &presenceServiceImpl{
+		store: store,
+		repo:  repo,
+		hub:   hub,
+		cfg:   cfg,
+	}
+}
+
+func (s *presenceServiceImpl) GetPresence(ctx context.Context, viewerID int64, userIDs []int64) (*dto.PresenceListResponse, error) {
+	if len(userIDs) == 0 {
+		return nil, entity.ErrInvalidUserIDs
+	}
+	if len(userIDs) > maxPresenceBatch {
+		return nil, entity.ErrTooManyUsers
+	}
+
+	presences, err := s.resolve(ctx, viewerID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PresenceResponse, 0, len(presences))
+	for _, presence := range presences {
+		responses = append(responses, dto.NewPresenceResponse(presence))
+	}
+
+	return &dto.PresenceListResponse{Presences: responses}, nil
+}
+
+func (s *presenceServiceImpl) UpdateSettings(ctx context.Context, userID int64, req dto.UpdatePresenceSettingsRequest) error {
+	return s.repo.SetHideLastSeen(ctx, userID, req.HideLastSeen)
+}
+
+func (s *presenceServiceImpl) OnlineUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	online := make(map[int64]bool, len(userIDs))
+	if len(userIDs) == 0 {
+		return online, nil
+	}
+
+	connections, err := s.store.GetConnections(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for userID, conns := range connections {
+		if entity.ResolveStatus(conns, now, s.cfg.AwayAfter) != entity.StatusOffline {
+			online[userID] = true
+		}
+	}
+
+	return online, nil
+}
+
+func (s *presenceServiceImpl) ConnectedElsewhere(ctx context.Context, userID int64, connID string) (bool, error) {
+	connections, err := s.store.GetConnections(ctx, []int64{userID})
+	if err != nil {
+		return false, err
+	}
+
+	for _, conn := range connections[userID] {
+		if conn.ID != connID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// resolve builds the presence of each user as seen by viewerID, in request order
+func (s *presenceServiceImpl) resolve(ctx context.Context, viewerID int64, userIDs []int64) ([]*entity.Presence, error) {
+	connections, err := s.store.GetConnections(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lastSeen, err := s.repo.GetLastSeen(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden, err := s.repo.GetHideLastSeen(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := make(map[int64]bool, len(userIDs))
+	presences := make([]*entity.Presence, 0, len(userIDs))
+
+	for _, userID := range userIDs {
+		stored, exists := lastSeen[userID]
+		if !exists || seen[userID] {
+			continue // Unknown or deleted users, duplicates
+		}
+		seen[userID] = true
+
+		presence := &entity.Presence{
+			UserID: userID,
+			Status: entity.ResolveStatus(connections[userID], now, s.cfg.AwayAfter),
+		}
+
+		if presence.Status == entity.StatusOffline {
+			presence.LastSeen = stored
+		} else {
+			presence.LastSeen = entity.LastActive(connections[userID])
+		}
+
+		if hidden[userID] && viewerID != userID {
+			presence.LastSeen = nil
+		}
+
+		presences = append(presences, presence)
+	}
+
+	return presences, nil
+}
+
+func (s *presenceServiceImpl) ClientConnected(userID int64, connID string) {
+	s.track(userID, func(ctx context.Context) error {
+		return s.store.Touch(ctx, userID, connID, true)
+	})
+}
+
+func (s *presenceServiceImpl) ClientHeartbeat(userID int64, connID string) {
+	s.track(userID, func(ctx context.Context) error {
+		return s.store.Touch(ctx, userID, connID, false)
+	})
+}
+
+func (s *presenceServiceImpl) ClientStatusChanged(userID int64, connID string, status string) {
+	s.track(userID, func(ctx context.Context) error {
+		switch entity.Status(status) {
+		case entity.StatusOnline:
+			return s.store.Touch(ctx, userID, connID, true)
+		case entity.StatusAway:
+			return s.store.SetAway(ctx, userID, connID)
+		default:
+			return entity.ErrInvalidStatus
+		}
+	})
+}
+
+func (s *presenceServiceImpl) MarkActive(userID int64) {
+	s.track(userID, func(ctx context.Context) error {
+		return s.store.MarkActive(ctx, userID)
+	})
+}
+
+func (s *presenceServiceImpl) ClientDisconnected(userID int64, connID string) {
+	s.track(userID, func(ctx context.Context) error {
+		return s.store.Remove(ctx, userID, connID)
+	})
+}
+
+// track applies a connection change and, if the user's aggregated status
+// changed, persists last_seen when going offline and notifies their contacts.
+// Changes that happen with time alone are found by sweep.
+func (s *presenceServiceImpl) track(userID int64, change func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), observerTimeout)
+	defer cancel()
+
+	before, _, err := s.status(ctx, userID)
+	if err != nil {
+		log.Printf("Presence: failed to get status of user %d: %v", userID, err)
+		return
+	}
+
+	if err := change(ctx); err != nil {
+		log.Printf("Presence: failed to update user %d: %v", userID, err)
+		return
+	}
+
+	after, connections, err := s.status(ctx, userID)
+	if err != nil {
+		log.Printf("Presence: failed to get status of user %d: %v", userID, err)
+		return
+	}
+
+	if before == after {
+		return
+	}
+
+	s.transition(ctx, userID, after, connections)
+}
+
+// transition persists last_seen when the user went offline, notifies their
+// contacts and schedules the next check for changes no client reports
+func (s *presenceServiceImpl) transition(ctx context.Context, userID int64, status entity.Status, connections []entity.Connection) {
+	now := time.Now()
+	if status == entity.StatusOffline {
+		if err := s.repo.UpdateLastSeen(ctx, userID, now); err != nil {
+			log.Printf("Presence: failed to persist last seen of user %d: %v", userID, err)
+		}
+	}
+
+	s.publish(ctx, userID)
+
+	if err := s.store.Watch(ctx, userID, status, entity.NextChange(connections, now, s.cfg.AwayAfter)); err != nil {
+		log.Printf("Presence: failed to watch user %d: %v", userID, err)
+	}
+}
+
+func (s *presenceServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweep(ctx, now)
+		}
+	}
+}
+
+// sweep publishes the changes no client action causes: connections that
+// expired because their node died or lost them, and users gone idle. The
+// claim is atomic in the store, so every replica can sweep.
+func (s *presenceServiceImpl) sweep(ctx context.Context, now time.Time) {
+	for {
+		due, err := s.store.ClaimDue(ctx, now, sweepLease, sweepBatchSize)
+		if err != nil {
+			log.Printf("Presence: failed to claim due checks: %v", err)
+			return
+		}
+
+		for userID, published := range due {
+			s.check(ctx, userID, published)
+		}
+
+		if len(due) < sweepBatchSize {
+			return
+		}
+	}
+}
+
+// check compares a user's status with the one last published for them
+func (s *presenceServiceImpl) check(ctx context.Context, userID int64, published entity.Status) {
+	ctx, cancel := context.WithTimeout(ctx, observerTimeout)
+	defer cancel()
+
+	status, connections, err := s.status(ctx, userID)
+	if err != nil {
+		log.Printf("Presence: failed to get status of user %d: %v", userID, err)
+		return
+	}
+
+	if status != published {
+		s.transition(ctx, userID, status, connections)
+		return
+	}
+
+	if err := s.store.Watch(ctx, userID, status, entity.NextChange(connections, time.Now(), s.cfg.AwayAfter)); err != nil {
+		log.Printf("Presence: failed to watch user %d: %v", userID, err)
+	}
+}
+
+func (s *presenceServiceImpl) status(ctx context.Context, userID int64) (entity.Status, []entity.Connection, error) {
+	connections, err := s.store.GetConnections(ctx, []int64{userID})
+	if err != nil {
+		return "", nil, err
+	}
+	return entity.ResolveStatus(connections[userID], time.Now(), s.cfg.AwayAfter), connections[userID], nil
+}
+
+// publish pushes the user's presence to their contacts as seen by others
+func (s *presenceServiceImpl) publish(ctx context.Context, userID int64) {
+	watchers, err := s.repo.GetWatchers(ctx, userID)
+	if err != nil {
+		log.Printf("Presence: failed to get watchers of user %d: %v", userID, err)
+		return
+	}
+	if len(watchers) == 0 {
+		return
+	}
+
+	presences, err := s.resolve(ctx, 0, []int64{userID})
+	if err != nil || len(presences) == 0 {
+		return
+	}
+
+	event := websocket.WSMessage{
+		Type: "presence.changed",
+		Data: dto.NewPresenceResponse(presences[0]),
+	}
+	if err := s.hub.BroadcastEphemeral(watchers, event); err != nil {
+		log.Printf("Presence: failed to publish presence of user %d: %v", userID, err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/repository"
+	presenceStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/infrastructure/persistence/redis"
+)
+
+// fakePresenceRepo records persisted last seen times and published changes.
+// It reports no watchers, so nothing reaches the hub.
+type fakePresenceRepo struct {
+	repository.PresenceRepository
+	lastSeen  map[int64]time.Time
+	published int
+}
+
+func (f *fakePresenceRepo) UpdateLastSeen(ctx context.Context, userID int64, lastSeen time.Time) error {
+	f.lastSeen[userID] = lastSeen
+	return nil
+}
+
+func (f *fakePresenceRepo) GetWatchers(ctx context.Context, userID int64) ([]int64, error) {
+	f.published++
+	return nil, nil
+}
+
+func TestSweepPublishesChangesNoClientReports(t *testing.T) {
+	tests := []struct {
+		name          string
+		ttl           time.Duration
+		awayAfter     time.Duration
+		wantPublished bool
+		wantLastSeen  bool
+	}{
+		{name: "connection of a crashed node expires", ttl: 20 * time.Millisecond, awayAfter: time.Hour, wantPublished: true, wantLastSeen: true},
+		{name: "user goes idle", ttl: time.Hour, awayAfter: 20 * time.Millisecond, wantPublished: true},
+		{name: "user still online", ttl: time.Hour, awayAfter: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePresenceRepo{lastSeen: make(map[int64]time.Time)}
+			svc := NewPresenceService(presenceStore.NewMemoryPresenceStore(tt.ttl), repo, nil,
+				Config{TTL: tt.ttl, AwayAfter: tt.awayAfter}).(*presenceServiceImpl)
+
+			// Connecting publishes online; the node then stops sending anything
+			svc.ClientConnected(1, "conn-1")
+			if repo.published != 1 {
+				t.Fatalf("published %d changes on connect, want 1", repo.published)
+			}
+
+			time.Sleep(30 * time.Millisecond)
+			svc.sweep(context.Background(), time.Now())
+
+			if published := repo.published > 1; published != tt.wantPublished {
+				t.Fatalf("published %d changes, want a change published %v", repo.published, tt.wantPublished)
+			}
+			if _, ok := repo.lastSeen[1]; ok != tt.wantLastSeen {
+				t.Fatalf("last seen persisted %v, want %v", ok, tt.wantLastSeen)
+			}
+
+			// A second sweep does not announce the same change again
+			published := repo.published
+			svc.sweep(context.Background(), time.Now().Add(time.Minute))
+			if repo.published != published {
+				t.Fatalf("published %d changes after a second sweep, want %d", repo.published, published)
+			}
+		})
+	}
+}
+
+func TestNextChange(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		connections []entity.Connection
+		want        time.Time
+	}{
+		{name: "no connections"},
+		{
+			name:        "active connection goes idle first",
+			connections: []entity.Connection{{ExpiresAt: now.Add(2 * time.Minute), LastActiveAt: now.Add(-4 * time.Minute)}},
+			want:        now.Add(time.Minute),
+		},
+		{
+			name:        "away connection only expires",
+			connections: []entity.Connection{{ExpiresAt: now.Add(time.Minute), LastActiveAt: now, Away: true}},
+			want:        now.Add(time.Minute),
+		},
+		{
+			name:        "idle connection only expires",
+			connections: []entity.Connection{{ExpiresAt: now.Add(time.Minute), LastActiveAt: now.Add(-time.Hour)}},
+			want:        now.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entity.NextChange(tt.connections, now, 5*time.Minute); !got.Equal(tt.want) {
+				t.Fatalf("NextChange = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
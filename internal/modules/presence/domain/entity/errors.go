@@ -0,0 +1,9 @@
+package entity
+
+import "errors"
+
+var (
+	ErrTooManyUsers   = errors.New("too many user IDs requested")
+	ErrInvalidStatus  = errors.New("invalid presence status")
+	ErrInvalidUserIDs = errors.New("invalid user IDs")
+)
@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+type Status string
+
+const (
+	StatusOnline  Status = "online"
+	StatusAway    Status = "away"
+	StatusOffline Status = "offline"
+)
+
+// Connection is one live client connection of a user, kept alive by heartbeats
+type Connection struct {
+	ID           string
+	ExpiresAt    time.Time
+	LastActiveAt time.Time
+	Away         bool
+}
+
+// Presence is the aggregated presence of a user across all connections and nodes
+type Presence struct {
+	UserID   int64
+	Status   Status
+	LastSeen *time.Time
+}
+
+// ResolveStatus derives a user's status from their connections.
+// The user is online if any live connection was active within awayAfter and
+// not explicitly marked away, away if connected otherwise, and offline if
+// every connection has expired.
+func ResolveStatus(connections []Connection, now time.Time, awayAfter time.Duration) Status {
+	status := StatusOffline
+
+	for _, conn := range connections {
+		if !conn.ExpiresAt.After(now) {
+			continue
+		}
+		if !conn.Away && now.Sub(conn.LastActiveAt) < awayAfter {
+			return StatusOnline
+		}
+		status = StatusAway
+	}
+
+	return status
+}
+
+// LastActive returns the most recent activity across connections, or nil if none
+func LastActive(connections []Connection) *time.Time {
+	var latest *time.Time
+	for i := range connections {
+		if latest == nil || connections[i].LastActiveAt.After(*latest) {
+			latest = &connections[i].LastActiveAt
+		}
+	}
+	return latest
+}
+
+// NextChange returns when ResolveStatus could next change without any client
+// action, as a connection expires or goes idle, or the zero time if the user
+// has no live connection
+func NextChange(connections []Connection, now time.Time, awayAfter time.Duration) time.Time {
+	var next time.Time
+	earliest := func(at time.Time) {
+		if at.After(now) && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+
+	for _, conn := range connections {
+		if !conn.ExpiresAt.After(now) {
+			continue
+		}
+		earliest(conn.ExpiresAt)
+		if !conn.Away {
+			earliest(conn.LastActiveAt.Add(awayAfter))
+		}
+	}
+
+	return next
+}
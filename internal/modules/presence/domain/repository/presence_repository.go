@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+)
+
+// PresenceStore holds ephemeral per-connection presence shared by all nodes
+type PresenceStore interface {
+	// Touch extends the connection's lifetime. If active is true its last activity is set to now and away is cleared.
+	Touch(ctx context.Context, userID int64, connID string, active bool) error
+	SetAway(ctx context.Context, userID int64, connID string) error
+
+	// MarkActive sets the last activity of every live connection of the user to now and clears away.
+	// It never creates connections, so it cannot revive one that was just removed.
+	MarkActive(ctx context.Context, userID int64) error
+
+	Remove(ctx context.Context, userID int64, connID string) error
+	GetConnections(ctx context.Context, userIDs []int64) (map[int64][]entity.Connection, error)
+
+	// Watch records the status last published for the user and when it should
+	// be checked again, because a connection may expire or go idle by then.
+	// A zero checkAt forgets the user.
+	Watch(ctx context.Context, userID int64, status entity.Status, checkAt time.Time) error
+
+	// ClaimDue returns up to limit users whose check is due, with the status
+	// last published for each, and pushes their check back by lease. Each due
+	// user goes to one caller, even across nodes; one that is not watched again
+	// within lease is returned again.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) (map[int64]entity.Status, error)
+}
+
+// PresenceRepository persists durable presence data in the users and contacts tables
+type PresenceRepository interface {
+	UpdateLastSeen(ctx context.Context, userID int64, lastSeen time.Time) error
+	GetLastSeen(ctx context.Context, userIDs []int64) (map[int64]*time.Time, error)
+	SetHideLastSeen(ctx context.Context, userID int64, hide bool) error
+	GetHideLastSeen(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+
+	// GetWatchers returns users who have userID in their contacts and are not blocked by them
+	GetWatchers(ctx context.Context, userID int64) ([]int64, error)
+}
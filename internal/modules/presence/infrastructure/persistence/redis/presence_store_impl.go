@@ -0,0 +1,234 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/repository"
+)
+
+// connectionRecord is the JSON stored per connection in the user's presence hash
+type connectionRecord struct {
+	ExpiresAt    int64 `json:"exp"`
+	LastActiveAt int64 `json:"active"`
+	Away         bool  `json:"away"`
+}
+
+type presenceStoreImpl struct {
+	client *goredis.Client
+	ttl    time.Duration
+}
+
+// NewPresenceStore creates a Redis presence store. A connection that misses
+// heartbeats for ttl (e.g. its node crashed) is treated as gone.
+func NewPresenceStore(client *goredis.Client, ttl time.Duration) repository.PresenceStore {
+	return &presenceStoreImpl{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func presenceKey(userID int64) string {
+	return fmt.Sprintf("presence:%d", userID)
+}
+
+const (
+	// Sorted set of watched users scored by when to check them, in milliseconds
+	presenceDueKey = "presence:due"
+
+	// Hash of the status last published per watched user
+	presencePublishedKey = "presence:published"
+)
+
+// updateScript rewrites one connection record in a single step, so concurrent
+// updates of the same user's hash never write back stale data.
+// KEYS[1] is the presence hash; ARGV is the connection ID, now and TTL in
+// milliseconds, and the mode: "active", "passive" or "away".
+var updateScript = goredis.NewScript(`
+local record = {}
+local raw = redis.call("HGET", KEYS[1], ARGV[1])
+if raw then
+	local ok, decoded = pcall(cjson.decode, raw)
+	if ok and type(decoded) == "table" then
+		record = decoded
+	end
+end
+
+local now = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local mode = ARGV[4]
+
+if mode == "active" or not record.active or record.active == 0 then
+	record.active = now
+	record.away = false
+end
+if mode == "away" then
+	record.away = true
+end
+record.exp = now + ttl
+
+redis.call("HSET", KEYS[1], ARGV[1], cjson.encode(record))
+redis.call("PEXPIRE", KEYS[1], ttl)
+return 1
+`)
+
+// activityScript marks every live connection in the hash as active now.
+// KEYS[1] is the presence hash; ARGV[1] is now in milliseconds.
+var activityScript = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+local fields = redis.call("HGETALL", KEYS[1])
+local updated = 0
+
+for i = 1, #fields, 2 do
+	local ok, record = pcall(cjson.decode, fields[i + 1])
+	if ok and type(record) == "table" and tonumber(record.exp or 0) > now then
+		record.active = now
+		record.away = false
+		redis.call("HSET", KEYS[1], fields[i], cjson.encode(record))
+		updated = updated + 1
+	end
+end
+
+return updated
+`)
+
+// claimScript returns due users with their published status and pushes their
+// check back by the lease in one step, so each goes to one node.
+// KEYS are the due set and published hash; ARGV is now, the lease in
+// milliseconds and the limit.
+var claimScript = goredis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, tonumber(ARGV[3]))
+local result = {}
+local leased = tonumber(ARGV[1]) + tonumber(ARGV[2])
+
+for _, userID in ipairs(due) do
+	redis.call("ZADD", KEYS[1], leased, userID)
+	table.insert(result, userID)
+	table.insert(result, redis.call("HGET", KEYS[2], userID) or "")
+end
+
+return result
+`)
+
+func (s *presenceStoreImpl) Touch(ctx context.Context, userID int64, connID string, active bool) error {
+	mode := "passive"
+	if active {
+		mode = "active"
+	}
+	return s.update(ctx, userID, connID, mode)
+}
+
+func (s *presenceStoreImpl) SetAway(ctx context.Context, userID int64, connID string) error {
+	return s.update(ctx, userID, connID, "away")
+}
+
+func (s *presenceStoreImpl) update(ctx context.Context, userID int64, connID string, mode string) error {
+	now := time.Now().UnixMilli()
+	err := updateScript.Run(ctx, s.client, []string{presenceKey(userID)}, connID, now, s.ttl.Milliseconds(), mode).Err()
+	if err != nil {
+		return fmt.Errorf("failed to update presence: %w", err)
+	}
+	return nil
+}
+
+func (s *presenceStoreImpl) MarkActive(ctx context.Context, userID int64) error {
+	err := activityScript.Run(ctx, s.client, []string{presenceKey(userID)}, time.Now().UnixMilli()).Err()
+	if err != nil {
+		return fmt.Errorf("failed to mark presence active: %w", err)
+	}
+	return nil
+}
+
+func (s *presenceStoreImpl) Remove(ctx context.Context, userID int64, connID string) error {
+	if err := s.client.HDel(ctx, presenceKey(userID), connID).Err(); err != nil {
+		return fmt.Errorf("failed to remove presence: %w", err)
+	}
+	return nil
+}
+
+func (s *presenceStoreImpl) GetConnections(ctx context.Context, userIDs []int64) (map[int64][]entity.Connection, error) {
+	pipe := s.client.Pipeline()
+	cmds := make(map[int64]*goredis.MapStringStringCmd, len(userIDs))
+	for _, userID := range userIDs {
+		cmds[userID] = pipe.HGetAll(ctx, presenceKey(userID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("failed to get presence: %w", err)
+	}
+
+	now := time.Now()
+	result := make(map[int64][]entity.Connection, len(userIDs))
+
+	for userID, cmd := range cmds {
+		var stale []string
+		for connID, raw := range cmd.Val() {
+			var record connectionRecord
+			if err := json.Unmarshal([]byte(raw), &record); err != nil {
+				stale = append(stale, connID)
+				continue
+			}
+
+			conn := entity.Connection{
+				ID:           connID,
+				ExpiresAt:    time.UnixMilli(record.ExpiresAt),
+				LastActiveAt: time.UnixMilli(record.LastActiveAt),
+				Away:         record.Away,
+			}
+			if !conn.ExpiresAt.After(now) {
+				stale = append(stale, connID)
+				continue
+			}
+			result[userID] = append(result[userID], conn)
+		}
+
+		// Lazily clean up connections left behind by crashed nodes
+		if len(stale) > 0 {
+			s.client.HDel(ctx, presenceKey(userID), stale...)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *presenceStoreImpl) Watch(ctx context.Context, userID int64, status entity.Status, checkAt time.Time) error {
+	member := strconv.FormatInt(userID, 10)
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		if checkAt.IsZero() {
+			pipe.ZRem(ctx, presenceDueKey, member)
+			pipe.HDel(ctx, presencePublishedKey, member)
+			return nil
+		}
+		pipe.ZAdd(ctx, presenceDueKey, goredis.Z{Score: float64(checkAt.UnixMilli()), Member: member})
+		pipe.HSet(ctx, presencePublishedKey, member, string(status))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch presence: %w", err)
+	}
+	return nil
+}
+
+func (s *presenceStoreImpl) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) (map[int64]entity.Status, error) {
+	values, err := claimScript.Run(ctx, s.client, []string{presenceDueKey, presencePublishedKey},
+		now.UnixMilli(), lease.Milliseconds(), limit).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim presence checks: %w", err)
+	}
+
+	due := make(map[int64]entity.Status, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		userID, err := strconv.ParseInt(values[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		due[userID] = entity.Status(values[i+1])
+	}
+	return due, nil
+}
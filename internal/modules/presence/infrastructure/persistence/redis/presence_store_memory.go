@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/repository"
+)
+
+type memoryPresenceStore struct {
+	ttl         time.Duration
+	connections map[int64]map[string]*entity.Connection
+	due         map[int64]time.Time
+	published   map[int64]entity.Status
+	mu          sync.Mutex
+}
+
+// NewMemoryPresenceStore creates an in-process presence store used when Redis is unavailable.
+// It only sees connections of the local node.
+func NewMemoryPresenceStore(ttl time.Duration) repository.PresenceStore {
+	return &memoryPresenceStore{
+		ttl:         ttl,
+		connections: make(map[int64]map[string]*entity.Connection),
+		due:         make(map[int64]time.Time),
+		published:   make(map[int64]entity.Status),
+	}
+}
+
+func (s *memoryPresenceStore) Touch(ctx context.Context, userID int64, connID string, active bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	conn := s.connection(userID, connID)
+	if active || conn.LastActiveAt.IsZero() {
+		conn.LastActiveAt = now
+		conn.Away = false
+	}
+	conn.ExpiresAt = now.Add(s.ttl)
+
+	return nil
+}
+
+func (s *memoryPresenceStore) SetAway(ctx context.Context, userID int64, connID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn := s.connection(userID, connID)
+	conn.Away = true
+	conn.ExpiresAt = time.Now().Add(s.ttl)
+
+	return nil
+}
+
+func (s *memoryPresenceStore) MarkActive(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, conn := range s.connections[userID] {
+		if conn.ExpiresAt.After(now) {
+			conn.LastActiveAt = now
+			conn.Away = false
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryPresenceStore) Remove(ctx context.Context, userID int64, connID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.connections[userID], connID)
+	if len(s.connections[userID]) == 0 {
+		delete(s.connections, userID)
+	}
+
+	return nil
+}
+
+func (s *memoryPresenceStore) GetConnections(ctx context.Context, userIDs []int64) (map[int64][]entity.Connection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	result := make(map[int64][]entity.Connection, len(userIDs))
+	for _, userID := range userIDs {
+		for _, conn := range s.connections[userID] {
+			if conn.ExpiresAt.After(now) {
+				result[userID] = append(result[userID], *conn)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *memoryPresenceStore) Watch(ctx context.Context, userID int64, status entity.Status, checkAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if checkAt.IsZero() {
+		delete(s.due, userID)
+		delete(s.published, userID)
+		return nil
+	}
+	s.due[userID] = checkAt
+	s.published[userID] = status
+
+	return nil
+}
+
+func (s *memoryPresenceStore) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) (map[int64]entity.Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make(map[int64]entity.Status)
+	for userID, checkAt := range s.due {
+		if len(due) >= limit {
+			break
+		}
+		if checkAt.After(now) {
+			continue
+		}
+		s.due[userID] = now.Add(lease)
+		due[userID] = s.published[userID]
+	}
+
+	return due, nil
+}
+
+func (s *memoryPresenceStore) connection(userID int64, connID string) *entity.Connection {
+	conns, ok := s.connections[userID]
+	if !ok {
+		conns = make(map[string]*entity.Connection)
+		s.connections[userID] = conns
+	}
+
+	conn, ok := conns[connID]
+	if !ok {
+		conn = &entity.Connection{ID: connID}
+		conns[connID] = conn
+	}
+
+	return conn
+}
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+)
+
+func TestMarkActiveBringsAwayConnectionsBackOnline(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryPresenceStore(time.Minute)
+
+	if err := store.Touch(ctx, 1, "phone", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetAway(ctx, 1, "phone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkActive(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	connections, err := store.GetConnections(ctx, []int64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := entity.ResolveStatus(connections[1], time.Now(), time.Minute); status != entity.StatusOnline {
+		t.Fatalf("status = %s, want %s", status, entity.StatusOnline)
+	}
+}
+
+func TestMarkActiveDoesNotReviveRemovedConnections(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryPresenceStore(time.Minute)
+
+	if err := store.Touch(ctx, 1, "phone", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Remove(ctx, 1, "phone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkActive(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	connections, err := store.GetConnections(ctx, []int64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections[1]) != 0 {
+		t.Fatalf("got %d connections after remove, want none", len(connections[1]))
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/repository"
+)
+
+type presenceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPresenceRepository creates a presence repository over the users and contacts tables
+func NewPresenceRepository(db *gorm.DB) repository.PresenceRepository {
+	return &presenceRepositoryImpl{db: db}
+}
+
+func (r *presenceRepositoryImpl) UpdateLastSeen(ctx context.Context, userID int64, lastSeen time.Time) error {
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ?", userID).
+		UpdateColumn("last_seen", lastSeen).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to update last seen: %w", err)
+	}
+
+	return nil
+}
+
+func (r *presenceRepositoryImpl) GetLastSeen(ctx context.Context, userIDs []int64) (map[int64]*time.Time, error) {
+	var rows []struct {
+		ID       int64
+		LastSeen *time.Time
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, last_seen").
+		Where("id IN ? AND is_deleted = ?", userIDs, false).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last seen: %w", err)
+	}
+
+	lastSeen := make(map[int64]*time.Time, len(rows))
+	for _, row := range rows {
+		lastSeen[row.ID] = row.LastSeen
+	}
+
+	return lastSeen, nil
+}
+
+func (r *presenceRepositoryImpl) SetHideLastSeen(ctx context.Context, userID int64, hide bool) error {
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ?", userID).
+		UpdateColumn("hide_last_seen", hide).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to update last seen privacy: %w", err)
+	}
+
+	return nil
+}
+
+func (r *presenceRepositoryImpl) GetHideLastSeen(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	var rows []struct {
+		ID           int64
+		HideLastSeen bool
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, hide_last_seen").
+		Where("id IN ?", userIDs).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last seen privacy: %w", err)
+	}
+
+	hidden := make(map[int64]bool, len(rows))
+	for _, row := range rows {
+		hidden[row.ID] = row.HideLastSeen
+	}
+
+	return hidden, nil
+}
+
+func (r *presenceRepositoryImpl) GetWatchers(ctx context.Context, userID int64) ([]int64, error) {
+	var watchers []int64
+
+	// Skip users that userID has blocked
+	err := r.db.WithContext(ctx).
+		Table("contacts").
+		Where("contact_user_id = ? AND is_blocked = ?", userID, false).
+		Where("user_id NOT IN (?)", r.db.Table("contacts").
+			Select("contact_user_id").
+			Where("user_id = ? AND is_blocked = ?", userID, true)).
+		Pluck("user_id", &watchers).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presence watchers: %w", err)
+	}
+
+	return watchers, nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type PresenceHandler struct {
+	presenceService service.PresenceService
+}
+
+func NewPresenceHandler(presenceService service.PresenceService) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService: presenceService,
+	}
+}
+
+// GetPresence godoc
+// @Summary Get presence of users
+// @Description Get online/away/offline status and last seen for up to 100 users
+// @Tags presence
+// @Produce json
+// @Security BearerAuth
+// @Param user_ids query string true "Comma separated user IDs"
+// @Success 200 {object} response.Response{data=dto.PresenceListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /presence [get]
+func (h *PresenceHandler) GetPresence(c *gin.Context) {
+	viewerID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var userIDs []int64
+	for _, part := range strings.Split(c.Query("user_ids"), ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid user IDs", entity.ErrInvalidUserIDs)
+			return
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	presences, err := h.presenceService.GetPresence(c.Request.Context(), viewerID, userIDs)
+	if err != nil {
+		if errors.Is(err, entity.ErrTooManyUsers) || errors.Is(err, entity.ErrInvalidUserIDs) {
+			response.Error(c, http.StatusBadRequest, "Invalid user IDs", err)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get presence", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Presence retrieved successfully", presences)
+}
+
+// UpdateSettings godoc
+// @Summary Update presence privacy
+// @Description Hide or show the caller's last seen time to other users
+// @Tags presence
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdatePresenceSettingsRequest true "Presence settings"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /presence/settings [put]
+func (h *PresenceHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.UpdatePresenceSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.presenceService.UpdateSettings(c.Request.Context(), userID, req); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to update presence settings", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Presence settings updated successfully", nil)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/presentation/http/handler"
+)
+
+// RegisterPresenceRoutes registers all presence-related routes
+func RegisterPresenceRoutes(router *gin.RouterGroup, presenceHandler *handler.PresenceHandler, auth gin.HandlerFunc) {
+	presence := router.Group("/presence", auth)
+	{
+		presence.GET("", presenceHandler.GetPresence)
+		presence.PUT("/settings", presenceHandler.UpdateSettings)
+	}
+}
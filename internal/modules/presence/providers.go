@@ -0,0 +1,102 @@
+package presence
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/domain/repository"
+	presenceStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/infrastructure/persistence/redis"
+	presenceRepo "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/infrastructure/persistence/repository"
+	presenceHandler "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/presentation/http/handler"
+	presenceRouter "github.com/ndxbinh1922001/VNalo-be/internal/modules/presence/presentation/http/router"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+)
+
+// Module provides presence module dependencies
+var Module = fx.Options(
+	fx.Provide(provideStore),
+	fx.Provide(provideRepository),
+	fx.Provide(provideService),
+	fx.Provide(provideHandler),
+	fx.Provide(
+		fx.Annotate(
+			provideRouteRegistration,
+			fx.ResultTags(`group:"routes"`),
+		),
+	),
+	fx.Invoke(observeConnections),
+	fx.Invoke(runSweeper),
+)
+
+func provideStore(redisClient *redis.Client, cfg infrastructure.Config) repository.PresenceStore {
+	ttl := time.Duration(cfg.GetPresenceConfig().TTL) * time.Second
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, presence is tracked for this node only")
+		return presenceStore.NewMemoryPresenceStore(ttl)
+	}
+
+	log.Println("📦 Creating presence store...")
+	return presenceStore.NewPresenceStore(redisClient, ttl)
+}
+
+func provideRepository(db *gorm.DB) repository.PresenceRepository {
+	log.Println("📦 Creating presence repository...")
+	return presenceRepo.NewPresenceRepository(db)
+}
+
+func provideService(
+	store repository.PresenceStore,
+	repo repository.PresenceRepository,
+	hub *websocket.Hub,
+	cfg infrastructure.Config,
+) service.PresenceService {
+	log.Println("⚙️  Creating presence service...")
+	presenceCfg := cfg.GetPresenceConfig()
+	return service.NewPresenceService(store, repo, hub, service.Config{
+		TTL:       time.Duration(presenceCfg.TTL) * time.Second,
+		AwayAfter: time.Duration(presenceCfg.AwayAfter) * time.Second,
+	})
+}
+
+func provideHandler(svc service.PresenceService) *presenceHandler.PresenceHandler {
+	log.Println("🎯 Creating presence handler...")
+	return presenceHandler.NewPresenceHandler(svc)
+}
+
+// observeConnections lets the presence service track WebSocket connections
+func observeConnections(hub *websocket.Hub, svc service.PresenceService) {
+	hub.AddObserver(svc)
+}
+
+// runSweeper publishes presence changes that no client reports for the app lifetime
+func runSweeper(lc fx.Lifecycle, svc service.PresenceService) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go svc.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// provideRouteRegistration returns a function to register presence routes
+func provideRouteRegistration(h *presenceHandler.PresenceHandler, tokens *token.Manager, sessions middleware.SessionChecker) func(*gin.RouterGroup) {
+	return func(router *gin.RouterGroup) {
+		log.Println("✅ Registering presence routes...")
+		presenceRouter.RegisterPresenceRoutes(router, h, middleware.Auth(tokens, sessions))
+	}
+}
@@ -13,11 +13,16 @@ type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,min=3"`
 }
 
-// UpdateUserRequest represents the input for updating a user
+// UpdateUserRequest represents the input for updating a user. The account
+// status and role are changed through their own admin routes.
 type UpdateUserRequest struct {
 	Username *string `json:"username,omitempty" validate:"omitempty,min=3"`
 	Language *string `json:"language,omitempty" validate:"omitempty,min=2,max=5"`
-	Status   *int    `json:"status,omitempty" validate:"omitempty,oneof=1 2"`
+}
+
+// AssignRoleRequest sets the system role of an account
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
 }
 
 // ChangePasswordRequest represents the input for changing password
@@ -26,17 +31,50 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password" validate:"required,min=8"`
 }
 
+// LoginRequest represents the input for logging in
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LoginResponse represents an issued access token. When the account needs a
+// second factor, only the challenge fields are set and the login is finished
+// at /auth/2fa/verify.
+type LoginResponse struct {
+	AccessToken string        `json:"access_token,omitempty"`
+	TokenType   string        `json:"token_type,omitempty"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
+	User        *UserResponse `json:"user,omitempty"`
+
+	TwoFactorRequired      bool       `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool       `json:"two_factor_setup_required,omitempty"` // Enroll at /auth/2fa/challenge/setup first
+	ChallengeToken         string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt     *time.Time `json:"challenge_expires_at,omitempty"`
+
+	// Set once, when a login also finished an enforced enrollment
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
 // UserResponse represents the output for user data
 type UserResponse struct {
-	ID            int64     `json:"id"`
-	Email         string    `json:"email"`
-	Username      string    `json:"username"`
-	Status        int       `json:"status"`
-	Language      string    `json:"language"`
-	IsVIP         bool      `json:"is_vip"`
-	LastLoginTime int64     `json:"last_login_time"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int64       `json:"id"`
+	Email         string      `json:"email"`
+	EmailVerified bool        `json:"email_verified"`
+	Phone         string      `json:"phone,omitempty"`
+	Username      string      `json:"username"`
+	DisplayName   string      `json:"display_name,omitempty"`
+	Bio           string      `json:"bio,omitempty"`
+	Avatar        *AvatarURLs `json:"avatar,omitempty"`
+	Status        int         `json:"status"`
+	Role          string      `json:"role"`
+	Language      string      `json:"language"`
+	IsVIP         bool        `json:"is_vip"`
+	LastLoginTime int64       `json:"last_login_time"` // Unix seconds; 0 before the first login
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+
+	// When the account will be purged, while its deletion can still be canceled
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
 // NewUserResponse converts domain entity to DTO
@@ -44,14 +82,138 @@ func NewUserResponse(user *entity.User) *UserResponse {
 	return &UserResponse{
 		ID:            user.ID,
 		Email:         user.Email.Value(),
+		EmailVerified: user.IsEmailVerified(),
+		Phone:         user.Phone.Value(),
 		Username:      user.Username,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
 		Status:        int(user.Status),
+		Role:          string(user.Role),
 		Language:      user.Language,
 		IsVIP:         user.IsVIP,
-		LastLoginTime: user.LastLoginTime,
+		LastLoginTime: lastLoginTime(user),
 		CreatedAt:     user.CreatedAt,
 		UpdatedAt:     user.UpdatedAt,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+}
+
+// lastLoginTime keeps the Unix timestamp clients were built against
+func lastLoginTime(user *entity.User) int64 {
+	if user.LastLoginAt == nil {
+		return 0
+	}
+	return user.LastLoginAt.Unix()
+}
+
+// WithAvatar sets the avatar URLs, which depend on where blobs are served
+func (r *UserResponse) WithAvatar(avatar *AvatarURLs) *UserResponse {
+	r.Avatar = avatar
+	return r
+}
+
+// UpdateProfileRequest changes the public profile. Omitted fields are kept;
+// empty strings clear them.
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name,omitempty" validate:"omitempty,max=256"`
+	Bio         *string `json:"bio,omitempty" validate:"omitempty,max=2000"`
+}
+
+// AvatarCrop selects the square of an uploaded image to keep, in pixels from
+// its top-left corner. A zero Size keeps the largest centred square.
+type AvatarCrop struct {
+	X    int
+	Y    int
+	Size int
+}
+
+// AvatarURLs are the square renditions of an avatar. An external avatar has
+// the same URL for every size.
+type AvatarURLs struct {
+	Small  string `json:"small"`  // 64 px
+	Medium string `json:"medium"` // 256 px
+	Large  string `json:"large"`  // 512 px
+}
+
+// PublicProfileResponse is what other users see of an account. Email and
+// phone are only shown to people the owner saved as contacts.
+type PublicProfileResponse struct {
+	ID          int64       `json:"id"`
+	Username    string      `json:"username"`
+	DisplayName string      `json:"display_name,omitempty"`
+	Bio         string      `json:"bio,omitempty"`
+	Avatar      *AvatarURLs `json:"avatar,omitempty"`
+	IsVIP       bool        `json:"is_vip"`
+	Email       string      `json:"email,omitempty"`
+	Phone       string      `json:"phone,omitempty"`
+
+	// Friends the viewer and the user have in common, when viewing a single profile
+	MutualFriends *int `json:"mutual_friends,omitempty"`
+}
+
+// NewPublicProfileResponse converts domain entity to DTO, with contact details
+// only when showContactInfo is set
+func NewPublicProfileResponse(user *entity.User, avatar *AvatarURLs, showContactInfo bool) *PublicProfileResponse {
+	resp := &PublicProfileResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Avatar:      avatar,
+		IsVIP:       user.IsVIP,
+	}
+	if showContactInfo {
+		resp.Email = user.Email.Value()
+		resp.Phone = user.Phone.Value()
 	}
+	return resp
+}
+
+// UserSearchResponse lists the accounts matching a search, best first
+type UserSearchResponse struct {
+	Users []*PublicProfileResponse `json:"users"`
+}
+
+// PrivacySettingsResponse describes who can find and message the caller
+type PrivacySettingsResponse struct {
+	DiscoverableByPhone   bool `json:"discoverable_by_phone"`
+	OnlyFriendsCanMessage bool `json:"only_friends_can_message"`
+}
+
+// NewPrivacySettingsResponse converts domain entity to DTO
+func NewPrivacySettingsResponse(user *entity.User) *PrivacySettingsResponse {
+	return &PrivacySettingsResponse{
+		DiscoverableByPhone:   user.DiscoverableByPhone,
+		OnlyFriendsCanMessage: user.OnlyFriendsCanMessage,
+	}
+}
+
+// UpdatePrivacySettingsRequest changes who can find and message the caller.
+// Omitted settings are kept.
+type UpdatePrivacySettingsRequest struct {
+	DiscoverableByPhone   *bool `json:"discoverable_by_phone,omitempty"`
+	OnlyFriendsCanMessage *bool `json:"only_friends_can_message,omitempty"`
+}
+
+// SyncContactsRequest carries a batch of an address book. Each hash is the
+// hex SHA-256 of a number in E.164 form, such as +84912345678.
+type SyncContactsRequest struct {
+	Hashes      []string `json:"hashes" validate:"required,min=1"`
+	AddContacts bool     `json:"add_contacts"` // Save the matched accounts as contacts
+}
+
+// ContactMatchResponse is an uploaded number that belongs to a user
+type ContactMatchResponse struct {
+	Hash   string `json:"hash"`
+	UserID int64  `json:"user_id"`
+}
+
+// SyncContactsResponse lists the numbers of a batch that are on VNalo.
+// Numbers without a match are not listed, nor kept.
+type SyncContactsResponse struct {
+	Matches []ContactMatchResponse `json:"matches"`
+	Added   int                    `json:"added"` // Contacts created by this sync
 }
 
 // UserListResponse represents a paginated list of users
@@ -62,3 +224,240 @@ type UserListResponse struct {
 	PageSize   int             `json:"page_size"`
 }
 
+// VerifyEmailRequest confirms an email address with the token from the verification email
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+// ResendVerificationRequest asks for a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ForgotPasswordRequest asks for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with the token from the reset email
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required,max=128"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// RequestOTPRequest asks for a one-time code by SMS
+type RequestOTPRequest struct {
+	Phone   string `json:"phone" validate:"required,max=32"` // E.164 or Vietnamese national format, e.g. 0912345678
+	Purpose string `json:"purpose" validate:"required,oneof=register login"`
+}
+
+// OTPResponse tells the client how long a code stays valid and when it may ask again
+type OTPResponse struct {
+	ExpiresIn  int `json:"expires_in"`  // seconds
+	RetryAfter int `json:"retry_after"` // seconds
+}
+
+// PhoneRegisterRequest creates an account for a phone number proven by a code
+type PhoneRegisterRequest struct {
+	Phone    string `json:"phone" validate:"required,max=32"`
+	Code     string `json:"code" validate:"required,numeric,max=10"`
+	Username string `json:"username" validate:"required,min=3"`
+}
+
+// PhoneLoginRequest logs in with a code sent to the account's phone number
+type PhoneLoginRequest struct {
+	Phone string `json:"phone" validate:"required,max=32"`
+	Code  string `json:"code" validate:"required,numeric,max=10"`
+}
+
+// TwoFactorStatusResponse describes the caller's two-factor authentication
+type TwoFactorStatusResponse struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TwoFactorSetupResponse carries a new TOTP secret. Clients render
+// ProvisioningURI as a QR code for authenticator apps.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest carries a code from the authenticator app
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// DisableTwoFactorRequest re-authenticates the caller before 2FA is turned
+// off. Password is required unless the account has none.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"max=128"`
+	Code     string `json:"code" validate:"required,max=32"` // Authenticator or recovery code
+}
+
+// RecoveryCodesResponse lists recovery codes; they are only ever shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeRequest carries the challenge token from a login response
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorLoginRequest finishes a login with an authenticator or recovery code
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// ClientInfo describes the device a login comes from
+type ClientInfo struct {
+	IP         string
+	UserAgent  string
+	DeviceName string
+	Platform   string
+}
+
+// SessionResponse represents a device the user is logged in on
+type SessionResponse struct {
+	ID           string    `json:"id"`
+	DeviceName   string    `json:"device_name,omitempty"`
+	Platform     string    `json:"platform,omitempty"`
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Current      bool      `json:"current"` // The session of the request's own access token
+}
+
+// NewSessionResponse converts a session entity to its response
+func NewSessionResponse(session *entity.Session, current bool) *SessionResponse {
+	return &SessionResponse{
+		ID:           session.ID,
+		DeviceName:   session.DeviceName,
+		Platform:     session.Platform,
+		IP:           session.IP,
+		UserAgent:    session.UserAgent,
+		CreatedAt:    session.CreatedAt,
+		LastActiveAt: session.LastActiveAt,
+		ExpiresAt:    session.ExpiresAt,
+		Current:      current,
+	}
+}
+
+// RevokeSessionsResponse reports how many sessions were signed out
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// SendFriendRequestRequest asks a user to become friends
+type SendFriendRequestRequest struct {
+	UserID int64 `json:"user_id" validate:"required"`
+}
+
+// FriendRequestResponse is a friend request, with the profile of the user on
+// the other side from the caller
+type FriendRequestResponse struct {
+	ID          int64                  `json:"id"`
+	RequesterID int64                  `json:"requester_id"`
+	AddresseeID int64                  `json:"addressee_id"`
+	Status      string                 `json:"status"` // PENDING or ACCEPTED
+	CreatedAt   time.Time              `json:"created_at"`
+	AcceptedAt  *time.Time             `json:"accepted_at,omitempty"`
+	User        *PublicProfileResponse `json:"user,omitempty"`
+}
+
+// NewFriendRequestResponse converts domain entity to DTO
+func NewFriendRequestResponse(friendship *entity.Friendship, other *PublicProfileResponse) *FriendRequestResponse {
+	return &FriendRequestResponse{
+		ID:          friendship.ID,
+		RequesterID: friendship.RequesterID,
+		AddresseeID: friendship.AddresseeID,
+		Status:      string(friendship.Status),
+		CreatedAt:   friendship.CreatedAt,
+		AcceptedAt:  friendship.AcceptedAt,
+		User:        other,
+	}
+}
+
+// FriendRequestListResponse lists pending friend requests, newest first
+type FriendRequestListResponse struct {
+	Requests []*FriendRequestResponse `json:"requests"`
+}
+
+// FriendListResponse lists the caller's friends
+type FriendListResponse struct {
+	Friends []*PublicProfileResponse `json:"friends"`
+}
+
+// RequestAccountDeletionRequest confirms deleting the caller's account
+type RequestAccountDeletionRequest struct {
+	Password string `json:"password"` // Required unless the account was created by phone
+}
+
+// AccountDeletionResponse tells when the caller's account will be purged
+type AccountDeletionResponse struct {
+	Scheduled   bool       `json:"scheduled"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+// NewAccountDeletionResponse converts domain entity to DTO
+func NewAccountDeletionResponse(user *entity.User) *AccountDeletionResponse {
+	return &AccountDeletionResponse{
+		Scheduled:   user.DeletionScheduledAt != nil,
+		ScheduledAt: user.DeletionScheduledAt,
+	}
+}
+
+// AccountExportResponse describes the latest archive of the caller's data
+type AccountExportResponse struct {
+	ID          int64      `json:"id"`
+	Status      string     `json:"status"` // PENDING, PROCESSING, READY or FAILED
+	SizeBytes   int64      `json:"size_bytes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewAccountExportResponse converts domain entity to DTO
+func NewAccountExportResponse(export *entity.AccountExport) *AccountExportResponse {
+	return &AccountExportResponse{
+		ID:          export.ID,
+		Status:      string(export.Status),
+		SizeBytes:   export.SizeBytes,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
+
+// ContactExport is a saved contact in an archive of the caller's data
+type ContactExport struct {
+	UserID     int64     `json:"user_id"`
+	Nickname   string    `json:"nickname,omitempty"`
+	IsFavorite bool      `json:"is_favorite"`
+	IsBlocked  bool      `json:"is_blocked"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewContactExport converts domain entity to DTO
+func NewContactExport(contact *entity.Contact) *ContactExport {
+	return &ContactExport{
+		UserID:     contact.ContactUserID,
+		Nickname:   contact.Nickname,
+		IsFavorite: contact.IsFavorite,
+		IsBlocked:  contact.IsBlocked,
+		CreatedAt:  contact.CreatedAt,
+	}
+}
+
+// ProfileExport is the account itself in an archive of the caller's data
+type ProfileExport struct {
+	User      *UserResponse            `json:"user"`
+	Privacy   *PrivacySettingsResponse `json:"privacy"`
+	FriendIDs []int64                  `json:"friend_ids"`
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// AccountService deletes accounts after a grace period and exports their data
+type AccountService interface {
+	// RequestDeletion schedules the caller's account to be purged once the
+	// grace period is over. The caller confirms with their password.
+	RequestDeletion(ctx context.Context, userID int64, req dto.RequestAccountDeletionRequest) (*dto.AccountDeletionResponse, error)
+	GetDeletion(ctx context.Context, userID int64) (*dto.AccountDeletionResponse, error)
+	CancelDeletion(ctx context.Context, userID int64) (*dto.AccountDeletionResponse, error)
+
+	// RemoveAccount soft deletes an account on behalf of staff and schedules
+	// its purge after the grace period
+	RemoveAccount(ctx context.Context, user *entity.User) error
+
+	// RequestExport starts building an archive of the caller's data
+	RequestExport(ctx context.Context, userID int64) (*dto.AccountExportResponse, error)
+	GetExport(ctx context.Context, userID int64) (*dto.AccountExportResponse, error)
+
+	// OpenExport returns the caller's latest archive while it can be
+	// downloaded; the caller closes the reader
+	OpenExport(ctx context.Context, userID int64) (io.ReadCloser, *dto.AccountExportResponse, error)
+
+	// Run builds requested exports and purges accounts whose deletion is due
+	// until ctx is cancelled
+	Run(ctx context.Context)
+}
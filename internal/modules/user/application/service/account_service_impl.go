@@ -0,0 +1,457 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	auditDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	auditService "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	messageDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/dto"
+	messageService "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/service"
+	messageEntity "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+)
+
+// AccountConfig controls account deletion and data exports
+type AccountConfig struct {
+	// Time between a deletion request and the purge, during which it can be canceled
+	GracePeriod time.Duration
+
+	// How long a finished export can be downloaded
+	ExportTTL time.Duration
+
+	PollInterval time.Duration
+	BatchSize    int
+
+	// Work claimed longer ago than ClaimTimeout is assumed interrupted and done again
+	ClaimTimeout time.Duration
+}
+
+type accountServiceImpl struct {
+	userRepo    repository.UserRepository
+	exports     repository.AccountExportRepository
+	friendships repository.FriendshipRepository
+	sessions    SessionService
+	messages    messageService.AccountDataService
+	audit       auditService.AuditService
+	blobs       storage.Storage
+	cfg         AccountConfig
+}
+
+// NewAccountService creates the account deletion and export service
+func NewAccountService(
+	userRepo repository.UserRepository,
+	exports repository.AccountExportRepository,
+	friendships repository.FriendshipRepository,
+	sessions SessionService,
+	messages messageService.AccountDataService,
+	audit auditService.AuditService,
+	blobs storage.Storage,
+	cfg AccountConfig,
+) AccountService {
+	return &accountServiceImpl{
+		userRepo:    userRepo,
+		exports:     exports,
+		friendships: friendships,
+		sessions:    sessions,
+		messages:    messages,
+		audit:       audit,
+		blobs:       blobs,
+		cfg:         cfg,
+	}
+}
+
+func (s *accountServiceImpl) RequestDeletion(ctx context.Context, userID int64, req dto.RequestAccountDeletionRequest) (*dto.AccountDeletionResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts created by phone have no password to confirm with
+	if !user.Password.IsZero() {
+		if err := user.Password.Compare(req.Password); err != nil {
+			return nil, value_object.ErrPasswordMismatch
+		}
+	}
+
+	if err := user.ScheduleDeletion(time.Now().Add(s.cfg.GracePeriod)); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateDeletion(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, "user.deletion_request", user.ID, map[string]auditDto.Change{
+		"deletion_scheduled_at": {Before: nil, After: user.DeletionScheduledAt},
+	})
+	return dto.NewAccountDeletionResponse(user), nil
+}
+
+func (s *accountServiceImpl) GetDeletion(ctx context.Context, userID int64) (*dto.AccountDeletionResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewAccountDeletionResponse(user), nil
+}
+
+func (s *accountServiceImpl) CancelDeletion(ctx context.Context, userID int64) (*dto.AccountDeletionResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledAt := user.DeletionScheduledAt
+	if err := user.CancelDeletion(); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateDeletion(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, "user.deletion_cancel", user.ID, map[string]auditDto.Change{
+		"deletion_scheduled_at": {Before: scheduledAt, After: nil},
+	})
+	return dto.NewAccountDeletionResponse(user), nil
+}
+
+func (s *accountServiceImpl) RemoveAccount(ctx context.Context, user *entity.User) error {
+	user.SoftDelete()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// A deletion the owner requested earlier keeps its sooner date
+	err := user.ScheduleDeletion(time.Now().Add(s.cfg.GracePeriod))
+	if errors.Is(err, entity.ErrDeletionAlreadyScheduled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.UpdateDeletion(ctx, user)
+}
+
+func (s *accountServiceImpl) RequestExport(ctx context.Context, userID int64) (*dto.AccountExportResponse, error) {
+	latest, err := s.exports.FindLatest(ctx, userID)
+	if err != nil && !errors.Is(err, entity.ErrExportNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.IsInProgress() {
+		return nil, entity.ErrExportInProgress
+	}
+
+	export := entity.NewAccountExport(userID)
+	if err := s.exports.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	return dto.NewAccountExportResponse(export), nil
+}
+
+func (s *accountServiceImpl) GetExport(ctx context.Context, userID int64) (*dto.AccountExportResponse, error) {
+	export, err := s.exports.FindLatest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewAccountExportResponse(export), nil
+}
+
+func (s *accountServiceImpl) OpenExport(ctx context.Context, userID int64) (io.ReadCloser, *dto.AccountExportResponse, error) {
+	export, err := s.exports.FindLatest(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !export.IsDownloadable(time.Now()) {
+		return nil, nil, entity.ErrExportNotFound
+	}
+
+	archive, err := s.blobs.Open(ctx, export.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, entity.ErrExportNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return archive, dto.NewAccountExportResponse(export), nil
+}
+
+func (s *accountServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.buildExports(ctx, now)
+			s.purgeDue(ctx, now)
+		}
+	}
+}
+
+// buildExports claims requested exports and builds them. Claiming is atomic
+// in the repository, so several replicas can run the worker at once.
+func (s *accountServiceImpl) buildExports(ctx context.Context, now time.Time) {
+	exports, err := s.exports.ClaimPending(ctx, now, now.Add(-s.cfg.ClaimTimeout), s.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Account export: failed to claim exports: %v", err)
+		return
+	}
+
+	for _, export := range exports {
+		if err := s.buildExport(ctx, export); err != nil {
+			log.Printf("Account export: failed to build export %d: %v", export.ID, err)
+			if err := s.exports.MarkFailed(ctx, export.ID, err.Error()); err != nil {
+				log.Printf("Account export: failed to mark export %d as failed: %v", export.ID, err)
+			}
+		}
+	}
+}
+
+// buildExport streams the archive into blob storage and marks it ready
+func (s *accountServiceImpl) buildExport(ctx context.Context, export *entity.AccountExport) error {
+	user, err := s.userRepo.FindByID(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Blobs are served publicly, so the key must not be guessable
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to generate export key: %w", err)
+	}
+	key := fmt.Sprintf("exports/%d/%s.zip", user.ID, hex.EncodeToString(suffix))
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(s.writeArchive(ctx, writer, user))
+	}()
+
+	counted := &countingReader{r: reader}
+	if err := s.blobs.Put(ctx, key, counted, "application/zip"); err != nil {
+		reader.CloseWithError(err)
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("Account export: failed to delete partial archive %s: %v", key, err)
+		}
+		return err
+	}
+
+	export.Complete(key, counted.n, s.cfg.ExportTTL)
+	if err := s.blobs.ExpireAt(ctx, key, *export.ExpiresAt); err != nil {
+		return err
+	}
+
+	return s.exports.MarkReady(ctx, export)
+}
+
+// writeArchive writes the user's profile, contacts, conversations, messages
+// and attachments as a zip archive
+func (s *accountServiceImpl) writeArchive(ctx context.Context, w io.Writer, user *entity.User) error {
+	archive := zip.NewWriter(w)
+
+	friendIDs, err := s.friendships.ListFriendIDs(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := writeArchiveJSON(archive, "profile.json", &dto.ProfileExport{
+		User:      dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)),
+		Privacy:   dto.NewPrivacySettingsResponse(user),
+		FriendIDs: friendIDs,
+	}); err != nil {
+		return err
+	}
+
+	contacts, err := s.userRepo.ListContacts(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	contactExports := make([]*dto.ContactExport, 0, len(contacts))
+	for _, contact := range contacts {
+		contactExports = append(contactExports, dto.NewContactExport(contact))
+	}
+	if err := writeArchiveJSON(archive, "contacts.json", contactExports); err != nil {
+		return err
+	}
+
+	memberships, err := s.messages.ListMemberships(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := writeArchiveJSON(archive, "conversations.json", memberships); err != nil {
+		return err
+	}
+
+	// Only blobs recorded as the user's uploads are copied; a message's
+	// metadata alone can name anyone's key
+	uploaded, err := s.messages.ListAttachmentKeys(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	owned := make(map[string]bool, len(uploaded))
+	for _, key := range uploaded {
+		owned[key] = true
+	}
+
+	// A zip entry must be finished before the next begins, so attachments
+	// are collected while messages are written and copied afterwards
+	file, err := archive.Create("messages.jsonl")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	attachments := make(map[string]string)
+	err = s.messages.ExportMessages(ctx, user.ID, func(message *messageDto.MessageResponse) error {
+		if key := message.Metadata[messageEntity.MetadataAttachmentKey]; owned[key] {
+			attachments[key] = "attachments/" + message.MessageID + "-" + path.Base(key)
+		}
+		return encoder.Encode(message)
+	})
+	if err != nil {
+		return err
+	}
+
+	for key, name := range attachments {
+		if err := s.copyAttachment(ctx, archive, key, name); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// copyAttachment adds a blob to the archive, skipping blobs that expired
+func (s *accountServiceImpl) copyAttachment(ctx context.Context, archive *zip.Writer, key, name string) error {
+	blob, err := s.blobs.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, blob)
+	return err
+}
+
+// purgeDue claims accounts whose deletion is due and purges them. A purge
+// that fails stays claimed and is tried again after ClaimTimeout.
+func (s *accountServiceImpl) purgeDue(ctx context.Context, now time.Time) {
+	ids, err := s.userRepo.ClaimDueDeletions(ctx, now, now.Add(-s.cfg.ClaimTimeout), s.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Account deletion: failed to claim accounts: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.purge(ctx, id); err != nil {
+			log.Printf("Account deletion: failed to purge user %d: %v", id, err)
+		}
+	}
+}
+
+// purge erases an account's data. Every step can be repeated, and the user
+// row goes last so a failed purge is claimed again.
+func (s *accountServiceImpl) purge(ctx context.Context, id int64) error {
+	if _, err := s.sessions.RevokeOtherSessions(ctx, id, ""); err != nil {
+		return err
+	}
+
+	if err := s.messages.PurgeUser(ctx, id); err != nil {
+		return err
+	}
+
+	keys, err := s.exports.DeleteByUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("Account deletion: failed to delete archive %s: %v", key, err)
+		}
+	}
+
+	username, err := purgedUsername(id)
+	if err != nil {
+		return err
+	}
+	avatarKey, err := s.userRepo.Purge(ctx, id, username)
+	if errors.Is(err, entity.ErrUserNotFound) {
+		// Another replica finished it after reclaiming
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	deleteAvatar(ctx, s.blobs, avatarKey)
+
+	s.record(ctx, "user.purge", id, nil)
+	return nil
+}
+
+// record writes a change to a user account to the audit log
+func (s *accountServiceImpl) record(ctx context.Context, action string, userID int64, changes map[string]auditDto.Change) {
+	s.audit.Record(ctx, auditDto.Record{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+		Changes:    changes,
+	})
+}
+
+// purgedUsername replaces the username of a purged account. The random part
+// avoids clashing with anyone who registered a similar name.
+func purgedUsername(id int64) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate username: %w", err)
+	}
+	return fmt.Sprintf("deleted_%d_%s", id, hex.EncodeToString(suffix)), nil
+}
+
+// writeArchiveJSON adds v to the archive as an indented JSON file
+func writeArchiveJSON(archive *zip.Writer, name string, v interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
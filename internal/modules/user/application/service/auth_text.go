@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// Language emails and text messages fall back to
+const fallbackLanguage = "en"
+
+// mailTemplate is the subject and body of an email; the body takes the username and a link
+type mailTemplate struct {
+	Subject string
+	Body    string
+}
+
+// verificationMails ask a new user to confirm their email address, per language
+var verificationMails = map[string]mailTemplate{
+	"en": {
+		Subject: "Confirm your VNalo email address",
+		Body: "Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\n" +
+			"If you did not create a VNalo account, you can ignore this email.\n",
+	},
+	"vi": {
+		Subject: "Xác nhận địa chỉ email VNalo",
+		Body: "Chào %s,\n\nVui lòng xác nhận địa chỉ email của bạn bằng liên kết sau:\n\n%s\n\n" +
+			"Nếu bạn không tạo tài khoản VNalo, hãy bỏ qua email này.\n",
+	},
+}
+
+// resetMails carry a password reset link, per language
+var resetMails = map[string]mailTemplate{
+	"en": {
+		Subject: "Reset your VNalo password",
+		Body: "Hi %s,\n\nSomeone asked to reset the password of your VNalo account. " +
+			"Open this link to choose a new one:\n\n%s\n\n" +
+			"If it was not you, ignore this email; your password stays the same.\n",
+	},
+	"vi": {
+		Subject: "Đặt lại mật khẩu VNalo",
+		Body: "Chào %s,\n\nCó người đã yêu cầu đặt lại mật khẩu tài khoản VNalo của bạn. " +
+			"Mở liên kết sau để chọn mật khẩu mới:\n\n%s\n\n" +
+			"Nếu không phải bạn, hãy bỏ qua email này; mật khẩu của bạn vẫn giữ nguyên.\n",
+	},
+}
+
+// renderMail fills a template in the user's language
+func renderMail(templates map[string]mailTemplate, language string, username string, link string) (string, string) {
+	template, ok := templates[language]
+	if !ok {
+		template = templates[fallbackLanguage]
+	}
+	return template.Subject, fmt.Sprintf(template.Body, username, link)
+}
+
+// otpTexts carry a one-time code and the minutes it is valid, per language
+var otpTexts = map[string]string{
+	"en": "Your VNalo code is %s. It expires in %d minutes. Never share it with anyone.",
+	"vi": "Mã xác thực VNalo của bạn là %s, có hiệu lực trong %d phút. Không chia sẻ mã này với bất kỳ ai.",
+}
+
+// renderOTP words the text message carrying a code in the user's language
+func renderOTP(language string, code string, ttl time.Duration) string {
+	text, ok := otpTexts[language]
+	if !ok {
+		text = otpTexts[fallbackLanguage]
+	}
+	return fmt.Sprintf(text, code, int(ttl.Minutes()))
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+)
+
+// ContactService defines how users find people they know from their address books
+type ContactService interface {
+	// SyncContacts matches a batch of hashed phone numbers against users that
+	// allow being found by phone
+	SyncContacts(ctx context.Context, userID int64, req dto.SyncContactsRequest) (*dto.SyncContactsResponse, error)
+}
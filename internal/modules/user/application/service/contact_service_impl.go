@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+// ContactSyncLimiters cap how much of the phone directory a user can probe
+type ContactSyncLimiters struct {
+	Requests ratelimit.Limiter // Sync calls
+	Hashes   ratelimit.Limiter // Numbers checked, counted per batch
+}
+
+type contactServiceImpl struct {
+	userRepo  repository.UserRepository
+	limiters  ContactSyncLimiters
+	batchSize int
+}
+
+// NewContactService creates the contact sync service. batchSize is the most
+// numbers accepted in one sync.
+func NewContactService(userRepo repository.UserRepository, limiters ContactSyncLimiters, batchSize int) ContactService {
+	return &contactServiceImpl{
+		userRepo:  userRepo,
+		limiters:  limiters,
+		batchSize: batchSize,
+	}
+}
+
+func (s *contactServiceImpl) SyncContacts(ctx context.Context, userID int64, req dto.SyncContactsRequest) (*dto.SyncContactsResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if len(req.Hashes) > s.batchSize {
+		return nil, entity.ErrContactSyncBatchTooLarge
+	}
+
+	hashes := make([]string, 0, len(req.Hashes))
+	seen := make(map[string]bool, len(req.Hashes))
+	for _, hash := range req.Hashes {
+		hash = strings.ToLower(strings.TrimSpace(hash))
+		if !entity.ValidPhoneHash(hash) {
+			return nil, entity.ErrInvalidPhoneHash
+		}
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+
+	key := strconv.FormatInt(userID, 10)
+	if err := allowAttempt(ctx, s.limiters.Requests, key); err != nil {
+		return nil, err
+	}
+	if err := allowAttemptN(ctx, s.limiters.Hashes, key, len(hashes)); err != nil {
+		return nil, err
+	}
+
+	// The hashes only live for this request: unmatched ones are never stored or logged
+	matches, err := s.userRepo.FindDiscoverableByPhoneHashes(ctx, userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.SyncContactsResponse{Matches: make([]dto.ContactMatchResponse, 0, len(matches))}
+	contactUserIDs := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		resp.Matches = append(resp.Matches, dto.ContactMatchResponse{Hash: match.PhoneHash, UserID: match.UserID})
+		contactUserIDs = append(contactUserIDs, match.UserID)
+	}
+
+	if req.AddContacts {
+		if resp.Added, err = s.userRepo.AddContacts(ctx, userID, contactUserIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+)
+
+// DiscoveryService defines how users find each other
+type DiscoveryService interface {
+	// SearchUsers matches query against usernames and display names, or
+	// exactly against phone numbers of accounts that allow it
+	SearchUsers(ctx context.Context, viewerID int64, query string, limit int) (*dto.UserSearchResponse, error)
+
+	GetPrivacy(ctx context.Context, userID int64) (*dto.PrivacySettingsResponse, error)
+	UpdatePrivacy(ctx context.Context, userID int64, req dto.UpdatePrivacySettingsRequest) (*dto.PrivacySettingsResponse, error)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+)
+
+const (
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 64
+
+	defaultSearchResults = 20
+	maxSearchResults     = 50
+)
+
+// SearchLimiters keep clients from scraping the user directory
+type SearchLimiters struct {
+	PerUser      ratelimit.Limiter // Searches of any kind
+	PhoneLookups ratelimit.Limiter // Searches by phone number, which can confirm who owns one
+}
+
+type discoveryServiceImpl struct {
+	userRepo repository.UserRepository
+	blobs    storage.Storage
+	limiters SearchLimiters
+}
+
+// NewDiscoveryService creates the user search service
+func NewDiscoveryService(userRepo repository.UserRepository, blobs storage.Storage, limiters SearchLimiters) DiscoveryService {
+	return &discoveryServiceImpl{
+		userRepo: userRepo,
+		blobs:    blobs,
+		limiters: limiters,
+	}
+}
+
+func (s *discoveryServiceImpl) SearchUsers(ctx context.Context, viewerID int64, query string, limit int) (*dto.UserSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minSearchQueryLength {
+		return nil, entity.ErrSearchQueryTooShort
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		query = string([]rune(query)[:maxSearchQueryLength])
+	}
+	if limit < 1 || limit > maxSearchResults {
+		limit = defaultSearchResults
+	}
+
+	key := strconv.FormatInt(viewerID, 10)
+	if err := allowAttempt(ctx, s.limiters.PerUser, key); err != nil {
+		return nil, err
+	}
+
+	var users []*entity.User
+	if looksLikePhone(query) {
+		phone, err := value_object.NewPhone(query)
+		if err != nil {
+			return &dto.UserSearchResponse{Users: []*dto.PublicProfileResponse{}}, nil
+		}
+		if err := allowAttempt(ctx, s.limiters.PhoneLookups, key); err != nil {
+			return nil, err
+		}
+
+		user, err := s.userRepo.FindDiscoverableByPhone(ctx, viewerID, phone)
+		switch {
+		case err == nil:
+			users = append(users, user)
+		case !errors.Is(err, entity.ErrUserNotFound):
+			return nil, err
+		}
+	} else {
+		var err error
+		if users, err = s.userRepo.Search(ctx, viewerID, strings.ToLower(query), limit); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &dto.UserSearchResponse{Users: make([]*dto.PublicProfileResponse, 0, len(users))}
+	for _, user := range users {
+		// A search result is not an introduction: contact details stay hidden
+		resp.Users = append(resp.Users, dto.NewPublicProfileResponse(user, avatarURLs(s.blobs, user), false))
+	}
+
+	return resp, nil
+}
+
+func (s *discoveryServiceImpl) GetPrivacy(ctx context.Context, userID int64) (*dto.PrivacySettingsResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return dto.NewPrivacySettingsResponse(user), nil
+}
+
+func (s *discoveryServiceImpl) UpdatePrivacy(ctx context.Context, userID int64, req dto.UpdatePrivacySettingsRequest) (*dto.PrivacySettingsResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if req.DiscoverableByPhone != nil {
+		user.DiscoverableByPhone = *req.DiscoverableByPhone
+	}
+	if req.OnlyFriendsCanMessage != nil {
+		user.OnlyFriendsCanMessage = *req.OnlyFriendsCanMessage
+	}
+
+	if err := s.userRepo.UpdatePrivacy(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return dto.NewPrivacySettingsResponse(user), nil
+}
+
+// looksLikePhone reports whether query is a phone number rather than a name:
+// digits with the separators people type, and enough digits to be a number
+func looksLikePhone(query string) bool {
+	digits := 0
+	for _, r := range query {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case strings.ContainsRune("+ -.()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 6
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+)
+
+// Notifier pushes realtime events to the connected devices of a user
+type Notifier interface {
+	SendToUser(userID int64, data interface{}) error
+}
+
+// FriendService runs the friend request workflow
+type FriendService interface {
+	// SendRequest asks another user to become friends. If they already asked
+	// the caller, their request is accepted instead.
+	SendRequest(ctx context.Context, userID int64, req dto.SendFriendRequestRequest) (*dto.FriendRequestResponse, error)
+
+	AcceptRequest(ctx context.Context, userID int64, requestID int64) (*dto.FriendRequestResponse, error)
+
+	// DeclineRequest deletes a request sent to the caller without telling its sender
+	DeclineRequest(ctx context.Context, userID int64, requestID int64) error
+
+	// CancelRequest withdraws a request the caller sent
+	CancelRequest(ctx context.Context, userID int64, requestID int64) error
+
+	// ListRequests returns the pending requests sent to the caller, or by the
+	// caller when outgoing is set
+	ListRequests(ctx context.Context, userID int64, outgoing bool) (*dto.FriendRequestListResponse, error)
+
+	ListFriends(ctx context.Context, userID int64) (*dto.FriendListResponse, error)
+	RemoveFriend(ctx context.Context, userID int64, friendID int64) error
+}
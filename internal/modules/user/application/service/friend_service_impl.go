@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+// Realtime events of the friend request workflow
+const (
+	eventFriendRequestReceived = "friend_request.received"
+	eventFriendRequestAccepted = "friend_request.accepted"
+	eventFriendRequestCanceled = "friend_request.canceled"
+)
+
+type friendServiceImpl struct {
+	userRepo    repository.UserRepository
+	friendships repository.FriendshipRepository
+	notifier    Notifier
+	blobs       storage.Storage
+}
+
+// NewFriendService creates the friend request service
+func NewFriendService(
+	userRepo repository.UserRepository,
+	friendships repository.FriendshipRepository,
+	notifier Notifier,
+	blobs storage.Storage,
+) FriendService {
+	return &friendServiceImpl{
+		userRepo:    userRepo,
+		friendships: friendships,
+		notifier:    notifier,
+		blobs:       blobs,
+	}
+}
+
+func (s *friendServiceImpl) SendRequest(ctx context.Context, userID int64, req dto.SendFriendRequestRequest) (*dto.FriendRequestResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	friendship, err := entity.NewFriendRequest(userID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !target.IsActive() {
+		return nil, entity.ErrUserNotFound
+	}
+
+	// Blocked users look the same as missing ones, so a block is not revealed
+	blocked, err := s.userRepo.IsBlockedBetween(ctx, userID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, entity.ErrUserNotFound
+	}
+
+	existing, err := s.friendships.FindBetween(ctx, userID, target.ID)
+	switch {
+	case errors.Is(err, entity.ErrFriendRequestNotFound):
+	case err != nil:
+		return nil, err
+	case !existing.IsPending():
+		return nil, entity.ErrAlreadyFriends
+	case existing.RequesterID == userID:
+		return nil, entity.ErrFriendRequestExists
+	default:
+		// Both want the same thing
+		return s.AcceptRequest(ctx, userID, existing.ID)
+	}
+
+	if err := s.friendships.Create(ctx, friendship); err != nil {
+		return nil, err
+	}
+
+	sender, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	s.notify(target.ID, eventFriendRequestReceived, dto.NewFriendRequestResponse(friendship, s.profile(sender)))
+
+	return dto.NewFriendRequestResponse(friendship, s.profile(target)), nil
+}
+
+func (s *friendServiceImpl) AcceptRequest(ctx context.Context, userID int64, requestID int64) (*dto.FriendRequestResponse, error) {
+	friendship, err := s.friendships.FindByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := friendship.Accept(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.friendships.Accept(ctx, friendship); err != nil {
+		return nil, err
+	}
+
+	users, err := s.profiles(ctx, []int64{friendship.RequesterID, friendship.AddresseeID})
+	if err != nil {
+		return nil, err
+	}
+	s.notify(friendship.RequesterID, eventFriendRequestAccepted, dto.NewFriendRequestResponse(friendship, users[userID]))
+
+	return dto.NewFriendRequestResponse(friendship, users[friendship.RequesterID]), nil
+}
+
+func (s *friendServiceImpl) DeclineRequest(ctx context.Context, userID int64, requestID int64) error {
+	friendship, err := s.friendships.FindByID(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if !friendship.IsPending() || friendship.AddresseeID != userID {
+		return entity.ErrFriendRequestNotFound
+	}
+
+	return s.friendships.Delete(ctx, friendship.ID, entity.FriendshipPending)
+}
+
+func (s *friendServiceImpl) CancelRequest(ctx context.Context, userID int64, requestID int64) error {
+	friendship, err := s.friendships.FindByID(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if !friendship.IsPending() || friendship.RequesterID != userID {
+		return entity.ErrFriendRequestNotFound
+	}
+
+	if err := s.friendships.Delete(ctx, friendship.ID, entity.FriendshipPending); err != nil {
+		return err
+	}
+
+	// The addressee's list of requests should not keep showing it
+	s.notify(friendship.AddresseeID, eventFriendRequestCanceled, dto.NewFriendRequestResponse(friendship, nil))
+	return nil
+}
+
+func (s *friendServiceImpl) ListRequests(ctx context.Context, userID int64, outgoing bool) (*dto.FriendRequestListResponse, error) {
+	friendships, err := s.friendships.ListPending(ctx, userID, outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	otherIDs := make([]int64, 0, len(friendships))
+	for _, friendship := range friendships {
+		otherIDs = append(otherIDs, friendship.OtherParty(userID))
+	}
+	users, err := s.profiles(ctx, otherIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.FriendRequestListResponse{Requests: make([]*dto.FriendRequestResponse, 0, len(friendships))}
+	for _, friendship := range friendships {
+		// Requests involving deleted accounts are left out
+		other, ok := users[friendship.OtherParty(userID)]
+		if !ok {
+			continue
+		}
+		resp.Requests = append(resp.Requests, dto.NewFriendRequestResponse(friendship, other))
+	}
+
+	return resp, nil
+}
+
+func (s *friendServiceImpl) ListFriends(ctx context.Context, userID int64) (*dto.FriendListResponse, error) {
+	friendIDs, err := s.friendships.ListFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friends, err := s.userRepo.FindByIDs(ctx, friendIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.FriendListResponse{Friends: make([]*dto.PublicProfileResponse, 0, len(friends))}
+	for _, friend := range friends {
+		resp.Friends = append(resp.Friends, s.profile(friend))
+	}
+
+	return resp, nil
+}
+
+func (s *friendServiceImpl) RemoveFriend(ctx context.Context, userID int64, friendID int64) error {
+	friendship, err := s.friendships.FindBetween(ctx, userID, friendID)
+	if errors.Is(err, entity.ErrFriendRequestNotFound) {
+		return entity.ErrNotFriends
+	}
+	if err != nil {
+		return err
+	}
+	if friendship.IsPending() {
+		return entity.ErrNotFriends
+	}
+
+	if err := s.friendships.Delete(ctx, friendship.ID, entity.FriendshipAccepted); err != nil {
+		if errors.Is(err, entity.ErrFriendRequestNotFound) {
+			return entity.ErrNotFriends
+		}
+		return err
+	}
+	return nil
+}
+
+// profile is what friends see of each other. Being friends alone does not
+// show contact details; saving a contact does.
+func (s *friendServiceImpl) profile(user *entity.User) *dto.PublicProfileResponse {
+	return dto.NewPublicProfileResponse(user, avatarURLs(s.blobs, user), false)
+}
+
+// profiles returns the profiles of the accounts among userIDs that are not deleted
+func (s *friendServiceImpl) profiles(ctx context.Context, userIDs []int64) (map[int64]*dto.PublicProfileResponse, error) {
+	users, err := s.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[int64]*dto.PublicProfileResponse, len(users))
+	for _, user := range users {
+		profiles[user.ID] = s.profile(user)
+	}
+	return profiles, nil
+}
+
+// notify pushes an event about a completed change; the change itself has
+// succeeded, so a failure here is only logged
+func (s *friendServiceImpl) notify(userID int64, eventType string, data interface{}) {
+	if err := s.notifier.SendToUser(userID, websocket.WSMessage{Type: eventType, Data: data}); err != nil {
+		log.Printf("Failed to send %s to user %d: %v", eventType, userID, err)
+	}
+}
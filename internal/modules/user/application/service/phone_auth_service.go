@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+)
+
+// PhoneAuthService defines sign up and login with one-time codes sent by SMS
+type PhoneAuthService interface {
+	// RequestOTP sends a code to the phone. Login codes are only sent to
+	// registered numbers, but the answer does not reveal whether one was.
+	RequestOTP(ctx context.Context, req dto.RequestOTPRequest, clientIP string) (*dto.OTPResponse, error)
+
+	RegisterWithPhone(ctx context.Context, req dto.PhoneRegisterRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+	LoginWithPhone(ctx context.Context, req dto.PhoneLoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+}
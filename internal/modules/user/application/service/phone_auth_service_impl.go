@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/sms"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+// OTPConfig controls the one-time codes sent by SMS
+type OTPConfig struct {
+	Length int
+	TTL    time.Duration
+
+	// A new code for the same number and purpose can be asked for after Cooldown
+	Cooldown time.Duration
+
+	// Wrong guesses allowed before a code is thrown away
+	MaxAttempts int
+
+	// Secret keys the hashes of stored codes
+	Secret []byte
+}
+
+type phoneAuthServiceImpl struct {
+	userRepo  repository.UserRepository
+	otps      repository.OTPStore
+	twoFactor TwoFactorService
+	sms       sms.Sender
+	limiters  AuthLimiters
+	cfg       OTPConfig
+}
+
+// NewPhoneAuthService creates the phone sign up and login service
+func NewPhoneAuthService(
+	userRepo repository.UserRepository,
+	otps repository.OTPStore,
+	twoFactor TwoFactorService,
+	sender sms.Sender,
+	limiters AuthLimiters,
+	cfg OTPConfig,
+) PhoneAuthService {
+	return &phoneAuthServiceImpl{
+		userRepo:  userRepo,
+		otps:      otps,
+		twoFactor: twoFactor,
+		sms:       sender,
+		limiters:  limiters,
+		cfg:       cfg,
+	}
+}
+
+func (s *phoneAuthServiceImpl) RequestOTP(ctx context.Context, req dto.RequestOTPRequest, clientIP string) (*dto.OTPResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	phone, err := value_object.NewPhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	purpose := entity.OTPPurpose(req.Purpose)
+
+	if err := allowAttempt(ctx, s.limiters.PerIP, clientIP); err != nil {
+		return nil, err
+	}
+
+	resp := &dto.OTPResponse{
+		ExpiresIn:  int(s.cfg.TTL.Seconds()),
+		RetryAfter: int(s.cfg.Cooldown.Seconds()),
+	}
+
+	// Language of the text; new users are most likely to read Vietnamese on a Vietnamese number
+	language := "en"
+	if strings.HasPrefix(phone.Value(), "+84") {
+		language = "vi"
+	}
+
+	switch purpose {
+	case entity.OTPPurposeRegister:
+		exists, err := s.userRepo.PhoneExists(ctx, phone)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, entity.ErrPhoneAlreadyExists
+		}
+	case entity.OTPPurposeLogin:
+		user, err := s.userRepo.FindByPhone(ctx, phone)
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if !user.IsActive() {
+			return resp, nil
+		}
+		language = user.Language
+	}
+
+	// The cooldown is checked first so that requests it turns away do not use
+	// up the daily limit. Both are checked before the code is replaced, so a
+	// refused request leaves the code already sent, and its attempts, alone.
+	if err := s.otps.StartCooldown(ctx, purpose, phone.Value(), s.cfg.Cooldown); err != nil {
+		return nil, err
+	}
+	if err := allowAttempt(ctx, s.limiters.PerPhone, phone.Value()); err != nil {
+		return nil, err
+	}
+
+	code, err := entity.GenerateOTP(s.cfg.Length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code: %w", err)
+	}
+	hash := entity.HashOTP(s.cfg.Secret, purpose, phone.Value(), code)
+	if err := s.otps.Store(ctx, purpose, phone.Value(), hash, s.cfg.TTL); err != nil {
+		return nil, err
+	}
+
+	if err := s.sms.Send(ctx, phone.Value(), renderOTP(language, code, s.cfg.TTL)); err != nil {
+		return nil, fmt.Errorf("failed to send code: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *phoneAuthServiceImpl) RegisterWithPhone(ctx context.Context, req dto.PhoneRegisterRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	phone, err := value_object.NewPhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.userRepo.PhoneExists(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, entity.ErrPhoneAlreadyExists
+	}
+
+	// Checked before the code, so a taken username does not use it up
+	if err := checkUsernameFree(ctx, s.userRepo, req.Username); err != nil {
+		return nil, err
+	}
+
+	if err := s.verify(ctx, entity.OTPPurposeRegister, phone, req.Code); err != nil {
+		return nil, err
+	}
+
+	user, err := entity.NewPhoneUser(phone, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(phone.Value(), "+84") {
+		user.ChangeLanguage("vi")
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.twoFactor.BeginLogin(ctx, user, client)
+}
+
+func (s *phoneAuthServiceImpl) LoginWithPhone(ctx context.Context, req dto.PhoneLoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	phone, err := value_object.NewPhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verify(ctx, entity.OTPPurposeLogin, phone, req.Code); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByPhone(ctx, phone)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return nil, entity.ErrInvalidOTP
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.IsActive() {
+		return nil, entity.ErrUserInactive
+	}
+
+	return s.twoFactor.BeginLogin(ctx, user, client)
+}
+
+func (s *phoneAuthServiceImpl) verify(ctx context.Context, purpose entity.OTPPurpose, phone value_object.Phone, code string) error {
+	hash := entity.HashOTP(s.cfg.Secret, purpose, phone.Value(), code)
+	return s.otps.Verify(ctx, purpose, phone.Value(), hash, s.cfg.MaxAttempts)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/sms"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+	userStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/redis"
+)
+
+func (f *fakeUsers) PhoneExists(ctx context.Context, phone value_object.Phone) (bool, error) {
+	for _, user := range f.users {
+		if user.Phone.Equals(phone) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var otpPattern = regexp.MustCompile(`\d{6}`)
+
+func TestRefusedOTPRequestKeepsEarlierCode(t *testing.T) {
+	tests := []struct {
+		name string
+		// Guesses before and after the refused request, then the expected result of the last one
+		wrongBefore int
+		wrongAfter  int
+		correct     bool
+		wantErr     error
+	}{
+		{name: "earlier code still verifies", correct: true},
+		{name: "attempts are not reset", wrongBefore: 1, wrongAfter: 1, wantErr: entity.ErrOTPAttemptsExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sender := sms.NewFakeSender()
+			perPhone := ratelimit.NewMemoryLimiter(1, 24*time.Hour)
+			perIP := ratelimit.NewMemoryLimiter(100, time.Minute)
+
+			svc := NewPhoneAuthService(&fakeUsers{users: map[int64]*entity.User{}}, userStore.NewMemoryOTPStore(), nil, sender,
+				AuthLimiters{PerPhone: perPhone, PerIP: perIP},
+				OTPConfig{Length: 6, TTL: 5 * time.Minute, MaxAttempts: 2, Secret: []byte("secret")},
+			).(*phoneAuthServiceImpl)
+
+			req := dto.RequestOTPRequest{Phone: "0912345678", Purpose: string(entity.OTPPurposeRegister)}
+			if _, err := svc.RequestOTP(ctx, req, "10.0.0.1"); err != nil {
+				t.Fatal(err)
+			}
+			phone, _ := value_object.NewPhone(req.Phone)
+			sent, ok := sender.Last(phone.Value())
+			if !ok {
+				t.Fatal("no code sent")
+			}
+			code := otpPattern.FindString(sent.Text)
+
+			guess := func(n int) error {
+				var err error
+				for i := 0; i < n; i++ {
+					err = svc.verify(ctx, entity.OTPPurposeRegister, phone, "000000")
+				}
+				return err
+			}
+
+			guess(tt.wrongBefore)
+			if _, err := svc.RequestOTP(ctx, req, "10.0.0.1"); !errors.Is(err, entity.ErrTooManyRequests) {
+				t.Fatalf("second request = %v, want ErrTooManyRequests", err)
+			}
+			if len(sender.Sent()) != 1 {
+				t.Fatalf("sent %d texts, want 1", len(sender.Sent()))
+			}
+
+			err := guess(tt.wrongAfter)
+			if tt.correct {
+				err = svc.verify(ctx, entity.OTPPurposeRegister, phone, code)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("last guess = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+)
+
+// ProfileService defines the public profile use cases
+type ProfileService interface {
+	UpdateProfile(ctx context.Context, userID int64, req dto.UpdateProfileRequest) (*dto.UserResponse, error)
+
+	// UploadAvatar crops image to a square, stores it in every size of
+	// entity.AvatarSizes and replaces the current avatar
+	UploadAvatar(ctx context.Context, userID int64, image io.Reader, crop dto.AvatarCrop) (*dto.UserResponse, error)
+	RemoveAvatar(ctx context.Context, userID int64) (*dto.UserResponse, error)
+
+	// GetPublicProfile returns userID's profile as viewerID sees it
+	GetPublicProfile(ctx context.Context, viewerID, userID int64) (*dto.PublicProfileResponse, error)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/imaging"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+const avatarQuality = 85
+
+type profileServiceImpl struct {
+	userRepo    repository.UserRepository
+	friendships repository.FriendshipRepository
+	blobs       storage.Storage
+}
+
+// NewProfileService creates the profile service
+func NewProfileService(userRepo repository.UserRepository, friendships repository.FriendshipRepository, blobs storage.Storage) ProfileService {
+	return &profileServiceImpl{
+		userRepo:    userRepo,
+		friendships: friendships,
+		blobs:       blobs,
+	}
+}
+
+func (s *profileServiceImpl) UpdateProfile(ctx context.Context, userID int64, req dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := user.UpdateProfile(req.DisplayName, req.Bio); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
+}
+
+func (s *profileServiceImpl) UploadAvatar(ctx context.Context, userID int64, image io.Reader, crop dto.AvatarCrop) (*dto.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	img, err := imaging.Decode(image)
+	if err != nil {
+		return nil, err
+	}
+	square, err := imaging.CropSquare(img, crop.X, crop.Y, crop.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new key per upload, so caches never serve the previous picture
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate avatar key: %w", err)
+	}
+	prefix := fmt.Sprintf("avatars/%d/%s", user.ID, hex.EncodeToString(suffix))
+
+	for _, size := range entity.AvatarSizes {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Resize(square, size, size), avatarQuality); err != nil {
+			deleteAvatar(ctx, s.blobs, prefix)
+			return nil, fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		if err := s.blobs.Put(ctx, avatarKey(prefix, size), &buf, "image/jpeg"); err != nil {
+			deleteAvatar(ctx, s.blobs, prefix)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	previous := user.SetAvatar(prefix)
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		deleteAvatar(ctx, s.blobs, prefix)
+		return nil, err
+	}
+	deleteAvatar(ctx, s.blobs, previous)
+
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
+}
+
+func (s *profileServiceImpl) RemoveAvatar(ctx context.Context, userID int64) (*dto.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	previous := user.RemoveAvatar()
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+	deleteAvatar(ctx, s.blobs, previous)
+
+	return dto.NewUserResponse(user), nil
+}
+
+func (s *profileServiceImpl) GetPublicProfile(ctx context.Context, viewerID, userID int64) (*dto.PublicProfileResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsDeleted {
+		return nil, entity.ErrUserNotFound
+	}
+
+	showContactInfo := viewerID == user.ID
+	if !showContactInfo {
+		// The owner decides: only people they saved may see how to reach them
+		if showContactInfo, err = s.userRepo.HasContact(ctx, user.ID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	profile := dto.NewPublicProfileResponse(user, avatarURLs(s.blobs, user), showContactInfo)
+	if viewerID != user.ID {
+		mutual, err := s.friendships.CountMutualFriends(ctx, viewerID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		profile.MutualFriends = &mutual
+	}
+
+	return profile, nil
+}
+
+// deleteAvatar removes every size of an uploaded avatar. Leftovers only cost
+// disk space, so failures are logged.
+func deleteAvatar(ctx context.Context, blobs storage.Storage, prefix string) {
+	if prefix == "" {
+		return
+	}
+	for _, size := range entity.AvatarSizes {
+		if err := blobs.Delete(ctx, avatarKey(prefix, size)); err != nil {
+			log.Printf("Failed to delete avatar %s: %v", avatarKey(prefix, size), err)
+		}
+	}
+}
+
+// avatarURLs returns where the user's avatar is served, or nil without one
+func avatarURLs(blobs storage.Storage, user *entity.User) *dto.AvatarURLs {
+	switch {
+	case user.AvatarKey != "":
+		return &dto.AvatarURLs{
+			Small:  blobs.URL(avatarKey(user.AvatarKey, 64)),
+			Medium: blobs.URL(avatarKey(user.AvatarKey, 256)),
+			Large:  blobs.URL(avatarKey(user.AvatarKey, 512)),
+		}
+	case user.AvatarURL != "":
+		return &dto.AvatarURLs{Small: user.AvatarURL, Medium: user.AvatarURL, Large: user.AvatarURL}
+	}
+	return nil
+}
+
+// avatarKey is the storage key of one size of an uploaded avatar
+func avatarKey(prefix string, size int) string {
+	return fmt.Sprintf("%s/%d.jpg", prefix, size)
+}
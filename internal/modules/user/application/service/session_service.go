@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// SessionService defines login sessions and the devices they belong to
+type SessionService interface {
+	// IssueLogin starts a session for a user who passed every login factor and
+	// issues its access token
+	IssueLogin(ctx context.Context, user *entity.User, client dto.ClientInfo) (*dto.LoginResponse, error)
+
+	// CheckSession rejects tokens of revoked sessions; it backs the Auth middleware
+	CheckSession(ctx context.Context, userID int64, sessionID string) error
+
+	ListSessions(ctx context.Context, userID int64, currentID string) ([]*dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID int64, currentID string) (*dto.RevokeSessionsResponse, error)
+
+	// Run closes the WebSocket connections of sessions revoked on any node until ctx is done
+	Run(ctx context.Context)
+}
+
+// ConnectionCloser closes the realtime connections of revoked sessions
+type ConnectionCloser interface {
+	DisconnectSessions(userID int64, sessionIDs []string)
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	auditDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	auditService "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+)
+
+const (
+	// Last-active times are written at most this often per session and node
+	sessionTouchInterval = time.Minute
+
+	// Time allowed for a last-active write, which happens off the request path
+	sessionTouchTimeout = 5 * time.Second
+
+	// Limits on client supplied device details
+	maxDeviceNameLength = 100
+	maxPlatformLength   = 32
+	maxUserAgentLength  = 512
+)
+
+type sessionServiceImpl struct {
+	userRepo    repository.UserRepository
+	sessions    repository.SessionRepository
+	revocations repository.SessionRevocationStore
+	tokens      *token.Manager
+	connections ConnectionCloser
+	audit       auditService.AuditService
+	blobs       storage.Storage
+
+	// Session ID -> last write of its last-active time from this node
+	touched   map[string]time.Time
+	touchedMu sync.Mutex
+}
+
+// NewSessionService creates the session service
+func NewSessionService(
+	userRepo repository.UserRepository,
+	sessions repository.SessionRepository,
+	revocations repository.SessionRevocationStore,
+	tokens *token.Manager,
+	connections ConnectionCloser,
+	audit auditService.AuditService,
+	blobs storage.Storage,
+) SessionService {
+	return &sessionServiceImpl{
+		userRepo:    userRepo,
+		sessions:    sessions,
+		revocations: revocations,
+		tokens:      tokens,
+		connections: connections,
+		audit:       audit,
+		blobs:       blobs,
+		touched:     make(map[string]time.Time),
+	}
+}
+
+func (s *sessionServiceImpl) IssueLogin(ctx context.Context, user *entity.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	session, err := entity.NewSession(
+		user.ID,
+		truncate(client.DeviceName, maxDeviceNameLength),
+		truncate(client.Platform, maxPlatformLength),
+		client.IP,
+		truncate(client.UserAgent, maxUserAgentLength),
+		s.tokens.TTL(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	accessToken, claims, err := s.tokens.Generate(user.ID, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue token: %w", err)
+	}
+
+	user.UpdateLastLogin()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	return &dto.LoginResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   &expiresAt,
+		User:        dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)),
+	}, nil
+}
+
+func (s *sessionServiceImpl) CheckSession(ctx context.Context, userID int64, sessionID string) error {
+	// Tokens issued before sessions existed cannot be revoked, so they are refused
+	if sessionID == "" {
+		return entity.ErrSessionRevoked
+	}
+
+	revoked, err := s.revocations.IsRevoked(ctx, sessionID)
+	if err != nil {
+		// The session row is the source of truth; the store only spares a query per request
+		log.Printf("Session revocation store unavailable: %v", err)
+
+		active, err := s.sessions.IsActive(ctx, userID, sessionID)
+		if err != nil {
+			return err
+		}
+		revoked = !active
+	}
+	if revoked {
+		return entity.ErrSessionRevoked
+	}
+
+	s.touch(sessionID)
+	return nil
+}
+
+// touch records activity on the session in the background, at most once per interval
+func (s *sessionServiceImpl) touch(sessionID string) {
+	now := time.Now()
+
+	s.touchedMu.Lock()
+	if last, ok := s.touched[sessionID]; ok && now.Sub(last) < sessionTouchInterval {
+		s.touchedMu.Unlock()
+		return
+	}
+	s.touched[sessionID] = now
+	for id, last := range s.touched {
+		if now.Sub(last) >= sessionTouchInterval {
+			delete(s.touched, id)
+		}
+	}
+	s.touchedMu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sessionTouchTimeout)
+		defer cancel()
+
+		if err := s.sessions.Touch(ctx, sessionID, now); err != nil {
+			log.Printf("Failed to record activity of session %s: %v", sessionID, err)
+		}
+	}()
+}
+
+func (s *sessionServiceImpl) ListSessions(ctx context.Context, userID int64, currentID string) ([]*dto.SessionResponse, error) {
+	sessions, err := s.sessions.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, dto.NewSessionResponse(session, session.ID == currentID))
+	}
+
+	return responses, nil
+}
+
+// RevokeSession writes the revocation store before the session row.
+// CheckSession trusts the store while it answers, so a row revoked without its
+// store entry would leave the token usable and the session gone from the list
+// the user could retry from.
+func (s *sessionServiceImpl) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	active, err := s.sessions.IsActive(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return entity.ErrSessionNotFound
+	}
+
+	if err := s.revocations.Revoke(ctx, userID, []string{sessionID}, s.tokens.TTL()); err != nil {
+		return err
+	}
+	if err := s.sessions.Revoke(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, auditDto.Record{
+		Action:     "session.revoke",
+		TargetType: "session",
+		TargetID:   sessionID,
+	})
+	return nil
+}
+
+// RevokeOtherSessions writes the revocation store before the rows, like RevokeSession
+func (s *sessionServiceImpl) RevokeOtherSessions(ctx context.Context, userID int64, currentID string) (*dto.RevokeSessionsResponse, error) {
+	active, err := s.sessions.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	announced := make(map[string]bool, len(active))
+	pending := make([]string, 0, len(active))
+	for _, session := range active {
+		if session.ID != currentID {
+			announced[session.ID] = true
+			pending = append(pending, session.ID)
+		}
+	}
+	if len(pending) > 0 {
+		if err := s.revocations.Revoke(ctx, userID, pending, s.tokens.TTL()); err != nil {
+			return nil, err
+		}
+	}
+
+	sessionIDs, err := s.sessions.RevokeOthers(ctx, userID, currentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessionIDs) == 0 {
+		return &dto.RevokeSessionsResponse{}, nil
+	}
+
+	// Sessions created since the list still need their store entry
+	var late []string
+	for _, id := range sessionIDs {
+		if !announced[id] {
+			late = append(late, id)
+		}
+	}
+	if len(late) > 0 {
+		if err := s.revocations.Revoke(ctx, userID, late, s.tokens.TTL()); err != nil {
+			return nil, err
+		}
+	}
+
+	s.audit.Record(ctx, auditDto.Record{
+		Action:     "session.revoke_others",
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+		Changes: map[string]auditDto.Change{
+			"sessions": {Before: sessionIDs, After: nil},
+		},
+	})
+
+	return &dto.RevokeSessionsResponse{Revoked: len(sessionIDs)}, nil
+}
+
+func (s *sessionServiceImpl) Run(ctx context.Context) {
+	for {
+		err := s.revocations.Subscribe(ctx, s.connections.DisconnectSessions)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Session revocation subscription ended, retrying: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+)
+
+var errStoreDown = errors.New("connection refused")
+
+// fakeSessionRows answers from a fixed set of active session IDs, or fails
+type fakeSessionRows struct {
+	repository.SessionRepository
+	active map[string]bool
+	err    error
+}
+
+func (f *fakeSessionRows) IsActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	return f.active[sessionID], f.err
+}
+
+func (f *fakeSessionRows) Touch(ctx context.Context, sessionID string, at time.Time) error {
+	return nil
+}
+
+func (f *fakeSessionRows) ListActive(ctx context.Context, userID int64) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	for id, active := range f.active {
+		if active {
+			sessions = append(sessions, &entity.Session{ID: id, UserID: userID})
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessionRows) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	if !f.active[sessionID] {
+		return entity.ErrSessionNotFound
+	}
+	f.active[sessionID] = false
+	return nil
+}
+
+func (f *fakeSessionRows) RevokeOthers(ctx context.Context, userID int64, keepID string) ([]string, error) {
+	var revoked []string
+	for id, active := range f.active {
+		if active && id != keepID {
+			f.active[id] = false
+			revoked = append(revoked, id)
+		}
+	}
+	return revoked, nil
+}
+
+type fakeRevocations struct {
+	repository.SessionRevocationStore
+	revoked   map[string]bool
+	err       error
+	revokeErr error
+}
+
+func (f *fakeRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return f.revoked[sessionID], f.err
+}
+
+func (f *fakeRevocations) Revoke(ctx context.Context, userID int64, sessionIDs []string, ttl time.Duration) error {
+	if f.revokeErr != nil {
+		return f.revokeErr
+	}
+	for _, id := range sessionIDs {
+		f.revoked[id] = true
+	}
+	return nil
+}
+
+func TestCheckSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionID   string
+		revocations *fakeRevocations
+		rows        *fakeSessionRows
+		wantErr     error
+	}{
+		{
+			name:        "active",
+			sessionID:   "s1",
+			revocations: &fakeRevocations{},
+			rows:        &fakeSessionRows{},
+		},
+		{
+			name:        "revoked",
+			sessionID:   "s1",
+			revocations: &fakeRevocations{revoked: map[string]bool{"s1": true}},
+			rows:        &fakeSessionRows{},
+			wantErr:     entity.ErrSessionRevoked,
+		},
+		{
+			name:        "token without session",
+			revocations: &fakeRevocations{},
+			rows:        &fakeSessionRows{},
+			wantErr:     entity.ErrSessionRevoked,
+		},
+		{
+			name:        "store down, session active",
+			sessionID:   "s1",
+			revocations: &fakeRevocations{err: errStoreDown},
+			rows:        &fakeSessionRows{active: map[string]bool{"s1": true}},
+		},
+		{
+			name:        "store down, session revoked",
+			sessionID:   "s1",
+			revocations: &fakeRevocations{err: errStoreDown},
+			rows:        &fakeSessionRows{},
+			wantErr:     entity.ErrSessionRevoked,
+		},
+		{
+			name:        "store and database down",
+			sessionID:   "s1",
+			revocations: &fakeRevocations{err: errStoreDown},
+			rows:        &fakeSessionRows{err: errStoreDown},
+			wantErr:     errStoreDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSessionService(nil, tt.rows, tt.revocations, nil, nil, nil, nil)
+
+			err := svc.CheckSession(context.Background(), 1, tt.sessionID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckSession = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeWritesTheStoreBeforeTheRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		revoke func(ctx context.Context, svc SessionService) error
+		signed []string // Sessions expected signed out, with current kept
+	}{
+		{
+			name: "one session",
+			revoke: func(ctx context.Context, svc SessionService) error {
+				return svc.RevokeSession(ctx, 1, "phone")
+			},
+			signed: []string{"phone"},
+		},
+		{
+			name: "other sessions",
+			revoke: func(ctx context.Context, svc SessionService) error {
+				_, err := svc.RevokeOtherSessions(ctx, 1, "current")
+				return err
+			},
+			signed: []string{"phone", "laptop"},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, storeDown := range []bool{false, true} {
+			name := tt.name
+			if storeDown {
+				name += ", store down"
+			}
+			t.Run(name, func(t *testing.T) {
+				rows := &fakeSessionRows{active: map[string]bool{"current": true, "phone": true, "laptop": true}}
+				revocations := &fakeRevocations{revoked: make(map[string]bool)}
+				if storeDown {
+					revocations.revokeErr = errStoreDown
+				}
+				svc := NewSessionService(nil, rows, revocations, token.NewManager("secret", time.Hour), nil, fakeAudit{}, nil)
+
+				err := tt.revoke(context.Background(), svc)
+				if storeDown {
+					if !errors.Is(err, errStoreDown) {
+						t.Fatalf("got %v, want the store error", err)
+					}
+					// Nothing was revoked, so every session is still listed to retry
+					for id, active := range rows.active {
+						if !active {
+							t.Fatalf("row of %s revoked without its store entry", id)
+						}
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, id := range tt.signed {
+					if rows.active[id] || !revocations.revoked[id] {
+						t.Fatalf("%s: row active %v, store revoked %v", id, rows.active[id], revocations.revoked[id])
+					}
+				}
+				if !rows.active["current"] || revocations.revoked["current"] {
+					t.Fatal("current session was signed out")
+				}
+			})
+		}
+	}
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	rows := &fakeSessionRows{active: map[string]bool{}}
+	revocations := &fakeRevocations{revoked: make(map[string]bool)}
+	svc := NewSessionService(nil, rows, revocations, token.NewManager("secret", time.Hour), nil, fakeAudit{}, nil)
+
+	if err := svc.RevokeSession(context.Background(), 1, "someone-else"); !errors.Is(err, entity.ErrSessionNotFound) {
+		t.Fatalf("got %v, want ErrSessionNotFound", err)
+	}
+	if revocations.revoked["someone-else"] {
+		t.Fatal("revoked a session the user does not own")
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// TwoFactorService defines TOTP two-factor authentication
+type TwoFactorService interface {
+	// BeginLogin is called once a user passed their first factor. It issues the
+	// access token, or a challenge when a second factor is enabled or required.
+	BeginLogin(ctx context.Context, user *entity.User, client dto.ClientInfo) (*dto.LoginResponse, error)
+
+	// VerifyLogin finishes a challenged login with an authenticator or recovery code
+	VerifyLogin(ctx context.Context, req dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+
+	// SetupWithChallenge starts the enrollment a login demanded, before the user has an access token
+	SetupWithChallenge(ctx context.Context, req dto.TwoFactorChallengeRequest) (*dto.TwoFactorSetupResponse, error)
+
+	GetStatus(ctx context.Context, userID int64) (*dto.TwoFactorStatusResponse, error)
+	Setup(ctx context.Context, userID int64) (*dto.TwoFactorSetupResponse, error)
+	Enable(ctx context.Context, userID int64, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID int64, req dto.DisableTwoFactorRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+}
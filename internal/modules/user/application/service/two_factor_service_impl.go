@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	auditDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	auditService "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/totp"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+// Purposes of the scoped tokens handed out by a challenged login
+const (
+	challengeVerify = "2fa"
+	challengeSetup  = "2fa_setup"
+)
+
+// TwoFactorConfig controls two-factor authentication
+type TwoFactorConfig struct {
+	// Issuer names the service in authenticator apps
+	Issuer string
+
+	// ChallengeTTL bounds the time between the password and the second factor
+	ChallengeTTL time.Duration
+
+	// VIP accounts cannot log in without a second factor when EnforceForVIP is set
+	EnforceForVIP bool
+
+	// Moderators and admins cannot log in without a second factor when EnforceForStaff is set
+	EnforceForStaff bool
+
+	RecoveryCodes int
+}
+
+type twoFactorServiceImpl struct {
+	userRepo  repository.UserRepository
+	twoFactor repository.TwoFactorRepository
+	sessions  SessionService
+	audit     auditService.AuditService
+	tokens    *token.Manager
+	limiter   ratelimit.Limiter // Code guesses per user
+	cfg       TwoFactorConfig
+}
+
+// NewTwoFactorService creates the two-factor authentication service
+func NewTwoFactorService(
+	userRepo repository.UserRepository,
+	twoFactor repository.TwoFactorRepository,
+	sessions SessionService,
+	audit auditService.AuditService,
+	tokens *token.Manager,
+	limiter ratelimit.Limiter,
+	cfg TwoFactorConfig,
+) TwoFactorService {
+	return &twoFactorServiceImpl{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+		sessions:  sessions,
+		audit:     audit,
+		tokens:    tokens,
+		limiter:   limiter,
+		cfg:       cfg,
+	}
+}
+
+func (s *twoFactorServiceImpl) BeginLogin(ctx context.Context, user *entity.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	twoFactor, err := s.twoFactor.FindByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, entity.ErrTwoFactorNotSetUp) {
+		return nil, err
+	}
+
+	switch {
+	case twoFactor != nil && twoFactor.IsEnabled():
+		return s.challenge(user.ID, challengeVerify)
+	case s.required(user):
+		return s.challenge(user.ID, challengeSetup)
+	default:
+		return s.sessions.IssueLogin(ctx, user, client)
+	}
+}
+
+func (s *twoFactorServiceImpl) challenge(userID int64, purpose string) (*dto.LoginResponse, error) {
+	challengeToken, claims, err := s.tokens.GenerateScoped(userID, purpose, s.cfg.ChallengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue challenge: %w", err)
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	return &dto.LoginResponse{
+		TwoFactorRequired:      purpose == challengeVerify,
+		TwoFactorSetupRequired: purpose == challengeSetup,
+		ChallengeToken:         challengeToken,
+		ChallengeExpiresAt:     &expiresAt,
+	}, nil
+}
+
+func (s *twoFactorServiceImpl) VerifyLogin(ctx context.Context, req dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	user, purpose, err := s.parseChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := allowAttempt(ctx, s.limiter, fmt.Sprint(user.ID)); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactor.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// An enforced enrollment is confirmed by the same code that finishes the login
+	if !twoFactor.IsEnabled() {
+		if purpose != challengeSetup {
+			return nil, entity.ErrTwoFactorNotEnabled
+		}
+
+		codes, err := s.confirm(ctx, twoFactor, req.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.sessions.IssueLogin(ctx, user, client)
+		if err != nil {
+			return nil, err
+		}
+		resp.RecoveryCodes = codes
+		return resp, nil
+	}
+
+	if err := s.check(ctx, twoFactor, req.Code, true); err != nil {
+		return nil, err
+	}
+
+	return s.sessions.IssueLogin(ctx, user, client)
+}
+
+func (s *twoFactorServiceImpl) SetupWithChallenge(ctx context.Context, req dto.TwoFactorChallengeRequest) (*dto.TwoFactorSetupResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	user, purpose, err := s.parseChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if purpose != challengeSetup {
+		return nil, entity.ErrInvalidToken
+	}
+
+	return s.setup(ctx, user)
+}
+
+// parseChallenge returns the user a challenge token was issued to and its purpose
+func (s *twoFactorServiceImpl) parseChallenge(ctx context.Context, challengeToken string) (*entity.User, string, error) {
+	purpose := challengeVerify
+	claims, err := s.tokens.ParseScoped(challengeToken, purpose)
+	if err != nil {
+		purpose = challengeSetup
+		if claims, err = s.tokens.ParseScoped(challengeToken, purpose); err != nil {
+			return nil, "", entity.ErrInvalidToken
+		}
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return nil, "", entity.ErrInvalidToken
+		}
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.IsActive() {
+		return nil, "", entity.ErrUserInactive
+	}
+
+	return user, purpose, nil
+}
+
+func (s *twoFactorServiceImpl) GetStatus(ctx context.Context, userID int64) (*dto.TwoFactorStatusResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	resp := &dto.TwoFactorStatusResponse{
+		Required: s.required(user),
+	}
+
+	twoFactor, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrTwoFactorNotSetUp) {
+			return resp, nil
+		}
+		return nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return resp, nil
+	}
+
+	left, err := s.twoFactor.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Enabled = true
+	resp.EnabledAt = twoFactor.EnabledAt
+	resp.RecoveryCodesLeft = left
+	return resp, nil
+}
+
+func (s *twoFactorServiceImpl) Setup(ctx context.Context, userID int64) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.setup(ctx, user)
+}
+
+// setup starts a pending enrollment, replacing an unconfirmed one
+func (s *twoFactorServiceImpl) setup(ctx context.Context, user *entity.User) (*dto.TwoFactorSetupResponse, error) {
+	twoFactor, err := entity.NewTwoFactor(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	if err := s.twoFactor.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:          totp.EncodeSecret(twoFactor.Secret),
+		ProvisioningURI: totp.ProvisioningURI(twoFactor.Secret, s.cfg.Issuer, accountLabel(user)),
+	}, nil
+}
+
+func (s *twoFactorServiceImpl) Enable(ctx context.Context, userID int64, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := allowAttempt(ctx, s.limiter, fmt.Sprint(userID)); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, entity.ErrTwoFactorEnabled
+	}
+
+	codes, err := s.confirm(ctx, twoFactor, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// confirm enables a pending enrollment once code shows the authenticator app
+// was set up, and returns the new recovery codes
+func (s *twoFactorServiceImpl) confirm(ctx context.Context, twoFactor *entity.TwoFactor, code string) ([]string, error) {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := entity.GenerateRecoveryCodes(s.cfg.RecoveryCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := s.twoFactor.Enable(ctx, twoFactor.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, "two_factor.enable", twoFactor.UserID)
+	return codes, nil
+}
+
+func (s *twoFactorServiceImpl) Disable(ctx context.Context, userID int64, req dto.DisableTwoFactorRequest) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := allowAttempt(ctx, s.limiter, fmt.Sprint(userID)); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if s.required(user) {
+		return entity.ErrTwoFactorRequired
+	}
+
+	// A stolen access token alone must not be enough to turn 2FA off
+	if !user.Password.IsZero() {
+		if err := user.Password.Compare(req.Password); err != nil {
+			return entity.ErrInvalidCredentials
+		}
+	}
+
+	twoFactor, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrTwoFactorNotSetUp) {
+			return entity.ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if !twoFactor.IsEnabled() {
+		return entity.ErrTwoFactorNotEnabled
+	}
+
+	if err := s.check(ctx, twoFactor, req.Code, true); err != nil {
+		return err
+	}
+
+	if err := s.twoFactor.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	s.record(ctx, "two_factor.disable", userID)
+	return nil
+}
+
+func (s *twoFactorServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID int64, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := allowAttempt(ctx, s.limiter, fmt.Sprint(userID)); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrTwoFactorNotSetUp) {
+			return nil, entity.ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return nil, entity.ErrTwoFactorNotEnabled
+	}
+
+	// Only the authenticator may mint new recovery codes, not an old recovery code
+	if err := s.check(ctx, twoFactor, req.Code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := entity.GenerateRecoveryCodes(s.cfg.RecoveryCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := s.twoFactor.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, "two_factor.regenerate_recovery_codes", userID)
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// check accepts an authenticator code once, or a recovery code when allowed
+func (s *twoFactorServiceImpl) check(ctx context.Context, twoFactor *entity.TwoFactor, code string, allowRecovery bool) error {
+	if entity.IsRecoveryCode(code) {
+		if !allowRecovery {
+			return entity.ErrInvalidTwoFactorCode
+		}
+		return s.twoFactor.ConsumeRecoveryCode(ctx, twoFactor.UserID, entity.HashRecoveryCode(code))
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	return s.twoFactor.UseStep(ctx, twoFactor.UserID, step)
+}
+
+// record writes a change to the user's second factor to the audit log
+func (s *twoFactorServiceImpl) record(ctx context.Context, action string, userID int64) {
+	s.audit.Record(ctx, auditDto.Record{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+	})
+}
+
+// required reports whether the user may not go without a second factor
+func (s *twoFactorServiceImpl) required(user *entity.User) bool {
+	return (s.cfg.EnforceForVIP && user.IsVIP) || (s.cfg.EnforceForStaff && user.Role.IsStaff())
+}
+
+// accountLabel names the account in authenticator apps
+func accountLabel(user *entity.User) string {
+	switch {
+	case !user.Email.IsZero():
+		return user.Email.Value()
+	case !user.Phone.IsZero():
+		return user.Phone.Value()
+	default:
+		return strings.TrimSpace(user.Username)
+	}
+}
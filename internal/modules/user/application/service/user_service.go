@@ -9,6 +9,7 @@ import (
 // UserService defines application use cases
 type UserService interface {
 	CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error)
+	Login(ctx context.Context, req dto.LoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
 	GetUserByID(ctx context.Context, id int64) (*dto.UserResponse, error)
 	GetUserByEmail(ctx context.Context, email string) (*dto.UserResponse, error)
 	UpdateUser(ctx context.Context, id int64, req dto.UpdateUserRequest) (*dto.UserResponse, error)
@@ -16,8 +17,24 @@ type UserService interface {
 	ListUsers(ctx context.Context, page, pageSize int) (*dto.UserListResponse, error)
 	PromoteUserToVIP(ctx context.Context, id int64) error
 	DemoteUserFromVIP(ctx context.Context, id int64) error
-	ChangePassword(ctx context.Context, id int64, req dto.ChangePasswordRequest) error
+
+	// ChangePassword signs out every session of the user but currentSessionID
+	ChangePassword(ctx context.Context, id int64, currentSessionID string, req dto.ChangePasswordRequest) error
+
 	ActivateUser(ctx context.Context, id int64) error
+
+	// DeactivateUser, and AssignRole with a lower role, sign out every session of the user
 	DeactivateUser(ctx context.Context, id int64) error
-}
+	AssignRole(ctx context.Context, id int64, req dto.AssignRoleRequest) (*dto.UserResponse, error)
+
+	// Authorize returns middleware.ErrForbidden unless the user is active and
+	// their role grants permission
+	Authorize(ctx context.Context, userID int64, permission string) error
 
+	// Email verification and password recovery. Requests naming an email never
+	// reveal whether an account exists for it. A reset signs out every session.
+	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
+	ResendVerification(ctx context.Context, req dto.ResendVerificationRequest, clientIP string) error
+	ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest, clientIP string) error
+	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest, clientIP string) error
+}
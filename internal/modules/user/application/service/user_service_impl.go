@@ -2,8 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/mailer"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	auditDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	auditService "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
@@ -11,14 +23,65 @@ import (
 	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
 )
 
+// Emails are sent in the background so responses do not depend on the mail server
+const mailTimeout = 30 * time.Second
+
+// AuthConfig controls email verification and password recovery
+type AuthConfig struct {
+	// Links in emails open AppURL + "/verify-email" and "/reset-password"
+	AppURL string
+
+	// Unverified accounts cannot log in when RequireEmailVerification is set
+	RequireEmailVerification bool
+
+	VerificationTTL time.Duration
+	ResetTTL        time.Duration
+}
+
+// AuthLimiters throttle requests that send emails or guess tokens
+type AuthLimiters struct {
+	PerEmail ratelimit.Limiter
+	PerPhone ratelimit.Limiter
+	PerIP    ratelimit.Limiter
+}
+
 type userServiceImpl struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	userTokens repository.UserTokenRepository
+	twoFactor  TwoFactorService
+	accounts   AccountService
+	sessions   SessionService
+	audit      auditService.AuditService
+	blobs      storage.Storage
+	mail       mailer.Mailer
+	limiters   AuthLimiters
+	cfg        AuthConfig
 }
 
 // NewUserService creates a new user application service
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	userTokens repository.UserTokenRepository,
+	twoFactor TwoFactorService,
+	accounts AccountService,
+	sessions SessionService,
+	audit auditService.AuditService,
+	blobs storage.Storage,
+	mail mailer.Mailer,
+	limiters AuthLimiters,
+	cfg AuthConfig,
+) UserService {
 	return &userServiceImpl{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		userTokens: userTokens,
+		twoFactor:  twoFactor,
+		accounts:   accounts,
+		sessions:   sessions,
+		audit:      audit,
+		blobs:      blobs,
+		mail:       mail,
+		limiters:   limiters,
+		cfg:        cfg,
 	}
 }
 
@@ -41,6 +104,9 @@ func (s *userServiceImpl) CreateUser(ctx context.Context, req dto.CreateUserRequ
 	if exists {
 		return nil, entity.ErrEmailAlreadyExists
 	}
+	if err := checkUsernameFree(ctx, s.userRepo, req.Username); err != nil {
+		return nil, err
+	}
 
 	// 3. Create password value object
 	password, err := value_object.NewPassword(req.Password)
@@ -59,8 +125,46 @@ func (s *userServiceImpl) CreateUser(ctx context.Context, req dto.CreateUserRequ
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// 6. Return DTO
-	return dto.NewUserResponse(user), nil
+	// 6. Ask the user to confirm their email address; they can ask again if this fails
+	if err := s.sendUserToken(ctx, user, entity.TokenPurposeEmailVerification); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	// 7. Return DTO
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
+}
+
+func (s *userServiceImpl) Login(ctx context.Context, req dto.LoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	email, err := value_object.NewEmail(req.Email)
+	if err != nil {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return nil, entity.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := user.Password.Compare(req.Password); err != nil {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	if !user.IsActive() {
+		return nil, entity.ErrUserInactive
+	}
+
+	if s.cfg.RequireEmailVerification && !user.IsEmailVerified() {
+		return nil, entity.ErrEmailNotVerified
+	}
+
+	return s.twoFactor.BeginLogin(ctx, user, client)
 }
 
 func (s *userServiceImpl) GetUserByID(ctx context.Context, id int64) (*dto.UserResponse, error) {
@@ -69,7 +173,7 @@ func (s *userServiceImpl) GetUserByID(ctx context.Context, id int64) (*dto.UserR
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return dto.NewUserResponse(user), nil
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
 }
 
 func (s *userServiceImpl) GetUserByEmail(ctx context.Context, email string) (*dto.UserResponse, error) {
@@ -83,7 +187,7 @@ func (s *userServiceImpl) GetUserByEmail(ctx context.Context, email string) (*dt
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return dto.NewUserResponse(user), nil
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
 }
 
 func (s *userServiceImpl) UpdateUser(ctx context.Context, id int64, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
@@ -93,45 +197,50 @@ func (s *userServiceImpl) UpdateUser(ctx context.Context, id int64, req dto.Upda
 	}
 
 	// Get user
-	user, err := s.userRepo.FindByID(ctx, id)
+	user, err := s.findManageable(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, err
 	}
 
 	// Update fields if provided
-	if req.Username != nil {
+	changes := map[string]auditDto.Change{}
+	if req.Username != nil && *req.Username != user.Username {
+		if err := checkUsernameFree(ctx, s.userRepo, *req.Username); err != nil {
+			return nil, err
+		}
+		changes["username"] = auditDto.Change{Before: user.Username, After: *req.Username}
 		user.Username = *req.Username
 	}
-	if req.Language != nil {
+	if req.Language != nil && *req.Language != user.Language {
+		changes["language"] = auditDto.Change{Before: user.Language, After: *req.Language}
 		user.ChangeLanguage(*req.Language)
 	}
-	if req.Status != nil {
-		user.Status = entity.UserStatus(*req.Status)
-	}
 
 	// Save
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
-	return dto.NewUserResponse(user), nil
+	if len(changes) > 0 {
+		s.record(ctx, "user.update", user, changes)
+	}
+
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
 }
 
 func (s *userServiceImpl) DeleteUser(ctx context.Context, id int64) error {
 	// Get user first to ensure it exists
-	user, err := s.userRepo.FindByID(ctx, id)
+	user, err := s.findManageable(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return err
 	}
 
-	// Soft delete
-	user.SoftDelete()
-
-	// Save
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	// Soft delete now; the account is purged and its email freed after the grace period
+	if err := s.accounts.RemoveAccount(ctx, user); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	s.record(ctx, "user.delete", user, nil)
 	return nil
 }
 
@@ -157,7 +266,7 @@ func (s *userServiceImpl) ListUsers(ctx context.Context, page, pageSize int) (*d
 
 	userResponses := make([]*dto.UserResponse, 0, len(users))
 	for _, user := range users {
-		userResponses = append(userResponses, dto.NewUserResponse(user))
+		userResponses = append(userResponses, dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)))
 	}
 
 	return &dto.UserListResponse{
@@ -174,12 +283,16 @@ func (s *userServiceImpl) PromoteUserToVIP(ctx context.Context, id int64) error
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	wasVIP := user.IsVIP
 	user.PromoteToVIP()
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	s.record(ctx, "user.promote_vip", user, map[string]auditDto.Change{
+		"is_vip": {Before: wasVIP, After: user.IsVIP},
+	})
 	return nil
 }
 
@@ -189,16 +302,20 @@ func (s *userServiceImpl) DemoteUserFromVIP(ctx context.Context, id int64) error
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	wasVIP := user.IsVIP
 	user.DemoteFromVIP()
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	s.record(ctx, "user.demote_vip", user, map[string]auditDto.Change{
+		"is_vip": {Before: wasVIP, After: user.IsVIP},
+	})
 	return nil
 }
 
-func (s *userServiceImpl) ChangePassword(ctx context.Context, id int64, req dto.ChangePasswordRequest) error {
+func (s *userServiceImpl) ChangePassword(ctx context.Context, id int64, currentSessionID string, req dto.ChangePasswordRequest) error {
 	// Validate request
 	if err := validator.Validate(&req); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
@@ -229,15 +346,19 @@ func (s *userServiceImpl) ChangePassword(ctx context.Context, id int64, req dto.
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	return nil
+	s.record(ctx, "user.change_password", user, nil)
+
+	// Whoever knew the old password must not stay signed in elsewhere
+	return s.signOut(ctx, user.ID, currentSessionID)
 }
 
 func (s *userServiceImpl) ActivateUser(ctx context.Context, id int64) error {
-	user, err := s.userRepo.FindByID(ctx, id)
+	user, err := s.findManageable(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return err
 	}
 
+	before := user.Status
 	if err := user.Activate(); err != nil {
 		return err
 	}
@@ -246,19 +367,107 @@ func (s *userServiceImpl) ActivateUser(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	s.record(ctx, "user.activate", user, map[string]auditDto.Change{
+		"status": {Before: int(before), After: int(user.Status)},
+	})
 	return nil
 }
 
 func (s *userServiceImpl) DeactivateUser(ctx context.Context, id int64) error {
+	user, err := s.findManageable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	before := user.Status
+	if err := user.Deactivate(); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.record(ctx, "user.deactivate", user, map[string]auditDto.Change{
+		"status": {Before: int(before), After: int(user.Status)},
+	})
+	return s.signOut(ctx, user.ID, "")
+}
+
+func (s *userServiceImpl) AssignRole(ctx context.Context, id int64, req dto.AssignRoleRequest) (*dto.UserResponse, error) {
+	if err := validator.Validate(&req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Keeps the last admin from locking everybody out of the admin routes
+	if middleware.ActorFromContext(ctx).UserID == id {
+		return nil, entity.ErrCannotChangeOwnRole
+	}
+
 	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	before := user.Role
+	if err := user.ChangeRole(entity.Role(req.Role)); err != nil {
+		return nil, err
+	}
+	if before == user.Role {
+		return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.record(ctx, "user.role_change", user, map[string]auditDto.Change{
+		"role": {Before: string(before), After: string(user.Role)},
+	})
+
+	// Sessions started with the higher role sign in again with the lower one
+	if before.Outranks(user.Role) {
+		if err := s.signOut(ctx, user.ID, ""); err != nil {
+			return nil, err
+		}
+	}
+	return dto.NewUserResponse(user).WithAvatar(avatarURLs(s.blobs, user)), nil
+}
+
+func (s *userServiceImpl) Authorize(ctx context.Context, userID int64, permission string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return middleware.ErrForbidden
+		}
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if err := user.Deactivate(); err != nil {
+	if !user.IsActive() || !user.Role.Can(permission) {
+		return middleware.ErrForbidden
+	}
+	return nil
+}
+
+func (s *userServiceImpl) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	userToken, err := s.userTokens.Consume(ctx, entity.TokenPurposeEmailVerification, entity.HashToken(req.Token))
+	if err != nil {
 		return err
 	}
 
+	user, err := s.userRepo.FindByID(ctx, userToken.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return entity.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.VerifyEmail()
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -266,3 +475,215 @@ func (s *userServiceImpl) DeactivateUser(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (s *userServiceImpl) ResendVerification(ctx context.Context, req dto.ResendVerificationRequest, clientIP string) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	user, err := s.findForEmail(ctx, req.Email, clientIP)
+	if err != nil || user == nil || user.IsEmailVerified() {
+		return err
+	}
+
+	return s.sendUserToken(ctx, user, entity.TokenPurposeEmailVerification)
+}
+
+func (s *userServiceImpl) ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest, clientIP string) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	user, err := s.findForEmail(ctx, req.Email, clientIP)
+	if err != nil || user == nil || !user.IsActive() {
+		return err
+	}
+
+	return s.sendUserToken(ctx, user, entity.TokenPurposePasswordReset)
+}
+
+func (s *userServiceImpl) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest, clientIP string) error {
+	if err := validator.Validate(&req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := allowAttempt(ctx, s.limiters.PerIP, clientIP); err != nil {
+		return err
+	}
+
+	// Check the password before the token is used up
+	newPassword, err := value_object.NewPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	userToken, err := s.userTokens.Consume(ctx, entity.TokenPurposePasswordReset, entity.HashToken(req.Token))
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userToken.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return entity.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.ChangePassword(newPassword)
+	// Following the link proves the user reads the mailbox
+	user.VerifyEmail()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	// Other reset links mailed earlier must not undo this reset
+	if err := s.userTokens.Revoke(ctx, user.ID, entity.TokenPurposePasswordReset); err != nil {
+		log.Printf("Failed to revoke reset tokens of user %d: %v", user.ID, err)
+	}
+
+	s.record(ctx, "user.reset_password", user, nil)
+
+	// A reset usually means the password leaked, so no session is kept
+	return s.signOut(ctx, user.ID, "")
+}
+
+// findForEmail applies the rate limits of requests that send an email, then
+// looks the account up. A missing account is not an error, so callers answer
+// the same whether or not the address is registered.
+func (s *userServiceImpl) findForEmail(ctx context.Context, address string, clientIP string) (*entity.User, error) {
+	email, err := value_object.NewEmail(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := allowAttempt(ctx, s.limiters.PerIP, clientIP); err != nil {
+		return nil, err
+	}
+	if err := allowAttempt(ctx, s.limiters.PerEmail, email.Value()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// checkUsernameFree returns ErrUsernameTaken when another account holds username
+func checkUsernameFree(ctx context.Context, userRepo repository.UserRepository, username string) error {
+	exists, err := userRepo.UsernameExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return entity.ErrUsernameTaken
+	}
+	return nil
+}
+
+// findManageable loads the account id for a change by the request's actor.
+// Staff only manage accounts below their own role; admins manage everyone.
+func (s *userServiceImpl) findManageable(ctx context.Context, id int64) (*entity.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	actorID := middleware.ActorFromContext(ctx).UserID
+	if actorID == 0 || actorID == user.ID || !user.Role.IsStaff() {
+		return user, nil
+	}
+
+	actor, err := s.userRepo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if actor.Role != entity.RoleAdmin && !actor.Role.Outranks(user.Role) {
+		return nil, entity.ErrCannotManageAccount
+	}
+
+	return user, nil
+}
+
+// signOut revokes every session of the user but keepID, which may be empty
+func (s *userServiceImpl) signOut(ctx context.Context, userID int64, keepID string) error {
+	if _, err := s.sessions.RevokeOtherSessions(ctx, userID, keepID); err != nil {
+		return fmt.Errorf("failed to sign out sessions: %w", err)
+	}
+	return nil
+}
+
+// record writes a change to a user account to the audit log
+func (s *userServiceImpl) record(ctx context.Context, action string, user *entity.User, changes map[string]auditDto.Change) {
+	s.audit.Record(ctx, auditDto.Record{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(user.ID, 10),
+		Changes:    changes,
+	})
+}
+
+// allowAttempt counts an attempt against a limiter. The limit is not enforced
+// while the limiter is unavailable, so an outage does not lock everybody out.
+func allowAttempt(ctx context.Context, limiter ratelimit.Limiter, key string) error {
+	return allowAttemptN(ctx, limiter, key, 1)
+}
+
+// allowAttemptN is allowAttempt for n units of a quota at once
+func allowAttemptN(ctx context.Context, limiter ratelimit.Limiter, key string, n int) error {
+	ok, err := limiter.AllowN(ctx, key, n)
+	if err != nil {
+		log.Printf("Rate limiter unavailable: %v", err)
+		return nil
+	}
+	if !ok {
+		return entity.ErrTooManyRequests
+	}
+	return nil
+}
+
+// sendUserToken replaces the user's outstanding tokens for purpose with a new
+// one and mails its link
+func (s *userServiceImpl) sendUserToken(ctx context.Context, user *entity.User, purpose entity.TokenPurpose) error {
+	ttl, path, templates := s.cfg.VerificationTTL, "/verify-email", verificationMails
+	if purpose == entity.TokenPurposePasswordReset {
+		ttl, path, templates = s.cfg.ResetTTL, "/reset-password", resetMails
+	}
+
+	if err := s.userTokens.Revoke(ctx, user.ID, purpose); err != nil {
+		return err
+	}
+
+	userToken, plain, err := entity.NewUserToken(user.ID, purpose, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	if err := s.userTokens.Create(ctx, userToken); err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(s.cfg.AppURL, "/") + path + "?token=" + url.QueryEscape(plain)
+	subject, body := renderMail(templates, user.Language, user.Username, link)
+	message := mailer.Message{
+		To:      user.Email.Value(),
+		Subject: subject,
+		Body:    body,
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+
+		if err := s.mail.Send(ctx, message); err != nil {
+			log.Printf("Failed to send %s email to user %d: %v", purpose, user.ID, err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/mailer"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	auditDto "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/dto"
+	auditService "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+)
+
+// fakeUsers holds users in memory by ID
+type fakeUsers struct {
+	repository.UserRepository
+	users map[int64]*entity.User
+}
+
+func (f *fakeUsers) FindByID(ctx context.Context, id int64) (*entity.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, entity.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeUsers) FindByEmail(ctx context.Context, email value_object.Email) (*entity.User, error) {
+	for _, user := range f.users {
+		if user.Email.Value() == email.Value() {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, entity.ErrUserNotFound
+}
+
+func (f *fakeUsers) Update(ctx context.Context, user *entity.User) error {
+	copied := *user
+	f.users[user.ID] = &copied
+	return nil
+}
+
+// fakeUserTokens keeps tokens in memory with the repository's single-use semantics
+type fakeUserTokens struct {
+	tokens []*entity.UserToken
+	mu     sync.Mutex
+}
+
+func (f *fakeUserTokens) Create(ctx context.Context, token *entity.UserToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tokens = append(f.tokens, token)
+	return nil
+}
+
+func (f *fakeUserTokens) Consume(ctx context.Context, purpose entity.TokenPurpose, hash string) (*entity.UserToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range f.tokens {
+		if token.Purpose == purpose && token.Hash == hash && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			return token, nil
+		}
+	}
+	return nil, entity.ErrInvalidToken
+}
+
+func (f *fakeUserTokens) Revoke(ctx context.Context, userID int64, purpose entity.TokenPurpose) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range f.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// fakeSessions records which sessions were signed out
+type fakeSessions struct {
+	SessionService
+	revoked []string // Session kept by each sign out, "" for none
+}
+
+func (f *fakeSessions) RevokeOtherSessions(ctx context.Context, userID int64, currentID string) (*dto.RevokeSessionsResponse, error) {
+	f.revoked = append(f.revoked, currentID)
+	return &dto.RevokeSessionsResponse{}, nil
+}
+
+type fakeAudit struct {
+	auditService.AuditService
+}
+
+func (fakeAudit) Record(ctx context.Context, record auditDto.Record) {}
+
+var resetLinkPattern = regexp.MustCompile(`/reset-password\?token=([^\s"<]+)`)
+
+// waitForResetToken reads the token from the n-th email, which is sent in the background
+func waitForResetToken(t *testing.T, mail *mailer.MemoryMailer, n int) string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(mail.Sent()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d emails, want %d", len(mail.Sent()), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	match := resetLinkPattern.FindStringSubmatch(mail.Sent()[n-1].Body)
+	if match == nil {
+		t.Fatalf("no reset link in %q", mail.Sent()[n-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func newResetTestService(t *testing.T) (*userServiceImpl, *fakeUsers, *mailer.MemoryMailer) {
+	svc, users, mail, _ := newTestUserService(t)
+	return svc, users, mail
+}
+
+func newTestUserService(t *testing.T) (*userServiceImpl, *fakeUsers, *mailer.MemoryMailer, *fakeSessions) {
+	t.Helper()
+
+	email, err := value_object.NewEmail("an@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := value_object.NewPassword("old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := entity.NewUser(email, password, "an")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.ID = 1
+
+	users := &fakeUsers{users: map[int64]*entity.User{1: user}}
+	mail := mailer.NewMemoryMailer()
+	limiter := ratelimit.NewMemoryLimiter(100, time.Minute)
+
+	sessions := &fakeSessions{}
+
+	svc := NewUserService(users, &fakeUserTokens{}, nil, nil, sessions, fakeAudit{}, nil, mail,
+		AuthLimiters{PerEmail: limiter, PerPhone: limiter, PerIP: limiter},
+		AuthConfig{AppURL: "https://app.example.com/", VerificationTTL: time.Hour, ResetTTL: time.Hour},
+	).(*userServiceImpl)
+
+	return svc, users, mail, sessions
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   func(plain string) string
+		wantErr error
+	}{
+		{name: "mailed token", token: func(plain string) string { return plain }},
+		{name: "unknown token", token: func(string) string { return "not-a-token" }, wantErr: entity.ErrInvalidToken},
+		{name: "hash of the token", token: entity.HashToken, wantErr: entity.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, users, mail := newResetTestService(t)
+
+			if err := svc.ForgotPassword(ctx, dto.ForgotPasswordRequest{Email: "an@example.com"}, "10.0.0.1"); err != nil {
+				t.Fatal(err)
+			}
+			plain := waitForResetToken(t, mail, 1)
+			if mail.Sent()[0].To != "an@example.com" {
+				t.Fatalf("reset mailed to %s", mail.Sent()[0].To)
+			}
+
+			err := svc.ResetPassword(ctx, dto.ResetPasswordRequest{Token: tt.token(plain), NewPassword: "new-password"}, "10.0.0.1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetPassword = %v, want %v", err, tt.wantErr)
+			}
+
+			wantPassword := "old-password"
+			if tt.wantErr == nil {
+				wantPassword = "new-password"
+				if !users.users[1].IsEmailVerified() {
+					t.Fatal("reset did not verify the email address")
+				}
+			}
+			if err := users.users[1].Password.Compare(wantPassword); err != nil {
+				t.Fatalf("password is not %q", wantPassword)
+			}
+		})
+	}
+}
+
+func TestResetTokensAreSingleUse(t *testing.T) {
+	ctx := context.Background()
+	svc, _, mail := newResetTestService(t)
+
+	tokens := make([]string, 0, 2)
+	for i := 1; i <= 2; i++ {
+		if err := svc.ForgotPassword(ctx, dto.ForgotPasswordRequest{Email: "an@example.com"}, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, waitForResetToken(t, mail, i))
+	}
+	first, second := tokens[0], tokens[1]
+
+	// A new email replaces the link of the previous one
+	if err := svc.ResetPassword(ctx, dto.ResetPasswordRequest{Token: first, NewPassword: "new-password"}, "10.0.0.1"); !errors.Is(err, entity.ErrInvalidToken) {
+		t.Fatalf("first link: got %v, want ErrInvalidToken", err)
+	}
+	if err := svc.ResetPassword(ctx, dto.ResetPasswordRequest{Token: second, NewPassword: "new-password"}, "10.0.0.1"); err != nil {
+		t.Fatalf("second link: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, dto.ResetPasswordRequest{Token: second, NewPassword: "other-password"}, "10.0.0.1"); !errors.Is(err, entity.ErrInvalidToken) {
+		t.Fatalf("reused link: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestForgotPasswordForUnknownEmail(t *testing.T) {
+	svc, _, mail := newResetTestService(t)
+
+	if err := svc.ForgotPassword(context.Background(), dto.ForgotPasswordRequest{Email: "nobody@example.com"}, "10.0.0.1"); err != nil {
+		t.Fatalf("got %v, want the same answer as for a registered address", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if sent := mail.Sent(); len(sent) != 0 {
+		t.Fatalf("mailed %+v to an unknown address", sent)
+	}
+}
+
+func TestAccountChangesSignOutSessions(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   entity.Role
+		change func(ctx context.Context, svc *userServiceImpl) error
+		want   []string
+	}{
+		{
+			name: "password reset",
+			change: func(ctx context.Context, svc *userServiceImpl) error {
+				if err := svc.ForgotPassword(ctx, dto.ForgotPasswordRequest{Email: "an@example.com"}, "10.0.0.1"); err != nil {
+					return err
+				}
+				plain := waitForResetToken(t, svc.mail.(*mailer.MemoryMailer), 1)
+				return svc.ResetPassword(ctx, dto.ResetPasswordRequest{Token: plain, NewPassword: "new-password"}, "10.0.0.1")
+			},
+			want: []string{""},
+		},
+		{
+			name: "password change keeps the current session",
+			change: func(ctx context.Context, svc *userServiceImpl) error {
+				return svc.ChangePassword(ctx, 1, "current", dto.ChangePasswordRequest{OldPassword: "old-password", NewPassword: "new-password"})
+			},
+			want: []string{"current"},
+		},
+		{
+			name: "wrong old password",
+			change: func(ctx context.Context, svc *userServiceImpl) error {
+				err := svc.ChangePassword(ctx, 1, "current", dto.ChangePasswordRequest{OldPassword: "wrong-password", NewPassword: "new-password"})
+				if !errors.Is(err, value_object.ErrPasswordMismatch) {
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			name: "deactivation",
+			change: func(ctx context.Context, svc *userServiceImpl) error {
+				return svc.DeactivateUser(ctx, 1)
+			},
+			want: []string{""},
+		},
+		{
+			name: "demotion",
+			role: entity.RoleAdmin,
+			change: func(ctx context.Context, svc *userServiceImpl) error {
+				_, err := svc.AssignRole(ctx, 1, dto.AssignRoleRequest{Role: string(entity.RoleModerator)})
+				return err
+			},
+			want: []string{""},
+		},
+		{
+			name: "promotion",
+			role: entity.RoleUser,
+			change: func(ctx context.Context, svc *userServiceImpl) error {
+				_, err := svc.AssignRole(ctx, 1, dto.AssignRoleRequest{Role: string(entity.RoleModerator)})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, users, _, sessions := newTestUserService(t)
+			if tt.role != "" {
+				users.users[1].Role = tt.role
+			}
+
+			if err := tt.change(context.Background(), svc); err != nil {
+				t.Fatal(err)
+			}
+			if len(sessions.revoked) != len(tt.want) {
+				t.Fatalf("signed out %q, want %q", sessions.revoked, tt.want)
+			}
+			for i := range tt.want {
+				if sessions.revoked[i] != tt.want[i] {
+					t.Fatalf("signed out %q, want %q", sessions.revoked, tt.want)
+				}
+			}
+		})
+	}
+}
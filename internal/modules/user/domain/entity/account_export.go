@@ -0,0 +1,57 @@
+package entity
+
+import "time"
+
+// ExportStatus is how far building an export has come
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "PENDING"
+	ExportProcessing ExportStatus = "PROCESSING"
+	ExportReady      ExportStatus = "READY"
+	ExportFailed     ExportStatus = "FAILED"
+)
+
+// AccountExport is an archive of a user's data. It is built in the
+// background and can be downloaded until it expires.
+type AccountExport struct {
+	ID            int64
+	UserID        int64
+	Status        ExportStatus
+	StorageKey    string // Set once the archive is ready
+	SizeBytes     int64
+	FailureReason string
+	CompletedAt   *time.Time
+	ExpiresAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// NewAccountExport creates a pending export of the user's data
+func NewAccountExport(userID int64) *AccountExport {
+	return &AccountExport{
+		UserID:    userID,
+		Status:    ExportPending,
+		CreatedAt: time.Now(),
+	}
+}
+
+// IsInProgress reports whether the archive is still being built
+func (e *AccountExport) IsInProgress() bool {
+	return e.Status == ExportPending || e.Status == ExportProcessing
+}
+
+// IsDownloadable reports whether the archive is ready and has not expired
+func (e *AccountExport) IsDownloadable(now time.Time) bool {
+	return e.Status == ExportReady && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+// Complete records the stored archive, which is kept for ttl
+func (e *AccountExport) Complete(storageKey string, size int64, ttl time.Duration) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	e.Status = ExportReady
+	e.StorageKey = storageKey
+	e.SizeBytes = size
+	e.CompletedAt = &now
+	e.ExpiresAt = &expiresAt
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"encoding/hex"
+	"time"
+)
+
+// Contact is an account saved in a user's contact list
+type Contact struct {
+	ContactUserID int64
+	Nickname      string
+	IsFavorite    bool
+	IsBlocked     bool
+	CreatedAt     time.Time
+}
+
+// ContactMatch is an account found through a hashed number of an uploaded
+// address book
+type ContactMatch struct {
+	PhoneHash string
+	UserID    int64
+}
+
+// ValidPhoneHash reports whether hash has the form of value_object.Phone.Hash
+func ValidPhoneHash(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
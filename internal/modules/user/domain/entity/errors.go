@@ -3,10 +3,48 @@ package entity
 import "errors"
 
 var (
-	ErrInvalidUsername     = errors.New("invalid username")
-	ErrUserAlreadyActive   = errors.New("user is already active")
-	ErrUserAlreadyDisabled = errors.New("user is already disabled")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrEmailAlreadyExists  = errors.New("email already exists")
-)
+	ErrInvalidUsername      = errors.New("invalid username")
+	ErrUserAlreadyActive    = errors.New("user is already active")
+	ErrUserAlreadyDisabled  = errors.New("user is already disabled")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrEmailAlreadyExists   = errors.New("email already exists")
+	ErrUsernameTaken        = errors.New("username is already taken")
+	ErrInvalidCredentials   = errors.New("invalid email or password")
+	ErrUserInactive         = errors.New("user is not active")
+	ErrEmailNotVerified     = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+	ErrTooManyRequests      = errors.New("too many requests, try again later")
+	ErrPhoneAlreadyExists   = errors.New("phone number already exists")
+	ErrInvalidOTP           = errors.New("invalid or expired code")
+	ErrOTPCooldown          = errors.New("a code was sent recently, wait before asking again")
+	ErrOTPAttemptsExceeded  = errors.New("too many wrong codes, ask for a new one")
+	ErrTwoFactorNotSetUp    = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorRequired    = errors.New("two-factor authentication is required for this account")
+	ErrInvalidTwoFactorCode = errors.New("invalid authentication code")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrSessionRevoked       = errors.New("session has been signed out")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrCannotChangeOwnRole  = errors.New("you cannot change your own role")
+	ErrInvalidDisplayName   = errors.New("display name must be at most 64 characters on one line")
+	ErrBioTooLong           = errors.New("bio must be at most 500 characters")
+	ErrSearchQueryTooShort  = errors.New("search query must be at least 2 characters")
+	ErrCannotManageAccount  = errors.New("you cannot manage an account of an equal or higher role")
+
+	ErrInvalidPhoneHash         = errors.New("phone hashes must be hex SHA-256 digests of E.164 numbers")
+	ErrContactSyncBatchTooLarge = errors.New("too many phone numbers in one sync")
 
+	ErrCannotFriendSelf      = errors.New("you cannot send a friend request to yourself")
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrFriendRequestExists   = errors.New("a friend request is already pending")
+	ErrAlreadyFriends        = errors.New("you are already friends")
+	ErrNotFriends            = errors.New("you are not friends")
+
+	ErrDeletionAlreadyScheduled = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled     = errors.New("account deletion is not scheduled")
+	ErrDeletionInProgress       = errors.New("account deletion is already in progress")
+	ErrExportInProgress         = errors.New("an export of your data is already being prepared")
+	ErrExportNotFound           = errors.New("no export of your data is available")
+)
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// FriendshipStatus is how far a friendship has come
+type FriendshipStatus string
+
+const (
+	FriendshipPending  FriendshipStatus = "PENDING"
+	FriendshipAccepted FriendshipStatus = "ACCEPTED"
+)
+
+// Friendship is a mutual relationship between two users. It starts as a
+// request from Requester that Addressee may accept; declined and canceled
+// requests are deleted.
+type Friendship struct {
+	ID          int64
+	RequesterID int64
+	AddresseeID int64
+	Status      FriendshipStatus
+	CreatedAt   time.Time
+	AcceptedAt  *time.Time
+}
+
+// NewFriendRequest creates a pending request from requesterID to addresseeID
+func NewFriendRequest(requesterID, addresseeID int64) (*Friendship, error) {
+	if requesterID == addresseeID {
+		return nil, ErrCannotFriendSelf
+	}
+
+	return &Friendship{
+		RequesterID: requesterID,
+		AddresseeID: addresseeID,
+		Status:      FriendshipPending,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Accept makes the request a friendship. Only its addressee may accept it.
+func (f *Friendship) Accept(userID int64) error {
+	if f.Status != FriendshipPending || f.AddresseeID != userID {
+		return ErrFriendRequestNotFound
+	}
+
+	now := time.Now()
+	f.Status = FriendshipAccepted
+	f.AcceptedAt = &now
+	return nil
+}
+
+// IsPending reports whether the friendship is still a request
+func (f *Friendship) IsPending() bool {
+	return f.Status == FriendshipPending
+}
+
+// OtherParty returns the user on the other side from userID
+func (f *Friendship) OtherParty(userID int64) int64 {
+	if f.RequesterID == userID {
+		return f.AddresseeID
+	}
+	return f.RequesterID
+}
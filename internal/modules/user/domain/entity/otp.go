@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+)
+
+// OTPPurpose is what a one-time code sent by SMS proves
+type OTPPurpose string
+
+const (
+	OTPPurposeRegister OTPPurpose = "register"
+	OTPPurposeLogin    OTPPurpose = "login"
+)
+
+// Valid reports whether the purpose is one of the known purposes
+func (p OTPPurpose) Valid() bool {
+	return p == OTPPurposeRegister || p == OTPPurposeLogin
+}
+
+// GenerateOTP returns a uniformly random numeric code of the given length
+func GenerateOTP(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+// HashOTP returns the stored form of a code. A keyed hash keeps a dump of the
+// store from revealing codes, which a plain hash of six digits would not.
+func HashOTP(secret []byte, purpose OTPPurpose, phone string, code string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(string(purpose) + ":" + phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of the public profile, counted in characters
+const (
+	MaxDisplayNameLength = 64
+	MaxBioLength         = 500
+)
+
+// AvatarSizes are the square renditions made of every uploaded avatar, in pixels
+var AvatarSizes = []int{64, 256, 512}
+
+// UpdateProfile changes the display name and bio. Blank values clear them:
+// without a display name clients show the username.
+func (u *User) UpdateProfile(displayName, bio *string) error {
+	name, text := u.DisplayName, u.Bio
+	if displayName != nil {
+		name = strings.TrimSpace(*displayName)
+		if utf8.RuneCountInString(name) > MaxDisplayNameLength || strings.ContainsAny(name, "\r\n\t") {
+			return ErrInvalidDisplayName
+		}
+	}
+	if bio != nil {
+		text = strings.TrimSpace(*bio)
+		if utf8.RuneCountInString(text) > MaxBioLength {
+			return ErrBioTooLong
+		}
+	}
+
+	u.DisplayName = name
+	u.Bio = text
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetAvatar switches to an uploaded avatar stored under key, and returns the
+// key of the avatar it replaces, if any
+func (u *User) SetAvatar(key string) string {
+	previous := u.AvatarKey
+	u.AvatarKey = key
+	u.AvatarURL = ""
+	u.UpdatedAt = time.Now()
+	return previous
+}
+
+// RemoveAvatar clears the avatar and returns the key of the uploaded one, if any
+func (u *User) RemoveAvatar() string {
+	return u.SetAvatar("")
+}
@@ -0,0 +1,77 @@
+package entity
+
+// Role is the system role of an account. It is unrelated to the roles of
+// members inside a conversation.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permissions checked by the admin routes
+const (
+	PermissionUsersView    = "users.view"
+	PermissionUsersEdit    = "users.edit"
+	PermissionUsersSuspend = "users.suspend" // Activate and deactivate accounts
+	PermissionUsersVIP     = "users.vip"
+	PermissionUsersDelete  = "users.delete"
+	PermissionRolesAssign  = "roles.assign"
+	PermissionAuditView    = "audit.view" // Read and export the audit log
+)
+
+var rolePermissions = map[Role][]string{
+	RoleModerator: {
+		PermissionUsersView,
+		PermissionUsersSuspend,
+	},
+	RoleAdmin: {
+		PermissionUsersView,
+		PermissionUsersEdit,
+		PermissionUsersSuspend,
+		PermissionUsersVIP,
+		PermissionUsersDelete,
+		PermissionRolesAssign,
+		PermissionAuditView,
+	},
+}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// IsStaff reports whether the role holds any permission
+func (r Role) IsStaff() bool {
+	return r == RoleModerator || r == RoleAdmin
+}
+
+// Can reports whether the role grants permission
+func (r Role) Can(permission string) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Outranks reports whether r is a higher role than other
+func (r Role) Outranks(other Role) bool {
+	return r.rank() > other.rank()
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleModerator:
+		return 1
+	case RoleAdmin:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Session is a login on one device. Its access token carries the session ID,
+// so revoking the session signs the device out.
+type Session struct {
+	ID           string
+	UserID       int64
+	DeviceName   string
+	Platform     string
+	IP           string
+	UserAgent    string
+	CreatedAt    time.Time
+	LastActiveAt time.Time
+	ExpiresAt    time.Time // When the access token of the session expires
+	RevokedAt    *time.Time
+}
+
+// NewSession starts a session for the user on the described device
+func NewSession(userID int64, deviceName, platform, ip, userAgent string, ttl time.Duration) (*Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Session{
+		ID:           hex.EncodeToString(id),
+		UserID:       userID,
+		DeviceName:   deviceName,
+		Platform:     platform,
+		IP:           ip,
+		UserAgent:    userAgent,
+		CreatedAt:    now,
+		LastActiveAt: now,
+		ExpiresAt:    now.Add(ttl),
+	}, nil
+}
+
+// IsActive reports whether the session is still signed in at now
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/pkg/totp"
+)
+
+// TwoFactor is a user's TOTP enrollment. It stays pending until the user
+// proves their authenticator app works by entering a code.
+type TwoFactor struct {
+	UserID       int64
+	Secret       []byte
+	EnabledAt    *time.Time // Nil while the enrollment is pending
+	LastUsedStep int64      // Time step of the last accepted code, so a code works once
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewTwoFactor starts an enrollment with a fresh secret
+func NewTwoFactor(userID int64) (*TwoFactor, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &TwoFactor{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// IsEnabled reports whether the enrollment was confirmed
+func (t *TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// recoveryCodeEncoding spells codes in lower case letters and digits 2-7,
+// which avoids the easily confused 0/O and 1/l
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// recoveryCodeLength is the characters in a recovery code; 40 random bits
+const recoveryCodeLength = 8
+
+// GenerateRecoveryCodes returns n single-use codes formatted for display,
+// along with the hashes to store
+func GenerateRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		raw := make([]byte, recoveryCodeLength*5/8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(raw)
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the stored form of a recovery code as the user typed it
+func HashRecoveryCode(code string) string {
+	return HashToken(normalizeRecoveryCode(code))
+}
+
+// IsRecoveryCode tells recovery codes apart from the shorter authenticator codes
+func IsRecoveryCode(code string) bool {
+	return len(normalizeRecoveryCode(code)) == recoveryCodeLength
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("got %d codes and %d hashes, want 10 each", len(codes), len(hashes))
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if len(code) != 9 || code[4] != '-' {
+			t.Fatalf("code %q is not formatted as xxxx-xxxx", code)
+		}
+		// Digits 0 and 1 are left out so they are not confused with o and l
+		if strings.Trim(code, "abcdefghijklmnopqrstuvwxyz234567-") != "" {
+			t.Fatalf("code %q has a character outside its alphabet", code)
+		}
+		if !IsRecoveryCode(code) {
+			t.Fatalf("IsRecoveryCode(%q) = false", code)
+		}
+		if HashRecoveryCode(code) != hashes[i] {
+			t.Fatalf("hash %d does not match its code", i)
+		}
+		if seen[code] {
+			t.Fatalf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := HashRecoveryCode("abcd-efgh")
+
+	tests := []struct {
+		name  string
+		typed string
+		same  bool
+	}{
+		{name: "as shown", typed: "abcd-efgh", same: true},
+		{name: "without dash", typed: "abcdefgh", same: true},
+		{name: "upper case", typed: "ABCD-EFGH", same: true},
+		{name: "spaces", typed: "abcd efgh ", same: true},
+		{name: "other code", typed: "abcd-efgi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := HashRecoveryCode(tt.typed) == want; same != tt.same {
+				t.Fatalf("HashRecoveryCode(%q) matches: %v, want %v", tt.typed, same, tt.same)
+			}
+		})
+	}
+}
+
+func TestIsRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: "abcd-efgh", want: true},
+		{code: "ABCDEFGH", want: true},
+		{code: "123456"},
+		{code: "123 456"},
+		{code: "abcd-efgh-ijkl"},
+		{code: ""},
+	}
+
+	for _, tt := range tests {
+		if got := IsRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("IsRecoveryCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -8,17 +8,36 @@ import (
 
 // User entity represents the core business object
 type User struct {
-	ID            int64
-	Email         value_object.Email
-	Password      value_object.Password
-	Username      string
-	Status        UserStatus
-	Language      string
-	IsVIP         bool
-	LastLoginTime int64
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	IsDeleted     bool
+	ID              int64
+	Email           value_object.Email // Zero for accounts created by phone
+	Phone           value_object.Phone // Zero unless the user signed up or linked a phone
+	Password        value_object.Password
+	Username        string
+	DisplayName     string
+	Bio             string
+	AvatarKey       string // Storage key prefix of the uploaded avatar; see AvatarSizes
+	AvatarURL       string // External avatar image, used when nothing was uploaded
+	Status          UserStatus
+	Role            Role
+	Language        string
+	IsVIP           bool
+	LastLoginAt     *time.Time // Nil until the first login
+	LastSeen        *time.Time // Maintained by the presence module
+	HideLastSeen    bool
+	EmailVerifiedAt *time.Time
+	PhoneVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	IsDeleted       bool
+
+	// Others may find the account by its exact phone number
+	DiscoverableByPhone bool
+
+	// Only friends may start a direct conversation with the account
+	OnlyFriendsCanMessage bool
+
+	// When the account is due to be purged; nil unless deletion was requested
+	DeletionScheduledAt *time.Time
 }
 
 type UserStatus int
@@ -36,16 +55,40 @@ func NewUser(email value_object.Email, password value_object.Password, username
 	}
 
 	return &User{
-		Email:         email,
-		Password:      password,
-		Username:      username,
-		Status:        UserStatusActive,
-		Language:      "en",
-		IsVIP:         false,
-		LastLoginTime: 0,
-		IsDeleted:     false,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		Email:     email,
+		Password:  password,
+		Username:  username,
+		Status:    UserStatusActive,
+		Role:      RoleUser,
+		Language:  "en",
+		IsVIP:     false,
+		IsDeleted: false,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+
+		DiscoverableByPhone: true,
+	}, nil
+}
+
+// NewPhoneUser creates a user who signed up with a phone number proven by an
+// OTP. The account has no email address and no password.
+func NewPhoneUser(phone value_object.Phone, username string) (*User, error) {
+	if username == "" {
+		return nil, ErrInvalidUsername
+	}
+
+	now := time.Now()
+	return &User{
+		Phone:           phone,
+		PhoneVerifiedAt: &now,
+		Username:        username,
+		Status:          UserStatusActive,
+		Role:            RoleUser,
+		Language:        "en",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+
+		DiscoverableByPhone: true,
 	}, nil
 }
 
@@ -83,6 +126,16 @@ func (u *User) DemoteFromVIP() {
 	u.UpdatedAt = time.Now()
 }
 
+// ChangeRole sets the system role of the account
+func (u *User) ChangeRole(role Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
 // IsActive checks if user is active
 func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive && !u.IsDeleted
@@ -90,8 +143,24 @@ func (u *User) IsActive() bool {
 
 // UpdateLastLogin updates the last login timestamp
 func (u *User) UpdateLastLogin() {
-	u.LastLoginTime = time.Now().Unix()
-	u.UpdatedAt = time.Now()
+	now := time.Now()
+	u.LastLoginAt = &now
+	u.UpdatedAt = now
+}
+
+// IsEmailVerified reports whether the user proved they own their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail marks the email address as verified
+func (u *User) VerifyEmail() {
+	if u.EmailVerifiedAt != nil {
+		return
+	}
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+	u.UpdatedAt = now
 }
 
 // ChangePassword changes user password
@@ -112,3 +181,25 @@ func (u *User) SoftDelete() {
 	u.UpdatedAt = time.Now()
 }
 
+// ScheduleDeletion schedules the account to be purged at the given time
+func (u *User) ScheduleDeletion(at time.Time) error {
+	if u.DeletionScheduledAt != nil {
+		return ErrDeletionAlreadyScheduled
+	}
+	u.DeletionScheduledAt = &at
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// CancelDeletion keeps an account whose deletion is still in its grace period
+func (u *User) CancelDeletion() error {
+	if u.DeletionScheduledAt == nil {
+		return ErrDeletionNotScheduled
+	}
+	if !u.DeletionScheduledAt.After(time.Now()) {
+		return ErrDeletionInProgress
+	}
+	u.DeletionScheduledAt = nil
+	u.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// TokenPurpose is what a user token may be used for
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use secret mailed to a user. Only its hash is kept,
+// so a leaked table cannot be used to take over accounts.
+type UserToken struct {
+	ID        int64
+	UserID    int64
+	Purpose   TokenPurpose
+	Hash      string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewUserToken generates a token for the user and returns it along with the
+// plain secret to mail; the secret cannot be recovered afterwards
+func NewUserToken(userID int64, purpose TokenPurpose, ttl time.Duration) (*UserToken, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now()
+	return &UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		Hash:      HashToken(plain),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, plain, nil
+}
+
+// HashToken returns the stored form of a plain token
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
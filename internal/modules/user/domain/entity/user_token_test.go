@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewUserToken(t *testing.T) {
+	token, plain, err := NewUserToken(7, TokenPurposePasswordReset, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.Hash == plain {
+		t.Fatal("token stores its plain secret")
+	}
+	if token.Hash != HashToken(plain) {
+		t.Fatal("stored hash does not match the mailed secret")
+	}
+	if token.UserID != 7 || token.Purpose != TokenPurposePasswordReset {
+		t.Fatalf("got user %d purpose %s", token.UserID, token.Purpose)
+	}
+	if ttl := token.ExpiresAt.Sub(token.CreatedAt); ttl != time.Hour {
+		t.Fatalf("token lives %v, want 1h", ttl)
+	}
+
+	other, otherPlain, err := NewUserToken(7, TokenPurposePasswordReset, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherPlain == plain || other.Hash == token.Hash {
+		t.Fatal("two tokens share a secret")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	tests := []struct {
+		plain string
+		want  string
+	}{
+		{plain: "", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{plain: "abc", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range tests {
+		if got := HashToken(tt.plain); got != tt.want {
+			t.Errorf("HashToken(%q) = %s, want %s", tt.plain, got, tt.want)
+		}
+	}
+}
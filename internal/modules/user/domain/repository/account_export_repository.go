@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// AccountExportRepository stores archives of users' data
+type AccountExportRepository interface {
+	Create(ctx context.Context, export *entity.AccountExport) error
+
+	// FindLatest returns the user's newest export, or ErrExportNotFound
+	FindLatest(ctx context.Context, userID int64) (*entity.AccountExport, error)
+
+	// ClaimPending moves up to limit pending exports, and exports claimed
+	// before claimedBefore, to PROCESSING so that one replica builds each
+	ClaimPending(ctx context.Context, now, claimedBefore time.Time, limit int) ([]*entity.AccountExport, error)
+
+	MarkReady(ctx context.Context, export *entity.AccountExport) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+
+	// DeleteByUser removes the user's exports and returns the storage keys
+	// of their archives
+	DeleteByUser(ctx context.Context, userID int64) ([]string, error)
+}
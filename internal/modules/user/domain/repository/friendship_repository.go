@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// FriendshipRepository stores friend requests and the friendships they become
+type FriendshipRepository interface {
+	// Create returns ErrFriendRequestExists if the pair already has a request
+	// or friendship, in either direction
+	Create(ctx context.Context, friendship *entity.Friendship) error
+
+	// FindByID and FindBetween return ErrFriendRequestNotFound when there is none
+	FindByID(ctx context.Context, id int64) (*entity.Friendship, error)
+	FindBetween(ctx context.Context, userID, otherID int64) (*entity.Friendship, error)
+
+	// Accept stores an accepted request. It fails with ErrFriendRequestNotFound
+	// unless the request is still pending.
+	Accept(ctx context.Context, friendship *entity.Friendship) error
+
+	// Delete removes a request or friendship if it still has status
+	Delete(ctx context.Context, id int64, status entity.FriendshipStatus) error
+
+	// ListPending returns the pending requests sent to userID, or sent by
+	// userID when outgoing is set, newest first
+	ListPending(ctx context.Context, userID int64, outgoing bool) ([]*entity.Friendship, error)
+
+	// ListFriendIDs returns the users userID is friends with
+	ListFriendIDs(ctx context.Context, userID int64) ([]int64, error)
+
+	// CountMutualFriends counts the accounts, deleted ones excluded, that both
+	// users are friends with
+	CountMutualFriends(ctx context.Context, userID, otherID int64) (int, error)
+}
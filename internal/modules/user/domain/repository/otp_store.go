@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// OTPStore keeps the hashed one-time codes sent by SMS until they are used or expire
+type OTPStore interface {
+	// StartCooldown claims the right to send a new code for the phone, unless
+	// one was claimed less than cooldown ago; then it returns ErrOTPCooldown.
+	// It leaves any stored code alone.
+	StartCooldown(ctx context.Context, purpose entity.OTPPurpose, phone string, cooldown time.Duration) error
+
+	// Store saves a code for the phone, replacing any earlier one and its attempts
+	Store(ctx context.Context, purpose entity.OTPPurpose, phone string, hash string, ttl time.Duration) error
+
+	// Verify checks a code. A match removes it, so it works once. A mismatch
+	// counts an attempt; after maxAttempts the code is removed and
+	// ErrOTPAttemptsExceeded returned. Otherwise mismatches return ErrInvalidOTP.
+	Verify(ctx context.Context, purpose entity.OTPPurpose, phone string, hash string, maxAttempts int) error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// SessionRepository stores login sessions
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+
+	// ListActive returns the user's unrevoked, unexpired sessions, most recently active first
+	ListActive(ctx context.Context, userID int64) ([]*entity.Session, error)
+
+	// Revoke signs out one active session of the user, or returns ErrSessionNotFound
+	Revoke(ctx context.Context, userID int64, sessionID string) error
+
+	// RevokeOthers signs out every active session of the user but keepID and
+	// returns the IDs of the sessions it revoked
+	RevokeOthers(ctx context.Context, userID int64, keepID string) ([]string, error)
+
+	// IsActive reports whether the session belongs to the user and is neither revoked nor expired
+	IsActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+
+	// Touch records activity on the session
+	Touch(ctx context.Context, sessionID string, at time.Time) error
+}
+
+// SessionRevocationStore tells every node about revoked sessions while their
+// access tokens are still valid, without a database query per request
+type SessionRevocationStore interface {
+	// Revoke marks the sessions revoked for ttl and notifies subscribers on all nodes
+	Revoke(ctx context.Context, userID int64, sessionIDs []string, ttl time.Duration) error
+
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+
+	// Subscribe calls fn for every revocation on any node until ctx is done
+	Subscribe(ctx context.Context, fn func(userID int64, sessionIDs []string)) error
+}
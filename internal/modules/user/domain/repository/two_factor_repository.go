@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// TwoFactorRepository stores TOTP enrollments and recovery codes
+type TwoFactorRepository interface {
+	// FindByUserID returns ErrTwoFactorNotSetUp when the user has no enrollment, pending or not
+	FindByUserID(ctx context.Context, userID int64) (*entity.TwoFactor, error)
+
+	// Save stores a pending enrollment, replacing an earlier pending one
+	Save(ctx context.Context, twoFactor *entity.TwoFactor) error
+
+	// Enable confirms the enrollment with the step of the code that proved it
+	// and replaces the user's recovery codes, in one transaction
+	Enable(ctx context.Context, userID int64, step int64, recoveryHashes []string) error
+
+	// Delete removes the enrollment and the recovery codes
+	Delete(ctx context.Context, userID int64) error
+
+	// UseStep records that a code of step was accepted. It returns
+	// ErrInvalidTwoFactorCode when that step or a later one was already used.
+	UseStep(ctx context.Context, userID int64, step int64) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error
+
+	// ConsumeRecoveryCode uses up an unused recovery code, or returns ErrInvalidTwoFactorCode
+	ConsumeRecoveryCode(ctx context.Context, userID int64, hash string) error
+
+	// CountRecoveryCodes returns how many unused recovery codes the user has left
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
@@ -18,5 +19,61 @@ type UserRepository interface {
 	List(ctx context.Context, offset, limit int) ([]*entity.User, error)
 	Count(ctx context.Context) (int64, error)
 	Exists(ctx context.Context, email value_object.Email) (bool, error)
+	FindByPhone(ctx context.Context, phone value_object.Phone) (*entity.User, error)
+	PhoneExists(ctx context.Context, phone value_object.Phone) (bool, error)
+
+	// UsernameExists reports whether any account, deleted ones included, holds username
+	UsernameExists(ctx context.Context, username string) (bool, error)
+
+	// UpdateProfile writes the display name, bio and avatar, including cleared ones
+	UpdateProfile(ctx context.Context, user *entity.User) error
+
+	// Search returns active accounts whose username or display name starts
+	// with or resembles query (lowercase), best matches first. Accounts that
+	// blocked viewerID or that viewerID blocked are left out.
+	Search(ctx context.Context, viewerID int64, query string, limit int) ([]*entity.User, error)
+
+	// FindDiscoverableByPhone returns the active account with phone that
+	// allows being found by it, with the same exclusions as Search
+	FindDiscoverableByPhone(ctx context.Context, viewerID int64, phone value_object.Phone) (*entity.User, error)
+
+	// FindDiscoverableByPhoneHashes returns the accounts among hashes (see
+	// value_object.Phone.Hash) that FindDiscoverableByPhone would return
+	FindDiscoverableByPhoneHashes(ctx context.Context, viewerID int64, hashes []string) ([]*entity.ContactMatch, error)
+
+	// UpdatePrivacy writes who may find and message the user
+	UpdatePrivacy(ctx context.Context, user *entity.User) error
+
+	// FindByIDs returns the accounts among ids that are not deleted, in no
+	// particular order
+	FindByIDs(ctx context.Context, ids []int64) ([]*entity.User, error)
+
+	// HasContact reports whether ownerID saved contactUserID as a contact
+	// without blocking them
+	HasContact(ctx context.Context, ownerID, contactUserID int64) (bool, error)
+
+	// IsBlockedBetween reports whether either user blocked the other
+	IsBlockedBetween(ctx context.Context, userID, otherID int64) (bool, error)
+
+	// AddContacts saves contactUserIDs as contacts of ownerID, leaving existing
+	// ones (blocked ones included) as they are, and returns how many were new
+	AddContacts(ctx context.Context, ownerID int64, contactUserIDs []int64) (int, error)
+
+	// ListContacts returns the contacts ownerID saved, blocked ones included
+	ListContacts(ctx context.Context, ownerID int64) ([]*entity.Contact, error)
+
+	// UpdateDeletion writes when the account is due to be purged, or that it is not
+	UpdateDeletion(ctx context.Context, user *entity.User) error
+
+	// ClaimDueDeletions marks up to limit accounts whose deletion is due as
+	// being purged and returns their IDs. Accounts claimed before
+	// claimedBefore are claimed again, since purging can be repeated.
+	ClaimDueDeletions(ctx context.Context, now, claimedBefore time.Time, limit int) ([]int64, error)
+
+	// Purge strips a claimed account of personal data and deletes its
+	// contacts, friendships, sessions, tokens and devices in one transaction.
+	// username replaces the old one, freeing it along with the email and
+	// phone. It returns the avatar key the account had.
+	Purge(ctx context.Context, id int64, username string) (avatarKey string, err error)
 }
 
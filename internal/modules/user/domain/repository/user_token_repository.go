@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// UserTokenRepository stores the single-use tokens mailed to users
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *entity.UserToken) error
+
+	// Consume marks an unused, unexpired token as used and returns it. Concurrent
+	// calls with the same token succeed at most once; the rest get ErrInvalidToken.
+	Consume(ctx context.Context, purpose entity.TokenPurpose, hash string) (*entity.UserToken, error)
+
+	// Revoke uses up every outstanding token of the user for purpose
+	Revoke(ctx context.Context, userID int64, purpose entity.TokenPurpose) error
+}
@@ -36,6 +36,11 @@ func (e Email) String() string {
 	return e.value
 }
 
+// IsZero reports whether no email address is set
+func (e Email) IsZero() bool {
+	return e.value == ""
+}
+
 // Equals compares two email value objects
 func (e Email) Equals(other Email) bool {
 	return e.value == other.value
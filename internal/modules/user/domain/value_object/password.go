@@ -39,6 +39,11 @@ func (p Password) Hash() string {
 	return p.hashedValue
 }
 
+// IsZero reports whether no password is set, as for accounts created by phone
+func (p Password) IsZero() bool {
+	return p.hashedValue == ""
+}
+
 // Compare checks if the plain password matches the hashed password
 func (p Password) Compare(plainPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(p.hashedValue), []byte(plainPassword))
@@ -0,0 +1,114 @@
+package value_object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidPhone = errors.New("invalid phone number")
+)
+
+// Country calling code of national numbers written with a leading 0
+const vietnamCallingCode = "84"
+
+// Phone value object - an E.164 number such as +84912345678
+type Phone struct {
+	value string
+}
+
+// NewPhone parses a phone number into E.164. Besides international numbers
+// (+84 912 345 678, 0084912345678) it accepts the Vietnamese national format
+// (0912 345 678) and the common mistake of keeping the 0 after +84.
+func NewPhone(phone string) (Phone, error) {
+	digits, international := stripPhone(phone)
+	if digits == "" {
+		return Phone{}, ErrInvalidPhone
+	}
+
+	switch {
+	case international:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		digits = vietnamCallingCode + digits[1:]
+	case strings.HasPrefix(digits, vietnamCallingCode) && len(digits) == 11:
+		// 84912345678: a Vietnamese mobile number without the +
+	default:
+		return Phone{}, ErrInvalidPhone
+	}
+
+	if strings.HasPrefix(digits, vietnamCallingCode+"0") {
+		digits = vietnamCallingCode + digits[3:]
+	}
+
+	// E.164 allows at most 15 digits and no country code starts with 0
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return Phone{}, ErrInvalidPhone
+	}
+	if strings.HasPrefix(digits, vietnamCallingCode) {
+		// Mobile numbers have 9 digits after the country code, landlines 10
+		if national := len(digits) - len(vietnamCallingCode); national != 9 && national != 10 {
+			return Phone{}, ErrInvalidPhone
+		}
+	}
+
+	return Phone{value: "+" + digits}, nil
+}
+
+// NewPhoneFromE164 creates a phone from a number already stored in E.164
+func NewPhoneFromE164(phone string) Phone {
+	return Phone{value: phone}
+}
+
+// stripPhone drops the separators people type in phone numbers and reports
+// whether the number started with +
+func stripPhone(phone string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+	if international {
+		phone = phone[1:]
+	}
+
+	var b strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), international
+}
+
+func (p Phone) Value() string {
+	return p.value
+}
+
+func (p Phone) String() string {
+	return p.value
+}
+
+// Hash is how clients refer to the number when syncing their address book:
+// the hex SHA-256 of its E.164 form. It is empty if no number is set.
+func (p Phone) Hash() string {
+	if p.value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(p.value))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsZero reports whether no phone number is set
+func (p Phone) IsZero() bool {
+	return p.value == ""
+}
+
+// Equals compares two phone value objects
+func (p Phone) Equals(other Phone) bool {
+	return p.value == other.value
+}
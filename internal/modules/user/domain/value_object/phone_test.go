@@ -0,0 +1,73 @@
+package value_object
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewPhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "E.164", input: "+84912345678", want: "+84912345678"},
+		{name: "international with spaces", input: "+84 912 345 678", want: "+84912345678"},
+		{name: "international with 00", input: "0084912345678", want: "+84912345678"},
+		{name: "national", input: "0912 345 678", want: "+84912345678"},
+		{name: "national with separators", input: "(091) 234-5678", want: "+84912345678"},
+		{name: "0 kept after +84", input: "+84 0912 345 678", want: "+84912345678"},
+		{name: "country code without +", input: "84912345678", want: "+84912345678"},
+		{name: "landline", input: "024 3825 1234", want: "+842438251234"},
+		{name: "other country", input: "+1 (415) 555-2671", want: "+14155552671"},
+		{name: "surrounding spaces", input: "  0912345678 ", want: "+84912345678"},
+		{name: "empty", input: ""},
+		{name: "letters", input: "0912abc678"},
+		{name: "no prefix", input: "912345678"},
+		{name: "too short", input: "+84 12345"},
+		{name: "vietnamese mobile missing a digit", input: "091234567"},
+		{name: "vietnamese number too long", input: "+84 9123 4567 890"},
+		{name: "more than 15 digits", input: "+1234567890123456"},
+		{name: "country code starting with 0", input: "+0123456789"},
+		{name: "+ in the middle", input: "09+12345678"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phone, err := NewPhone(tt.input)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidPhone) {
+					t.Fatalf("NewPhone(%q) = %q, %v, want ErrInvalidPhone", tt.input, phone.Value(), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewPhone(%q) failed: %v", tt.input, err)
+			}
+			if phone.Value() != tt.want {
+				t.Fatalf("NewPhone(%q) = %q, want %q", tt.input, phone.Value(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPhoneHash(t *testing.T) {
+	national, err := NewPhone("0912 345 678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	international, err := NewPhone("+84912345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if national.Hash() != international.Hash() {
+		t.Fatal("formats of one number hash differently")
+	}
+	if len(national.Hash()) != 64 {
+		t.Fatalf("hash %q is not hex SHA-256", national.Hash())
+	}
+	if (Phone{}).Hash() != "" {
+		t.Fatal("an unset phone has a hash")
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// AccountExportModel is the GORM model for archives of users' data
+type AccountExportModel struct {
+	ID            int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID        int64      `gorm:"column:user_id;not null"`
+	Status        string     `gorm:"column:status;not null"`
+	StorageKey    *string    `gorm:"column:storage_key"`
+	SizeBytes     *int64     `gorm:"column:size_bytes"`
+	FailureReason *string    `gorm:"column:failure_reason"`
+	ClaimedAt     *time.Time `gorm:"column:claimed_at"`
+	CompletedAt   *time.Time `gorm:"column:completed_at"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at;not null"`
+}
+
+func (AccountExportModel) TableName() string {
+	return "account_exports"
+}
+
+// ToEntity converts GORM model to domain entity
+func (m *AccountExportModel) ToEntity() *entity.AccountExport {
+	export := &entity.AccountExport{
+		ID:            m.ID,
+		UserID:        m.UserID,
+		Status:        entity.ExportStatus(m.Status),
+		StorageKey:    derefString(m.StorageKey),
+		FailureReason: derefString(m.FailureReason),
+		CompletedAt:   m.CompletedAt,
+		ExpiresAt:     m.ExpiresAt,
+		CreatedAt:     m.CreatedAt,
+	}
+	if m.SizeBytes != nil {
+		export.SizeBytes = *m.SizeBytes
+	}
+	return export
+}
+
+// FromAccountExportEntity converts domain entity to GORM model
+func FromAccountExportEntity(export *entity.AccountExport) *AccountExportModel {
+	return &AccountExportModel{
+		ID:            export.ID,
+		UserID:        export.UserID,
+		Status:        string(export.Status),
+		StorageKey:    optionalString(export.StorageKey),
+		FailureReason: optionalString(export.FailureReason),
+		CompletedAt:   export.CompletedAt,
+		ExpiresAt:     export.ExpiresAt,
+		CreatedAt:     export.CreatedAt,
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// FriendshipModel is the GORM model for friend requests and friendships
+type FriendshipModel struct {
+	ID          int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	RequesterID int64      `gorm:"column:requester_id;not null"`
+	AddresseeID int64      `gorm:"column:addressee_id;not null"`
+	Status      string     `gorm:"column:status;not null"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null"`
+	AcceptedAt  *time.Time `gorm:"column:accepted_at"`
+}
+
+func (FriendshipModel) TableName() string {
+	return "friendships"
+}
+
+// ToEntity converts GORM model to domain entity
+func (m *FriendshipModel) ToEntity() *entity.Friendship {
+	return &entity.Friendship{
+		ID:          m.ID,
+		RequesterID: m.RequesterID,
+		AddresseeID: m.AddresseeID,
+		Status:      entity.FriendshipStatus(m.Status),
+		CreatedAt:   m.CreatedAt,
+		AcceptedAt:  m.AcceptedAt,
+	}
+}
+
+// FromFriendshipEntity converts domain entity to GORM model
+func FromFriendshipEntity(friendship *entity.Friendship) *FriendshipModel {
+	return &FriendshipModel{
+		ID:          friendship.ID,
+		RequesterID: friendship.RequesterID,
+		AddresseeID: friendship.AddresseeID,
+		Status:      string(friendship.Status),
+		CreatedAt:   friendship.CreatedAt,
+		AcceptedAt:  friendship.AcceptedAt,
+	}
+}
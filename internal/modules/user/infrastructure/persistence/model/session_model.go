@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// SessionModel is the GORM model for login sessions
+type SessionModel struct {
+	ID           string     `gorm:"column:id;primaryKey"`
+	UserID       int64      `gorm:"column:user_id;not null"`
+	DeviceName   string     `gorm:"column:device_name;not null"`
+	Platform     string     `gorm:"column:platform;not null"`
+	IP           string     `gorm:"column:ip;not null"`
+	UserAgent    string     `gorm:"column:user_agent;not null"`
+	CreatedAt    time.Time  `gorm:"column:created_at;not null"`
+	LastActiveAt time.Time  `gorm:"column:last_active_at;not null"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;not null"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at"`
+}
+
+func (SessionModel) TableName() string {
+	return "user_sessions"
+}
+
+// ToEntity converts GORM model to domain entity
+func (m *SessionModel) ToEntity() *entity.Session {
+	return &entity.Session{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		DeviceName:   m.DeviceName,
+		Platform:     m.Platform,
+		IP:           m.IP,
+		UserAgent:    m.UserAgent,
+		CreatedAt:    m.CreatedAt,
+		LastActiveAt: m.LastActiveAt,
+		ExpiresAt:    m.ExpiresAt,
+		RevokedAt:    m.RevokedAt,
+	}
+}
+
+// FromSessionEntity converts domain entity to GORM model
+func FromSessionEntity(session *entity.Session) *SessionModel {
+	return &SessionModel{
+		ID:           session.ID,
+		UserID:       session.UserID,
+		DeviceName:   session.DeviceName,
+		Platform:     session.Platform,
+		IP:           session.IP,
+		UserAgent:    session.UserAgent,
+		CreatedAt:    session.CreatedAt,
+		LastActiveAt: session.LastActiveAt,
+		ExpiresAt:    session.ExpiresAt,
+		RevokedAt:    session.RevokedAt,
+	}
+}
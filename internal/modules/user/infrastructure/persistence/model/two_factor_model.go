@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// TwoFactorModel is the GORM model for TOTP enrollments
+type TwoFactorModel struct {
+	UserID           int64      `gorm:"column:user_id;primaryKey"`
+	SecretCiphertext []byte     `gorm:"column:secret_ciphertext;not null"`
+	EnabledAt        *time.Time `gorm:"column:enabled_at"`
+	LastUsedStep     int64      `gorm:"column:last_used_step;not null;default:0"`
+	CreatedAt        time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (TwoFactorModel) TableName() string {
+	return "user_two_factor"
+}
+
+// RecoveryCodeModel is the GORM model for two-factor recovery codes
+type RecoveryCodeModel struct {
+	ID        int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    int64      `gorm:"column:user_id;not null"`
+	CodeHash  string     `gorm:"column:code_hash;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (RecoveryCodeModel) TableName() string {
+	return "user_recovery_codes"
+}
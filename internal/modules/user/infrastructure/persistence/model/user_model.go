@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
@@ -9,61 +10,177 @@ import (
 
 // UserModel is the GORM model for database persistence
 type UserModel struct {
-	ID            int64     `gorm:"column:id;primaryKey;autoIncrement"`
-	Email         string    `gorm:"column:email;unique;not null;index"`
-	PasswordHash  string    `gorm:"column:password_hash;not null"`
-	Username      string    `gorm:"column:username;not null"`
-	Status        int       `gorm:"column:status;not null;default:1"`
-	Language      string    `gorm:"column:language;not null;default:en"`
-	IsVIP         bool      `gorm:"column:is_vip;not null;default:false"`
-	LastLoginTime int64     `gorm:"column:last_login_time;default:0"`
-	CreatedAt     time.Time `gorm:"column:created_at;not null;autoCreateTime"`
-	UpdatedAt     time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
-	IsDeleted     bool      `gorm:"column:is_deleted;not null;default:false;index"`
+	ID              int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Email           *string    `gorm:"column:email;unique;index"` // NULL for accounts created by phone
+	Phone           *string    `gorm:"column:phone;index"`        // E.164
+	PhoneHash       *string    `gorm:"column:phone_hash"`         // Kept in step with phone for contact sync
+	PasswordHash    string     `gorm:"column:password_hash;not null"`
+	Username        string     `gorm:"column:username;not null"`
+	DisplayName     *string    `gorm:"column:display_name"`
+	Bio             *string    `gorm:"column:bio"`
+	AvatarKey       *string    `gorm:"column:avatar_key"`
+	AvatarURL       *string    `gorm:"column:avatar_url"`
+	Status          string     `gorm:"column:status;not null;default:ACTIVE"`
+	Role            string     `gorm:"column:role;not null;default:user"`
+	Language        string     `gorm:"column:language;not null;default:en"`
+	IsVIP           bool       `gorm:"column:is_vip;not null;default:false"`
+	LastLoginAt     *time.Time `gorm:"column:last_login_at"`
+	LastSeen        *time.Time `gorm:"column:last_seen;->"`      // Read-only: written by the presence module
+	HideLastSeen    bool       `gorm:"column:hide_last_seen;->"` // Read-only: written by the presence module
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"`
+	PhoneVerifiedAt *time.Time `gorm:"column:phone_verified_at"`
+	CreatedAt       time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+	IsDeleted       bool       `gorm:"column:is_deleted;not null;default:false;index"`
+
+	// Read-only here: new accounts take the column defaults and UpdatePrivacy changes them
+	DiscoverableByPhone   bool `gorm:"column:discoverable_by_phone;->"`
+	OnlyFriendsCanMessage bool `gorm:"column:only_friends_can_message;->"`
+
+	// Read-only here: written by UpdateDeletion
+	DeletionScheduledAt *time.Time `gorm:"column:deletion_scheduled_at;->"`
 }
 
 func (UserModel) TableName() string {
 	return "users"
 }
 
+// Values of the status column
+const (
+	statusActive   = "ACTIVE"
+	statusDisabled = "DISABLED"
+)
+
 // ToEntity converts GORM model to domain entity
 func (m *UserModel) ToEntity() (*entity.User, error) {
-	email, err := value_object.NewEmail(m.Email)
-	if err != nil {
-		return nil, err
+	var status entity.UserStatus
+	switch m.Status {
+	case statusActive:
+		status = entity.UserStatusActive
+	case statusDisabled:
+		status = entity.UserStatusDisabled
+	default:
+		return nil, fmt.Errorf("user %d has unknown status %q", m.ID, m.Status)
+	}
+
+	var email value_object.Email
+	if m.Email != nil {
+		var err error
+		if email, err = value_object.NewEmail(*m.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	var phone value_object.Phone
+	if m.Phone != nil {
+		phone = value_object.NewPhoneFromE164(*m.Phone)
 	}
 
 	password := value_object.NewPasswordFromHash(m.PasswordHash)
 
 	return &entity.User{
-		ID:            m.ID,
-		Email:         email,
-		Password:      password,
-		Username:      m.Username,
-		Status:        entity.UserStatus(m.Status),
-		Language:      m.Language,
-		IsVIP:         m.IsVIP,
-		LastLoginTime: m.LastLoginTime,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
-		IsDeleted:     m.IsDeleted,
+		ID:              m.ID,
+		Email:           email,
+		Phone:           phone,
+		Password:        password,
+		Username:        m.Username,
+		DisplayName:     derefString(m.DisplayName),
+		Bio:             derefString(m.Bio),
+		AvatarKey:       derefString(m.AvatarKey),
+		AvatarURL:       derefString(m.AvatarURL),
+		Status:          status,
+		Role:            entity.Role(m.Role),
+		Language:        m.Language,
+		IsVIP:           m.IsVIP,
+		LastLoginAt:     m.LastLoginAt,
+		LastSeen:        m.LastSeen,
+		HideLastSeen:    m.HideLastSeen,
+		EmailVerifiedAt: m.EmailVerifiedAt,
+		PhoneVerifiedAt: m.PhoneVerifiedAt,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+		IsDeleted:       m.IsDeleted,
+
+		DiscoverableByPhone:   m.DiscoverableByPhone,
+		OnlyFriendsCanMessage: m.OnlyFriendsCanMessage,
+
+		DeletionScheduledAt: m.DeletionScheduledAt,
 	}, nil
 }
 
 // FromEntity converts domain entity to GORM model
 func FromEntity(user *entity.User) *UserModel {
 	return &UserModel{
-		ID:            user.ID,
-		Email:         user.Email.Value(),
-		PasswordHash:  user.Password.Hash(),
-		Username:      user.Username,
-		Status:        int(user.Status),
-		Language:      user.Language,
-		IsVIP:         user.IsVIP,
-		LastLoginTime: user.LastLoginTime,
-		CreatedAt:     user.CreatedAt,
-		UpdatedAt:     user.UpdatedAt,
-		IsDeleted:     user.IsDeleted,
+		ID:              user.ID,
+		Email:           optionalString(user.Email.Value()),
+		Phone:           optionalString(user.Phone.Value()),
+		PhoneHash:       optionalString(user.Phone.Hash()),
+		PasswordHash:    user.Password.Hash(),
+		Username:        user.Username,
+		DisplayName:     optionalString(user.DisplayName),
+		Bio:             optionalString(user.Bio),
+		AvatarKey:       optionalString(user.AvatarKey),
+		AvatarURL:       optionalString(user.AvatarURL),
+		Status:          statusColumn(user.Status),
+		Role:            string(user.Role),
+		Language:        user.Language,
+		IsVIP:           user.IsVIP,
+		LastLoginAt:     user.LastLoginAt,
+		LastSeen:        user.LastSeen,
+		HideLastSeen:    user.HideLastSeen,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneVerifiedAt: user.PhoneVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		IsDeleted:       user.IsDeleted,
+	}
+}
+
+// statusColumn maps a status to its column value. The zero status maps to an
+// empty value, which updates skip.
+func statusColumn(status entity.UserStatus) string {
+	switch status {
+	case entity.UserStatusActive:
+		return statusActive
+	case entity.UserStatusDisabled:
+		return statusDisabled
+	}
+	return ""
+}
+
+// optionalString maps an empty value to NULL
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// derefString maps NULL to an empty value
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// PrivacyColumns returns the privacy settings of user for an update, which
+// Updates with a struct would skip when they are off
+func PrivacyColumns(user *entity.User) map[string]interface{} {
+	return map[string]interface{}{
+		"discoverable_by_phone":    user.DiscoverableByPhone,
+		"only_friends_can_message": user.OnlyFriendsCanMessage,
 	}
 }
 
+// ProfileColumns returns the profile fields of user for an update that may
+// clear them, which Updates with a struct would skip
+func ProfileColumns(user *entity.User) map[string]interface{} {
+	return map[string]interface{}{
+		"display_name": optionalString(user.DisplayName),
+		"bio":          optionalString(user.Bio),
+		"avatar_key":   optionalString(user.AvatarKey),
+		"avatar_url":   optionalString(user.AvatarURL),
+		"updated_at":   user.UpdatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+)
+
+// UserTokenModel is the GORM model for single-use user tokens
+type UserTokenModel struct {
+	ID        int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    int64      `gorm:"column:user_id;not null"`
+	Purpose   string     `gorm:"column:purpose;not null"`
+	TokenHash string     `gorm:"column:token_hash;not null;unique"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (UserTokenModel) TableName() string {
+	return "user_tokens"
+}
+
+// ToEntity converts GORM model to domain entity
+func (m *UserTokenModel) ToEntity() *entity.UserToken {
+	return &entity.UserToken{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Purpose:   entity.TokenPurpose(m.Purpose),
+		Hash:      m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// FromUserTokenEntity converts domain entity to GORM model
+func FromUserTokenEntity(token *entity.UserToken) *UserTokenModel {
+	return &UserTokenModel{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		Purpose:   string(token.Purpose),
+		TokenHash: token.Hash,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    token.UsedAt,
+		CreatedAt: token.CreatedAt,
+	}
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+)
+
+// Results of verifyScript
+const (
+	otpMatched  = 1
+	otpMismatch = 0
+	otpMissing  = -1
+	otpExceeded = -2
+)
+
+// verifyScript checks a code and counts the attempt in one step, so parallel
+// guesses cannot get past the attempt limit
+var verifyScript = goredis.NewScript(`
+local stored = redis.call("HGET", KEYS[1], "hash")
+if not stored then
+	return -1
+end
+if stored == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 1
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+if attempts >= tonumber(ARGV[2]) then
+	redis.call("DEL", KEYS[1])
+	return -2
+end
+return 0
+`)
+
+type otpStoreImpl struct {
+	client *goredis.Client
+}
+
+// NewOTPStore creates a Redis OTP store shared by every node
+func NewOTPStore(client *goredis.Client) repository.OTPStore {
+	return &otpStoreImpl{client: client}
+}
+
+func otpKey(purpose entity.OTPPurpose, phone string) string {
+	return "otp:" + string(purpose) + ":" + phone
+}
+
+func cooldownKey(purpose entity.OTPPurpose, phone string) string {
+	return "otp:cooldown:" + string(purpose) + ":" + phone
+}
+
+func (s *otpStoreImpl) StartCooldown(ctx context.Context, purpose entity.OTPPurpose, phone string, cooldown time.Duration) error {
+	ok, err := s.client.SetNX(ctx, cooldownKey(purpose, phone), 1, cooldown).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check OTP cooldown: %w", err)
+	}
+	if !ok {
+		return entity.ErrOTPCooldown
+	}
+	return nil
+}
+
+func (s *otpStoreImpl) Store(ctx context.Context, purpose entity.OTPPurpose, phone string, hash string, ttl time.Duration) error {
+	key := otpKey(purpose, phone)
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "hash", hash, "attempts", 0)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store OTP: %w", err)
+	}
+
+	return nil
+}
+
+func (s *otpStoreImpl) Verify(ctx context.Context, purpose entity.OTPPurpose, phone string, hash string, maxAttempts int) error {
+	result, err := verifyScript.Run(ctx, s.client, []string{otpKey(purpose, phone)}, hash, maxAttempts).Int()
+	if err != nil {
+		return fmt.Errorf("failed to verify OTP: %w", err)
+	}
+
+	switch result {
+	case otpMatched:
+		return nil
+	case otpExceeded:
+		return entity.ErrOTPAttemptsExceeded
+	default:
+		return entity.ErrInvalidOTP
+	}
+}
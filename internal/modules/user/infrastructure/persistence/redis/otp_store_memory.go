@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+)
+
+type memoryOTP struct {
+	hash      string
+	attempts  int
+	expiresAt time.Time
+}
+
+type memoryOTPStore struct {
+	codes     map[string]*memoryOTP
+	cooldowns map[string]time.Time
+	mu        sync.Mutex
+}
+
+// NewMemoryOTPStore creates an in-process OTP store.
+// It is used when Redis is unavailable; a code then only verifies on the node that sent it.
+func NewMemoryOTPStore() repository.OTPStore {
+	return &memoryOTPStore{
+		codes:     make(map[string]*memoryOTP),
+		cooldowns: make(map[string]time.Time),
+	}
+}
+
+func (s *memoryOTPStore) StartCooldown(_ context.Context, purpose entity.OTPPurpose, phone string, cooldown time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	key := otpKey(purpose, phone)
+	if until, ok := s.cooldowns[key]; ok && now.Before(until) {
+		return entity.ErrOTPCooldown
+	}
+
+	s.cooldowns[key] = now.Add(cooldown)
+	return nil
+}
+
+func (s *memoryOTPStore) Store(_ context.Context, purpose entity.OTPPurpose, phone string, hash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes[otpKey(purpose, phone)] = &memoryOTP{hash: hash, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryOTPStore) Verify(_ context.Context, purpose entity.OTPPurpose, phone string, hash string, maxAttempts int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := otpKey(purpose, phone)
+	code, ok := s.codes[key]
+	if !ok || !time.Now().Before(code.expiresAt) {
+		delete(s.codes, key)
+		return entity.ErrInvalidOTP
+	}
+
+	if subtle.ConstantTimeCompare([]byte(code.hash), []byte(hash)) == 1 {
+		delete(s.codes, key)
+		return nil
+	}
+
+	code.attempts++
+	if code.attempts >= maxAttempts {
+		delete(s.codes, key)
+		return entity.ErrOTPAttemptsExceeded
+	}
+	return entity.ErrInvalidOTP
+}
+
+// sweep forgets expired codes and cooldowns
+func (s *memoryOTPStore) sweep(now time.Time) {
+	for key, code := range s.codes {
+		if !now.Before(code.expiresAt) {
+			delete(s.codes, key)
+		}
+	}
+	for key, until := range s.cooldowns {
+		if !now.Before(until) {
+			delete(s.cooldowns, key)
+		}
+	}
+}
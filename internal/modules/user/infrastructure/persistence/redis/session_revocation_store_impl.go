@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+)
+
+// revocationChannel carries revocations to every node, so each can close the
+// WebSocket connections it holds for the revoked sessions
+const revocationChannel = "sessions:revoked"
+
+type revocationEvent struct {
+	UserID     int64    `json:"user_id"`
+	SessionIDs []string `json:"session_ids"`
+}
+
+type sessionRevocationStore struct {
+	client *goredis.Client
+}
+
+// NewSessionRevocationStore creates a revocation store backed by Redis keys and pub/sub
+func NewSessionRevocationStore(client *goredis.Client) repository.SessionRevocationStore {
+	return &sessionRevocationStore{client: client}
+}
+
+func revokedKey(sessionID string) string {
+	return fmt.Sprintf("session:revoked:%s", sessionID)
+}
+
+func (s *sessionRevocationStore) Revoke(ctx context.Context, userID int64, sessionIDs []string, ttl time.Duration) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	pipe := s.client.Pipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Set(ctx, revokedKey(sessionID), 1, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	payload, err := json.Marshal(revocationEvent{UserID: userID, SessionIDs: sessionIDs})
+	if err != nil {
+		return err
+	}
+	if err := s.client.Publish(ctx, revocationChannel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish session revocation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sessionRevocationStore) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *sessionRevocationStore) Subscribe(ctx context.Context, fn func(userID int64, sessionIDs []string)) error {
+	pubsub := s.client.Subscribe(ctx, revocationChannel)
+	defer pubsub.Close()
+
+	// Wait for the subscription so revocations right after startup are not missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to session revocations: %w", err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			var event revocationEvent
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				log.Printf("Invalid session revocation event: %v", err)
+				continue
+			}
+			fn(event.UserID, event.SessionIDs)
+		}
+	}
+}
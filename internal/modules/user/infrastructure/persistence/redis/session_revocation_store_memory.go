@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+)
+
+type memorySessionRevocationStore struct {
+	revoked     map[string]time.Time // session ID -> when the entry can be forgotten
+	subscribers []func(userID int64, sessionIDs []string)
+	mu          sync.Mutex
+}
+
+// NewMemorySessionRevocationStore creates an in-process revocation store.
+// It is used when Redis is unavailable; revocations then only reach this node.
+func NewMemorySessionRevocationStore() repository.SessionRevocationStore {
+	return &memorySessionRevocationStore{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+func (s *memorySessionRevocationStore) Revoke(_ context.Context, userID int64, sessionIDs []string, ttl time.Duration) error {
+	s.mu.Lock()
+	now := time.Now()
+	s.sweep(now)
+	for _, sessionID := range sessionIDs {
+		s.revoked[sessionID] = now.Add(ttl)
+	}
+	subscribers := s.subscribers
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(userID, sessionIDs)
+	}
+	return nil
+}
+
+func (s *memorySessionRevocationStore) IsRevoked(_ context.Context, sessionID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.revoked[sessionID]
+	return ok && time.Now().Before(until), nil
+}
+
+func (s *memorySessionRevocationStore) Subscribe(ctx context.Context, fn func(userID int64, sessionIDs []string)) error {
+	s.mu.Lock()
+	s.subscribers = append(s.subscribers, fn)
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// sweep forgets revocations of sessions whose tokens have expired
+func (s *memorySessionRevocationStore) sweep(now time.Time) {
+	for sessionID, until := range s.revoked {
+		if !now.Before(until) {
+			delete(s.revoked, sessionID)
+		}
+	}
+}
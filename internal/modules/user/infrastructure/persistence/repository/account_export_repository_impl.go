@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/model"
+)
+
+type accountExportRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAccountExportRepository creates a repository over the account_exports table
+func NewAccountExportRepository(db *gorm.DB) repository.AccountExportRepository {
+	return &accountExportRepositoryImpl{db: db}
+}
+
+func (r *accountExportRepositoryImpl) Create(ctx context.Context, export *entity.AccountExport) error {
+	exportModel := model.FromAccountExportEntity(export)
+
+	if err := r.db.WithContext(ctx).Create(exportModel).Error; err != nil {
+		return fmt.Errorf("failed to create account export: %w", err)
+	}
+
+	export.ID = exportModel.ID
+	return nil
+}
+
+func (r *accountExportRepositoryImpl) FindLatest(ctx context.Context, userID int64) (*entity.AccountExport, error) {
+	var exportModel model.AccountExportModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		First(&exportModel).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to find account export: %w", err)
+	}
+
+	return exportModel.ToEntity(), nil
+}
+
+func (r *accountExportRepositoryImpl) ClaimPending(ctx context.Context, now, claimedBefore time.Time, limit int) ([]*entity.AccountExport, error) {
+	var exportModels []model.AccountExportModel
+
+	// An export whose replica stopped mid-build is claimed again; building is repeatable
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE account_exports
+		SET status = ?, claimed_at = ?
+		WHERE id IN (
+			SELECT id FROM account_exports
+			WHERE status = ? OR (status = ? AND claimed_at < ?)
+			ORDER BY created_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		string(entity.ExportProcessing), now,
+		string(entity.ExportPending), string(entity.ExportProcessing), claimedBefore, limit,
+	).Scan(&exportModels).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim account exports: %w", err)
+	}
+
+	exports := make([]*entity.AccountExport, 0, len(exportModels))
+	for i := range exportModels {
+		exports = append(exports, exportModels[i].ToEntity())
+	}
+
+	return exports, nil
+}
+
+func (r *accountExportRepositoryImpl) MarkReady(ctx context.Context, export *entity.AccountExport) error {
+	err := r.db.WithContext(ctx).
+		Model(&model.AccountExportModel{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]interface{}{
+			"status":       string(export.Status),
+			"storage_key":  export.StorageKey,
+			"size_bytes":   export.SizeBytes,
+			"completed_at": export.CompletedAt,
+			"expires_at":   export.ExpiresAt,
+		}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to mark account export as ready: %w", err)
+	}
+
+	return nil
+}
+
+func (r *accountExportRepositoryImpl) MarkFailed(ctx context.Context, id int64, reason string) error {
+	err := r.db.WithContext(ctx).
+		Model(&model.AccountExportModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":         string(entity.ExportFailed),
+			"failure_reason": reason,
+			"completed_at":   time.Now(),
+		}).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to mark account export as failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *accountExportRepositoryImpl) DeleteByUser(ctx context.Context, userID int64) ([]string, error) {
+	var exportModels []model.AccountExportModel
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "storage_key"}}}).
+		Where("user_id = ?", userID).
+		Delete(&exportModels).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete account exports: %w", err)
+	}
+
+	keys := make([]string, 0, len(exportModels))
+	for _, exportModel := range exportModels {
+		if exportModel.StorageKey != nil {
+			keys = append(keys, *exportModel.StorageKey)
+		}
+	}
+
+	return keys, nil
+}
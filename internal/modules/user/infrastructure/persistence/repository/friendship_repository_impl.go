@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/model"
+)
+
+type friendshipRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewFriendshipRepository creates a repository over the friendships table
+func NewFriendshipRepository(db *gorm.DB) repository.FriendshipRepository {
+	return &friendshipRepositoryImpl{db: db}
+}
+
+func (r *friendshipRepositoryImpl) Create(ctx context.Context, friendship *entity.Friendship) error {
+	friendshipModel := model.FromFriendshipEntity(friendship)
+
+	if err := r.db.WithContext(ctx).Create(friendshipModel).Error; err != nil {
+		if conflict := uniqueConflict(err); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to create friend request: %w", err)
+	}
+
+	friendship.ID = friendshipModel.ID
+	return nil
+}
+
+func (r *friendshipRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Friendship, error) {
+	var friendshipModel model.FriendshipModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&friendshipModel).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrFriendRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to find friend request: %w", err)
+	}
+
+	return friendshipModel.ToEntity(), nil
+}
+
+func (r *friendshipRepositoryImpl) FindBetween(ctx context.Context, userID, otherID int64) (*entity.Friendship, error) {
+	var friendshipModel model.FriendshipModel
+
+	err := r.db.WithContext(ctx).
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", userID, otherID, otherID, userID).
+		First(&friendshipModel).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrFriendRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to find friendship: %w", err)
+	}
+
+	return friendshipModel.ToEntity(), nil
+}
+
+func (r *friendshipRepositoryImpl) Accept(ctx context.Context, friendship *entity.Friendship) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.FriendshipModel{}).
+		Where("id = ? AND status = ?", friendship.ID, string(entity.FriendshipPending)).
+		Updates(map[string]interface{}{
+			"status":      string(friendship.Status),
+			"accepted_at": friendship.AcceptedAt,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to accept friend request: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return entity.ErrFriendRequestNotFound
+	}
+
+	return nil
+}
+
+func (r *friendshipRepositoryImpl) Delete(ctx context.Context, id int64, status entity.FriendshipStatus) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND status = ?", id, string(status)).
+		Delete(&model.FriendshipModel{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete friendship: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return entity.ErrFriendRequestNotFound
+	}
+
+	return nil
+}
+
+func (r *friendshipRepositoryImpl) ListPending(ctx context.Context, userID int64, outgoing bool) ([]*entity.Friendship, error) {
+	column := "addressee_id"
+	if outgoing {
+		column = "requester_id"
+	}
+
+	var friendshipModels []model.FriendshipModel
+
+	err := r.db.WithContext(ctx).
+		Where(column+" = ? AND status = ?", userID, string(entity.FriendshipPending)).
+		Order("created_at DESC, id DESC").
+		Find(&friendshipModels).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friend requests: %w", err)
+	}
+
+	friendships := make([]*entity.Friendship, 0, len(friendshipModels))
+	for i := range friendshipModels {
+		friendships = append(friendships, friendshipModels[i].ToEntity())
+	}
+
+	return friendships, nil
+}
+
+func (r *friendshipRepositoryImpl) ListFriendIDs(ctx context.Context, userID int64) ([]int64, error) {
+	var friendIDs []int64
+
+	err := r.db.WithContext(ctx).
+		Raw(friendIDsQuery, userID, userID, userID).
+		Scan(&friendIDs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends: %w", err)
+	}
+
+	return friendIDs, nil
+}
+
+func (r *friendshipRepositoryImpl) CountMutualFriends(ctx context.Context, userID, otherID int64) (int, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Raw(`
+			SELECT COUNT(*) FROM (`+friendIDsQuery+`) mine
+			JOIN (`+friendIDsQuery+`) theirs ON theirs.friend_id = mine.friend_id
+			JOIN users ON users.id = mine.friend_id AND users.is_deleted = FALSE`,
+			userID, userID, userID, otherID, otherID, otherID,
+		).
+		Scan(&count).Error
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count mutual friends: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// friendIDsQuery selects the friends of the user bound three times
+const friendIDsQuery = `
+	SELECT CASE WHEN requester_id = ? THEN addressee_id ELSE requester_id END AS friend_id
+	FROM friendships
+	WHERE status = 'ACCEPTED' AND (requester_id = ? OR addressee_id = ?)`
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/model"
+)
+
+type sessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new session repository implementation
+func NewSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return &sessionRepositoryImpl{db: db}
+}
+
+func (r *sessionRepositoryImpl) Create(ctx context.Context, session *entity.Session) error {
+	if err := r.db.WithContext(ctx).Create(model.FromSessionEntity(session)).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepositoryImpl) ListActive(ctx context.Context, userID int64) ([]*entity.Session, error) {
+	var sessionModels []model.SessionModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_active_at DESC").
+		Find(&sessionModels).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*entity.Session, 0, len(sessionModels))
+	for i := range sessionModels {
+		sessions = append(sessions, sessionModels[i].ToEntity())
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepositoryImpl) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&model.SessionModel{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, now).
+		Update("revoked_at", now)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepositoryImpl) RevokeOthers(ctx context.Context, userID int64, keepID string) ([]string, error) {
+	var sessionModels []model.SessionModel
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).
+		Model(&sessionModels).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, keepID, now).
+		Update("revoked_at", now).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	sessionIDs := make([]string, 0, len(sessionModels))
+	for _, sessionModel := range sessionModels {
+		sessionIDs = append(sessionIDs, sessionModel.ID)
+	}
+
+	return sessionIDs, nil
+}
+
+func (r *sessionRepositoryImpl) IsActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.SessionModel{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *sessionRepositoryImpl) Touch(ctx context.Context, sessionID string, at time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&model.SessionModel{}).
+		Where("id = ? AND last_active_at < ?", sessionID, at).
+		Update("last_active_at", at).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/model"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/crypto"
+)
+
+type twoFactorRepositoryImpl struct {
+	db     *gorm.DB
+	cipher *crypto.Cipher
+}
+
+// NewTwoFactorRepository creates a new two-factor repository implementation.
+// TOTP secrets are encrypted with cipher before they reach the database.
+func NewTwoFactorRepository(db *gorm.DB, cipher *crypto.Cipher) repository.TwoFactorRepository {
+	return &twoFactorRepositoryImpl{db: db, cipher: cipher}
+}
+
+func (r *twoFactorRepositoryImpl) FindByUserID(ctx context.Context, userID int64) (*entity.TwoFactor, error) {
+	var twoFactorModel model.TwoFactorModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&twoFactorModel).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrTwoFactorNotSetUp
+		}
+		return nil, fmt.Errorf("failed to find two-factor enrollment: %w", err)
+	}
+
+	secret, err := r.cipher.Decrypt(twoFactorModel.SecretCiphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt two-factor secret: %w", err)
+	}
+
+	return &entity.TwoFactor{
+		UserID:       twoFactorModel.UserID,
+		Secret:       secret,
+		EnabledAt:    twoFactorModel.EnabledAt,
+		LastUsedStep: twoFactorModel.LastUsedStep,
+		CreatedAt:    twoFactorModel.CreatedAt,
+		UpdatedAt:    twoFactorModel.UpdatedAt,
+	}, nil
+}
+
+func (r *twoFactorRepositoryImpl) Save(ctx context.Context, twoFactor *entity.TwoFactor) error {
+	ciphertext, err := r.cipher.Encrypt(twoFactor.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt two-factor secret: %w", err)
+	}
+
+	twoFactorModel := &model.TwoFactorModel{
+		UserID:           twoFactor.UserID,
+		SecretCiphertext: ciphertext,
+		EnabledAt:        twoFactor.EnabledAt,
+		LastUsedStep:     twoFactor.LastUsedStep,
+		CreatedAt:        twoFactor.CreatedAt,
+		UpdatedAt:        twoFactor.UpdatedAt,
+	}
+
+	// Only a pending enrollment may be replaced; an enabled one has to be disabled first
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret_ciphertext", "last_used_step", "created_at", "updated_at"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_two_factor.enabled_at IS NULL"}}},
+		}).
+		Create(twoFactorModel)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to save two-factor enrollment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrTwoFactorEnabled
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepositoryImpl) Enable(ctx context.Context, userID int64, step int64, recoveryHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.TwoFactorModel{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"enabled_at":     now,
+				"last_used_step": step,
+				"updated_at":     now,
+			})
+
+		if result.Error != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrTwoFactorEnabled
+		}
+
+		return replaceRecoveryCodes(tx, userID, recoveryHashes)
+	})
+}
+
+func (r *twoFactorRepositoryImpl) Delete(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCodeModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		result := tx.Where("user_id = ?", userID).Delete(&model.TwoFactorModel{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete two-factor enrollment: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrTwoFactorNotSetUp
+		}
+
+		return nil
+	})
+}
+
+func (r *twoFactorRepositoryImpl) UseStep(ctx context.Context, userID int64, step int64) error {
+	// The conditional UPDATE stops two requests from both spending the same code
+	result := r.db.WithContext(ctx).
+		Model(&model.TwoFactorModel{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Updates(map[string]interface{}{
+			"last_used_step": step,
+			"updated_at":     time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to record two-factor code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID int64, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCodeModel{}).Error; err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codeModels := make([]model.RecoveryCodeModel, 0, len(hashes))
+	for _, hash := range hashes {
+		codeModels = append(codeModels, model.RecoveryCodeModel{UserID: userID, CodeHash: hash})
+	}
+	if len(codeModels) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&codeModels).Error; err != nil {
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepositoryImpl) ConsumeRecoveryCode(ctx context.Context, userID int64, hash string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.RecoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to consume recovery code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepositoryImpl) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.RecoveryCodeModel{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return int(count), nil
+}
@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
@@ -13,6 +18,9 @@ import (
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/model"
 )
 
+// SQLSTATE of a unique constraint violation
+const uniqueViolation = "23505"
+
 type userRepositoryImpl struct {
 	db *gorm.DB
 }
@@ -27,6 +35,9 @@ func (r *userRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 	userModel := model.FromEntity(user)
 
 	if err := r.db.WithContext(ctx).Create(userModel).Error; err != nil {
+		if conflict := uniqueConflict(err); conflict != nil {
+			return conflict
+		}
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -52,6 +63,33 @@ func (r *userRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Us
 	return userModel.ToEntity()
 }
 
+func (r *userRepositoryImpl) FindByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	if len(ids) == 0 {
+		return []*entity.User{}, nil
+	}
+
+	var userModels []model.UserModel
+
+	err := r.db.WithContext(ctx).
+		Where("id IN ? AND is_deleted = ?", ids, false).
+		Find(&userModels).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users by ID: %w", err)
+	}
+
+	users := make([]*entity.User, 0, len(userModels))
+	for i := range userModels {
+		user, err := userModels[i].ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
 func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email value_object.Email) (*entity.User, error) {
 	var userModel model.UserModel
 
@@ -78,6 +116,9 @@ func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) erro
 		Updates(userModel)
 
 	if result.Error != nil {
+		if conflict := uniqueConflict(result.Error); conflict != nil {
+			return conflict
+		}
 		return fmt.Errorf("failed to update user: %w", result.Error)
 	}
 
@@ -88,6 +129,77 @@ func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) erro
 	return nil
 }
 
+func (r *userRepositoryImpl) UpdateProfile(ctx context.Context, user *entity.User) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("id = ?", user.ID).
+		Updates(model.ProfileColumns(user))
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update profile: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) HasContact(ctx context.Context, ownerID, contactUserID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("contacts").
+		Where("user_id = ? AND contact_user_id = ? AND is_blocked = ?", ownerID, contactUserID, false).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check contact: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *userRepositoryImpl) IsBlockedBetween(ctx context.Context, userID, otherID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("contacts").
+		Where("is_blocked = ?", true).
+		Where("(user_id = ? AND contact_user_id = ?) OR (user_id = ? AND contact_user_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check block: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *userRepositoryImpl) AddContacts(ctx context.Context, ownerID int64, contactUserIDs []int64) (int, error) {
+	if len(contactUserIDs) == 0 {
+		return 0, nil
+	}
+
+	contacts := make([]map[string]interface{}, 0, len(contactUserIDs))
+	for _, contactUserID := range contactUserIDs {
+		contacts = append(contacts, map[string]interface{}{
+			"user_id":         ownerID,
+			"contact_user_id": contactUserID,
+		})
+	}
+
+	result := r.db.WithContext(ctx).
+		Table("contacts").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(contacts)
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to add contacts: %w", result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
 func (r *userRepositoryImpl) Delete(ctx context.Context, id int64) error {
 	result := r.db.WithContext(ctx).Delete(&model.UserModel{}, id)
 
@@ -158,3 +270,336 @@ func (r *userRepositoryImpl) Exists(ctx context.Context, email value_object.Emai
 	return count > 0, nil
 }
 
+func (r *userRepositoryImpl) FindByPhone(ctx context.Context, phone value_object.Phone) (*entity.User, error) {
+	var userModel model.UserModel
+
+	err := r.db.WithContext(ctx).
+		Where("phone = ? AND is_deleted = ?", phone.Value(), false).
+		First(&userModel).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by phone: %w", err)
+	}
+
+	return userModel.ToEntity()
+}
+
+func (r *userRepositoryImpl) PhoneExists(ctx context.Context, phone value_object.Phone) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("phone = ? AND is_deleted = ?", phone.Value(), false).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check if phone exists: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *userRepositoryImpl) UsernameExists(ctx context.Context, username string) (bool, error) {
+	var count int64
+
+	// The unique constraint covers soft deleted accounts too
+	err := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("username = ?", username).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check if username exists: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// uniqueConflict maps a unique violation on the users table to the domain
+// error for the taken value. Checks before writing make these rare; they
+// still happen when two sign ups race.
+func uniqueConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return nil
+	}
+
+	switch pgErr.ConstraintName {
+	case "users_email_key":
+		return entity.ErrEmailAlreadyExists
+	case "users_username_key":
+		return entity.ErrUsernameTaken
+	case "idx_users_phone_unique":
+		return entity.ErrPhoneAlreadyExists
+	case "idx_friendships_pair":
+		return entity.ErrFriendRequestExists
+	}
+	return nil
+}
+
+func (r *userRepositoryImpl) Search(ctx context.Context, viewerID int64, query string, limit int) ([]*entity.User, error) {
+	prefix := escapeLike(query) + "%"
+
+	var userModels []model.UserModel
+	err := r.db.WithContext(ctx).
+		Scopes(r.discoverableBy(viewerID)).
+		Where("(lower(username) LIKE ? OR lower(display_name) LIKE ? OR lower(username) % ? OR lower(display_name) % ?)",
+			prefix, prefix, query, query).
+		Order(clause.Expr{
+			SQL: "(lower(username) LIKE ? OR lower(display_name) LIKE ?) DESC, " +
+				"GREATEST(similarity(lower(username), ?), similarity(coalesce(lower(display_name), ''), ?)) DESC, id",
+			Vars: []interface{}{prefix, prefix, query, query},
+		}).
+		Limit(limit).
+		Find(&userModels).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	users := make([]*entity.User, 0, len(userModels))
+	for i := range userModels {
+		user, err := userModels[i].ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r *userRepositoryImpl) FindDiscoverableByPhone(ctx context.Context, viewerID int64, phone value_object.Phone) (*entity.User, error) {
+	var userModel model.UserModel
+
+	err := r.db.WithContext(ctx).
+		Scopes(r.discoverableBy(viewerID)).
+		Where("phone = ? AND discoverable_by_phone = ?", phone.Value(), true).
+		First(&userModel).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by phone: %w", err)
+	}
+
+	return userModel.ToEntity()
+}
+
+func (r *userRepositoryImpl) FindDiscoverableByPhoneHashes(ctx context.Context, viewerID int64, hashes []string) ([]*entity.ContactMatch, error) {
+	var rows []struct {
+		ID        int64
+		PhoneHash string
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Scopes(r.discoverableBy(viewerID)).
+		Select("id, phone_hash").
+		Where("phone_hash IN ? AND discoverable_by_phone = ?", hashes, true).
+		Find(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to match phone hashes: %w", err)
+	}
+
+	matches := make([]*entity.ContactMatch, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, &entity.ContactMatch{PhoneHash: row.PhoneHash, UserID: row.ID})
+	}
+
+	return matches, nil
+}
+
+func (r *userRepositoryImpl) UpdatePrivacy(ctx context.Context, user *entity.User) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("id = ?", user.ID).
+		UpdateColumns(model.PrivacyColumns(user))
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update privacy settings: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) ListContacts(ctx context.Context, ownerID int64) ([]*entity.Contact, error) {
+	var rows []struct {
+		ContactUserID int64
+		Nickname      *string
+		IsFavorite    *bool
+		IsBlocked     *bool
+		CreatedAt     *time.Time
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("contacts").
+		Select("contact_user_id, nickname, is_favorite, is_blocked, created_at").
+		Where("user_id = ?", ownerID).
+		Order("created_at").
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	contacts := make([]*entity.Contact, 0, len(rows))
+	for _, row := range rows {
+		contact := &entity.Contact{
+			ContactUserID: row.ContactUserID,
+			IsFavorite:    row.IsFavorite != nil && *row.IsFavorite,
+			IsBlocked:     row.IsBlocked != nil && *row.IsBlocked,
+		}
+		if row.Nickname != nil {
+			contact.Nickname = *row.Nickname
+		}
+		if row.CreatedAt != nil {
+			contact.CreatedAt = *row.CreatedAt
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, nil
+}
+
+func (r *userRepositoryImpl) UpdateDeletion(ctx context.Context, user *entity.User) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("id = ? AND purged_at IS NULL", user.ID).
+		UpdateColumn("deletion_scheduled_at", user.DeletionScheduledAt)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update account deletion: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) ClaimDueDeletions(ctx context.Context, now, claimedBefore time.Time, limit int) ([]int64, error) {
+	var ids []int64
+
+	// SKIP LOCKED lets replicas claim disjoint batches without waiting on each other
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE users
+		SET purge_claimed_at = ?
+		WHERE id IN (
+			SELECT id FROM users
+			WHERE deletion_scheduled_at <= ? AND purged_at IS NULL
+				AND (purge_claimed_at IS NULL OR purge_claimed_at < ?)
+			ORDER BY deletion_scheduled_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		now, now, claimedBefore, limit,
+	).Scan(&ids).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due account deletions: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *userRepositoryImpl) Purge(ctx context.Context, id int64, username string) (string, error) {
+	var avatarKey string
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userModel model.UserModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND purged_at IS NULL", id).
+			First(&userModel).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to find user to purge: %w", err)
+		}
+		if userModel.AvatarKey != nil {
+			avatarKey = *userModel.AvatarKey
+		}
+
+		now := time.Now()
+		err = tx.Model(&model.UserModel{}).
+			Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"email":                 nil,
+				"email_verified_at":     nil,
+				"phone":                 nil,
+				"phone_hash":            nil,
+				"phone_verified_at":     nil,
+				"password_hash":         "",
+				"username":              username,
+				"display_name":          nil,
+				"bio":                   nil,
+				"avatar_key":            nil,
+				"avatar_url":            nil,
+				"status":                "DISABLED",
+				"role":                  string(entity.RoleUser),
+				"is_vip":                false,
+				"last_login_at":         nil,
+				"last_seen":             nil,
+				"discoverable_by_phone": false,
+				"is_deleted":            true,
+				"purge_claimed_at":      nil,
+				"purged_at":             now,
+				"updated_at":            now,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+
+		// Rows that only describe the account and its relationships go entirely
+		statements := []string{
+			"DELETE FROM contacts WHERE user_id = @id OR contact_user_id = @id",
+			"DELETE FROM friendships WHERE requester_id = @id OR addressee_id = @id",
+			"DELETE FROM user_sessions WHERE user_id = @id",
+			"DELETE FROM user_tokens WHERE user_id = @id",
+			"DELETE FROM user_recovery_codes WHERE user_id = @id",
+			"DELETE FROM user_two_factor WHERE user_id = @id",
+			"DELETE FROM device_tokens WHERE user_id = @id",
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement, sql.Named("id", id)).Error; err != nil {
+				return fmt.Errorf("failed to delete data of purged user: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return avatarKey, err
+}
+
+// discoverableBy limits a query to active accounts other than viewerID,
+// leaving out anyone on either side of a block with viewerID
+func (r *userRepositoryImpl) discoverableBy(viewerID int64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("is_deleted = ? AND status = ? AND id <> ?", false, "ACTIVE", viewerID).
+			Where("id NOT IN (?)", r.db.Table("contacts").
+				Select("contact_user_id").
+				Where("user_id = ? AND is_blocked = ?", viewerID, true)).
+			Where("id NOT IN (?)", r.db.Table("contacts").
+				Select("user_id").
+				Where("contact_user_id = ? AND is_blocked = ?", viewerID, true))
+	}
+}
+
+// escapeLike makes s match literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq" // PostgreSQL driver for Goose
+	"github.com/pressly/goose/v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+)
+
+// Relative to this package, where go test runs
+const migrationsDir = "../../../../../../migrations/postgres"
+
+// openTestDB migrates the database at TEST_DATABASE_URL and returns it. The
+// database must be a throwaway one: every migration is rolled back first and
+// again when the test ends.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		t.Fatal(err)
+	}
+	goose.SetLogger(goose.NopLogger())
+
+	// Up, down and up again proves every migration can be rolled back
+	if err := goose.Reset(sqlDB, migrationsDir); err != nil {
+		t.Fatalf("goose reset: %v", err)
+	}
+	if err := goose.Up(sqlDB, migrationsDir); err != nil {
+		t.Fatalf("goose up: %v", err)
+	}
+	if err := goose.Reset(sqlDB, migrationsDir); err != nil {
+		t.Fatalf("goose down: %v", err)
+	}
+	if err := goose.Up(sqlDB, migrationsDir); err != nil {
+		t.Fatalf("goose up after down: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := goose.Reset(sqlDB, migrationsDir); err != nil {
+			t.Errorf("goose reset: %v", err)
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gormSQL, err := db.DB(); err == nil {
+		t.Cleanup(func() { gormSQL.Close() })
+	}
+	return db
+}
+
+func newTestUser(t *testing.T, email, username, phone string) *entity.User {
+	t.Helper()
+
+	address, err := value_object.NewEmail(email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := value_object.NewPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := entity.NewUser(address, password, username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if phone != "" {
+		if user.Phone, err = value_object.NewPhone(phone); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return user
+}
+
+func TestUserRepositoryPostgres(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("round trip", func(t *testing.T) {
+		user := newTestUser(t, "an@example.com", "an", "0912345678")
+		user.DisplayName = "An Nguyễn"
+		user.Bio = "hello"
+		user.Role = entity.RoleModerator
+		user.Language = "vi"
+		user.IsVIP = true
+		user.VerifyEmail()
+
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if user.ID == 0 {
+			t.Fatal("Create did not set the ID")
+		}
+
+		got, err := repo.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case !got.Email.Equals(user.Email):
+			t.Fatalf("email %s, want %s", got.Email.Value(), user.Email.Value())
+		case !got.Phone.Equals(user.Phone):
+			t.Fatalf("phone %s, want %s", got.Phone, user.Phone)
+		case got.Password.Compare("password123") != nil:
+			t.Fatal("password hash did not survive")
+		case got.Username != user.Username || got.DisplayName != user.DisplayName || got.Bio != user.Bio:
+			t.Fatalf("profile %q %q %q", got.Username, got.DisplayName, got.Bio)
+		case got.Role != user.Role || got.Status != user.Status || got.Language != user.Language || got.IsVIP != user.IsVIP:
+			t.Fatalf("got role %s status %d language %s vip %v", got.Role, got.Status, got.Language, got.IsVIP)
+		case got.EmailVerifiedAt == nil || got.EmailVerifiedAt.Sub(*user.EmailVerifiedAt).Abs() > time.Millisecond:
+			t.Fatalf("email verified at %v, want %v", got.EmailVerifiedAt, user.EmailVerifiedAt)
+		}
+
+		if err := got.Deactivate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatal(err)
+		}
+		updated, err := repo.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Status != entity.UserStatusDisabled {
+			t.Fatalf("status %d after update, want disabled", updated.Status)
+		}
+	})
+
+	t.Run("unique conflicts", func(t *testing.T) {
+		taken := newTestUser(t, "taken@example.com", "taken", "0987654321")
+		if err := repo.Create(ctx, taken); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name     string
+			email    string
+			username string
+			phone    string
+			want     error
+		}{
+			{name: "users_email_key", email: "taken@example.com", username: "other1", want: entity.ErrEmailAlreadyExists},
+			{name: "users_username_key", email: "other2@example.com", username: "taken", want: entity.ErrUsernameTaken},
+			{name: "idx_users_phone_unique", email: "other3@example.com", username: "other3", phone: "+84 987 654 321", want: entity.ErrPhoneAlreadyExists},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name+" on create", func(t *testing.T) {
+				err := repo.Create(ctx, newTestUser(t, tt.email, tt.username, tt.phone))
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("Create = %v, want %v", err, tt.want)
+				}
+			})
+
+			t.Run(tt.name+" on update", func(t *testing.T) {
+				user := newTestUser(t, "update-"+tt.name+"@example.com", "update-"+tt.name, "")
+				if err := repo.Create(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+
+				conflicting := newTestUser(t, tt.email, tt.username, tt.phone)
+				if tt.email == taken.Email.Value() {
+					user.Email = conflicting.Email
+				}
+				if tt.username == taken.Username {
+					user.Username = conflicting.Username
+				}
+				if !conflicting.Phone.IsZero() {
+					user.Phone = conflicting.Phone
+				}
+
+				if err := repo.Update(ctx, user); !errors.Is(err, tt.want) {
+					t.Fatalf("Update = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+}
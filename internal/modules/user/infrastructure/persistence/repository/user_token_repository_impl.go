@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/model"
+)
+
+type userTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository creates a new user token repository implementation
+func NewUserTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	return &userTokenRepositoryImpl{db: db}
+}
+
+func (r *userTokenRepositoryImpl) Create(ctx context.Context, token *entity.UserToken) error {
+	tokenModel := model.FromUserTokenEntity(token)
+
+	if err := r.db.WithContext(ctx).Create(tokenModel).Error; err != nil {
+		return fmt.Errorf("failed to create user token: %w", err)
+	}
+
+	token.ID = tokenModel.ID
+	return nil
+}
+
+func (r *userTokenRepositoryImpl) Consume(ctx context.Context, purpose entity.TokenPurpose, hash string) (*entity.UserToken, error) {
+	var tokenModels []model.UserTokenModel
+	now := time.Now()
+
+	// A single conditional UPDATE makes the token single-use without a transaction
+	err := r.db.WithContext(ctx).
+		Model(&tokenModels).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, string(purpose), now).
+		Update("used_at", now).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume user token: %w", err)
+	}
+	if len(tokenModels) == 0 {
+		return nil, entity.ErrInvalidToken
+	}
+
+	return tokenModels[0].ToEntity(), nil
+}
+
+func (r *userTokenRepositoryImpl) Revoke(ctx context.Context, userID int64, purpose entity.TokenPurpose) error {
+	err := r.db.WithContext(ctx).
+		Model(&model.UserTokenModel{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, string(purpose)).
+		Update("used_at", time.Now()).Error
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// RequestDeletion godoc
+// @Summary Delete my account
+// @Description Schedule the caller's account to be deleted after a grace period. Until then the account works as usual and the deletion can be canceled. Accounts created by phone leave the password empty.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RequestAccountDeletionRequest true "Current password"
+// @Success 202 {object} response.Response{data=dto.AccountDeletionResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /me/deletion [post]
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.RequestAccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(c.Request.Context(), userID, req)
+	if err != nil {
+		respondAccountError(c, "Failed to request account deletion", err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Account deletion scheduled", deletion)
+}
+
+// GetDeletion godoc
+// @Summary Get my account deletion
+// @Description Tell whether the caller's account is scheduled for deletion, and when
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.AccountDeletionResponse}
+// @Failure 401 {object} response.Response
+// @Router /me/deletion [get]
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	deletion, err := h.accountService.GetDeletion(c.Request.Context(), userID)
+	if err != nil {
+		respondAccountError(c, "Failed to get account deletion", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account deletion retrieved successfully", deletion)
+}
+
+// CancelDeletion godoc
+// @Summary Keep my account
+// @Description Cancel a deletion that is still in its grace period
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.AccountDeletionResponse}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /me/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	deletion, err := h.accountService.CancelDeletion(c.Request.Context(), userID)
+	if err != nil {
+		respondAccountError(c, "Failed to cancel account deletion", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account deletion canceled", deletion)
+}
+
+// RequestExport godoc
+// @Summary Export my data
+// @Description Start building a zip archive of the caller's profile, contacts, conversations, messages and attachments. Poll GET /me/export until it is READY.
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} response.Response{data=dto.AccountExportResponse}
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /me/export [post]
+func (h *AccountHandler) RequestExport(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	export, err := h.accountService.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		respondAccountError(c, "Failed to request data export", err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Data export requested", export)
+}
+
+// GetExport godoc
+// @Summary Get my data export
+// @Description Get the status of the caller's latest data export
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.AccountExportResponse}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /me/export [get]
+func (h *AccountHandler) GetExport(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	export, err := h.accountService.GetExport(c.Request.Context(), userID)
+	if err != nil {
+		respondAccountError(c, "Failed to get data export", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Data export retrieved successfully", export)
+}
+
+// DownloadExport godoc
+// @Summary Download my data export
+// @Description Download the caller's latest data export while it is READY and has not expired
+// @Tags account
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file "Zip archive"
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /me/export/download [get]
+func (h *AccountHandler) DownloadExport(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	archive, export, err := h.accountService.OpenExport(c.Request.Context(), userID)
+	if err != nil {
+		respondAccountError(c, "Failed to download data export", err)
+		return
+	}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			log.Printf("Failed to close data export %d: %v", export.ID, err)
+		}
+	}()
+
+	c.DataFromReader(http.StatusOK, export.SizeBytes, "application/zip", archive, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="vnalo-export-%d.zip"`, export.ID),
+	})
+}
+
+// respondAccountError maps account deletion and export errors to HTTP status codes
+func respondAccountError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, value_object.ErrPasswordMismatch):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrUserNotFound),
+		errors.Is(err, entity.ErrDeletionNotScheduled),
+		errors.Is(err, entity.ErrExportNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.Is(err, entity.ErrDeletionAlreadyScheduled),
+		errors.Is(err, entity.ErrDeletionInProgress),
+		errors.Is(err, entity.ErrExportInProgress):
+		response.Error(c, http.StatusConflict, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
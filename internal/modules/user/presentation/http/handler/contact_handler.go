@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+type ContactHandler struct {
+	contactService service.ContactService
+}
+
+func NewContactHandler(contactService service.ContactService) *ContactHandler {
+	return &ContactHandler{
+		contactService: contactService,
+	}
+}
+
+// SyncContacts godoc
+// @Summary Sync my address book
+// @Description Upload a batch of address book numbers, each as the lowercase hex SHA-256 of its E.164 form (+84912345678), and get back the users they belong to. Only users who allow being found by phone are matched. Unmatched hashes are not stored. Batches and daily totals are limited per user.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param request body dto.SyncContactsRequest true "Hashed phone numbers"
+// @Success 200 {object} response.Response{data=dto.SyncContactsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Security BearerAuth
+// @Router /contacts/sync [post]
+func (h *ContactHandler) SyncContacts(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.SyncContactsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if len(req.Hashes) == 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", errors.New("hashes is required"))
+		return
+	}
+
+	result, err := h.contactService.SyncContacts(c.Request.Context(), userID, req)
+	if err != nil {
+		respondContactError(c, "Failed to sync contacts", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Contacts synced successfully", result)
+}
+
+// respondContactError maps contact sync errors to HTTP status codes
+func respondContactError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrTooManyRequests):
+		response.Error(c, http.StatusTooManyRequests, message, err)
+	case errors.Is(err, entity.ErrInvalidPhoneHash),
+		errors.Is(err, entity.ErrContactSyncBatchTooLarge):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+type DiscoveryHandler struct {
+	discoveryService service.DiscoveryService
+}
+
+func NewDiscoveryHandler(discoveryService service.DiscoveryService) *DiscoveryHandler {
+	return &DiscoveryHandler{
+		discoveryService: discoveryService,
+	}
+}
+
+// Search godoc
+// @Summary Search users
+// @Description Find users by username or display name, matching prefixes and near spellings. A query that is a phone number matches only the account with that exact number, if its owner allows it. Deleted, disabled and blocked accounts are never returned.
+// @Tags users
+// @Produce json
+// @Param q query string true "Name, username or phone number (at least 2 characters)"
+// @Param limit query int false "Maximum results (default 20, max 50)"
+// @Success 200 {object} response.Response{data=dto.UserSearchResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Security BearerAuth
+// @Router /users/search [get]
+func (h *DiscoveryHandler) Search(c *gin.Context) {
+	viewerID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid limit", err)
+			return
+		}
+	}
+
+	result, err := h.discoveryService.SearchUsers(c.Request.Context(), viewerID, c.Query("q"), limit)
+	if err != nil {
+		respondDiscoveryError(c, "Failed to search users", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Users retrieved successfully", result)
+}
+
+// GetPrivacy godoc
+// @Summary Get my privacy settings
+// @Description Get who can find the caller through search and who can start direct conversations with them
+// @Tags me
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.PrivacySettingsResponse}
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me/privacy [get]
+func (h *DiscoveryHandler) GetPrivacy(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	settings, err := h.discoveryService.GetPrivacy(c.Request.Context(), userID)
+	if err != nil {
+		respondDiscoveryError(c, "Failed to get privacy settings", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Privacy settings retrieved successfully", settings)
+}
+
+// UpdatePrivacy godoc
+// @Summary Update my privacy settings
+// @Description Choose whether others can find the caller by searching for their exact phone number, and whether only friends can start direct conversations with them. Omitted settings are kept.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdatePrivacySettingsRequest true "Privacy settings"
+// @Success 200 {object} response.Response{data=dto.PrivacySettingsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me/privacy [put]
+func (h *DiscoveryHandler) UpdatePrivacy(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.UpdatePrivacySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	settings, err := h.discoveryService.UpdatePrivacy(c.Request.Context(), userID, req)
+	if err != nil {
+		respondDiscoveryError(c, "Failed to update privacy settings", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Privacy settings updated successfully", settings)
+}
+
+// respondDiscoveryError maps user search errors to HTTP status codes
+func respondDiscoveryError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, entity.ErrUserNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.Is(err, entity.ErrTooManyRequests):
+		response.Error(c, http.StatusTooManyRequests, message, err)
+	case errors.Is(err, entity.ErrSearchQueryTooShort):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+type FriendHandler struct {
+	friendService service.FriendService
+}
+
+func NewFriendHandler(friendService service.FriendService) *FriendHandler {
+	return &FriendHandler{
+		friendService: friendService,
+	}
+}
+
+// SendRequest godoc
+// @Summary Send a friend request
+// @Description Ask a user to become friends. The user is notified over the WebSocket with a friend_request.received event. If they already asked the caller, their request is accepted instead.
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Param request body dto.SendFriendRequestRequest true "User to befriend"
+// @Success 201 {object} response.Response{data=dto.FriendRequestResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /friends/requests [post]
+func (h *FriendHandler) SendRequest(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.SendFriendRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.UserID <= 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", errors.New("user_id is required"))
+		return
+	}
+
+	request, err := h.friendService.SendRequest(c.Request.Context(), userID, req)
+	if err != nil {
+		respondFriendError(c, "Failed to send friend request", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Friend request sent successfully", request)
+}
+
+// ListRequests godoc
+// @Summary List my friend requests
+// @Description List pending friend requests sent to the caller, or sent by the caller with direction=outgoing
+// @Tags friends
+// @Produce json
+// @Param direction query string false "incoming (default) or outgoing"
+// @Success 200 {object} response.Response{data=dto.FriendRequestListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /friends/requests [get]
+func (h *FriendHandler) ListRequests(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var outgoing bool
+	switch c.DefaultQuery("direction", "incoming") {
+	case "incoming":
+	case "outgoing":
+		outgoing = true
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid direction", errors.New("direction must be incoming or outgoing"))
+		return
+	}
+
+	requests, err := h.friendService.ListRequests(c.Request.Context(), userID, outgoing)
+	if err != nil {
+		respondFriendError(c, "Failed to list friend requests", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Friend requests retrieved successfully", requests)
+}
+
+// AcceptRequest godoc
+// @Summary Accept a friend request
+// @Description Accept a request sent to the caller. Its sender is notified with a friend_request.accepted event.
+// @Tags friends
+// @Produce json
+// @Param id path int true "Friend request ID"
+// @Success 200 {object} response.Response{data=dto.FriendRequestResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /friends/requests/{id}/accept [post]
+func (h *FriendHandler) AcceptRequest(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	requestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid friend request ID", err)
+		return
+	}
+
+	request, err := h.friendService.AcceptRequest(c.Request.Context(), userID, requestID)
+	if err != nil {
+		respondFriendError(c, "Failed to accept friend request", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Friend request accepted successfully", request)
+}
+
+// DeclineRequest godoc
+// @Summary Decline a friend request
+// @Description Decline a request sent to the caller. Its sender is not told.
+// @Tags friends
+// @Produce json
+// @Param id path int true "Friend request ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /friends/requests/{id}/decline [post]
+func (h *FriendHandler) DeclineRequest(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	requestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid friend request ID", err)
+		return
+	}
+
+	if err := h.friendService.DeclineRequest(c.Request.Context(), userID, requestID); err != nil {
+		respondFriendError(c, "Failed to decline friend request", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Friend request declined successfully", nil)
+}
+
+// CancelRequest godoc
+// @Summary Cancel a friend request
+// @Description Withdraw a request the caller sent. Its addressee is notified with a friend_request.canceled event.
+// @Tags friends
+// @Produce json
+// @Param id path int true "Friend request ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /friends/requests/{id} [delete]
+func (h *FriendHandler) CancelRequest(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	requestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid friend request ID", err)
+		return
+	}
+
+	if err := h.friendService.CancelRequest(c.Request.Context(), userID, requestID); err != nil {
+		respondFriendError(c, "Failed to cancel friend request", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Friend request canceled successfully", nil)
+}
+
+// ListFriends godoc
+// @Summary List my friends
+// @Tags friends
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.FriendListResponse}
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /friends [get]
+func (h *FriendHandler) ListFriends(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	friends, err := h.friendService.ListFriends(c.Request.Context(), userID)
+	if err != nil {
+		respondFriendError(c, "Failed to list friends", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Friends retrieved successfully", friends)
+}
+
+// RemoveFriend godoc
+// @Summary Remove a friend
+// @Tags friends
+// @Produce json
+// @Param user_id path int true "Friend's user ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /friends/{user_id} [delete]
+func (h *FriendHandler) RemoveFriend(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	friendID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	if err := h.friendService.RemoveFriend(c.Request.Context(), userID, friendID); err != nil {
+		respondFriendError(c, "Failed to remove friend", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Friend removed successfully", nil)
+}
+
+// respondFriendError maps friend request errors to HTTP status codes
+func respondFriendError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrUserNotFound),
+		errors.Is(err, entity.ErrFriendRequestNotFound),
+		errors.Is(err, entity.ErrNotFriends):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.Is(err, entity.ErrFriendRequestExists),
+		errors.Is(err, entity.ErrAlreadyFriends):
+		response.Error(c, http.StatusConflict, message, err)
+	case errors.Is(err, entity.ErrCannotFriendSelf):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
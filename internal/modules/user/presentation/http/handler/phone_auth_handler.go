@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+type PhoneAuthHandler struct {
+	phoneAuthService service.PhoneAuthService
+}
+
+func NewPhoneAuthHandler(phoneAuthService service.PhoneAuthService) *PhoneAuthHandler {
+	return &PhoneAuthHandler{
+		phoneAuthService: phoneAuthService,
+	}
+}
+
+// RequestOTP godoc
+// @Summary Send a one-time code by SMS
+// @Description Send a code to sign up or log in with a phone number. Numbers may be written in E.164 (+84912345678) or the Vietnamese national format (0912345678).
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RequestOTPRequest true "Phone number and purpose"
+// @Success 202 {object} response.Response{data=dto.OTPResponse}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/phone/otp [post]
+func (h *PhoneAuthHandler) RequestOTP(c *gin.Context) {
+	var req dto.RequestOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.phoneAuthService.RequestOTP(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		respondPhoneAuthError(c, "Failed to send code", err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Code sent", result)
+}
+
+// Register godoc
+// @Summary Sign up with a phone number
+// @Description Create an account for a phone number proven by a code from /auth/phone/otp, and log in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PhoneRegisterRequest true "Phone number, code and username"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-Platform header string false "Client platform, e.g. ios, android or web"
+// @Success 201 {object} response.Response{data=dto.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/phone/register [post]
+func (h *PhoneAuthHandler) Register(c *gin.Context) {
+	var req dto.PhoneRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.phoneAuthService.RegisterWithPhone(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		respondPhoneAuthError(c, "Sign up failed", err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Signed up successfully", result)
+}
+
+// Login godoc
+// @Summary Log in with a phone number
+// @Description Exchange a code from /auth/phone/otp for an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PhoneLoginRequest true "Phone number and code"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-Platform header string false "Client platform, e.g. ios, android or web"
+// @Success 200 {object} response.Response{data=dto.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/phone/login [post]
+func (h *PhoneAuthHandler) Login(c *gin.Context) {
+	var req dto.PhoneLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.phoneAuthService.LoginWithPhone(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		respondPhoneAuthError(c, "Login failed", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Logged in successfully", result)
+}
+
+// respondPhoneAuthError maps errors of the phone flows to HTTP status codes
+func respondPhoneAuthError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrTooManyRequests),
+		errors.Is(err, entity.ErrOTPCooldown),
+		errors.Is(err, entity.ErrOTPAttemptsExceeded):
+		response.Error(c, http.StatusTooManyRequests, message, err)
+	case errors.Is(err, entity.ErrPhoneAlreadyExists),
+		errors.Is(err, entity.ErrUsernameTaken),
+		errors.Is(err, entity.ErrEmailAlreadyExists):
+		response.Error(c, http.StatusConflict, message, err)
+	case errors.Is(err, entity.ErrInvalidOTP),
+		errors.Is(err, entity.ErrUserInactive):
+		response.Error(c, http.StatusUnauthorized, message, err)
+	case errors.Is(err, value_object.ErrInvalidPhone),
+		errors.Is(err, entity.ErrInvalidUsername):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
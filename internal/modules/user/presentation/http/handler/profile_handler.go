@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/imaging"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+// Room for the multipart headers and crop fields around the image itself
+const multipartOverhead = 64 << 10
+
+type ProfileHandler struct {
+	profileService service.ProfileService
+	maxAvatarBytes int64
+}
+
+func NewProfileHandler(profileService service.ProfileService, maxAvatarBytes int64) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		maxAvatarBytes: maxAvatarBytes,
+	}
+}
+
+// UpdateProfile godoc
+// @Summary Update my profile
+// @Description Change the display name or bio. Omitted fields are kept; empty strings clear them.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateProfileRequest true "Profile fields"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me/profile [patch]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(c.Request.Context(), userID, req)
+	if err != nil {
+		respondProfileError(c, "Failed to update profile", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Profile updated successfully", user)
+}
+
+// UploadAvatar godoc
+// @Summary Upload my avatar
+// @Description Upload a JPEG, PNG or GIF image. It is cropped to a square (the largest centred one unless crop_size is given) and stored at 64, 256 and 512 px.
+// @Tags me
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Image"
+// @Param crop_x formData int false "Left edge of the square, in pixels"
+// @Param crop_y formData int false "Top edge of the square, in pixels"
+// @Param crop_size formData int false "Side of the square, in pixels"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Security BearerAuth
+// @Router /me/avatar [put]
+func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxAvatarBytes+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "Avatar is too large", err)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Missing avatar file", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.maxAvatarBytes {
+		response.Error(c, http.StatusRequestEntityTooLarge, "Avatar is too large", errors.New("file exceeds the upload limit"))
+		return
+	}
+
+	var crop dto.AvatarCrop
+	for _, field := range []struct {
+		name string
+		dest *int
+	}{{"crop_x", &crop.X}, {"crop_y", &crop.Y}, {"crop_size", &crop.Size}} {
+		raw := c.Request.FormValue(field.name)
+		if raw == "" {
+			continue
+		}
+		if *field.dest, err = strconv.Atoi(raw); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid "+field.name, err)
+			return
+		}
+	}
+
+	user, err := h.profileService.UploadAvatar(c.Request.Context(), userID, file, crop)
+	if err != nil {
+		respondProfileError(c, "Failed to upload avatar", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Avatar updated successfully", user)
+}
+
+// RemoveAvatar godoc
+// @Summary Remove my avatar
+// @Description Remove the caller's avatar
+// @Tags me
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me/avatar [delete]
+func (h *ProfileHandler) RemoveAvatar(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	user, err := h.profileService.RemoveAvatar(c.Request.Context(), userID)
+	if err != nil {
+		respondProfileError(c, "Failed to remove avatar", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Avatar removed successfully", user)
+}
+
+// GetProfile godoc
+// @Summary Get a user's profile
+// @Description Get the public profile of a user. Email and phone are included only if the user saved the caller as a contact.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=dto.PublicProfileResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /users/{id}/profile [get]
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	viewerID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	profile, err := h.profileService.GetPublicProfile(c.Request.Context(), viewerID, id)
+	if err != nil {
+		respondProfileError(c, "Failed to get profile", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Profile retrieved successfully", profile)
+}
+
+// respondProfileError maps profile errors to HTTP status codes
+func respondProfileError(c *gin.Context, message string, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrUserNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.As(err, &tooLarge):
+		response.Error(c, http.StatusRequestEntityTooLarge, message, err)
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		response.Error(c, http.StatusUnsupportedMediaType, message, err)
+	case errors.Is(err, imaging.ErrTooLarge),
+		errors.Is(err, imaging.ErrInvalidCrop),
+		errors.Is(err, entity.ErrInvalidDisplayName),
+		errors.Is(err, entity.ErrBioTooLong):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
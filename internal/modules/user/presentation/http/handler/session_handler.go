@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+)
+
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// clientInfo describes the device a login request comes from
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		DeviceName: c.GetHeader("X-Device-Name"),
+		Platform:   c.GetHeader("X-Platform"),
+	}
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List the devices the caller is logged in on, most recently active first
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]dto.SessionResponse}
+// @Failure 401 {object} response.Response
+// @Router /me/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(c)
+
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list sessions", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary Sign out a session
+// @Description Sign out one device. Its access token stops working and its WebSocket connections are closed.
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), userID, c.Param("id")); err != nil {
+		respondSessionError(c, "Failed to sign out session", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Session signed out", nil)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Log out all other devices
+// @Description Sign out every session except the one making the request
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.RevokeSessionsResponse}
+// @Failure 401 {object} response.Response
+// @Router /me/sessions/revoke-others [post]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(c)
+
+	result, err := h.sessionService.RevokeOtherSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		respondSessionError(c, "Failed to sign out other sessions", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Other sessions signed out", result)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Sign out the session of the access token making the request
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/logout [post]
+func (h *SessionHandler) Logout(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(c)
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		respondSessionError(c, "Logout failed", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// respondSessionError maps session errors to HTTP status codes
+func respondSessionError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, entity.ErrSessionNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
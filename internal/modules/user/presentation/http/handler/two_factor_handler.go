@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Verify godoc
+// @Summary Finish a login with a second factor
+// @Description Exchange the challenge token from a login response and an authenticator or recovery code for an access token. A login that demanded enrollment is finished with the first code from the new authenticator, and the response then carries the recovery codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "Challenge token and code"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-Platform header string false "Client platform, e.g. ios, android or web"
+// @Success 200 {object} response.Response{data=dto.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.twoFactorService.VerifyLogin(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		respondTwoFactorError(c, "Login failed", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Logged in successfully", result)
+}
+
+// ChallengeSetup godoc
+// @Summary Enroll in 2FA during login
+// @Description Start the enrollment a login demanded with two_factor_setup_required. Finish it at /auth/2fa/verify.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorChallengeRequest true "Challenge token"
+// @Success 200 {object} response.Response{data=dto.TwoFactorSetupResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/2fa/challenge/setup [post]
+func (h *TwoFactorHandler) ChallengeSetup(c *gin.Context) {
+	var req dto.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.twoFactorService.SetupWithChallenge(c.Request.Context(), req)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to set up two-factor authentication", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scan the code with an authenticator app", result)
+}
+
+// GetStatus godoc
+// @Summary Get 2FA status
+// @Description Get whether two-factor authentication is enabled or required for the caller
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.TwoFactorStatusResponse}
+// @Failure 401 {object} response.Response
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	result, err := h.twoFactorService.GetStatus(c.Request.Context(), userID)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to get two-factor status", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor status retrieved successfully", result)
+}
+
+// Setup godoc
+// @Summary Start 2FA enrollment
+// @Description Generate a TOTP secret. Clients show provisioning_uri as a QR code; 2FA is only on after /auth/2fa/enable.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.TwoFactorSetupResponse}
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	result, err := h.twoFactorService.Setup(c.Request.Context(), userID)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to set up two-factor authentication", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scan the code with an authenticator app", result)
+}
+
+// Enable godoc
+// @Summary Enable 2FA
+// @Description Confirm the enrollment with a code from the authenticator app. The recovery codes in the response are never shown again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} response.Response{data=dto.RecoveryCodesResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.twoFactorService.Enable(c.Request.Context(), userID, req)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor authentication enabled", result)
+}
+
+// Disable godoc
+// @Summary Disable 2FA
+// @Description Turn two-factor authentication off. Requires the password, unless the account has none, and an authenticator or recovery code.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, req); err != nil {
+		respondTwoFactorError(c, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes. Requires an authenticator code; recovery codes are not accepted.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} response.Response{data=dto.RecoveryCodesResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userID, req)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Recovery codes regenerated", result)
+}
+
+// respondTwoFactorError maps two-factor errors to HTTP status codes
+func respondTwoFactorError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrTooManyRequests):
+		response.Error(c, http.StatusTooManyRequests, message, err)
+	case errors.Is(err, entity.ErrInvalidToken),
+		errors.Is(err, entity.ErrInvalidCredentials),
+		errors.Is(err, entity.ErrUserInactive):
+		response.Error(c, http.StatusUnauthorized, message, err)
+	case errors.Is(err, entity.ErrTwoFactorRequired):
+		response.Error(c, http.StatusForbidden, message, err)
+	case errors.Is(err, entity.ErrTwoFactorEnabled):
+		response.Error(c, http.StatusConflict, message, err)
+	case errors.Is(err, entity.ErrInvalidTwoFactorCode),
+		errors.Is(err, entity.ErrTwoFactorNotSetUp),
+		errors.Is(err, entity.ErrTwoFactorNotEnabled):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/dto"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/value_object"
 	"github.com/ndxbinh1922001/VNalo-be/pkg/response"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/validator"
 )
 
 type UserHandler struct {
@@ -30,6 +35,7 @@ func NewUserHandler(userService service.UserService) *UserHandler {
 // @Param request body dto.CreateUserRequest true "Create user request"
 // @Success 201 {object} response.Response{data=dto.UserResponse}
 // @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -42,24 +48,199 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 
 	user, err := h.userService.CreateUser(c.Request.Context(), req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to create user", err)
+		respondUserError(c, "Failed to create user", err)
 		return
 	}
 
 	response.Success(c, http.StatusCreated, "User created successfully", user)
 }
 
+// Login godoc
+// @Summary Log in
+// @Description Exchange email and password for an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginRequest true "Login request"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-Platform header string false "Client platform, e.g. ios, android or web"
+// @Success 200 {object} response.Response{data=dto.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /auth/login [post]
+func (h *UserHandler) Login(c *gin.Context) {
+	var req dto.LoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.userService.Login(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCredentials) || errors.Is(err, entity.ErrUserInactive) {
+			response.Error(c, http.StatusUnauthorized, "Login failed", err)
+			return
+		}
+		if errors.Is(err, entity.ErrEmailNotVerified) {
+			response.Error(c, http.StatusForbidden, "Login failed", err)
+			return
+		}
+		if validator.IsValidationError(err) {
+			response.Error(c, http.StatusBadRequest, "Login failed", err)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Login failed", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Logged in successfully", result)
+}
+
+// GetMe godoc
+// @Summary Get my account
+// @Description Get the account of the caller
+// @Tags me
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		respondUserError(c, "Failed to get account", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account retrieved successfully", user)
+}
+
+// UpdateMe godoc
+// @Summary Update my account
+// @Description Update the username or language of the caller
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateUserRequest true "Update user request"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me [put]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	var req dto.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	user, err := h.userService.UpdateUser(c.Request.Context(), userID, req)
+	if err != nil {
+		respondUserError(c, "Failed to update account", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account updated successfully", user)
+}
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Change the password of the caller and sign out their other sessions
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordRequest true "Change password request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /me/change-password [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", ErrUnauthenticated)
+		return
+	}
+
+	sessionID, _ := middleware.GetSessionID(c)
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), userID, sessionID, req); err != nil {
+		respondUserError(c, "Failed to change password", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password changed successfully", nil)
+}
+
+// AssignRole godoc
+// @Summary Assign a system role
+// @Description Make an account a user, moderator or admin. Admins cannot change their own role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	user, err := h.userService.AssignRole(c.Request.Context(), id, req)
+	if err != nil {
+		respondUserError(c, "Failed to assign role", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Role assigned successfully", user)
+}
+
 // GetUser godoc
 // @Summary Get user by ID
 // @Description Get user details by ID
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response{data=dto.UserResponse}
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id} [get]
+// @Security BearerAuth
+// @Router /admin/users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -70,7 +251,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
-		response.Error(c, http.StatusNotFound, "User not found", err)
+		respondUserError(c, "User not found", err)
 		return
 	}
 
@@ -80,15 +261,17 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // UpdateUser godoc
 // @Summary Update user
 // @Description Update user information
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Param request body dto.UpdateUserRequest true "Update user request"
 // @Success 200 {object} response.Response{data=dto.UserResponse}
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id} [put]
+// @Security BearerAuth
+// @Router /admin/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -105,7 +288,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	user, err := h.userService.UpdateUser(c.Request.Context(), id, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to update user", err)
+		respondUserError(c, "Failed to update user", err)
 		return
 	}
 
@@ -115,14 +298,16 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // DeleteUser godoc
 // @Summary Delete user
 // @Description Delete user by ID (soft delete)
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id} [delete]
+// @Security BearerAuth
+// @Router /admin/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -132,7 +317,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	if err := h.userService.DeleteUser(c.Request.Context(), id); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to delete user", err)
+		respondUserError(c, "Failed to delete user", err)
 		return
 	}
 
@@ -142,14 +327,16 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // ListUsers godoc
 // @Summary List users
 // @Description Get paginated list of users
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
 // @Success 200 {object} response.Response{data=dto.UserListResponse}
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
-// @Router /users [get]
+// @Security BearerAuth
+// @Router /admin/users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
@@ -166,14 +353,16 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 // PromoteToVIP godoc
 // @Summary Promote user to VIP
 // @Description Promote user to VIP status
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id}/promote-vip [post]
+// @Security BearerAuth
+// @Router /admin/users/{id}/promote-vip [post]
 func (h *UserHandler) PromoteToVIP(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -183,7 +372,7 @@ func (h *UserHandler) PromoteToVIP(c *gin.Context) {
 	}
 
 	if err := h.userService.PromoteUserToVIP(c.Request.Context(), id); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to promote user to VIP", err)
+		respondUserError(c, "Failed to promote user to VIP", err)
 		return
 	}
 
@@ -193,14 +382,16 @@ func (h *UserHandler) PromoteToVIP(c *gin.Context) {
 // DemoteFromVIP godoc
 // @Summary Demote user from VIP
 // @Description Remove VIP status from user
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id}/demote-vip [post]
+// @Security BearerAuth
+// @Router /admin/users/{id}/demote-vip [post]
 func (h *UserHandler) DemoteFromVIP(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -210,58 +401,26 @@ func (h *UserHandler) DemoteFromVIP(c *gin.Context) {
 	}
 
 	if err := h.userService.DemoteUserFromVIP(c.Request.Context(), id); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to demote user from VIP", err)
+		respondUserError(c, "Failed to demote user from VIP", err)
 		return
 	}
 
 	response.Success(c, http.StatusOK, "User demoted from VIP successfully", nil)
 }
 
-// ChangePassword godoc
-// @Summary Change user password
-// @Description Change user password
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param id path int true "User ID"
-// @Param request body dto.ChangePasswordRequest true "Change password request"
-// @Success 200 {object} response.Response
-// @Failure 400 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Router /users/{id}/change-password [post]
-func (h *UserHandler) ChangePassword(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		response.Error(c, http.StatusBadRequest, "Invalid user ID", err)
-		return
-	}
-
-	var req dto.ChangePasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
-		return
-	}
-
-	if err := h.userService.ChangePassword(c.Request.Context(), id, req); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to change password", err)
-		return
-	}
-
-	response.Success(c, http.StatusOK, "Password changed successfully", nil)
-}
-
 // ActivateUser godoc
 // @Summary Activate user
 // @Description Activate user account
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id}/activate [post]
+// @Security BearerAuth
+// @Router /admin/users/{id}/activate [post]
 func (h *UserHandler) ActivateUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -271,7 +430,7 @@ func (h *UserHandler) ActivateUser(c *gin.Context) {
 	}
 
 	if err := h.userService.ActivateUser(c.Request.Context(), id); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to activate user", err)
+		respondUserError(c, "Failed to activate user", err)
 		return
 	}
 
@@ -281,14 +440,16 @@ func (h *UserHandler) ActivateUser(c *gin.Context) {
 // DeactivateUser godoc
 // @Summary Deactivate user
 // @Description Deactivate user account
-// @Tags users
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
-// @Router /users/{id}/deactivate [post]
+// @Security BearerAuth
+// @Router /admin/users/{id}/deactivate [post]
 func (h *UserHandler) DeactivateUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -298,10 +459,155 @@ func (h *UserHandler) DeactivateUser(c *gin.Context) {
 	}
 
 	if err := h.userService.DeactivateUser(c.Request.Context(), id); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to deactivate user", err)
+		respondUserError(c, "Failed to deactivate user", err)
 		return
 	}
 
 	response.Success(c, http.StatusOK, "User deactivated successfully", nil)
 }
 
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm the caller's email address with the token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /auth/verify-email [post]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userService.VerifyEmail(c.Request.Context(), req); err != nil {
+		respondAuthError(c, "Failed to verify email", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link. The answer is the same whether or not the address is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResendVerificationRequest true "Email address"
+// @Success 202 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/resend-verification [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userService.ResendVerification(c.Request.Context(), req, c.ClientIP()); err != nil {
+		respondAuthError(c, "Failed to resend verification email", err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "If the address needs verifying, an email is on its way", nil)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The answer is the same whether or not the address is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Email address"
+// @Success 202 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/forgot-password [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userService.ForgotPassword(c.Request.Context(), req, c.ClientIP()); err != nil {
+		respondAuthError(c, "Failed to request password reset", err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "If the address is registered, a reset link is on its way", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with the token from the reset email. The token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userService.ResetPassword(c.Request.Context(), req, c.ClientIP()); err != nil {
+		respondAuthError(c, "Failed to reset password", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password reset successfully", nil)
+}
+
+// respondUserError maps errors of account management to HTTP status codes
+func respondUserError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrUserNotFound):
+		response.Error(c, http.StatusNotFound, message, err)
+	case errors.Is(err, entity.ErrCannotManageAccount),
+		errors.Is(err, entity.ErrCannotChangeOwnRole):
+		response.Error(c, http.StatusForbidden, message, err)
+	case errors.Is(err, entity.ErrUserAlreadyActive),
+		errors.Is(err, entity.ErrUserAlreadyDisabled),
+		errors.Is(err, entity.ErrEmailAlreadyExists),
+		errors.Is(err, entity.ErrUsernameTaken),
+		errors.Is(err, entity.ErrPhoneAlreadyExists):
+		response.Error(c, http.StatusConflict, message, err)
+	case errors.Is(err, entity.ErrInvalidRole),
+		errors.Is(err, entity.ErrInvalidUsername),
+		errors.Is(err, value_object.ErrInvalidEmail),
+		errors.Is(err, value_object.ErrPasswordMismatch),
+		errors.Is(err, value_object.ErrInvalidPassword):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
+
+// respondAuthError maps errors of the account recovery flows to HTTP status codes
+func respondAuthError(c *gin.Context, message string, err error) {
+	switch {
+	case validator.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, message, err)
+	case errors.Is(err, entity.ErrTooManyRequests):
+		response.Error(c, http.StatusTooManyRequests, message, err)
+	case errors.Is(err, entity.ErrInvalidToken),
+		errors.Is(err, value_object.ErrInvalidEmail),
+		errors.Is(err, value_object.ErrInvalidPassword):
+		response.Error(c, http.StatusBadRequest, message, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err)
+	}
+}
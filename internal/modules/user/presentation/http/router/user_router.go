@@ -3,25 +3,148 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/entity"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/presentation/http/handler"
 )
 
-// RegisterUserRoutes registers all user-related routes
-func RegisterUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler) {
-	users := router.Group("/users")
+// RegisterAuthRoutes registers authentication routes
+func RegisterAuthRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler) {
+	auth := router.Group("/auth")
 	{
-		users.POST("", userHandler.CreateUser)
-		users.GET("", userHandler.ListUsers)
-		users.GET("/:id", userHandler.GetUser)
-		users.PUT("/:id", userHandler.UpdateUser)
-		users.DELETE("/:id", userHandler.DeleteUser)
+		auth.POST("/login", userHandler.Login)
+		auth.POST("/verify-email", userHandler.VerifyEmail)
+		auth.POST("/resend-verification", userHandler.ResendVerification)
+		auth.POST("/forgot-password", userHandler.ForgotPassword)
+		auth.POST("/reset-password", userHandler.ResetPassword)
+	}
+}
+
+// RegisterPhoneAuthRoutes registers sign up and login by phone number
+func RegisterPhoneAuthRoutes(router *gin.RouterGroup, phoneAuthHandler *handler.PhoneAuthHandler) {
+	phone := router.Group("/auth/phone")
+	{
+		phone.POST("/otp", phoneAuthHandler.RequestOTP)
+		phone.POST("/register", phoneAuthHandler.Register)
+		phone.POST("/login", phoneAuthHandler.Login)
+	}
+}
+
+// RegisterTwoFactorRoutes registers two-factor authentication routes. Finishing
+// a challenged login happens before the caller has an access token.
+func RegisterTwoFactorRoutes(router *gin.RouterGroup, twoFactorHandler *handler.TwoFactorHandler, auth gin.HandlerFunc) {
+	twoFactor := router.Group("/auth/2fa")
+	{
+		twoFactor.POST("/verify", twoFactorHandler.Verify)
+		twoFactor.POST("/challenge/setup", twoFactorHandler.ChallengeSetup)
+
+		twoFactor.GET("", auth, twoFactorHandler.GetStatus)
+		twoFactor.POST("/setup", auth, twoFactorHandler.Setup)
+		twoFactor.POST("/enable", auth, twoFactorHandler.Enable)
+		twoFactor.POST("/disable", auth, twoFactorHandler.Disable)
+		twoFactor.POST("/recovery-codes", auth, twoFactorHandler.RegenerateRecoveryCodes)
+	}
+}
+
+// RegisterSessionRoutes registers routes for the caller's login sessions
+func RegisterSessionRoutes(router *gin.RouterGroup, sessionHandler *handler.SessionHandler, auth gin.HandlerFunc) {
+	router.POST("/auth/logout", auth, sessionHandler.Logout)
+
+	sessions := router.Group("/me/sessions", auth)
+	{
+		sessions.GET("", sessionHandler.ListSessions)
+		sessions.POST("/revoke-others", sessionHandler.RevokeOtherSessions)
+		sessions.DELETE("/:id", sessionHandler.RevokeSession)
+	}
+}
+
+// RegisterUserRoutes registers sign up and the caller's own account
+func RegisterUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, auth gin.HandlerFunc) {
+	router.POST("/users", userHandler.CreateUser)
+
+	me := router.Group("/me", auth)
+	{
+		me.GET("", userHandler.GetMe)
+		me.PUT("", userHandler.UpdateMe)
+		me.POST("/change-password", userHandler.ChangePassword)
+	}
+}
+
+// RegisterAccountRoutes registers deleting the caller's account and exporting their data
+func RegisterAccountRoutes(router *gin.RouterGroup, accountHandler *handler.AccountHandler, auth gin.HandlerFunc) {
+	me := router.Group("/me", auth)
+	{
+		me.POST("/deletion", accountHandler.RequestDeletion)
+		me.GET("/deletion", accountHandler.GetDeletion)
+		me.DELETE("/deletion", accountHandler.CancelDeletion)
 
-		// Additional user actions
-		users.POST("/:id/promote-vip", userHandler.PromoteToVIP)
-		users.POST("/:id/demote-vip", userHandler.DemoteFromVIP)
-		users.POST("/:id/change-password", userHandler.ChangePassword)
-		users.POST("/:id/activate", userHandler.ActivateUser)
-		users.POST("/:id/deactivate", userHandler.DeactivateUser)
+		me.POST("/export", accountHandler.RequestExport)
+		me.GET("/export", accountHandler.GetExport)
+		me.GET("/export/download", accountHandler.DownloadExport)
 	}
 }
 
+// RegisterProfileRoutes registers the caller's profile and other users' public profiles
+func RegisterProfileRoutes(router *gin.RouterGroup, profileHandler *handler.ProfileHandler, auth gin.HandlerFunc) {
+	me := router.Group("/me", auth)
+	{
+		me.PATCH("/profile", profileHandler.UpdateProfile)
+		me.PUT("/avatar", profileHandler.UploadAvatar)
+		me.DELETE("/avatar", profileHandler.RemoveAvatar)
+	}
+
+	router.GET("/users/:id/profile", auth, profileHandler.GetProfile)
+}
+
+// RegisterDiscoveryRoutes registers user search and the settings that limit it
+func RegisterDiscoveryRoutes(router *gin.RouterGroup, discoveryHandler *handler.DiscoveryHandler, auth gin.HandlerFunc) {
+	router.GET("/users/search", auth, discoveryHandler.Search)
+
+	me := router.Group("/me", auth)
+	{
+		me.GET("/privacy", discoveryHandler.GetPrivacy)
+		me.PUT("/privacy", discoveryHandler.UpdatePrivacy)
+	}
+}
+
+// RegisterContactRoutes registers address book sync
+func RegisterContactRoutes(router *gin.RouterGroup, contactHandler *handler.ContactHandler, auth gin.HandlerFunc) {
+	router.POST("/contacts/sync", auth, contactHandler.SyncContacts)
+}
+
+// RegisterFriendRoutes registers friend requests and the friend list
+func RegisterFriendRoutes(router *gin.RouterGroup, friendHandler *handler.FriendHandler, auth gin.HandlerFunc) {
+	friends := router.Group("/friends", auth)
+	{
+		friends.GET("", friendHandler.ListFriends)
+		friends.DELETE("/:user_id", friendHandler.RemoveFriend)
+
+		friends.GET("/requests", friendHandler.ListRequests)
+		friends.POST("/requests", friendHandler.SendRequest)
+		friends.POST("/requests/:id/accept", friendHandler.AcceptRequest)
+		friends.POST("/requests/:id/decline", friendHandler.DeclineRequest)
+		friends.DELETE("/requests/:id", friendHandler.CancelRequest)
+	}
+}
+
+// RegisterAdminUserRoutes registers account management for staff. Each route
+// requires the permission its system role grants.
+func RegisterAdminUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, auth gin.HandlerFunc, authz middleware.Authorizer) {
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(authz, permission)
+	}
+
+	users := router.Group("/admin/users", auth)
+	{
+		users.GET("", can(entity.PermissionUsersView), userHandler.ListUsers)
+		users.GET("/:id", can(entity.PermissionUsersView), userHandler.GetUser)
+		users.PUT("/:id", can(entity.PermissionUsersEdit), userHandler.UpdateUser)
+		users.DELETE("/:id", can(entity.PermissionUsersDelete), userHandler.DeleteUser)
+
+		users.POST("/:id/promote-vip", can(entity.PermissionUsersVIP), userHandler.PromoteToVIP)
+		users.POST("/:id/demote-vip", can(entity.PermissionUsersVIP), userHandler.DemoteFromVIP)
+		users.POST("/:id/activate", can(entity.PermissionUsersSuspend), userHandler.ActivateUser)
+		users.POST("/:id/deactivate", can(entity.PermissionUsersSuspend), userHandler.DeactivateUser)
+		users.PUT("/:id/role", can(entity.PermissionRolesAssign), userHandler.AssignRole)
+	}
+}
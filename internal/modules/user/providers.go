@@ -1,31 +1,75 @@
 package user
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
 	"gorm.io/gorm"
 
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/mailer"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/ratelimit"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/sms"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/storage"
+	"github.com/ndxbinh1922001/VNalo-be/internal/infrastructure/websocket"
+	"github.com/ndxbinh1922001/VNalo-be/internal/middleware"
+
+	auditService "github.com/ndxbinh1922001/VNalo-be/internal/modules/audit/application/service"
+	messageService "github.com/ndxbinh1922001/VNalo-be/internal/modules/message/application/service"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
 	userService "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/application/service"
 	"github.com/ndxbinh1922001/VNalo-be/internal/modules/user/domain/repository"
+	userStore "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/redis"
 	userRepo "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/infrastructure/persistence/repository"
 	userHandler "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/presentation/http/handler"
 	userRouter "github.com/ndxbinh1922001/VNalo-be/internal/modules/user/presentation/http/router"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/crypto"
+	"github.com/ndxbinh1922001/VNalo-be/pkg/token"
 )
 
 // Module provides user module dependencies
 var Module = fx.Options(
 	fx.Provide(provideRepository),
+	fx.Provide(provideTokenRepository),
+	fx.Provide(provideSessionRepository),
+	fx.Provide(provideFriendshipRepository),
+	fx.Provide(provideAccountExportRepository),
+	fx.Provide(provideSessionRevocationStore),
+	fx.Provide(provideTwoFactorRepository),
+	fx.Provide(provideOTPStore),
+	fx.Provide(provideLimiters),
+	fx.Provide(provideSessionService),
+	fx.Provide(provideSessionChecker),
+	fx.Provide(provideTwoFactorService),
+	fx.Provide(provideAccountService),
 	fx.Provide(provideService),
+	fx.Provide(provideAuthorizer),
+	fx.Provide(providePhoneAuthService),
+	fx.Provide(provideProfileService),
+	fx.Provide(provideDiscoveryService),
+	fx.Provide(provideContactService),
+	fx.Provide(provideFriendService),
 	fx.Provide(provideHandler),
+	fx.Provide(providePhoneAuthHandler),
+	fx.Provide(provideTwoFactorHandler),
+	fx.Provide(provideSessionHandler),
+	fx.Provide(provideProfileHandler),
+	fx.Provide(provideDiscoveryHandler),
+	fx.Provide(provideContactHandler),
+	fx.Provide(provideFriendHandler),
+	fx.Provide(provideAccountHandler),
 	fx.Provide(
 		fx.Annotate(
 			provideRouteRegistration,
 			fx.ResultTags(`group:"routes"`), // ← Tag để Fx auto-collect
 		),
 	),
+	fx.Invoke(runSessionRevocations),
+	fx.Invoke(runAccountJobs),
 )
 
 func provideRepository(db *gorm.DB) repository.UserRepository {
@@ -33,9 +77,290 @@ func provideRepository(db *gorm.DB) repository.UserRepository {
 	return userRepo.NewUserRepository(db)
 }
 
-func provideService(repo repository.UserRepository) service.UserService {
+func provideTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	log.Println("📦 Creating user token repository...")
+	return userRepo.NewUserTokenRepository(db)
+}
+
+func provideSessionRepository(db *gorm.DB) repository.SessionRepository {
+	log.Println("📦 Creating session repository...")
+	return userRepo.NewSessionRepository(db)
+}
+
+func provideFriendshipRepository(db *gorm.DB) repository.FriendshipRepository {
+	log.Println("📦 Creating friendship repository...")
+	return userRepo.NewFriendshipRepository(db)
+}
+
+func provideAccountExportRepository(db *gorm.DB) repository.AccountExportRepository {
+	log.Println("📦 Creating account export repository...")
+	return userRepo.NewAccountExportRepository(db)
+}
+
+func provideSessionRevocationStore(redisClient *redis.Client) repository.SessionRevocationStore {
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, signed out sessions are only refused on this node")
+		return userStore.NewMemorySessionRevocationStore()
+	}
+
+	log.Println("📦 Creating session revocation store...")
+	return userStore.NewSessionRevocationStore(redisClient)
+}
+
+func provideTwoFactorRepository(db *gorm.DB, cfg infrastructure.Config) (repository.TwoFactorRepository, error) {
+	log.Println("📦 Creating two-factor repository...")
+	key := cfg.GetTwoFactorConfig().EncryptionKey
+	if key == "" {
+		log.Println("⚠️  two_factor.encryption_key not set, TOTP secrets are encrypted with the JWT secret")
+		key = cfg.GetJWTConfig().Secret
+	}
+
+	cipher, err := crypto.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return userRepo.NewTwoFactorRepository(db, cipher), nil
+}
+
+func provideOTPStore(redisClient *redis.Client) repository.OTPStore {
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, OTP codes only verify on the node that sent them")
+		return userStore.NewMemoryOTPStore()
+	}
+
+	log.Println("📦 Creating OTP store...")
+	return userStore.NewOTPStore(redisClient)
+}
+
+func provideLimiters(redisClient *redis.Client, cfg infrastructure.Config) service.AuthLimiters {
+	authCfg := cfg.GetAuthConfig()
+	window := time.Duration(authCfg.RateLimitWindow) * time.Second
+	dailyOTPs := cfg.GetOTPConfig().DailyLimit
+
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, auth rate limits apply per node")
+		return service.AuthLimiters{
+			PerEmail: ratelimit.NewMemoryLimiter(authCfg.RateLimitPerEmail, window),
+			PerPhone: ratelimit.NewMemoryLimiter(dailyOTPs, 24*time.Hour),
+			PerIP:    ratelimit.NewMemoryLimiter(authCfg.RateLimitPerIP, window),
+		}
+	}
+
+	return service.AuthLimiters{
+		PerEmail: ratelimit.NewRedisLimiter(redisClient, "auth:email", authCfg.RateLimitPerEmail, window),
+		PerPhone: ratelimit.NewRedisLimiter(redisClient, "auth:phone", dailyOTPs, 24*time.Hour),
+		PerIP:    ratelimit.NewRedisLimiter(redisClient, "auth:ip", authCfg.RateLimitPerIP, window),
+	}
+}
+
+func provideSessionService(
+	repo repository.UserRepository,
+	sessions repository.SessionRepository,
+	revocations repository.SessionRevocationStore,
+	tokens *token.Manager,
+	hub *websocket.Hub,
+	audit auditService.AuditService,
+	blobs storage.Storage,
+) service.SessionService {
+	log.Println("⚙️  Creating session service...")
+	return userService.NewSessionService(repo, sessions, revocations, tokens, hub, audit, blobs)
+}
+
+// provideSessionChecker lets the Auth middleware of every module refuse signed out sessions
+func provideSessionChecker(svc service.SessionService) middleware.SessionChecker {
+	return svc
+}
+
+// runSessionRevocations closes the connections of sessions signed out on any node
+func runSessionRevocations(lc fx.Lifecycle, svc service.SessionService) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go svc.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+func provideTwoFactorService(
+	repo repository.UserRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	sessions service.SessionService,
+	audit auditService.AuditService,
+	tokens *token.Manager,
+	redisClient *redis.Client,
+	cfg infrastructure.Config,
+) service.TwoFactorService {
+	log.Println("⚙️  Creating two-factor service...")
+	twoFactorCfg := cfg.GetTwoFactorConfig()
+	challengeTTL := time.Duration(twoFactorCfg.ChallengeTTL) * time.Second
+
+	// Six digit codes fall to guessing without a limit on attempts
+	var limiter ratelimit.Limiter
+	if redisClient == nil {
+		limiter = ratelimit.NewMemoryLimiter(twoFactorCfg.MaxAttempts, challengeTTL)
+	} else {
+		limiter = ratelimit.NewRedisLimiter(redisClient, "auth:2fa", twoFactorCfg.MaxAttempts, challengeTTL)
+	}
+
+	return userService.NewTwoFactorService(repo, twoFactorRepo, sessions, audit, tokens, limiter, service.TwoFactorConfig{
+		Issuer:          twoFactorCfg.Issuer,
+		ChallengeTTL:    challengeTTL,
+		EnforceForVIP:   twoFactorCfg.EnforceForVIP,
+		EnforceForStaff: twoFactorCfg.EnforceForStaff,
+		RecoveryCodes:   twoFactorCfg.RecoveryCodes,
+	})
+}
+
+func provideAccountService(
+	repo repository.UserRepository,
+	exports repository.AccountExportRepository,
+	friendships repository.FriendshipRepository,
+	sessions service.SessionService,
+	messages messageService.AccountDataService,
+	audit auditService.AuditService,
+	blobs storage.Storage,
+	cfg infrastructure.Config,
+) service.AccountService {
+	log.Println("⚙️  Creating account service...")
+	deletionCfg := cfg.GetAccountDeletionConfig()
+	return userService.NewAccountService(repo, exports, friendships, sessions, messages, audit, blobs, service.AccountConfig{
+		GracePeriod:  time.Duration(deletionCfg.GracePeriod) * time.Second,
+		ExportTTL:    time.Duration(deletionCfg.ExportTTL) * time.Second,
+		PollInterval: time.Duration(deletionCfg.PollInterval) * time.Second,
+		BatchSize:    deletionCfg.BatchSize,
+		ClaimTimeout: time.Duration(deletionCfg.ClaimTimeout) * time.Second,
+	})
+}
+
+// runAccountJobs builds data exports and purges deleted accounts in the background
+func runAccountJobs(lc fx.Lifecycle, svc service.AccountService) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go svc.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+func provideService(
+	repo repository.UserRepository,
+	userTokens repository.UserTokenRepository,
+	twoFactor service.TwoFactorService,
+	accounts service.AccountService,
+	sessions service.SessionService,
+	audit auditService.AuditService,
+	blobs storage.Storage,
+	mail mailer.Mailer,
+	limiters service.AuthLimiters,
+	cfg infrastructure.Config,
+) service.UserService {
 	log.Println("⚙️  Creating user service...")
-	return userService.NewUserService(repo)
+	authCfg := cfg.GetAuthConfig()
+	return userService.NewUserService(repo, userTokens, twoFactor, accounts, sessions, audit, blobs, mail, limiters, service.AuthConfig{
+		AppURL:                   authCfg.AppURL,
+		RequireEmailVerification: authCfg.RequireEmailVerification,
+		VerificationTTL:          time.Duration(authCfg.VerificationTTL) * time.Second,
+		ResetTTL:                 time.Duration(authCfg.ResetTTL) * time.Second,
+	})
+}
+
+// provideAuthorizer checks the permissions of the admin routes against system roles
+func provideAuthorizer(svc service.UserService) middleware.Authorizer {
+	return svc
+}
+
+func providePhoneAuthService(
+	repo repository.UserRepository,
+	otps repository.OTPStore,
+	twoFactor service.TwoFactorService,
+	sender sms.Sender,
+	limiters service.AuthLimiters,
+	cfg infrastructure.Config,
+) service.PhoneAuthService {
+	log.Println("⚙️  Creating phone auth service...")
+	otpCfg := cfg.GetOTPConfig()
+	return userService.NewPhoneAuthService(repo, otps, twoFactor, sender, limiters, service.OTPConfig{
+		Length:      otpCfg.Length,
+		TTL:         time.Duration(otpCfg.TTL) * time.Second,
+		Cooldown:    time.Duration(otpCfg.Cooldown) * time.Second,
+		MaxAttempts: otpCfg.MaxAttempts,
+		// Codes are hashed with the token signing secret; rotating it voids outstanding codes
+		Secret: []byte(cfg.GetJWTConfig().Secret),
+	})
+}
+
+func provideProfileService(repo repository.UserRepository, friendships repository.FriendshipRepository, blobs storage.Storage) service.ProfileService {
+	log.Println("⚙️  Creating profile service...")
+	return userService.NewProfileService(repo, friendships, blobs)
+}
+
+func provideDiscoveryService(
+	repo repository.UserRepository,
+	blobs storage.Storage,
+	redisClient *redis.Client,
+	cfg infrastructure.Config,
+) service.DiscoveryService {
+	log.Println("⚙️  Creating discovery service...")
+	searchCfg := cfg.GetSearchConfig()
+
+	var limiters service.SearchLimiters
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, search rate limits apply per node")
+		limiters = service.SearchLimiters{
+			PerUser:      ratelimit.NewMemoryLimiter(searchCfg.PerMinute, time.Minute),
+			PhoneLookups: ratelimit.NewMemoryLimiter(searchCfg.PhoneLookupsPerDay, 24*time.Hour),
+		}
+	} else {
+		limiters = service.SearchLimiters{
+			PerUser:      ratelimit.NewRedisLimiter(redisClient, "users:search", searchCfg.PerMinute, time.Minute),
+			PhoneLookups: ratelimit.NewRedisLimiter(redisClient, "users:search:phone", searchCfg.PhoneLookupsPerDay, 24*time.Hour),
+		}
+	}
+
+	return userService.NewDiscoveryService(repo, blobs, limiters)
+}
+
+func provideContactService(repo repository.UserRepository, redisClient *redis.Client, cfg infrastructure.Config) service.ContactService {
+	log.Println("⚙️  Creating contact service...")
+	syncCfg := cfg.GetContactSyncConfig()
+
+	var limiters service.ContactSyncLimiters
+	if redisClient == nil {
+		log.Println("⚠️  Redis not available, contact sync quotas apply per node")
+		limiters = service.ContactSyncLimiters{
+			Requests: ratelimit.NewMemoryLimiter(syncCfg.PerHour, time.Hour),
+			Hashes:   ratelimit.NewMemoryLimiter(syncCfg.HashesPerDay, 24*time.Hour),
+		}
+	} else {
+		limiters = service.ContactSyncLimiters{
+			Requests: ratelimit.NewRedisLimiter(redisClient, "contacts:sync", syncCfg.PerHour, time.Hour),
+			Hashes:   ratelimit.NewRedisLimiter(redisClient, "contacts:sync:hashes", syncCfg.HashesPerDay, 24*time.Hour),
+		}
+	}
+
+	return userService.NewContactService(repo, limiters, syncCfg.BatchSize)
+}
+
+func provideFriendService(
+	repo repository.UserRepository,
+	friendships repository.FriendshipRepository,
+	hub *websocket.Hub,
+	blobs storage.Storage,
+) service.FriendService {
+	log.Println("⚙️  Creating friend service...")
+	return userService.NewFriendService(repo, friendships, hub, blobs)
 }
 
 func provideHandler(svc service.UserService) *userHandler.UserHandler {
@@ -43,12 +368,76 @@ func provideHandler(svc service.UserService) *userHandler.UserHandler {
 	return userHandler.NewUserHandler(svc)
 }
 
+func providePhoneAuthHandler(svc service.PhoneAuthService) *userHandler.PhoneAuthHandler {
+	log.Println("🎯 Creating phone auth handler...")
+	return userHandler.NewPhoneAuthHandler(svc)
+}
+
+func provideTwoFactorHandler(svc service.TwoFactorService) *userHandler.TwoFactorHandler {
+	log.Println("🎯 Creating two-factor handler...")
+	return userHandler.NewTwoFactorHandler(svc)
+}
+
+func provideSessionHandler(svc service.SessionService) *userHandler.SessionHandler {
+	log.Println("🎯 Creating session handler...")
+	return userHandler.NewSessionHandler(svc)
+}
+
+func provideProfileHandler(svc service.ProfileService, cfg infrastructure.Config) *userHandler.ProfileHandler {
+	log.Println("🎯 Creating profile handler...")
+	return userHandler.NewProfileHandler(svc, cfg.GetProfileConfig().MaxAvatarBytes)
+}
+
+func provideDiscoveryHandler(svc service.DiscoveryService) *userHandler.DiscoveryHandler {
+	log.Println("🎯 Creating discovery handler...")
+	return userHandler.NewDiscoveryHandler(svc)
+}
+
+func provideContactHandler(svc service.ContactService) *userHandler.ContactHandler {
+	log.Println("🎯 Creating contact handler...")
+	return userHandler.NewContactHandler(svc)
+}
+
+func provideFriendHandler(svc service.FriendService) *userHandler.FriendHandler {
+	log.Println("🎯 Creating friend handler...")
+	return userHandler.NewFriendHandler(svc)
+}
+
+func provideAccountHandler(svc service.AccountService) *userHandler.AccountHandler {
+	log.Println("🎯 Creating account handler...")
+	return userHandler.NewAccountHandler(svc)
+}
+
 // provideRouteRegistration returns a function to register user routes
 // Fx will collect this function và router sẽ tự động gọi nó! ✨
-func provideRouteRegistration(h *userHandler.UserHandler) func(*gin.RouterGroup) {
+func provideRouteRegistration(
+	h *userHandler.UserHandler,
+	phoneHandler *userHandler.PhoneAuthHandler,
+	twoFactorHandler *userHandler.TwoFactorHandler,
+	sessionHandler *userHandler.SessionHandler,
+	profileHandler *userHandler.ProfileHandler,
+	discoveryHandler *userHandler.DiscoveryHandler,
+	contactHandler *userHandler.ContactHandler,
+	friendHandler *userHandler.FriendHandler,
+	accountHandler *userHandler.AccountHandler,
+	tokens *token.Manager,
+	sessions middleware.SessionChecker,
+	authz middleware.Authorizer,
+) func(*gin.RouterGroup) {
 	return func(router *gin.RouterGroup) {
 		log.Println("✅ Registering user routes...")
 		// Dùng trực tiếp function RegisterUserRoutes có sẵn! ✨
-		userRouter.RegisterUserRoutes(router, h)
+		auth := middleware.Auth(tokens, sessions)
+		userRouter.RegisterUserRoutes(router, h, auth)
+		userRouter.RegisterProfileRoutes(router, profileHandler, auth)
+		userRouter.RegisterDiscoveryRoutes(router, discoveryHandler, auth)
+		userRouter.RegisterContactRoutes(router, contactHandler, auth)
+		userRouter.RegisterFriendRoutes(router, friendHandler, auth)
+		userRouter.RegisterAccountRoutes(router, accountHandler, auth)
+		userRouter.RegisterAdminUserRoutes(router, h, auth, authz)
+		userRouter.RegisterAuthRoutes(router, h)
+		userRouter.RegisterPhoneAuthRoutes(router, phoneHandler)
+		userRouter.RegisterTwoFactorRoutes(router, twoFactorHandler, auth)
+		userRouter.RegisterSessionRoutes(router, sessionHandler, auth)
 	}
 }
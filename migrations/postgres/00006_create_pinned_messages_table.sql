@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pinned_messages (
+    id BIGSERIAL PRIMARY KEY,
+    conversation_id BIGINT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    message_id VARCHAR(100) NOT NULL,
+    pinned_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    pinned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(conversation_id, message_id)
+);
+
+CREATE INDEX idx_pinned_messages_conversation ON pinned_messages(conversation_id, pinned_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pinned_messages;
+-- +goose StatementEnd
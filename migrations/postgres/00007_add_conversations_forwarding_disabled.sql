@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS forwarding_disabled BOOLEAN DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE conversations DROP COLUMN IF EXISTS forwarding_disabled;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Disappearing messages: seconds a new message lives, 0 keeps messages forever
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS message_ttl INT DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE conversations DROP COLUMN IF EXISTS message_ttl;
+-- +goose StatementEnd
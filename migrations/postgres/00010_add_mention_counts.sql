@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Unread mentions per member, reset when the member reads the conversation
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS mention_count INT DEFAULT 0;
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS mentions JSONB DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE scheduled_messages DROP COLUMN IF EXISTS mentions;
+ALTER TABLE conversation_members DROP COLUMN IF EXISTS mention_count;
+-- +goose StatementEnd
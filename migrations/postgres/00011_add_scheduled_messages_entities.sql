@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Formatting entities of scheduled text messages
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS entities JSONB DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE scheduled_messages DROP COLUMN IF EXISTS entities;
+-- +goose StatementEnd
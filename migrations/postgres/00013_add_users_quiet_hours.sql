@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Quiet hours are minutes after midnight in the user's timezone; no pushes are sent in between
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_hours_start SMALLINT CHECK (quiet_hours_start BETWEEN 0 AND 1439);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_hours_end SMALLINT CHECK (quiet_hours_end BETWEEN 0 AND 1439);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_hours_end;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_hours_start;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-conversation notifications: all, mentions only or none, optionally muted until a point in time.
+-- They replace is_muted (now 'mentions') and notification_enabled (now 'none').
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS notification_level VARCHAR(16) NOT NULL DEFAULT 'all'
+    CHECK (notification_level IN ('all', 'mentions', 'none'));
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS muted_until TIMESTAMPTZ;
+
+UPDATE conversation_members SET notification_level = 'mentions' WHERE is_muted = TRUE;
+UPDATE conversation_members SET notification_level = 'none' WHERE notification_enabled = FALSE;
+
+ALTER TABLE conversation_members DROP COLUMN IF EXISTS is_muted;
+ALTER TABLE conversation_members DROP COLUMN IF EXISTS notification_enabled;
+
+-- Preview privacy: pushes show only who wrote, not what
+ALTER TABLE users ADD COLUMN IF NOT EXISTS push_previews BOOLEAN NOT NULL DEFAULT TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS push_previews;
+
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS is_muted BOOLEAN DEFAULT FALSE;
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS notification_enabled BOOLEAN DEFAULT TRUE;
+
+UPDATE conversation_members SET is_muted = TRUE WHERE notification_level = 'mentions' OR muted_until > NOW();
+UPDATE conversation_members SET notification_enabled = FALSE WHERE notification_level = 'none';
+
+ALTER TABLE conversation_members DROP COLUMN IF EXISTS muted_until;
+ALTER TABLE conversation_members DROP COLUMN IF EXISTS notification_level;
+-- +goose StatementEnd
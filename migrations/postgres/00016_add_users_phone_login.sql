@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts created by phone have no email; every account keeps at least one of the two
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_email_or_phone CHECK (email IS NOT NULL OR phone IS NOT NULL);
+
+-- Phones are stored in E.164 and identify an account like emails do
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_unique ON users(phone) WHERE phone IS NOT NULL AND is_deleted = FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_phone_unique;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_or_phone;
+-- Fails while accounts without an email exist
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
+-- +goose StatementEnd
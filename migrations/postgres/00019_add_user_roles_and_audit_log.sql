@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- System role of each account. Nobody is staff after this migration; grant
+-- the first admin by hand:
+--   UPDATE users SET role = 'admin' WHERE email = 'ops@example.com';
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user'
+        CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE INDEX idx_users_role ON users(role) WHERE role <> 'user';
+
+-- Append-only record of privileged actions
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id VARCHAR(64) NOT NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    changes JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_logs_target ON audit_logs(target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_logs;
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Audit entries are evidence: refuse edits and deletes, even from the
+-- application. The one exception is actor_id being cleared when the actor's
+-- row is deleted (ON DELETE SET NULL).
+CREATE OR REPLACE FUNCTION audit_logs_reject_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.actor_id IS NULL
+        AND (NEW.id, NEW.action, NEW.target_type, NEW.target_id, NEW.ip, NEW.user_agent, NEW.changes::text, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.action, OLD.target_type, OLD.target_id, OLD.ip, OLD.user_agent, OLD.changes::text, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_reject_change();
+
+-- Filters used by the admin audit log endpoint
+CREATE INDEX idx_audit_logs_action ON audit_logs(action, created_at DESC);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_logs_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_action;
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_reject_change();
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Profile fields. avatar_url (from 00001) keeps pointing at external images;
+-- uploaded avatars are stored by key and served in several sizes.
+ALTER TABLE users
+    ADD COLUMN display_name VARCHAR(64),
+    ADD COLUMN avatar_key VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS avatar_key,
+    DROP COLUMN IF EXISTS display_name;
+-- +goose StatementEnd
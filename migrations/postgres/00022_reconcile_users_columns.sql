@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Status is stored by name. Rows written while the application sent the
+-- numeric enum hold '1' or '2'.
+UPDATE users SET status = 'ACTIVE' WHERE status IS NULL OR status = '1';
+UPDATE users SET status = 'DISABLED' WHERE status = '2';
+ALTER TABLE users
+    ALTER COLUMN status TYPE VARCHAR(16),
+    ALTER COLUMN status SET DEFAULT 'ACTIVE',
+    ALTER COLUMN status SET NOT NULL,
+    ADD CONSTRAINT users_status_check CHECK (status IN ('ACTIVE', 'DISABLED'));
+
+-- Flags the application never leaves unset
+UPDATE users SET language = 'en' WHERE language IS NULL;
+UPDATE users SET is_vip = FALSE WHERE is_vip IS NULL;
+UPDATE users SET is_deleted = FALSE WHERE is_deleted IS NULL;
+UPDATE users SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE users SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE users
+    ALTER COLUMN language SET NOT NULL,
+    ALTER COLUMN is_vip SET NOT NULL,
+    ALTER COLUMN is_deleted SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL;
+
+-- Last successful login. Some databases got a last_login_time column (Unix
+-- seconds) added by hand; carry its values over.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP;
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'last_login_time'
+    ) THEN
+        UPDATE users SET last_login_at = to_timestamp(last_login_time) AT TIME ZONE 'UTC'
+        WHERE last_login_time > 0 AND last_login_at IS NULL;
+        ALTER TABLE users DROP COLUMN last_login_time;
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+ALTER TABLE users
+    ALTER COLUMN language DROP NOT NULL,
+    ALTER COLUMN is_vip DROP NOT NULL,
+    ALTER COLUMN is_deleted DROP NOT NULL,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check,
+    ALTER COLUMN status DROP NOT NULL,
+    ALTER COLUMN status TYPE VARCHAR(50);
+-- +goose StatementEnd
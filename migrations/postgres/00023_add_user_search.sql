@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Fuzzy matching on usernames and display names
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (lower(username) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_display_name_trgm ON users USING GIN (lower(display_name) gin_trgm_ops);
+
+-- Prefix matching ("ngu" finds "nguyen") without relying on the trigram threshold
+CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users (lower(username) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_display_name_prefix ON users (lower(display_name) text_pattern_ops);
+
+-- Whether others can find the account by typing its exact phone number
+ALTER TABLE users ADD COLUMN IF NOT EXISTS discoverable_by_phone BOOLEAN NOT NULL DEFAULT TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS discoverable_by_phone;
+DROP INDEX IF EXISTS idx_users_display_name_prefix;
+DROP INDEX IF EXISTS idx_users_username_prefix;
+DROP INDEX IF EXISTS idx_users_display_name_trgm;
+DROP INDEX IF EXISTS idx_users_username_trgm;
+-- The extension may be used by other tables, so it stays
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Contact sync matches address books by the hex SHA-256 of each E.164 number,
+-- so clients never upload the numbers themselves
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_hash CHAR(64);
+
+UPDATE users SET phone_hash = encode(sha256(convert_to(phone, 'UTF8')), 'hex') WHERE phone IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_phone_hash ON users(phone_hash) WHERE phone_hash IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_phone_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_hash;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mutual relationships between users. A row is a pending request from
+-- requester to addressee until it is accepted; declined and canceled requests
+-- are deleted so they can be sent again.
+CREATE TABLE IF NOT EXISTS friendships (
+    id BIGSERIAL PRIMARY KEY,
+    requester_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    addressee_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'ACCEPTED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    CHECK (requester_id <> addressee_id)
+);
+
+-- One relationship per pair, whichever side asked first
+CREATE UNIQUE INDEX idx_friendships_pair ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+CREATE INDEX idx_friendships_requester ON friendships(requester_id, status);
+CREATE INDEX idx_friendships_addressee ON friendships(addressee_id, status);
+
+-- Refuse new direct conversations from people who are not friends
+ALTER TABLE users ADD COLUMN IF NOT EXISTS only_friends_can_message BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS only_friends_can_message;
+DROP TABLE IF EXISTS friendships;
+-- +goose StatementEnd
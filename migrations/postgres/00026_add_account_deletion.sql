@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts are purged once deletion_scheduled_at passes, unless the owner
+-- cancels first. Purged rows stay behind without personal data so that
+-- conversations and audit logs keep pointing at something.
+ALTER TABLE users
+    ADD COLUMN deletion_scheduled_at TIMESTAMP,
+    ADD COLUMN purge_claimed_at TIMESTAMP,
+    ADD COLUMN purged_at TIMESTAMP;
+
+CREATE INDEX idx_users_deletion_due ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL AND purged_at IS NULL;
+
+-- Purged accounts keep neither email nor phone
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_or_phone;
+ALTER TABLE users ADD CONSTRAINT users_email_or_phone
+    CHECK (email IS NOT NULL OR phone IS NOT NULL OR purged_at IS NOT NULL);
+
+-- Archives of an account's data, built in the background and kept in blob
+-- storage until expires_at
+CREATE TABLE IF NOT EXISTS account_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'PROCESSING', 'READY', 'FAILED')),
+    storage_key VARCHAR(255),
+    size_bytes BIGINT,
+    failure_reason TEXT,
+    claimed_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_account_exports_user ON account_exports(user_id, created_at DESC);
+CREATE INDEX idx_account_exports_pending ON account_exports(created_at) WHERE status = 'PENDING';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_exports;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_or_phone;
+-- Fails while purged accounts exist
+ALTER TABLE users ADD CONSTRAINT users_email_or_phone CHECK (email IS NOT NULL OR phone IS NOT NULL);
+DROP INDEX IF EXISTS idx_users_deletion_due;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS purged_at,
+    DROP COLUMN IF EXISTS purge_claimed_at,
+    DROP COLUMN IF EXISTS deletion_scheduled_at;
+-- +goose StatementEnd
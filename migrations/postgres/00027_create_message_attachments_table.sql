@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Blobs uploaded for media messages. A message may only reference an
+-- attachment its sender uploaded to the same conversation, and account
+-- export and purge only touch blobs recorded here for the account.
+CREATE TABLE IF NOT EXISTS message_attachments (
+    storage_key VARCHAR(255) PRIMARY KEY,
+    uploader_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    conversation_id BIGINT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_message_attachments_uploader ON message_attachments(uploader_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS message_attachments;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pins of disappearing messages expire with the message, so they stop
+-- counting toward the per-conversation limit
+ALTER TABLE pinned_messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pinned_messages DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,129 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	// Formats accepted by Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+// Images larger than this are refused before they are decoded, so a small
+// file cannot expand into gigabytes of pixels
+const maxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+	ErrInvalidCrop       = errors.New("crop area is outside the image")
+)
+
+// Decode reads a JPEG, PNG or GIF image
+func Decode(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	return img, nil
+}
+
+// CropSquare cuts the square of side size whose top-left corner is (x, y),
+// relative to the image's own origin. A size of zero takes the largest
+// centred square.
+func CropSquare(img image.Image, x, y, size int) (image.Image, error) {
+	bounds := img.Bounds()
+
+	if size == 0 {
+		size = min(bounds.Dx(), bounds.Dy())
+		x = (bounds.Dx() - size) / 2
+		y = (bounds.Dy() - size) / 2
+	}
+
+	area := image.Rect(x, y, x+size, y+size).Add(bounds.Min)
+	if size <= 0 || x < 0 || y < 0 || !area.In(bounds) {
+		return nil, ErrInvalidCrop
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), img, area.Min, draw.Src)
+	return dst, nil
+}
+
+// Resize scales img to width x height. Each target pixel averages the source
+// pixels it covers, which keeps downscaled photos free of aliasing.
+func Resize(img image.Image, width, height int) image.Image {
+	src := toRGBA(img)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for dy := 0; dy < height; dy++ {
+		y0 := dy * sh / height
+		y1 := max((dy+1)*sh/height, y0+1)
+
+		for dx := 0; dx < width; dx++ {
+			x0 := dx * sw / width
+			x1 := max((dx+1)*sw/width, x0+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			offset := dst.PixOffset(dx, dy)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG writes img as a JPEG. Transparent areas become white, since
+// JPEG has no alpha channel.
+func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: quality})
+}
+
+// toRGBA returns img as an RGBA image whose bounds start at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,61 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// The SHA-1 secret of the RFC 6238 test vectors
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		if got := Code(rfcSecret, Step(time.Unix(tt.unix, 0))); got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: Code(rfcSecret, current), wantStep: current, wantOK: true},
+		{name: "previous step", code: Code(rfcSecret, current-1), wantStep: current - 1, wantOK: true},
+		{name: "next step", code: Code(rfcSecret, current+1), wantStep: current + 1, wantOK: true},
+		{name: "two steps behind", code: Code(rfcSecret, current-2)},
+		{name: "two steps ahead", code: Code(rfcSecret, current+2)},
+		{name: "spaces", code: "005 924", wantStep: current, wantOK: true},
+		{name: "wrong code", code: "123456"},
+		{name: "too short", code: "00592"},
+		{name: "too long", code: "0059240"},
+		{name: "empty", code: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("Validate(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"errors"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -20,3 +22,8 @@ func GetValidator() *validator.Validate {
 	return validate
 }
 
+// IsValidationError reports whether err, possibly wrapped, was returned by Validate
+func IsValidationError(err error) bool {
+	var validationErrors validator.ValidationErrors
+	return errors.As(err, &validationErrors)
+}